@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+	"github.com/svetob/terraform-provider-netcupdns/internal/export"
+)
+
+// Subcommands of the provider binary. Terraform starts the plugin without arguments,
+// so these never interfere with the plugin handshake.
+var commands = map[string]func(args []string, stdout, stderr io.Writer) int{
+	"export":               runExport,
+	"validate-credentials": runValidateCredentials,
+}
+
+// Credentials of the Netcup CCP API
+type credentials struct {
+	customerNumber string
+	apiKey         string
+	apiPassword    string
+}
+
+// Fill credentials not set otherwise from the standard NETCUP_* environment variables
+func (c *credentials) fromEnv() error {
+	if c.customerNumber == "" {
+		c.customerNumber = os.Getenv("NETCUP_CUSTOMER_NUMBER")
+	}
+	if c.apiKey == "" {
+		c.apiKey = os.Getenv("NETCUP_API_KEY")
+	}
+	if c.apiPassword == "" {
+		c.apiPassword = os.Getenv("NETCUP_API_PASSWORD")
+	}
+
+	var missing []string
+	if c.customerNumber == "" {
+		missing = append(missing, "NETCUP_CUSTOMER_NUMBER")
+	}
+	if c.apiKey == "" {
+		missing = append(missing, "NETCUP_API_KEY")
+	}
+	if c.apiPassword == "" {
+		missing = append(missing, "NETCUP_API_PASSWORD")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing environment variables: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Create a client from the standard NETCUP_* environment variables
+func clientFromEnv() (*client.CCPClient, error) {
+	var creds credentials
+	if err := creds.fromEnv(); err != nil {
+		return nil, err
+	}
+
+	return client.NewCCPClient(context.Background(), creds.customerNumber, creds.apiKey, creds.apiPassword, clientOptions()...)
+}
+
+// Options of the clients of the subcommands. Like for the provider,
+// NETCUP_API_ENDPOINT sends the requests to another endpoint than Netcup's.
+func clientOptions() []client.Option {
+	if endpoint := os.Getenv("NETCUP_API_ENDPOINT"); endpoint != "" {
+		return []client.Option{client.WithEndpoint(endpoint)}
+	}
+	return nil
+}
+
+func runExport(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	domain := flags.String("domain", "", "domain to export (required)")
+	types := flags.String("types", "", "comma separated record types to export, e.g. A,AAAA,MX (default all)")
+	forEach := flags.Bool("for-each", false, "emit a single for_each resource over a locals map")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *domain == "" {
+		fmt.Fprintln(stderr, "export: -domain is required")
+		flags.Usage()
+		return 2
+	}
+
+	c, err := clientFromEnv()
+	if err != nil {
+		fmt.Fprintln(stderr, "export: "+err.Error())
+		return 1
+	}
+
+	records, err := c.GetDnsRecords(context.Background(), *domain)
+	if err != nil {
+		fmt.Fprintln(stderr, "export: "+err.Error())
+		return 1
+	}
+
+	opts := export.Options{ForEach: *forEach}
+	if *types != "" {
+		opts.Types = strings.Split(*types, ",")
+	}
+
+	if err := export.Render(stdout, *domain, records, opts); err != nil {
+		fmt.Fprintln(stderr, "export: "+err.Error())
+		return 1
+	}
+	return 0
+}
+
+// Exit codes of validate-credentials
+const (
+	exitInvalidCredentials = 3
+	exitThrottled          = 4
+	exitUnreachable        = 5
+	exitAPINotActivated    = 6
+)
+
+func runValidateCredentials(args []string, stdout, stderr io.Writer) int {
+	var creds credentials
+	flags := flag.NewFlagSet("validate-credentials", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&creds.customerNumber, "customer-number", "", "Netcup customer number (default $NETCUP_CUSTOMER_NUMBER)")
+	flags.StringVar(&creds.apiKey, "api-key", "", "Netcup CCP API key (default $NETCUP_API_KEY)")
+	flags.StringVar(&creds.apiPassword, "api-password", "", "Netcup CCP API password (default $NETCUP_API_PASSWORD)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := creds.fromEnv(); err != nil {
+		fmt.Fprintln(stderr, "validate-credentials: "+err.Error())
+		return 2
+	}
+
+	// a preflight reports throttling or an unreachable endpoint right away instead of retrying
+	opts := append(clientOptions(), client.WithRetries(0, 0))
+	c, err := client.NewCCPClient(context.Background(), creds.customerNumber, creds.apiKey, creds.apiPassword, opts...)
+	if err != nil {
+		switch client.ClassifyLoginError(err) {
+		case client.LoginErrorCredentials:
+			fmt.Fprintln(stdout, "FAIL: credentials rejected for customer "+creds.customerNumber+": "+err.Error())
+			return exitInvalidCredentials
+		case client.LoginErrorAPINotActivated:
+			fmt.Fprintln(stdout, "FAIL: API access not activated for customer "+creds.customerNumber+": "+err.Error())
+			return exitAPINotActivated
+		case client.LoginErrorThrottled:
+			fmt.Fprintln(stdout, "FAIL: account locked or throttled, retry later: "+err.Error())
+			return exitThrottled
+		case client.LoginErrorUnreachable:
+			fmt.Fprintln(stdout, "FAIL: API endpoint unreachable: "+err.Error())
+			return exitUnreachable
+		default:
+			fmt.Fprintln(stdout, "FAIL: login failed: "+err.Error())
+			return 1
+		}
+	}
+
+	if err := c.Logout(context.Background()); err != nil {
+		fmt.Fprintln(stdout, "OK: credentials valid for customer "+creds.customerNumber+" (logout failed: "+err.Error()+")")
+		return 0
+	}
+
+	fmt.Fprintln(stdout, "OK: credentials valid for customer "+creds.customerNumber)
+	return 0
+}
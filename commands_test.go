@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of the tests")
+
+// Compare got with testdata/<name>, or write it there with -update
+func checkGolden(t *testing.T, name string, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file, create it with -update: %s", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s, run with -update if the change is intended:\n%s", path, got)
+	}
+}
+
+const (
+	testCustomerNumber = "12345"
+	testApiKey         = "k3y-0123456789"
+	testApiPassword    = "s3cret-password"
+)
+
+// Fake of the CCP API the subcommands are run against. Logins with the test
+// credentials succeed unless loginError is set.
+type apiServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	records    map[string][]client.DnsRecord
+	loginError *client.APIError
+	// answer with the HTML error page of a gateway instead of the API
+	unavailable bool
+	actions     []string
+}
+
+func newAPIServer(t *testing.T) *apiServer {
+	t.Helper()
+
+	s := &apiServer{records: make(map[string][]client.DnsRecord)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	t.Setenv("NETCUP_API_ENDPOINT", s.URL)
+	t.Setenv("NETCUP_CUSTOMER_NUMBER", testCustomerNumber)
+	t.Setenv("NETCUP_API_KEY", testApiKey)
+	t.Setenv("NETCUP_API_PASSWORD", testApiPassword)
+	return s
+}
+
+func (s *apiServer) serve(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Action string `json:"action"`
+		Param  struct {
+			client.LoginData
+			DomainName string `json:"domainname"`
+		} `json:"param"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actions = append(s.actions, request.Action)
+	if s.unavailable {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html><body><h1>502 Bad Gateway</h1></body></html>"))
+		return
+	}
+
+	response := map[string]interface{}{
+		"serverrequestid": "test",
+		"clientrequestid": "",
+		"action":          request.Action,
+		"status":          "success",
+		"statuscode":      2000,
+		"shortmessage":    "",
+		"longmessage":     "",
+		"responsedata":    "",
+	}
+	failure := func(apiErr client.APIError) {
+		response["status"] = "error"
+		response["statuscode"] = apiErr.StatusCode
+		response["shortmessage"] = apiErr.ShortMessage
+		response["longmessage"] = apiErr.LongMessage
+	}
+
+	switch request.Action {
+	case "login":
+		login := request.Param.LoginData
+		switch {
+		case s.loginError != nil:
+			failure(*s.loginError)
+		case login.CustomerNumber != testCustomerNumber || login.APIKey != testApiKey || login.APIPassword != testApiPassword:
+			failure(client.APIError{StatusCode: 4000, ShortMessage: "Login failed.", LongMessage: "The apikey or apipassword is invalid."})
+		default:
+			response["responsedata"] = map[string]string{"apisessionid": "session"}
+		}
+	case "logout":
+	case "infoDnsRecords":
+		records, ok := s.records[request.Param.DomainName]
+		if !ok {
+			failure(client.APIError{StatusCode: 5029, ShortMessage: "Can not get DNS records for zone.", LongMessage: "Domain not found."})
+			break
+		}
+		response["responsedata"] = client.DnsRecordSet{DnsRecords: records}
+	default:
+		failure(client.APIError{StatusCode: 4000, ShortMessage: "Unknown action " + request.Action})
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// Actions received by the server
+func (s *apiServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.actions...)
+}
+
+// Run a subcommand, returning its exit code, stdout and stderr
+func runCommand(name string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := commands[name](args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// Records of a zone with the cases the export has to handle: apex, wildcard,
+// repeated names, priorities, quotes and template sequences
+var exportRecords = []client.DnsRecord{
+	{Id: "101", Hostname: "www", Type: "A", Priority: "0", Destination: "192.0.2.2"},
+	{Id: "102", Hostname: "@", Type: "A", Priority: "0", Destination: "192.0.2.1"},
+	{Id: "103", Hostname: "www", Type: "A", Priority: "0", Destination: "192.0.2.3"},
+	{Id: "104", Hostname: "www", Type: "AAAA", Priority: "0", Destination: "2001:db8::1"},
+	{Id: "105", Hostname: "@", Type: "MX", Priority: "20", Destination: "backup.example.com"},
+	{Id: "106", Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+	{Id: "107", Hostname: "@", Type: "TXT", Priority: "0", Destination: `"v=spf1 mx -all"`},
+	{Id: "108", Hostname: "templated", Type: "TXT", Priority: "0", Destination: `${var} %{ if } back\slash`},
+	{Id: "109", Hostname: "*", Type: "CNAME", Priority: "0", Destination: "www.example.com."},
+	{Id: "110", Hostname: "_sip._tcp", Type: "SRV", Priority: "10", Destination: "5 5060 sip.example.com"},
+	{Id: "111", Hostname: "3com", Type: "A", Priority: "0", Destination: "192.0.2.4"},
+}
+
+func TestExportGolden(t *testing.T) {
+	server := newAPIServer(t)
+	server.records["example.com"] = exportRecords
+
+	tests := []struct {
+		golden string
+		args   []string
+	}{
+		{"export.golden", nil},
+		{"export_types.golden", []string{"-types", "mx, TXT"}},
+		{"export_for_each.golden", []string{"-for-each"}},
+		{"export_for_each_types.golden", []string{"-for-each", "-types", "A"}},
+	}
+	for _, tt := range tests {
+		code, stdout, stderr := runCommand("export", append([]string{"-domain", "example.com"}, tt.args...)...)
+		if code != 0 {
+			t.Fatalf("export %q exited with %d: %s", tt.args, code, stderr)
+		}
+		if stderr != "" {
+			t.Errorf("export %q wrote to stderr: %s", tt.args, stderr)
+		}
+		checkGolden(t, tt.golden, stdout)
+	}
+}
+
+func TestExportEmptyZone(t *testing.T) {
+	server := newAPIServer(t)
+	server.records["example.com"] = nil
+
+	code, stdout, stderr := runCommand("export", "-domain", "example.com")
+	if code != 0 || stdout != "" {
+		t.Errorf("export of an empty zone exited with %d and printed %q: %s", code, stdout, stderr)
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	server := newAPIServer(t)
+
+	code, stdout, stderr := runCommand("export")
+	if code != 2 || stdout != "" || !strings.Contains(stderr, "-domain is required") {
+		t.Errorf("export without -domain exited with %d, printed %q and %q", code, stdout, stderr)
+	}
+	if actions := server.received(); len(actions) != 0 {
+		t.Errorf("export without -domain sent %v", actions)
+	}
+
+	code, stdout, stderr = runCommand("export", "-domain", "missing.example")
+	if code != 1 || stdout != "" || !strings.Contains(stderr, "Domain not found") {
+		t.Errorf("export of an unknown domain exited with %d, printed %q and %q", code, stdout, stderr)
+	}
+
+	t.Setenv("NETCUP_API_PASSWORD", "")
+	code, _, stderr = runCommand("export", "-domain", "example.com")
+	if code != 1 || !strings.Contains(stderr, "NETCUP_API_PASSWORD") {
+		t.Errorf("export without password exited with %d: %s", code, stderr)
+	}
+}
+
+func TestValidateCredentials(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, server *apiServer)
+		args    []string
+		code    int
+		result  string
+		actions []string
+	}{
+		{
+			name:    "valid",
+			code:    0,
+			result:  "OK: credentials valid for customer 12345",
+			actions: []string{"login", "logout"},
+		},
+		{
+			name:    "credentials from flags",
+			setup:   func(t *testing.T, server *apiServer) { t.Setenv("NETCUP_API_PASSWORD", "wrong") },
+			args:    []string{"-api-password", testApiPassword},
+			code:    0,
+			result:  "OK: credentials valid for customer 12345",
+			actions: []string{"login", "logout"},
+		},
+		{
+			name:    "wrong credentials",
+			setup:   func(t *testing.T, server *apiServer) { t.Setenv("NETCUP_API_KEY", "wrong") },
+			code:    exitInvalidCredentials,
+			result:  "FAIL: credentials rejected for customer 12345",
+			actions: []string{"login"},
+		},
+		{
+			name: "throttled",
+			setup: func(t *testing.T, server *apiServer) {
+				server.loginError = &client.APIError{StatusCode: 4013, ShortMessage: "Validation Error.", LongMessage: "More than 180 requests per minute. Please wait and retry later."}
+			},
+			code:    exitThrottled,
+			result:  "FAIL: account locked or throttled, retry later",
+			actions: []string{"login"},
+		},
+		{
+			name: "locked",
+			setup: func(t *testing.T, server *apiServer) {
+				server.loginError = &client.APIError{StatusCode: 4000, ShortMessage: "Login failed.", LongMessage: "The account is locked after too many failed logins."}
+			},
+			code:    exitThrottled,
+			result:  "FAIL: account locked or throttled, retry later",
+			actions: []string{"login"},
+		},
+		{
+			name: "api not activated",
+			setup: func(t *testing.T, server *apiServer) {
+				server.loginError = &client.APIError{StatusCode: 4000, ShortMessage: "Login failed.", LongMessage: "API access is not activated for this customer."}
+			},
+			code:    exitAPINotActivated,
+			result:  "FAIL: API access not activated for customer 12345",
+			actions: []string{"login"},
+		},
+		{
+			name:    "gateway error",
+			setup:   func(t *testing.T, server *apiServer) { server.unavailable = true },
+			code:    exitUnreachable,
+			result:  "FAIL: API endpoint unreachable",
+			actions: []string{"login"},
+		},
+		{
+			name:   "endpoint unreachable",
+			setup:  func(t *testing.T, server *apiServer) { t.Setenv("NETCUP_API_ENDPOINT", closed.URL) },
+			code:   exitUnreachable,
+			result: "FAIL: API endpoint unreachable",
+		},
+		{
+			name: "unknown failure",
+			setup: func(t *testing.T, server *apiServer) {
+				server.loginError = &client.APIError{StatusCode: 5000, ShortMessage: "Internal error."}
+			},
+			code:    1,
+			result:  "FAIL: login failed",
+			actions: []string{"login"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newAPIServer(t)
+			if tt.setup != nil {
+				tt.setup(t, server)
+			}
+
+			code, stdout, stderr := runCommand("validate-credentials", tt.args...)
+			if code != tt.code {
+				t.Errorf("exited with %d, want %d: %s %s", code, tt.code, stdout, stderr)
+			}
+			if lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n"); len(lines) != 1 || !strings.HasPrefix(lines[0], tt.result) {
+				t.Errorf("printed %q, want a single line starting with %q", stdout, tt.result)
+			}
+			for _, secret := range []string{testApiPassword, testApiKey, "wrong"} {
+				if strings.Contains(stdout+stderr, secret) {
+					t.Errorf("output %q contains the secret %q", stdout+stderr, secret)
+				}
+			}
+			if actions := server.received(); strings.Join(actions, ",") != strings.Join(tt.actions, ",") {
+				t.Errorf("sent %v, want %v", actions, tt.actions)
+			}
+		})
+	}
+}
+
+func TestValidateCredentialsUsage(t *testing.T) {
+	server := newAPIServer(t)
+	t.Setenv("NETCUP_CUSTOMER_NUMBER", "")
+
+	code, stdout, stderr := runCommand("validate-credentials")
+	if code != 2 || stdout != "" || !strings.Contains(stderr, "NETCUP_CUSTOMER_NUMBER") {
+		t.Errorf("exited with %d, printed %q and %q, want usage error 2 naming the missing variable", code, stdout, stderr)
+	}
+	code, _, _ = runCommand("validate-credentials", "-unknown")
+	if code != 2 {
+		t.Errorf("unknown flag exited with %d, want 2", code)
+	}
+	if actions := server.received(); len(actions) != 0 {
+		t.Errorf("sent %v without complete credentials", actions)
+	}
+}
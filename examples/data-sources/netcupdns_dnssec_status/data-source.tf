@@ -0,0 +1,10 @@
+data "netcupdns_dnssec_status" "example" {
+  domainname = "example.com"
+}
+
+check "dnssec" {
+  assert {
+    condition     = data.netcupdns_dnssec_status.example.enabled
+    error_message = "DNSSEC must be enabled for example.com"
+  }
+}
@@ -0,0 +1,13 @@
+data "netcupdns_effective_record" "www" {
+  domainname = "example.com"
+  hostname   = "www"
+  type       = "A"
+}
+
+output "www_chain" {
+  value = join(" -> ", data.netcupdns_effective_record.www.chain)
+}
+
+output "www_ips" {
+  value = data.netcupdns_effective_record.www.destinations
+}
@@ -0,0 +1,7 @@
+data "netcupdns_mx_records" "example" {
+  domainname = "example.com"
+}
+
+output "primary_mx" {
+  value = try(data.netcupdns_mx_records.example.records[0].destination, null)
+}
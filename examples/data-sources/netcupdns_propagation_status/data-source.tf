@@ -0,0 +1,14 @@
+data "netcupdns_propagation_status" "www" {
+  name                  = "www.example.com"
+  type                  = "A"
+  expected              = ["1.2.3.4"]
+  resolvers             = ["1.1.1.1", "8.8.8.8", "9.9.9.9"]
+  consistency_threshold = 2
+}
+
+check "www_propagated" {
+  assert {
+    condition     = data.netcupdns_propagation_status.www.consistent
+    error_message = "Only ${data.netcupdns_propagation_status.www.percentage}% of the resolvers serve the new A record of www.example.com"
+  }
+}
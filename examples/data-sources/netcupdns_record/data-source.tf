@@ -0,0 +1,9 @@
+data "netcupdns_record" "apex" {
+  domainname = "example.com"
+  hostname   = "@"
+  type       = "A"
+}
+
+output "apex_ip" {
+  value = data.netcupdns_record.apex.destination
+}
@@ -0,0 +1,18 @@
+data "netcupdns_record_exists" "docs" {
+  domainname = "example.com"
+  hostname   = "docs"
+}
+
+resource "netcupdns_record" "docs" {
+  domainname  = "example.com"
+  hostname    = "docs"
+  type        = "CNAME"
+  destination = "example.github.io."
+
+  lifecycle {
+    precondition {
+      condition     = !data.netcupdns_record_exists.docs.exists
+      error_message = "A record already exists at docs.example.com"
+    }
+  }
+}
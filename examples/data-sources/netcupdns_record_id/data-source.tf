@@ -0,0 +1,21 @@
+locals {
+  existing = {
+    www  = { hostname = "www", type = "A" }
+    mail = { hostname = "@", type = "MX" }
+  }
+}
+
+data "netcupdns_record_id" "existing" {
+  for_each = local.existing
+
+  domainname = "example.com"
+  hostname   = each.value.hostname
+  type       = each.value.type
+}
+
+import {
+  for_each = local.existing
+
+  to = netcupdns_record.existing[each.key]
+  id = data.netcupdns_record_id.existing[each.key].import_id
+}
@@ -0,0 +1,9 @@
+data "netcupdns_record_set" "www" {
+  domainname = "example.com"
+  hostname   = "www"
+  type       = "A"
+}
+
+output "www_ips" {
+  value = data.netcupdns_record_set.www.destinations
+}
@@ -0,0 +1,27 @@
+data "netcupdns_records" "a_records" {
+  domainname = "example.com"
+  type       = "A"
+}
+
+data "netcupdns_records" "services" {
+  domainname     = "example.com"
+  hostname_regex = "^_.*\\._tcp$"
+}
+
+data "netcupdns_records" "zone" {
+  domainname = "example.com"
+}
+
+output "www_ip" {
+  value = data.netcupdns_records.zone.records_by_key["www/A"].destination
+}
+
+data "netcupdns_records" "mail" {
+  domainnames   = ["example.com", "example.org"]
+  type          = "MX"
+  fail_on_error = false
+}
+
+output "mx_by_domain" {
+  value = { for r in data.netcupdns_records.mail.records : r.domainname => r.destination... }
+}
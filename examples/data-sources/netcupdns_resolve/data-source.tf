@@ -0,0 +1,12 @@
+data "netcupdns_resolve" "www" {
+  name       = "www.example.com"
+  type       = "A"
+  domainname = "example.com"
+}
+
+check "www_served" {
+  assert {
+    condition     = data.netcupdns_resolve.www.matches_api
+    error_message = "The nameservers don't serve the A records of www.example.com yet"
+  }
+}
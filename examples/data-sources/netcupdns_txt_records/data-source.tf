@@ -0,0 +1,11 @@
+data "netcupdns_txt_records" "apex" {
+  domainname = "example.com"
+  hostname   = "@"
+}
+
+check "spf" {
+  assert {
+    condition     = contains(data.netcupdns_txt_records.apex.values, "v=spf1 include:_spf.google.com ~all")
+    error_message = "The SPF record of example.com is missing"
+  }
+}
@@ -0,0 +1,7 @@
+data "netcupdns_zone" "example" {
+  domainname = "example.com"
+}
+
+output "zone_ttl" {
+  value = data.netcupdns_zone.example.ttl
+}
@@ -0,0 +1,9 @@
+data "netcupdns_zone_file" "backup" {
+  domainname    = "example.com"
+  exclude_types = ["DS"]
+}
+
+resource "local_file" "backup" {
+  filename = "example.com.zone"
+  content  = data.netcupdns_zone_file.backup.content
+}
@@ -0,0 +1,17 @@
+data "netcupdns_zone_serial" "example" {
+  domainname = "example.com"
+}
+
+resource "terraform_data" "zone_changed" {
+  input = data.netcupdns_zone_serial.example.serial
+}
+
+resource "terraform_data" "sync_secondary" {
+  lifecycle {
+    replace_triggered_by = [terraform_data.zone_changed]
+  }
+
+  provisioner "local-exec" {
+    command = "./sync-secondary.sh example.com"
+  }
+}
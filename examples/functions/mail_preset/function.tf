@@ -0,0 +1,20 @@
+locals {
+  mail = provider::netcupdns::mail_preset("microsoft365", "example.com")
+}
+
+resource "netcupdns_record" "mx" {
+  for_each = { for mx in local.mail.mx : mx.destination => mx }
+
+  domainname  = "example.com"
+  hostname    = "@"
+  type        = "MX"
+  priority    = tostring(each.value.priority)
+  destination = each.value.destination
+}
+
+resource "netcupdns_record" "spf" {
+  domainname  = "example.com"
+  hostname    = "@"
+  type        = "TXT"
+  destination = local.mail.spf
+}
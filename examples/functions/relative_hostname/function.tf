@@ -0,0 +1,10 @@
+locals {
+  fqdn = "grafana.ops.example.com"
+}
+
+resource "netcupdns_record" "grafana" {
+  domainname  = "example.com"
+  hostname    = provider::netcupdns::relative_hostname(local.fqdn, "example.com")
+  type        = "CNAME"
+  destination = "ingress.example.com."
+}
@@ -0,0 +1,6 @@
+resource "netcupdns_autoconfig_mail" "mail" {
+  domainname     = "example.com"
+  mail_host      = "mail.example.com"
+  imaps_srv      = true
+  submission_srv = true
+}
@@ -0,0 +1,4 @@
+resource "netcupdns_dnssec" "example" {
+  domainname = "example.com"
+  enabled    = true
+}
@@ -0,0 +1,5 @@
+# Import by domainname and Netcup record id
+terraform import netcupdns_record.root example.com/123456
+
+# Import by domainname, hostname, type and destination
+terraform import netcupdns_record.root example.com/@/A/1.2.3.4
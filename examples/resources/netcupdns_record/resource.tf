@@ -11,4 +11,21 @@ resource "netcupdns_record" "mail" {
   hostname    = "@"
   type        = "MX"
   priority    = "5"
-}
\ No newline at end of file
+}
+
+resource "netcupdns_record" "appliance" {
+  destination   = "192.0.2.10"
+  domainname    = "example.com"
+  hostname      = "vpn"
+  type          = "A"
+  update_policy = "create_only"
+  comment       = "Rewritten by the VPN appliance after setup"
+}
+
+resource "netcupdns_record" "adopted" {
+  destination = "192.0.2.20"
+  domainname  = "example.com"
+  hostname    = "legacy"
+  type        = "A"
+  record_id   = "123456"
+}
@@ -0,0 +1,5 @@
+# Import by domainname and the comma separated ids of the records
+terraform import netcupdns_record_set.example example.com/12,13,14,15
+
+# Import every record of the zone by domainname alone
+terraform import netcupdns_record_set.example example.com
@@ -0,0 +1,10 @@
+resource "netcupdns_record_set" "example" {
+  domainname = "example.com"
+
+  records = [
+    { hostname = "@", type = "A", destination = "203.0.113.10" },
+    { hostname = "www", type = "CNAME", destination = "example.com." },
+    { hostname = "@", type = "MX", priority = "10", destination = "mail.example.com." },
+    { hostname = "@", type = "TXT", destination = "v=spf1 mx -all" },
+  ]
+}
@@ -0,0 +1,5 @@
+# Import by domainname, service and protocol
+terraform import netcupdns_srv_set.sip example.com/sip/udp
+
+# Import the records of a service offered for a name inside the zone, here _sip._udp.office
+terraform import netcupdns_srv_set.sip example.com/sip/udp/office
@@ -0,0 +1,11 @@
+resource "netcupdns_srv_set" "sip" {
+  domainname = "example.com"
+  service    = "sip"
+  protocol   = "udp"
+
+  targets = [
+    { priority = 10, weight = 60, port = 5060, target = "sip1.example.com" },
+    { priority = 10, weight = 40, port = 5060, target = "sip2.example.com" },
+    { priority = 20, weight = 0, port = 5060, target = "sip-backup.example.com" },
+  ]
+}
@@ -0,0 +1,2 @@
+# Import by domainname
+terraform import netcupdns_zone.example example.com
@@ -0,0 +1,4 @@
+resource "netcupdns_zone" "example" {
+  domainname = "example.com"
+  ttl        = 300
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Delays between attempts, growing by factor up to max. With jitter the delays
+// of parallel callers are spread between half and the full delay.
+type backoff struct {
+	initial time.Duration
+	max     time.Duration
+	factor  float64
+	jitter  bool
+}
+
+var defaultBackoff = backoff{
+	initial: 2 * time.Second,
+	max:     30 * time.Second,
+	factor:  2,
+}
+
+func (b backoff) next(delay time.Duration) time.Duration {
+	delay = time.Duration(float64(delay) * b.factor)
+	if delay > b.max {
+		return b.max
+	}
+	return delay
+}
+
+func (b backoff) wait(delay time.Duration) time.Duration {
+	half := delay / 2
+	if !b.jitter || half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryUntil calls attempt until it reports done, returns an error or ctx expires
+func retryUntil(ctx context.Context, b backoff, attempt func() (bool, error)) error {
+	return newRetries(b).until(ctx, func(*retries) (bool, error) {
+		return attempt()
+	})
+}
+
+// State of a retried operation. Attempts may switch to another backoff, e.g.
+// for failures that last longer.
+type retries struct {
+	backoff backoff
+	delay   time.Duration
+	// number of the running attempt, counting from 1
+	attempt int
+}
+
+func newRetries(b backoff) *retries {
+	return &retries{backoff: b, delay: b.initial}
+}
+
+// Continue with another backoff, starting from its initial delay
+func (r *retries) use(b backoff) {
+	r.backoff = b
+	r.delay = b.initial
+}
+
+// Like retryUntil, with access to the state of the retries
+func (r *retries) until(ctx context.Context, attempt func(*retries) (bool, error)) error {
+	for {
+		r.attempt++
+		done, err := attempt(r)
+		if err != nil || done {
+			return err
+		}
+
+		if err := sleep(ctx, r.backoff.wait(r.delay)); err != nil {
+			return err
+		}
+		r.delay = r.backoff.next(r.delay)
+	}
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+)
+
+// Records of a domain as cached by the client, indexed by id and by hostname/type
+type zoneRecords struct {
+	records []DnsRecord
+	byId    map[string]int
+	byName  map[string][]int
+}
+
+func newZoneRecords(records []DnsRecord) *zoneRecords {
+	z := &zoneRecords{
+		records: records,
+		byId:    make(map[string]int, len(records)),
+		byName:  make(map[string][]int),
+	}
+	for i, record := range records {
+		z.byId[record.Id] = i
+		key := recordKey(record.Hostname, record.Type)
+		z.byName[key] = append(z.byName[key], i)
+	}
+	return z
+}
+
+func (z *zoneRecords) findById(id string) (*DnsRecord, bool) {
+	i, ok := z.byId[id]
+	if !ok {
+		return nil, false
+	}
+	record := z.records[i]
+	return &record, true
+}
+
+// Record of an id in records without indexing them, for a single lookup in a
+// response. Indexing costs more than a scan unless the records are looked up repeatedly.
+func findRecordById(records []DnsRecord, id string) (*DnsRecord, bool) {
+	for _, record := range records {
+		if record.Id == id {
+			return &record, true
+		}
+	}
+	return nil, false
+}
+
+// Records sharing hostname and type, in API order
+func (z *zoneRecords) findByName(hostname, recordType string) []DnsRecord {
+	indexes := z.byName[recordKey(hostname, recordType)]
+	records := make([]DnsRecord, 0, len(indexes))
+	for _, i := range indexes {
+		records = append(records, z.records[i])
+	}
+	return records
+}
+
+// Hostnames are case-insensitive, types are compared in their canonical uppercase form
+func recordKey(hostname, recordType string) string {
+	return strings.ToLower(hostname) + "/" + strings.ToUpper(recordType)
+}
+
+// Number of domains whose records are cached by default
+const DefaultRecordCacheSize = 100
+
+// Records of the most recently used domains. Least recently used domains are
+// evicted once more than size domains are cached and are fetched again on use.
+// Safe for concurrent use.
+type recordCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type recordCacheEntry struct {
+	domainName string
+	records    *zoneRecords
+}
+
+func newRecordCache(size int) *recordCache {
+	return &recordCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *recordCache) get(domainName string) (*zoneRecords, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[domainName]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*recordCacheEntry).records, true
+}
+
+func (c *recordCache) put(domainName string, records *zoneRecords) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[domainName]; ok {
+		element.Value.(*recordCacheEntry).records = records
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[domainName] = c.order.PushFront(&recordCacheEntry{domainName: domainName, records: records})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*recordCacheEntry).domainName)
+	}
+}
+
+// Number of domains the cache holds
+func (c *recordCache) capacity() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+// Raise the number of domains the cache holds to size, smaller sizes are ignored
+func (c *recordCache) grow(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if size > c.size {
+		c.size = size
+	}
+}
+
+// Drop the records of a domain, e.g. after writing to it
+func (c *recordCache) invalidate(domainName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[domainName]; ok {
+		c.order.Remove(element)
+		delete(c.entries, domainName)
+	}
+}
+
+// Settings of the zones read during a run. Safe for concurrent use.
+type zoneCache struct {
+	mu    sync.RWMutex
+	zones map[string]DnsZone
+}
+
+func newZoneCache() *zoneCache {
+	return &zoneCache{zones: make(map[string]DnsZone)}
+}
+
+func (c *zoneCache) get(domainName string) (DnsZone, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	zone, ok := c.zones[domainName]
+	return zone, ok
+}
+
+func (c *zoneCache) put(domainName string, zone DnsZone) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.zones[domainName] = zone
+}
+
+func (c *zoneCache) invalidate(domainName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.zones, domainName)
+}
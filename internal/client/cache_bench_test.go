@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+// Zone sizes of the benchmarks, up to the largest zones seen at Netcup customers
+var benchmarkZoneSizes = []int{100, 1000, 5000}
+
+// Records of a zone with n records, ids counting from 1
+func benchmarkRecords(n int) []DnsRecord {
+	records := benchmarkSeed(n)
+	for i := range records {
+		records[i].Id = strconv.Itoa(i + 1)
+		records[i].State = "yes"
+	}
+	return records
+}
+
+// Records to seed a zone with n records, which get the ids of benchmarkRecords
+func benchmarkSeed(n int) []DnsRecord {
+	records := make([]DnsRecord, 0, n)
+	for i := 1; i <= n; i++ {
+		records = append(records, DnsRecord{
+			Hostname:    fmt.Sprintf("host%d", i%(n/4+1)),
+			Type:        []string{"A", "AAAA", "TXT", "CNAME"}[i%4],
+			Priority:    "0",
+			Destination: fmt.Sprintf("destination%d", i),
+		})
+	}
+	return records
+}
+
+// Id of the i-th lookup, spread over a zone of n records
+func benchmarkId(i, n int) string {
+	return strconv.Itoa(i*7919%n + 1)
+}
+
+// Matching created records by rescanning the response for each of them, as
+// done before the match index
+func scanNewRecords(domainName string, before, after []DnsRecord, requested []NewDnsRecord) []DnsRecord {
+	existing := make(map[string]bool, len(before))
+	for _, record := range before {
+		existing[record.Id] = true
+	}
+	matched := make([]DnsRecord, 0, len(requested))
+	for _, record := range requested {
+		for _, candidate := range after {
+			if !existing[candidate.Id] && record.Matches(candidate, domainName) {
+				matched = append(matched, candidate)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// Refreshes of every resource look up their record by id in the cached zone
+func BenchmarkGetDnsRecordById(b *testing.B) {
+	for _, n := range benchmarkZoneSizes {
+		records := benchmarkRecords(n)
+
+		b.Run(fmt.Sprintf("records=%d/indexed", n), func(b *testing.B) {
+			c, transport := newTestClient(b)
+			seedRecords(b, transport, "example.com", benchmarkSeed(n)...)
+			ctx := context.Background()
+			if _, err := c.GetDnsRecords(ctx, "example.com"); err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.GetDnsRecordById(ctx, "example.com", benchmarkId(i, n)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		// the lookup without index, as before the cache was indexed
+		b.Run(fmt.Sprintf("records=%d/scan", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, ok := findRecordById(records, benchmarkId(i, n)); !ok {
+					b.Fatal("record not found")
+				}
+			}
+		})
+	}
+}
+
+// Lookups by hostname and type, e.g. of the data sources and duplicate checks
+func BenchmarkGetDnsRecordsFiltered(b *testing.B) {
+	for _, n := range benchmarkZoneSizes {
+		b.Run(fmt.Sprintf("records=%d", n), func(b *testing.B) {
+			c, transport := newTestClient(b)
+			seedRecords(b, transport, "example.com", benchmarkSeed(n)...)
+			ctx := context.Background()
+			if _, err := c.GetDnsRecords(ctx, "example.com"); err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				filter := RecordFilter{Hostname: fmt.Sprintf("host%d", i%(n/4+1)), Type: "A"}
+				if _, err := c.GetDnsRecordsFiltered(ctx, "example.com", filter); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// Telling 20 created records apart in the response listing the whole zone
+func BenchmarkMatchNewRecords(b *testing.B) {
+	const created = 20
+	for _, n := range benchmarkZoneSizes {
+		before := benchmarkRecords(n)
+		after := append([]DnsRecord(nil), before...)
+		requested := make([]NewDnsRecord, 0, created)
+		for i := 0; i < created; i++ {
+			record := NewDnsRecord{Hostname: fmt.Sprintf("new%d", i), Type: "A", Destination: "192.0.2.1"}
+			requested = append(requested, record)
+			after = append(after, DnsRecord{Id: strconv.Itoa(n + i + 1), Hostname: record.Hostname, Type: record.Type, Priority: "0", Destination: record.Destination, State: "yes"})
+		}
+
+		b.Run(fmt.Sprintf("records=%d/indexed", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := matchNewRecords("example.com", before, after, requested); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("records=%d/scan", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if matched := scanNewRecords("example.com", before, after, requested); len(matched) != created {
+					b.Fatalf("matched %d records, want %d", len(matched), created)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// Check that the indexes of zone point at exactly the records of its slice
+func checkIndex(t *testing.T, zone *zoneRecords) {
+	t.Helper()
+
+	if len(zone.byId) != len(zone.records) {
+		t.Errorf("id index has %d entries for %d records", len(zone.byId), len(zone.records))
+	}
+	named := 0
+	for key, indexes := range zone.byName {
+		named += len(indexes)
+		for _, i := range indexes {
+			record := zone.records[i]
+			if recordKey(record.Hostname, record.Type) != key {
+				t.Errorf("record %s indexed under %s", record.Id, key)
+			}
+		}
+	}
+	if named != len(zone.records) {
+		t.Errorf("name index has %d entries for %d records", named, len(zone.records))
+	}
+	for _, record := range zone.records {
+		found, ok := zone.findById(record.Id)
+		if !ok || !reflect.DeepEqual(*found, record) {
+			t.Errorf("id %s found as %v, want %v", record.Id, found, record)
+		}
+	}
+}
+
+// The cached zone of domainName, failing if there is none
+func cachedZone(t *testing.T, c *CCPClient, domainName string) *zoneRecords {
+	t.Helper()
+
+	zone, ok := c.dnsRecordsByDomain.get(domainName)
+	if !ok {
+		t.Fatalf("records of %s are not cached", domainName)
+	}
+	return zone
+}
+
+func TestZoneRecordsIndex(t *testing.T) {
+	zone := newZoneRecords([]DnsRecord{
+		{Id: "1", Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+		{Id: "2", Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+		{Id: "3", Hostname: "WWW", Type: "a", Destination: "192.0.2.2"},
+		{Id: "4", Hostname: "www", Type: "AAAA", Destination: "2001:db8::1"},
+	})
+	checkIndex(t, zone)
+
+	if _, ok := zone.findById("5"); ok {
+		t.Error("found a record of an unknown id")
+	}
+	var ids []string
+	for _, record := range zone.findByName("Www", "A") {
+		ids = append(ids, record.Id)
+	}
+	if !reflect.DeepEqual(ids, []string{"1", "3"}) {
+		t.Errorf("found ids %v for www A, want 1 and 3 in API order", ids)
+	}
+	if records := zone.findByName("mail", "MX"); len(records) != 0 {
+		t.Errorf("found %v for a name without records", records)
+	}
+
+	// found records are copies, changing them leaves the cache alone
+	found, _ := zone.findById("1")
+	found.Destination = "192.0.2.9"
+	if again, _ := zone.findById("1"); again.Destination != "192.0.2.1" {
+		t.Errorf("changing a found record changed the cache to %s", again.Destination)
+	}
+	zone.findByName("www", "A")[0].Destination = "192.0.2.9"
+	if again, _ := zone.findById("1"); again.Destination != "192.0.2.1" {
+		t.Errorf("changing a record found by name changed the cache to %s", again.Destination)
+	}
+}
+
+// Writes invalidate the cached zone, whose indexes are rebuilt with the next read
+func TestRecordIndexFollowsWrites(t *testing.T) {
+	c, transport := newTestClient(t)
+	ctx := context.Background()
+	seeded := seedRecords(t, transport, "example.com",
+		DnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+		DnsRecord{Hostname: "mail", Type: "A", Destination: "192.0.2.2"},
+	)
+
+	read := func() *zoneRecords {
+		t.Helper()
+		if _, err := c.GetDnsRecords(ctx, "example.com"); err != nil {
+			t.Fatal(err)
+		}
+		zone := cachedZone(t, c, "example.com")
+		checkIndex(t, zone)
+		return zone
+	}
+	read()
+
+	created, err := c.CreateDnsRecord(ctx, "example.com", NewDnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.dnsRecordsByDomain.get("example.com"); ok {
+		t.Error("records still cached after a create")
+	}
+	zone := read()
+	if _, ok := zone.findById(created.Id); !ok {
+		t.Errorf("created record %s not indexed", created.Id)
+	}
+	if n := len(zone.findByName("www", "A")); n != 2 {
+		t.Errorf("found %d records for www A after the create, want 2", n)
+	}
+
+	update := seeded[0]
+	update.Hostname = "web"
+	if _, err := c.UpdateDnsRecord(ctx, "example.com", update); err != nil {
+		t.Fatal(err)
+	}
+	zone = read()
+	if found, _ := zone.findById(update.Id); found == nil || found.Hostname != "web" {
+		t.Errorf("updated record indexed as %v", found)
+	}
+	if n := len(zone.findByName("www", "A")); n != 1 {
+		t.Errorf("found %d records for www A after renaming one, want 1", n)
+	}
+	if n := len(zone.findByName("web", "A")); n != 1 {
+		t.Errorf("found %d records for web A after the rename, want 1", n)
+	}
+
+	if err := c.DeleteDnsRecord(ctx, "example.com", seeded[1]); err != nil {
+		t.Fatal(err)
+	}
+	zone = read()
+	if _, ok := zone.findById(seeded[1].Id); ok {
+		t.Error("deleted record still indexed by id")
+	}
+	if records := zone.findByName("mail", "A"); len(records) != 0 {
+		t.Errorf("deleted record still indexed by name: %v", records)
+	}
+	if _, err := c.GetDnsRecordById(ctx, "example.com", seeded[1].Id); err == nil {
+		t.Error("GetDnsRecordById found the deleted record")
+	}
+}
+
+// Domains of a record cache from the most to the least recently used
+func cachedDomains(c *recordCache) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var domains []string
+	for element := c.order.Front(); element != nil; element = element.Next() {
+		domains = append(domains, element.Value.(*recordCacheEntry).domainName)
+	}
+	return domains
+}
+
+func TestRecordCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newRecordCache(3)
+	for _, domain := range []string{"a.example", "b.example", "c.example"} {
+		cache.put(domain, newZoneRecords(nil))
+	}
+
+	steps := []struct {
+		name  string
+		apply func()
+		want  []string
+	}{
+		{"get moves to front", func() { cache.get("a.example") }, []string{"a.example", "c.example", "b.example"}},
+		{"put evicts the least recently used", func() { cache.put("d.example", newZoneRecords(nil)) }, []string{"d.example", "a.example", "c.example"}},
+		{"put of a cached domain moves to front", func() { cache.put("c.example", newZoneRecords(nil)) }, []string{"c.example", "d.example", "a.example"}},
+		{"missed get changes nothing", func() { cache.get("b.example") }, []string{"c.example", "d.example", "a.example"}},
+		{"invalidate frees a slot", func() { cache.invalidate("d.example") }, []string{"c.example", "a.example"}},
+		{"put after invalidate evicts nothing", func() { cache.put("e.example", newZoneRecords(nil)) }, []string{"e.example", "c.example", "a.example"}},
+		{"grow keeps the cached domains", func() { cache.grow(4); cache.put("f.example", newZoneRecords(nil)) }, []string{"f.example", "e.example", "c.example", "a.example"}},
+		{"grow never shrinks", func() { cache.grow(2); cache.get("a.example") }, []string{"a.example", "f.example", "e.example", "c.example"}},
+	}
+	for _, step := range steps {
+		step.apply()
+		if got := cachedDomains(cache); !reflect.DeepEqual(got, step.want) {
+			t.Fatalf("%s: cached %v, want %v", step.name, got, step.want)
+		}
+		if len(cache.entries) != cache.order.Len() {
+			t.Fatalf("%s: %d entries for %d domains", step.name, len(cache.entries), cache.order.Len())
+		}
+	}
+}
+
+func TestEvictedDomainFetchedAgain(t *testing.T) {
+	c, transport := newTestClient(t, WithRecordCacheSize(2))
+	ctx := context.Background()
+	for _, domain := range []string{"one.example", "two.example", "three.example"} {
+		seedRecords(t, transport, domain, DnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"})
+	}
+
+	read := func(domain string, wantRequests int) {
+		t.Helper()
+		records, err := c.GetDnsRecords(ctx, domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 || records[0].Hostname != "www" {
+			t.Errorf("read %v from %s, want its record", records, domain)
+		}
+		if got := transport.count("infoDnsRecords"); got != wantRequests {
+			t.Errorf("reading %s: %d requests in total, want %d", domain, got, wantRequests)
+		}
+	}
+
+	read("one.example", 1)
+	read("two.example", 2)
+	read("one.example", 2)
+	// evicts two.example, the least recently used
+	read("three.example", 3)
+	read("one.example", 3)
+	read("two.example", 4)
+	if got, want := cachedDomains(c.dnsRecordsByDomain), []string{"two.example", "one.example"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cached %v, want %v", got, want)
+	}
+
+	// writes still invalidate cached domains and leave the others alone
+	if _, err := c.CreateDnsRecord(ctx, "one.example", NewDnsRecord{Hostname: "mail", Type: "A", Destination: "192.0.2.2"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cachedDomains(c.dnsRecordsByDomain), []string{"two.example"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cached %v after the create, want %v", got, want)
+	}
+	records, err := c.GetDnsRecords(ctx, "one.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Errorf("read %d records of one.example after the create, want 2", len(records))
+	}
+}
+
+func TestRecordCacheSizeOption(t *testing.T) {
+	if c := newClient(); c.dnsRecordsByDomain.capacity() != DefaultRecordCacheSize {
+		t.Errorf("default capacity %d, want %d", c.dnsRecordsByDomain.capacity(), DefaultRecordCacheSize)
+	}
+	if c := newClient(WithRecordCacheSize(0)); c.dnsRecordsByDomain.capacity() != DefaultRecordCacheSize {
+		t.Errorf("capacity %d for size 0, want the default %d", c.dnsRecordsByDomain.capacity(), DefaultRecordCacheSize)
+	}
+	if c := newClient(WithRecordCacheSize(400)); c.dnsRecordsByDomain.capacity() != 400 {
+		t.Errorf("capacity %d, want 400", c.dnsRecordsByDomain.capacity())
+	}
+}
+
+// Configurations sharing a session share the cache, which holds as many
+// domains as the largest of them configured
+func TestSharedRecordCacheGrows(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry()
+	defer registry.Close(ctx)
+	transport := newTestTransport()
+
+	first, err := registry.Acquire(ctx, "12345", "key", "password", WithMemoryBackend(), withTransport(transport), WithRecordCacheSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := registry.Acquire(ctx, "12345", "key", "password", WithMemoryBackend(), withTransport(transport), WithRecordCacheSize(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.dnsRecordsByDomain != second.dnsRecordsByDomain {
+		t.Fatal("configurations with the same credentials don't share the record cache")
+	}
+	if capacity := first.dnsRecordsByDomain.capacity(); capacity != 5 {
+		t.Errorf("shared cache holds %d domains, want 5", capacity)
+	}
+}
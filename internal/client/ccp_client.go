@@ -1,23 +1,44 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 )
 
 const HostURL string = "https://ccp.netcup.net/run/webservice/servers/endpoint.php?JSON"
 
+// Number of requests sent at the same time by default
+const DefaultMaxConcurrentRequests = 4
+
+// Time a single request may take by default, including reading the response
+const DefaultRequestTimeout = 10 * time.Second
+
 type CCPClient struct {
-	hostURL    string
-	httpClient http.Client
-	authData   AuthData
-	UserAgent  string
-	DnsRecordsByDomain map[string][]DnsRecord
+	hostURL            string
+	httpClient         http.Client
+	session            *session
+	UserAgent          string
+	dnsRecordsByDomain *recordCache
+	dnsZonesByDomain   *zoneCache
+	domains            *domainLocks
+	requests           *requestCounter
+	rateLimitWarning   rateLimitWarning
+	dumpDir            string
+	zoneUpdateWait     zoneUpdateWait
+	dnssecNotice       dnssecNotice
+	planned            plannedRecords
+	dnssecOwners       dnssecOwners
+	refresh            refreshSettings
+	retry              retryPolicy
+	strict             strictDecoding
+	limiter            chan struct{}
 }
 
 type AuthData struct {
@@ -39,6 +60,7 @@ type RequestBody struct {
 
 type ResponseBody struct {
 	ServerRequestId string `json:"serverrequestid"`
+	ClientRequestId string `json:"clientrequestid"`
 	Action          string `json:"action"`
 	Status          string `json:"status"`     // Status of the Message like "error", "started", "pending", "warning" or "success".
 	StatusCode      int    `json:"statuscode"` // Status code of the Message like 2011.
@@ -50,21 +72,11 @@ type SessionData struct {
 	SessionId string `json:"apisessionid"`
 }
 
-type LoginResponse struct {
-	ResponseBody
-	ResponseData SessionData `json:"responsedata"`
-}
-
 type DomainInfoRequest struct {
 	AuthData
 	DomainName string `json:"domainname"`
 }
 
-type DnsZoneResponse struct {
-	ResponseBody
-	ResponseData DnsZone `json:"responsedata"`
-}
-
 type CreateDnsRecordsRequest struct {
 	DomainInfoRequest
 	DnsRecordSet NewDnsRecordSet `json:"dnsrecordset"`
@@ -75,48 +87,97 @@ type UpdateDnsRecordsRequest struct {
 	DnsRecordSet DnsRecordSet `json:"dnsrecordset"`
 }
 
-type DnsRecordsResponse struct {
-	ResponseBody
-	ResponseData DnsRecordSet `json:"responsedata"`
+type UpdateDnsZoneRequest struct {
+	DomainInfoRequest
+	DnsZone DnsZone `json:"dnszone"`
 }
 
-func NewCCPClient(customerNumber, apiKey, apiPassword string) (*CCPClient, error) {
-	c := CCPClient{
-		hostURL:    HostURL,
-		httpClient: http.Client{Timeout: 10 * time.Second},
-		DnsRecordsByDomain: make(map[string][]DnsRecord),
-	}
+func NewCCPClient(ctx context.Context, customerNumber, apiKey, apiPassword string, opts ...Option) (*CCPClient, error) {
+	c := newClient(opts...)
 
-	err := c.login(customerNumber, apiKey, apiPassword)
+	err := c.login(ctx, customerNumber, apiKey, apiPassword)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &c, nil
+	return c, nil
+}
+
+// Create a client that is not logged in yet
+func newClient(opts ...Option) *CCPClient {
+	c := CCPClient{
+		hostURL:            HostURL,
+		session:            &session{renew: make(chan struct{}, 1)},
+		httpClient:         http.Client{Timeout: DefaultRequestTimeout},
+		dnsRecordsByDomain: newRecordCache(DefaultRecordCacheSize),
+		dnsZonesByDomain:   newZoneCache(),
+		domains:            newDomainLocks(),
+		requests:           newRequestCounter(RateLimitWindow),
+		rateLimitWarning:   rateLimitWarning{threshold: DefaultRateLimitWarningThreshold},
+		dnssecNotice:       dnssecNotice{notified: make(map[string]bool)},
+		dumpDir:            os.Getenv("NETCUP_DEBUG_DUMP"),
+		zoneUpdateWait:     zoneUpdateWait{timeout: DefaultZoneUpdateTimeout, backoff: defaultBackoff},
+		retry:              defaultRetryPolicy,
+		strict:             strictDecoding{enabled: strictDecodingFromEnv()},
+		limiter:            make(chan struct{}, DefaultMaxConcurrentRequests),
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return &c
 }
 
-func (c *CCPClient) login(customerNumber, apiKey, apiPassword string) error {
-	body, err := c.doRequest("login", LoginData{
+func (c *CCPClient) login(ctx context.Context, customerNumber, apiKey, apiPassword string) error {
+	body, err := c.doRequestContext(ctx, "login", LoginData{
 		CustomerNumber: customerNumber,
 		APIKey:         apiKey,
 		APIPassword:    apiPassword,
 	})
-	res := LoginResponse{}
-	err = json.Unmarshal(body, &res)
 	if err != nil {
 		return err
 	}
 
-	c.authData = AuthData{
+	session := SessionData{}
+	err = c.decode(ctx, "login", body, &session)
+	if err != nil {
+		return err
+	}
+	if session.SessionId == "" {
+		return &DecodeError{Action: "login", Reason: "response contains no session id"}
+	}
+
+	c.setSession(LoginData{
 		CustomerNumber: customerNumber,
 		APIKey:         apiKey,
-		SessionId:      res.ResponseData.SessionId,
-	}
+		APIPassword:    apiPassword,
+	}, session.SessionId)
 	return nil
 }
 
-func (c *CCPClient) doRequest(action string, param interface{}) ([]byte, error) {
+// Logout ends the API session of the client
+func (c *CCPClient) Logout(ctx context.Context) error {
+	body, err := c.doRequestContext(ctx, "logout", c.auth())
+	if err != nil {
+		return err
+	}
+
+	return c.decode(ctx, "logout", body, nil)
+}
+
+func (c *CCPClient) doRequestContext(ctx context.Context, action string, param interface{}) ([]byte, error) {
+	return c.sendRequest(ctx, action, param, nil)
+}
+
+// Send a request creating records. before holds the records of the zone before
+// the request, to tell the records it created apart if it fails without response.
+func (c *CCPClient) doCreateRequest(ctx context.Context, action string, param interface{}, before *zoneRecords) ([]byte, error) {
+	return c.sendRequest(ctx, action, param, before)
+}
+
+func (c *CCPClient) sendRequest(ctx context.Context, action string, param interface{}, before *zoneRecords) ([]byte, error) {
 	rb, err := json.Marshal(RequestBody{
 		Action: action,
 		Param:  param,
@@ -126,34 +187,174 @@ func (c *CCPClient) doRequest(action string, param interface{}) ([]byte, error)
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", c.hostURL, strings.NewReader(string(rb)))
+	started := time.Now()
+	policy := c.retry
+	locked := false
+	renewed := false
+	var result []byte
+	// result of the retried attempt if retrying stops early, e.g. when ctx ends while waiting
+	var giveUp func() ([]byte, error)
+	// error of an attempt failed without response, which may have been processed
+	var unconfirmed error
+	err = newRetries(policy.backoff).until(ctx, func(r *retries) (bool, error) {
+		giveUp = nil
+
+		// creates are retried only if they weren't processed before the failure
+		if unconfirmed != nil {
+			if domainName, creates := requestedCreates(param); len(creates) > 0 {
+				if before == nil {
+					return false, unconfirmed
+				}
+				created, checkErr := c.createdRecords(ctx, domainName, before, creates, unconfirmed)
+				if checkErr != nil {
+					return false, checkErr
+				}
+				if created != nil {
+					logInfo(ctx, "API request was processed before it failed, adopting the created records", map[string]interface{}{"action": action})
+					result = created
+					return true, nil
+				}
+			}
+			unconfirmed = nil
+		}
+
+		statusCode, body, err := c.exchange(ctx, action, rb)
+		// sessions expire when idle, e.g. while waiting for a slow resource of the run
+		if err == nil && statusCode == http.StatusOK && action != "login" && action != "logout" && !renewed && sessionExpired(body) {
+			renewed = true
+			logWarn(ctx, "API session expired, logging in again", map[string]interface{}{"action": action})
+			sessionId, err := c.renewSession(ctx, requestSession(rb))
+			if err != nil {
+				return false, fmt.Errorf("could not renew expired API session: %w", err)
+			}
+			rb, err = withSession(rb, sessionId)
+			if err != nil {
+				return false, err
+			}
+			statusCode, body, err = c.exchange(ctx, action, rb)
+		}
+		if err != nil {
+			// cancelled, e.g. by Ctrl-C or a timeout of the operation
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
+			if !transientError(err) || r.attempt > policy.maxRetries {
+				return false, err
+			}
+			logWarn(ctx, "API request failed without response, retrying", map[string]interface{}{"action": action, "error": err.Error()})
+			unconfirmed = err
+			giveUp = func() ([]byte, error) { return nil, err }
+			return false, nil
+		}
+
+		retryErr := policy.retryableError(statusCode, body, action)
+		if retryErr == nil {
+			if statusCode != http.StatusOK {
+				return false, &HTTPError{StatusCode: statusCode, Body: validText(body)}
+			}
+			result = body
+			return true, nil
+		}
+		if c.retry.isZoneLocked(retryErr) && !locked {
+			locked = true
+			logWarn(ctx, "API request blocked by a concurrent change of the zone, retrying", map[string]interface{}{"action": action})
+			policy = c.retry.locked()
+			r.use(policy.backoff)
+		}
+
+		attempts := r.attempt
+		giveUp = func() ([]byte, error) {
+			switch {
+			case c.retry.isZoneLocked(retryErr):
+				return nil, &ZoneLockedError{Action: action, Attempts: attempts, Elapsed: time.Since(started), Err: retryErr}
+			case isRateLimited(retryErr):
+				return nil, &RateLimitError{Action: action, Attempts: attempts, Elapsed: time.Since(started), Err: retryErr}
+			}
+			// statuscodes retried by configuration fail like without retries
+			if statusCode != http.StatusOK {
+				return nil, retryErr
+			}
+			return body, nil
+		}
+		if r.attempt > policy.maxRetries {
+			result, err = giveUp()
+			giveUp = nil
+			return true, err
+		}
+		return false, nil
+	})
+	// ctx ended while waiting for the next attempt
+	if giveUp != nil && errors.Is(err, ctx.Err()) {
+		return giveUp()
+	}
 	if err != nil {
 		return nil, err
 	}
+	return result, nil
+}
+
+// Send a single request, logging it and dumping it if NETCUP_DEBUG_DUMP is set
+func (c *CCPClient) exchange(ctx context.Context, action string, rb []byte) (int, []byte, error) {
+	var exchange *dumpExchange
+	if c.dumpDir != "" {
+		exchange = newDumpExchange(action, rb)
+		rb = exchange.request
+	}
+
+	started := time.Now()
+	statusCode, body, err := c.send(ctx, rb)
+	logExchange(ctx, action, rb, statusCode, body, err, time.Since(started))
+	if exchange != nil {
+		exchange.write(ctx, c.dumpDir, statusCode, body, err)
+	}
+	return statusCode, body, err
+}
+
+func (c *CCPClient) send(ctx context.Context, rb []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.hostURL, strings.NewReader(string(rb)))
+	if err != nil {
+		return 0, nil, err
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", c.UserAgent)
 
+	// wait for a free slot, giving up when the request is cancelled
+	select {
+	case c.limiter <- struct{}{}:
+	case <-ctx.Done():
+		return 0, nil, ctx.Err()
+	}
+	defer func() { <-c.limiter }()
+
+	c.requests.add()
 	res, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return 0, nil, err
 	}
 
 	defer res.Body.Close()
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return nil, err
+		return res.StatusCode, nil, err
 	}
 
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status: %d, body: %s", res.StatusCode, body)
+	return res.StatusCode, body, nil
+}
+
+func (c *CCPClient) GetDnsZone(ctx context.Context, domainName string) (*DnsZone, error) {
+	// zone settings rarely change during a run, so they are cached like the records
+	zone, present := c.dnsZonesByDomain.get(domainName)
+	if present {
+		return &zone, nil
 	}
 
-	return body, err
+	return c.RefreshDnsZone(ctx, domainName)
 }
 
-func (c *CCPClient) GetDnsZone(domainName string) (*DnsZone, error) {
-	body, err := c.doRequest("infoDnsZone", DomainInfoRequest{
-		AuthData:   c.authData,
+// RefreshDnsZone reads the zone from the API, bypassing and updating the cache
+func (c *CCPClient) RefreshDnsZone(ctx context.Context, domainName string) (*DnsZone, error) {
+	body, err := c.doRequestContext(ctx, "infoDnsZone", DomainInfoRequest{
+		AuthData:   c.auth(),
 		DomainName: domainName,
 	})
 
@@ -161,165 +362,186 @@ func (c *CCPClient) GetDnsZone(domainName string) (*DnsZone, error) {
 		return nil, err
 	}
 
-	res := DnsZoneResponse{}
-	err = json.Unmarshal(body, &res)
+	zone := DnsZone{}
+	err = c.decode(ctx, "infoDnsZone", body, &zone)
 	if err != nil {
 		return nil, err
 	}
-	return &res.ResponseData, nil
+
+	// cache zone for this domain
+	c.dnsZonesByDomain.put(domainName, zone)
+
+	return &zone, nil
 }
 
-func (c *CCPClient) GetDnsRecords(domainName string) ([]DnsRecord, error) {
-	// check if we have the records for this domain cached to avoid triggering API rate limits
-	records, present := c.DnsRecordsByDomain[domainName]
-	if present {
-		return records, nil
-	}
+// UpdateDnsZone writes the settings of a zone and returns them as stored by the API
+func (c *CCPClient) UpdateDnsZone(ctx context.Context, domainName string, zone DnsZone) (*DnsZone, error) {
+	defer c.domains.write(domainName)()
 
-	body, err := c.doRequest("infoDnsRecords", DomainInfoRequest{
-		AuthData:   c.authData,
-		DomainName: domainName,
-	})
-	fmt.Printf(string(body))
+	c.dnsZonesByDomain.invalidate(domainName)
 
+	body, err := c.doRequestContext(ctx, "updateDnsZone", UpdateDnsZoneRequest{
+		DomainInfoRequest: DomainInfoRequest{
+			AuthData:   c.auth(),
+			DomainName: domainName,
+		},
+		DnsZone: zone,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	res := DnsRecordsResponse{}
-	err = json.Unmarshal(body, &res)
+	updated := DnsZone{}
+	err = c.decode(ctx, "updateDnsZone", body, &updated)
 	if err != nil {
 		return nil, err
 	}
 
-	// cache records for this domain
-	c.DnsRecordsByDomain[domainName] = res.ResponseData.DnsRecords
-
-	return res.ResponseData.DnsRecords, nil
+	c.dnsZonesByDomain.put(domainName, updated)
+	return &updated, nil
 }
 
-func (c *CCPClient) GetDnsRecordById(domainName string, id string) (*DnsRecord, error) {
-	records, err := c.GetDnsRecords(domainName)
+func (c *CCPClient) GetDnsRecords(ctx context.Context, domainName string) ([]DnsRecord, error) {
+	zone, err := c.zoneRecords(ctx, domainName)
 	if err != nil {
 		return nil, err
 	}
+	return zone.records, nil
+}
 
-	for _, record := range records {
-		if record.Id == id {
-			return &record, nil
-		}
+func (c *CCPClient) zoneRecords(ctx context.Context, domainName string) (*zoneRecords, error) {
+	// check if we have the records for this domain cached to avoid triggering API rate limits
+	cached, present := c.dnsRecordsByDomain.get(domainName)
+	if present {
+		return cached, nil
 	}
-	return nil, fmt.Errorf("could not find DNS record with ID %s for domain %s", id, domainName)
-}
 
-func (c *CCPClient) CreateDnsRecord(domainName string, record NewDnsRecord) (*DnsRecord, error) {
-	fmt.Printf("%+v", record)
-	fmt.Println(domainName)
+	defer c.domains.read(domainName)()
+	return c.lockedZoneRecords(ctx, domainName)
+}
 
-	// flush cache for this domain to be sure we're not faking an incorrect state
-	delete(c.DnsRecordsByDomain, domainName)
+// Records of a domain, read through the cache by a caller already holding the domain lock
+func (c *CCPClient) lockedZoneRecords(ctx context.Context, domainName string) (*zoneRecords, error) {
+	cached, present := c.dnsRecordsByDomain.get(domainName)
+	if present {
+		return cached, nil
+	}
 
-	body, err := c.doRequest("updateDnsRecords", CreateDnsRecordsRequest{
-		DomainInfoRequest: DomainInfoRequest{
-			AuthData:   c.authData,
-			DomainName: domainName,
-		},
-		DnsRecordSet: NewDnsRecordSet{DnsRecords: []NewDnsRecord{record}},
+	body, err := c.doRequestContext(ctx, "infoDnsRecords", DomainInfoRequest{
+		AuthData:   c.auth(),
+		DomainName: domainName,
 	})
-
 	if err != nil {
 		return nil, err
 	}
 
-	res := DnsRecordsResponse{}
-	err = json.Unmarshal(body, &res)
+	recordSet := DnsRecordSet{}
+	err = c.decode(ctx, "infoDnsRecords", body, &recordSet)
 	if err != nil {
-		fmt.Printf(string(body))
 		return nil, err
 	}
 
-	newRecord, err := findNewRecord(res.ResponseData.DnsRecords, record)
+	// cache records for this domain
+	zone := newZoneRecords(recordSet.DnsRecords)
+	c.dnsRecordsByDomain.put(domainName, zone)
+
+	return zone, nil
+}
+
+func (c *CCPClient) GetDnsRecordById(ctx context.Context, domainName string, id string) (*DnsRecord, error) {
+	zone, err := c.zoneRecords(ctx, domainName)
 	if err != nil {
 		return nil, err
 	}
 
-	return newRecord, nil
+	record, ok := zone.findById(id)
+	if ok {
+		return record, nil
+	}
+	return nil, fmt.Errorf("%w with ID %s for domain %s", ErrRecordNotFound, id, domainName)
 }
 
-func (c *CCPClient) UpdateDnsRecord(domainName string, record DnsRecord) (*DnsRecord, error) {
-	// flush cache for this domain to be sure we're not faking an incorrect state
-	delete(c.DnsRecordsByDomain, domainName)
+func (c *CCPClient) CreateDnsRecord(ctx context.Context, domainName string, record NewDnsRecord) (*DnsRecord, error) {
+	created, err := c.CreateDnsRecords(ctx, domainName, []NewDnsRecord{record})
+	if err != nil {
+		return nil, err
+	}
+	return &created[0], nil
+}
 
-	body, err := c.doRequest("updateDnsRecords", UpdateDnsRecordsRequest{
-		DomainInfoRequest: DomainInfoRequest{
-			AuthData:   c.authData,
-			DomainName: domainName,
-		},
-		DnsRecordSet: DnsRecordSet{DnsRecords: []DnsRecord{record}},
-	})
+func (c *CCPClient) CreateDnsRecords(ctx context.Context, domainName string, records []NewDnsRecord) ([]DnsRecord, error) {
+	defer c.domains.write(domainName)()
 
+	// records existing before the write, usually served from the cache, tell the new ones apart
+	zone, err := c.lockedZoneRecords(ctx, domainName)
 	if err != nil {
 		return nil, err
 	}
 
-	res := DnsRecordsResponse{}
-	err = json.Unmarshal(body, &res)
+	// flush cache for this domain to be sure we're not faking an incorrect state
+	c.dnsRecordsByDomain.invalidate(domainName)
+
+	body, err := c.doCreateRequest(ctx, "updateDnsRecords", CreateDnsRecordsRequest{
+		DomainInfoRequest: DomainInfoRequest{
+			AuthData:   c.auth(),
+			DomainName: domainName,
+		},
+		DnsRecordSet: NewDnsRecordSet{DnsRecords: records},
+	}, zone)
+
 	if err != nil {
-		fmt.Printf(string(body))
 		return nil, err
 	}
 
-	newRecord, err := findRecordById(res.ResponseData.DnsRecords, record.Id)
+	recordSet := DnsRecordSet{}
+	err = c.decode(ctx, "updateDnsRecords", body, &recordSet)
 	if err != nil {
 		return nil, err
 	}
 
-	return newRecord, nil
+	return matchNewRecords(domainName, zone.records, recordSet.DnsRecords, records)
 }
 
-func (c *CCPClient) DeleteDnsRecord(domainName string, record DnsRecord) error {
+func (c *CCPClient) UpdateDnsRecord(ctx context.Context, domainName string, record DnsRecord) (*DnsRecord, error) {
+	defer c.domains.write(domainName)()
+
 	// flush cache for this domain to be sure we're not faking an incorrect state
-	delete(c.DnsRecordsByDomain, domainName)
+	c.dnsRecordsByDomain.invalidate(domainName)
 
-	deleteRecord := record
-	deleteRecord.DeleteRecord = true
-	body, err := c.doRequest("updateDnsRecords", UpdateDnsRecordsRequest{
+	body, err := c.doRequestContext(ctx, "updateDnsRecords", UpdateDnsRecordsRequest{
 		DomainInfoRequest: DomainInfoRequest{
-			AuthData:   c.authData,
+			AuthData:   c.auth(),
 			DomainName: domainName,
 		},
-		DnsRecordSet: DnsRecordSet{DnsRecords: []DnsRecord{deleteRecord}},
+		DnsRecordSet: DnsRecordSet{DnsRecords: []DnsRecord{record}},
 	})
 
-	fmt.Printf(string(body))
-
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
-}
+	recordSet := DnsRecordSet{}
+	err = c.decode(ctx, "updateDnsRecords", body, &recordSet)
+	if err != nil {
+		return nil, err
+	}
 
-func findRecordById(records []DnsRecord, id string) (*DnsRecord, error) {
-	for _, record := range records {
-		if record.Id == id {
-			return &record, nil
-		}
+	newRecord, ok := findRecordById(recordSet.DnsRecords, record.Id)
+	if !ok {
+		return nil, fmt.Errorf("%w with ID %s", ErrRecordNotFound, record.Id)
 	}
-	return nil, fmt.Errorf("could not find DNS record with ID %s", id)
+
+	return newRecord, nil
 }
 
-func findNewRecord(newRecords []DnsRecord, requestedRecord NewDnsRecord) (*DnsRecord, error) {
-	for _, record := range newRecords {
-		if requestedRecord.Matches(record) {
-			return &record, nil
-		}
-	}
-	return nil, errors.New("could not retrieve newly created DNS record")
+func (c *CCPClient) DeleteDnsRecord(ctx context.Context, domainName string, record DnsRecord) error {
+	return c.DeleteDnsRecords(ctx, domainName, []DnsRecord{record})
 }
 
-func (r NewDnsRecord) Matches(r2 DnsRecord) bool {
-	isMatch := r.Hostname == r2.Hostname && r.Type == r2.Type && r.Destination == r2.Destination
+// Matches reports whether r2 is the record r requested, comparing RecordIdentity
+// and the priority if one was requested.
+func (r NewDnsRecord) Matches(r2 DnsRecord, domainName string) bool {
+	isMatch := RecordIdentity(r.Hostname, r.Type, r.Destination, domainName) == RecordIdentity(r2.Hostname, r2.Type, r2.Destination, domainName)
 
 	if r.Priority != "" {
 		isMatch = isMatch && (r.Priority == r2.Priority)
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// Changes of the records of a zone written by ChangeDnsRecords
+type RecordChanges struct {
+	Create []NewDnsRecord
+	Update []DnsRecord
+	Delete []DnsRecord
+}
+
+// ChangeDnsRecords writes creates, updates and deletes of a zone in a single
+// updateDnsRecords call. The result holds the created records in the order of
+// changes.Create and the updated records as stored by the API.
+func (c *CCPClient) ChangeDnsRecords(ctx context.Context, domainName string, changes RecordChanges) (ReplaceResult, error) {
+	defer c.domains.write(domainName)()
+
+	// records existing before the write, usually served from the cache, tell the new ones apart
+	zone, err := c.lockedZoneRecords(ctx, domainName)
+	if err != nil {
+		return ReplaceResult{}, err
+	}
+
+	// flush cache for this domain to be sure we're not faking an incorrect state
+	c.dnsRecordsByDomain.invalidate(domainName)
+
+	records := make([]DnsRecord, 0, len(changes.Delete)+len(changes.Update)+len(changes.Create))
+	for _, record := range changes.Delete {
+		record.DeleteRecord = true
+		records = append(records, record)
+	}
+	records = append(records, changes.Update...)
+	for _, record := range changes.Create {
+		records = append(records, DnsRecord{
+			Hostname:    record.Hostname,
+			Type:        record.Type,
+			Priority:    record.Priority,
+			Destination: record.Destination,
+		})
+	}
+	if len(records) == 0 {
+		return ReplaceResult{}, nil
+	}
+
+	written, err := c.writeDnsRecords(ctx, domainName, zone, records)
+	if err != nil {
+		return ReplaceResult{}, err
+	}
+
+	// the response lists the records of the zone after the write
+	after := newZoneRecords(written)
+	var remaining []DnsRecord
+	for _, record := range changes.Delete {
+		if current, ok := after.findById(record.Id); ok {
+			remaining = append(remaining, *current)
+		}
+	}
+	if len(remaining) > 0 {
+		return ReplaceResult{}, &DeleteError{DomainName: domainName, Remaining: remaining}
+	}
+
+	result := ReplaceResult{Deleted: changes.Delete}
+	for _, record := range changes.Update {
+		updated, ok := after.findById(record.Id)
+		if !ok {
+			return ReplaceResult{}, fmt.Errorf("%w with ID %s for domain %s", ErrRecordNotFound, record.Id, domainName)
+		}
+		result.Updated = append(result.Updated, *updated)
+	}
+	if len(changes.Create) > 0 {
+		result.Created, err = matchNewRecords(domainName, zone.records, written, changes.Create)
+		if err != nil {
+			return ReplaceResult{}, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"testing"
+)
+
+func TestChangeDnsRecordsSingleCall(t *testing.T) {
+	c, transport := newTestClient(t)
+
+	creates := []NewDnsRecord{
+		{Hostname: "a", Type: "A", Destination: "192.0.2.1"},
+		{Hostname: "b", Type: "A", Destination: "192.0.2.2"},
+		{Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+		{Hostname: "@", Type: "TXT", Destination: "v=spf1 -all"},
+	}
+	result, err := c.ChangeDnsRecords(context.Background(), "example.com", RecordChanges{Create: creates})
+	if err != nil {
+		t.Fatalf("ChangeDnsRecords failed: %s", err)
+	}
+	if n := transport.count("updateDnsRecords"); n != 1 {
+		t.Errorf("sent %d updateDnsRecords calls for %d records, want 1", n, len(creates))
+	}
+	if len(result.Created) != len(creates) {
+		t.Fatalf("created %d records, want %d", len(result.Created), len(creates))
+	}
+	for i, record := range result.Created {
+		if record.Id == "" {
+			t.Errorf("created record %v has no id", record)
+		}
+		if !creates[i].Matches(record, "example.com") {
+			t.Errorf("created record %d is %v, want %v", i, record, creates[i])
+		}
+	}
+}
+
+func TestChangeDnsRecordsDelta(t *testing.T) {
+	c, transport := newTestClient(t)
+	live := seedRecords(t, transport, "example.com",
+		DnsRecord{Hostname: "a", Type: "A", Destination: "192.0.2.1"},
+		DnsRecord{Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+		DnsRecord{Hostname: "old", Type: "A", Destination: "192.0.2.9"},
+		DnsRecord{Hostname: "other", Type: "A", Destination: "192.0.2.8"},
+	)
+
+	mx := live[1]
+	mx.Priority = "20"
+	result, err := c.ChangeDnsRecords(context.Background(), "example.com", RecordChanges{
+		Create: []NewDnsRecord{{Hostname: "b", Type: "A", Destination: "192.0.2.2"}},
+		Update: []DnsRecord{mx},
+		Delete: []DnsRecord{live[2]},
+	})
+	if err != nil {
+		t.Fatalf("ChangeDnsRecords failed: %s", err)
+	}
+	if n := transport.count("updateDnsRecords"); n != 1 {
+		t.Errorf("sent %d updateDnsRecords calls, want 1", n)
+	}
+	if len(result.Updated) != 1 || result.Updated[0].Id != mx.Id || result.Updated[0].Priority != "20" {
+		t.Errorf("updated %v, want record %s with priority 20", result.Updated, mx.Id)
+	}
+	if len(result.Deleted) != 1 || result.Deleted[0].Id != live[2].Id {
+		t.Errorf("deleted %v, want record %s", result.Deleted, live[2].Id)
+	}
+
+	records, err := c.GetDnsRecords(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("GetDnsRecords failed: %s", err)
+	}
+	ids := make(map[string]bool)
+	for _, record := range records {
+		ids[record.Id] = true
+	}
+	// untouched records keep their ids, the created one is new
+	for _, want := range []string{live[0].Id, mx.Id, live[3].Id, result.Created[0].Id} {
+		if !ids[want] {
+			t.Errorf("zone lacks record %s: %v", want, records)
+		}
+	}
+	if ids[live[2].Id] || len(records) != 4 {
+		t.Errorf("zone has records %v, want 4 without %s", records, live[2].Id)
+	}
+}
+
+func TestChangeDnsRecordsTracksIds(t *testing.T) {
+	c, transport := newTestClient(t)
+	// an identical record existing before the write is not taken for the new one
+	live := seedRecords(t, transport, "example.com",
+		DnsRecord{Hostname: "a", Type: "A", Destination: "192.0.2.1"},
+	)
+
+	result, err := c.ChangeDnsRecords(context.Background(), "example.com", RecordChanges{
+		Create: []NewDnsRecord{
+			{Hostname: "A", Type: "a", Destination: " 192.0.2.1 "},
+			{Hostname: "@", Type: "TXT", Destination: `"quoted"`},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ChangeDnsRecords failed: %s", err)
+	}
+	if len(result.Created) != 2 {
+		t.Fatalf("created %v, want 2 records", result.Created)
+	}
+	if result.Created[0].Id == live[0].Id {
+		t.Errorf("created record took the id %s of the existing record", live[0].Id)
+	}
+	if result.Created[0].Id == result.Created[1].Id {
+		t.Errorf("created records share id %s", result.Created[0].Id)
+	}
+}
+
+func TestChangeDnsRecordsNothing(t *testing.T) {
+	c, transport := newTestClient(t)
+	result, err := c.ChangeDnsRecords(context.Background(), "example.com", RecordChanges{})
+	if err != nil {
+		t.Fatalf("ChangeDnsRecords failed: %s", err)
+	}
+	if result.HasChanges() {
+		t.Errorf("got changes %+v", result)
+	}
+	if n := transport.count("updateDnsRecords"); n != 0 {
+		t.Errorf("sent %d updateDnsRecords calls without changes", n)
+	}
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Run with -race: settings changed by a provider configuration while resources
+// of another one use the client must not race with requests
+func TestSettingsConcurrentWithRequests(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		i := i
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.SetSkipRefresh(i%2 == 0)
+			c.SetDriftWarnings(i%2 == 1)
+			c.SetZoneUpdateWait(i%2 == 0, time.Second)
+			c.SetDnssecNotice(i%2 == 1)
+			c.SetStrictDecoding(i%2 == 0)
+			c.SetRateLimitWarningThreshold(0.5)
+		}()
+		go func() {
+			defer wg.Done()
+			domain := fmt.Sprintf("domain%d.example", i%3)
+			_ = c.SkipRefresh()
+			_ = c.DriftWarnings()
+			_, _ = c.NearRateLimit()
+			if _, _, err := c.ZoneSerialBeforeWrite(ctx, domain); err != nil {
+				t.Error(err)
+			}
+			if _, err := c.DnssecNotice(ctx, domain); err != nil {
+				t.Error(err)
+			}
+			if _, err := c.CreateDnsRecord(ctx, domain, NewDnsRecord{Hostname: fmt.Sprintf("host%d", i), Type: "A", Destination: "192.0.2.1"}); err != nil {
+				t.Error(err)
+			}
+			if _, err := c.GetDnsRecords(ctx, domain); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Concurrent creates of one domain are serialized, so none is lost
+func TestConcurrentCreatesKeepAllRecords(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.CreateDnsRecord(ctx, "example.com", NewDnsRecord{Hostname: fmt.Sprintf("host%d", i), Type: "A", Destination: "192.0.2.1"})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	records, err := c.GetDnsRecords(ctx, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 30 {
+		t.Errorf("expected 30 records, got %d", len(records))
+	}
+}
+
+// Requests wait for a free slot, so at most max of them are sent at the same time
+func TestMaxConcurrentRequests(t *testing.T) {
+	for _, max := range []int{1, 3} {
+		t.Run(fmt.Sprint(max), func(t *testing.T) {
+			c, transport := newTestClient(t, WithMaxConcurrentRequests(max))
+			ctx := context.Background()
+
+			var mu sync.Mutex
+			inFlight, highest := 0, 0
+			transport.setIntercept(func(_ *http.Request, action string, _ int, _ []byte) (*http.Response, error) {
+				mu.Lock()
+				inFlight++
+				if inFlight > highest {
+					highest = inFlight
+				}
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+				return nil, nil
+			})
+
+			concurrently(12, func(i int) {
+				if _, err := c.GetDnsRecords(ctx, fmt.Sprintf("domain%d.example", i)); err != nil {
+					t.Error(err)
+				}
+			})
+			if highest != max {
+				t.Errorf("%d requests in flight at the same time, want %d", highest, max)
+			}
+		})
+	}
+}
+
+// A request waiting for a slot gives up when it is cancelled
+func TestMaxConcurrentRequestsCancelled(t *testing.T) {
+	c, transport := newTestClient(t, WithMaxConcurrentRequests(1), WithRetries(0, 0))
+	release := make(chan struct{})
+	started := make(chan struct{})
+	transport.setIntercept(func(_ *http.Request, action string, _ int, _ []byte) (*http.Response, error) {
+		if action == "infoDnsRecords" {
+			close(started)
+			<-release
+		}
+		return nil, nil
+	})
+	defer close(release)
+
+	go func() { _, _ = c.GetDnsRecords(context.Background(), "busy.example") }()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetDnsRecords(ctx, "waiting.example"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want the deadline of the request", err)
+	}
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"testing"
+)
+
+// The API stores hostnames lower-case and trims destinations, the created
+// record is found anyway
+func TestCreateDnsRecordNormalizedByApi(t *testing.T) {
+	tests := []struct {
+		name   string
+		record NewDnsRecord
+	}{
+		{"mixed-case hostname", NewDnsRecord{Hostname: "WWW", Type: "A", Destination: "192.0.2.1"}},
+		{"lower-case type", NewDnsRecord{Hostname: "www", Type: "aaaa", Destination: "2001:db8::1"}},
+		{"padded destination", NewDnsRecord{Hostname: "www", Type: "A", Destination: " 192.0.2.2 "}},
+		{"quoted TXT", NewDnsRecord{Hostname: "@", Type: "TXT", Destination: `"v=spf1 -all"`}},
+		{"apex spelling", NewDnsRecord{Hostname: "example.com", Type: "TXT", Destination: "apex"}},
+		{"MX without priority", NewDnsRecord{Hostname: "@", Type: "MX", Destination: "mail.example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t)
+			record, err := c.CreateDnsRecord(context.Background(), "example.com", tt.record)
+			if err != nil {
+				t.Fatalf("CreateDnsRecord failed: %s", err)
+			}
+			if record.Id == "" {
+				t.Errorf("created record %v has no id", record)
+			}
+		})
+	}
+}
+
+func TestNewDnsRecordMatches(t *testing.T) {
+	stored := DnsRecord{Id: "1", Hostname: "www", Type: "MX", Priority: "10", Destination: "mail.example.com"}
+	tests := []struct {
+		name    string
+		request NewDnsRecord
+		want    bool
+	}{
+		{"same", NewDnsRecord{Hostname: "www", Type: "MX", Priority: "10", Destination: "mail.example.com"}, true},
+		{"case", NewDnsRecord{Hostname: "WWW", Type: "mx", Priority: "10", Destination: "mail.example.com"}, true},
+		{"padded", NewDnsRecord{Hostname: "www", Type: "MX", Destination: " mail.example.com "}, true},
+		{"without priority", NewDnsRecord{Hostname: "www", Type: "MX", Destination: "mail.example.com"}, true},
+		{"other priority", NewDnsRecord{Hostname: "www", Type: "MX", Priority: "20", Destination: "mail.example.com"}, false},
+		{"other destination", NewDnsRecord{Hostname: "www", Type: "MX", Destination: "mx.example.com"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.request.Matches(stored, "example.com"); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// Keys whose values never end up in a dump
+var redactedKeys = map[string]bool{
+	"apikey":       true,
+	"apipassword":  true,
+	"apisessionid": true,
+}
+
+// Numbers the dumps of this process
+var dumpCounter uint64
+
+// One request/response exchange written to the NETCUP_DEBUG_DUMP directory
+type dumpExchange struct {
+	number          uint64
+	action          string
+	clientRequestId string
+	started         time.Time
+	request         []byte
+}
+
+type dumpFile struct {
+	ClientRequestId string          `json:"clientrequestid"`
+	Action          string          `json:"action"`
+	Started         string          `json:"started"`
+	DurationMs      int64           `json:"duration_ms"`
+	Request         json.RawMessage `json:"request"`
+	StatusCode      int             `json:"status_code,omitempty"`
+	Response        json.RawMessage `json:"response,omitempty"`
+	Error           string          `json:"error,omitempty"`
+}
+
+// Tag the request with a clientrequestid, so the dump can be matched with the logs of Netcup support
+func newDumpExchange(action string, request []byte) *dumpExchange {
+	number := atomic.AddUint64(&dumpCounter, 1)
+	e := &dumpExchange{
+		number:          number,
+		action:          action,
+		clientRequestId: fmt.Sprintf("tf-%d-%d-%d", os.Getpid(), time.Now().Unix(), number),
+		started:         time.Now(),
+		request:         request,
+	}
+
+	var body map[string]interface{}
+	if json.Unmarshal(request, &body) != nil {
+		return e
+	}
+	param, ok := body["param"].(map[string]interface{})
+	if !ok {
+		return e
+	}
+	param["clientrequestid"] = e.clientRequestId
+	if tagged, err := json.Marshal(body); err == nil {
+		e.request = tagged
+	}
+	return e
+}
+
+// Write the exchange as <pid>-<number>-<action>.json. Failures are only logged,
+// dumping must never fail a request.
+func (e *dumpExchange) write(ctx context.Context, dir string, statusCode int, response []byte, reqErr error) {
+	dump := dumpFile{
+		ClientRequestId: e.clientRequestId,
+		Action:          e.action,
+		Started:         e.started.Format(time.RFC3339Nano),
+		DurationMs:      time.Since(e.started).Milliseconds(),
+		Request:         redactJSON(e.request),
+		StatusCode:      statusCode,
+	}
+	if len(response) > 0 {
+		dump.Response = redactJSON(response)
+	}
+	if reqErr != nil {
+		dump.Error = reqErr.Error()
+	}
+
+	content, err := json.MarshalIndent(dump, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(filepath.Join(dir, dumpFileName(os.Getpid(), e.number, e.action)), content)
+	}
+	if err != nil {
+		logWarn(ctx, "Could not write NETCUP_DEBUG_DUMP file", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+func dumpFileName(pid int, number uint64, action string) string {
+	return fmt.Sprintf("%d-%06d-%s.json", pid, number, action)
+}
+
+// Replace the values of redactedKeys at any depth. Bodies that are no JSON
+// are kept as JSON string, they can't carry the redacted keys in a form the API returns.
+func redactJSON(data []byte) json.RawMessage {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		quoted, _ := json.Marshal(string(data))
+		return quoted
+	}
+
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		quoted, _ := json.Marshal(string(data))
+		return quoted
+	}
+	return redacted
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if redactedKeys[key] {
+				v[key] = "REDACTED"
+				continue
+			}
+			v[key] = redactValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+// Write to a temporary file in the same directory and rename it, so readers never see partial dumps
+func writeFileAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".netcup-dump-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
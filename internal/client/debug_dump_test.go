@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Dumps of the directory in the order they were written, decoded
+func readDumps(t *testing.T, dir string) ([]string, []dumpFile) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	var dumps []dumpFile
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var dump dumpFile
+		if err := json.Unmarshal(content, &dump); err != nil {
+			t.Fatalf("dump %s is no JSON: %s", entry.Name(), err)
+		}
+		names = append(names, entry.Name())
+		dumps = append(dumps, dump)
+	}
+	return names, dumps
+}
+
+// Client request ids of the requests received by the API
+type requestIds struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (r *requestIds) intercept(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+	var request struct {
+		Param map[string]interface{} `json:"param"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, err
+	}
+	id, _ := request.Param["clientrequestid"].(string)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids = append(r.ids, id)
+	return nil, nil
+}
+
+func TestDebugDumpRedactsSecrets(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("NETCUP_DEBUG_DUMP", dir)
+	c, _ := newTestClient(t)
+	if _, err := c.CreateDnsRecord(context.Background(), "example.com", NewDnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	names, dumps := readDumps(t, dir)
+	if len(dumps) < 2 {
+		t.Fatalf("got dumps %v, want the login and the writes", names)
+	}
+	for i, dump := range dumps {
+		content, _ := json.Marshal(dump)
+		for _, secret := range []string{`"key"`, `"password"`, c.auth().SessionId} {
+			if strings.Contains(string(content), secret) {
+				t.Errorf("dump %s contains %s: %s", names[i], secret, content)
+			}
+		}
+
+		var request struct {
+			Param map[string]interface{} `json:"param"`
+		}
+		if err := json.Unmarshal(dump.Request, &request); err != nil {
+			t.Fatalf("request of dump %s: %s", names[i], err)
+		}
+		for key := range redactedKeys {
+			if value, ok := request.Param[key]; ok && value != "REDACTED" {
+				t.Errorf("%s of dump %s is %v, want it redacted", key, names[i], value)
+			}
+		}
+	}
+
+	// the session id of the login response is redacted, too
+	var login struct {
+		ResponseData map[string]interface{} `json:"responsedata"`
+	}
+	if err := json.Unmarshal(dumps[0].Response, &login); err != nil || dumps[0].Action != "login" {
+		t.Fatalf("first dump is %s %s, want the login response", dumps[0].Action, dumps[0].Response)
+	}
+	if login.ResponseData["apisessionid"] != "REDACTED" {
+		t.Errorf("session id of the login response dumped as %v", login.ResponseData["apisessionid"])
+	}
+}
+
+func TestDebugDumpFileNames(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("NETCUP_DEBUG_DUMP", dir)
+	c, transport := newTestClient(t)
+	ids := &requestIds{}
+	transport.setIntercept(ids.intercept)
+	ctx := context.Background()
+	if _, err := c.GetDnsZone(ctx, "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetDnsRecords(ctx, "example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	names, dumps := readDumps(t, dir)
+	pattern := regexp.MustCompile(`^` + strconv.Itoa(os.Getpid()) + `-(\d{6})-(\w+)\.json$`)
+	var previous int
+	var actions []string
+	for i, name := range names {
+		match := pattern.FindStringSubmatch(name)
+		if match == nil {
+			t.Fatalf("dump %s isn't named <pid>-<number>-<action>.json, or a temporary file was left", name)
+		}
+		number, _ := strconv.Atoi(match[1])
+		if number <= previous {
+			t.Errorf("dump %s numbered after %d", name, previous)
+		}
+		previous = number
+		if match[2] != dumps[i].Action {
+			t.Errorf("dump %s holds the exchange of %s", name, dumps[i].Action)
+		}
+		if dumps[i].Started == "" || dumps[i].StatusCode != http.StatusOK {
+			t.Errorf("dump %s has no timing or statuscode: %+v", name, dumps[i])
+		}
+		actions = append(actions, dumps[i].Action)
+	}
+	if got, want := strings.Join(actions, ","), "login,infoDnsZone,infoDnsRecords"; got != want {
+		t.Errorf("dumped %s, want %s", got, want)
+	}
+
+	// the clientrequestid of the dump is the one sent to the API
+	if len(ids.ids) != 2 {
+		t.Fatalf("API received %d requests, want 2", len(ids.ids))
+	}
+	for i, id := range ids.ids {
+		if dump := dumps[i+1]; id == "" || dump.ClientRequestId != id {
+			t.Errorf("dump %s has clientrequestid %q, the API received %q", names[i+1], dump.ClientRequestId, id)
+		}
+	}
+}
+
+func TestDebugDumpInertWhenUnset(t *testing.T) {
+	t.Setenv("NETCUP_DEBUG_DUMP", "")
+	c, transport := newTestClient(t)
+	ids := &requestIds{}
+	transport.setIntercept(ids.intercept)
+	if _, err := c.GetDnsRecords(context.Background(), "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if c.dumpDir != "" {
+		t.Errorf("dump directory %q without NETCUP_DEBUG_DUMP", c.dumpDir)
+	}
+	if len(ids.ids) != 1 || ids.ids[0] != "" {
+		t.Errorf("sent clientrequestids %q without NETCUP_DEBUG_DUMP, want none", ids.ids)
+	}
+}
+
+// Dumping must never fail a request
+func TestDebugDumpUnwritableDirectory(t *testing.T) {
+	t.Setenv("NETCUP_DEBUG_DUMP", filepath.Join(t.TempDir(), "missing"))
+	c, _ := newTestClient(t)
+	if _, err := c.GetDnsRecords(context.Background(), "example.com"); err != nil {
+		t.Errorf("request failed with an unwritable dump directory: %s", err)
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"nested", `{"param":{"apikey":"k","apipassword":"p","customernumber":"1"}}`, `{"param":{"apikey":"REDACTED","apipassword":"REDACTED","customernumber":"1"}}`},
+		{"arrays", `[{"apisessionid":"s"},{"records":[{"apisessionid":"s"}]}]`, `[{"apisessionid":"REDACTED"},{"records":[{"apisessionid":"REDACTED"}]}]`},
+		{"objects as value", `{"apikey":{"value":"k"}}`, `{"apikey":"REDACTED"}`},
+		{"no JSON", `502 Bad Gateway`, `"502 Bad Gateway"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := string(redactJSON([]byte(test.body))); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDumpFileName(t *testing.T) {
+	if got, want := dumpFileName(4242, 7, "updateDnsRecords"), "4242-000007-updateDnsRecords.json"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
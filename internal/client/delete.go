@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Maximum number of records sent in one updateDnsRecords call when deleting
+const DeleteBatchSize = 100
+
+// Records still present in the zone after they were deleted
+type DeleteError struct {
+	DomainName string
+	Remaining  []DnsRecord
+}
+
+func (e *DeleteError) Error() string {
+	ids := make([]string, 0, len(e.Remaining))
+	for _, record := range e.Remaining {
+		ids = append(ids, fmt.Sprintf("%s (%s %s %s)", record.Id, record.Hostname, record.Type, record.Destination))
+	}
+	return fmt.Sprintf("%d DNS records of domain %s were not deleted: %s", len(e.Remaining), e.DomainName, strings.Join(ids, ", "))
+}
+
+// DeleteDnsRecords deletes records in as few updateDnsRecords calls as possible
+// and verifies that none of them is left in the zone
+func (c *CCPClient) DeleteDnsRecords(ctx context.Context, domainName string, records []DnsRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	defer c.domains.write(domainName)()
+
+	// flush cache for this domain to be sure we're not faking an incorrect state
+	c.dnsRecordsByDomain.invalidate(domainName)
+
+	deletes := make([]DnsRecord, 0, len(records))
+	for _, record := range records {
+		record.DeleteRecord = true
+		deletes = append(deletes, record)
+	}
+
+	var remaining []DnsRecord
+	for _, batch := range batches(deletes, DeleteBatchSize) {
+		written, err := c.writeDnsRecords(ctx, domainName, nil, batch)
+		if err != nil {
+			return err
+		}
+
+		// the response lists the records of the zone after the write
+		zone := newZoneRecords(written)
+		for _, record := range batch {
+			if current, ok := zone.findById(record.Id); ok {
+				remaining = append(remaining, *current)
+			}
+		}
+	}
+
+	if len(remaining) > 0 {
+		return &DeleteError{DomainName: domainName, Remaining: remaining}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Seed n A records, www0 to www<n-1>
+func seedHosts(t *testing.T, transport *scriptedTransport, domainName string, n int) []DnsRecord {
+	t.Helper()
+
+	records := make([]DnsRecord, 0, n)
+	for i := 0; i < n; i++ {
+		records = append(records, DnsRecord{Hostname: fmt.Sprintf("www%d", i), Type: "A", Destination: "192.0.2.1"})
+	}
+	return seedRecords(t, transport, domainName, records...)
+}
+
+// Records of the updateDnsRecords requests, a list per request
+type sentRecords struct {
+	requests [][]DnsRecord
+}
+
+func (s *sentRecords) intercept(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+	if action != "updateDnsRecords" {
+		return nil, nil
+	}
+	var request struct {
+		Param struct {
+			DnsRecordSet DnsRecordSet `json:"dnsrecordset"`
+		} `json:"param"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, err
+	}
+	s.requests = append(s.requests, request.Param.DnsRecordSet.DnsRecords)
+	return nil, nil
+}
+
+func TestDeleteDnsRecordsSingleCall(t *testing.T) {
+	c, transport := newTestClient(t)
+	ctx := context.Background()
+	records := seedHosts(t, transport, "example.com", 25)
+	if _, err := c.GetDnsRecords(ctx, "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	sent := &sentRecords{}
+	transport.setIntercept(sent.intercept)
+
+	if err := c.DeleteDnsRecords(ctx, "example.com", records[:20]); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent.requests) != 1 {
+		t.Fatalf("sent %d updateDnsRecords calls for 20 records, want 1", len(sent.requests))
+	}
+	if len(sent.requests[0]) != 20 {
+		t.Errorf("sent %d records, want the 20 deleted ones", len(sent.requests[0]))
+	}
+	for _, record := range sent.requests[0] {
+		if !record.DeleteRecord || record.Id == "" {
+			t.Errorf("sent %+v, want it marked for deletion by id", record)
+		}
+	}
+
+	// the cache was dropped, so the zone is read again
+	left, err := c.GetDnsRecords(ctx, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reads := transport.count("infoDnsRecords"); reads != 2 {
+		t.Errorf("read the zone %d times, want once more after the delete", reads)
+	}
+	if got := recordSummaries(left); len(got) != 5 {
+		t.Errorf("left %q, want the 5 records not deleted", got)
+	}
+}
+
+func TestDeleteDnsRecordsChunked(t *testing.T) {
+	c, transport := newTestClient(t)
+	records := seedHosts(t, transport, "example.com", DeleteBatchSize+5)
+	sent := &sentRecords{}
+	transport.setIntercept(sent.intercept)
+
+	if err := c.DeleteDnsRecords(context.Background(), "example.com", records); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent.requests) != 2 || len(sent.requests[0]) != DeleteBatchSize || len(sent.requests[1]) != 5 {
+		sizes := []int{}
+		for _, request := range sent.requests {
+			sizes = append(sizes, len(request))
+		}
+		t.Errorf("sent batches of %v records, want %d and 5", sizes, DeleteBatchSize)
+	}
+	left, err := c.GetDnsRecords(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Errorf("%d records left, want none", len(left))
+	}
+}
+
+// Records the API still lists after the write are reported, each of them
+func TestDeleteDnsRecordsPartialFailure(t *testing.T) {
+	c, transport := newTestClient(t)
+	records := seedHosts(t, transport, "example.com", 5)
+	kept := []DnsRecord{records[1], records[3]}
+
+	// the API accepts the request, but ignores two of the deletions
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		if action != "updateDnsRecords" {
+			return nil, nil
+		}
+		data, err := json.Marshal(map[string]interface{}{
+			"action": "updateDnsRecords", "status": "success", "statuscode": 2000,
+			"responsedata": DnsRecordSet{DnsRecords: kept},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return jsonResponse(string(data)), nil
+	})
+
+	err := c.DeleteDnsRecords(context.Background(), "example.com", records)
+	var deleteErr *DeleteError
+	if !errors.As(err, &deleteErr) {
+		t.Fatalf("got %v, want a DeleteError", err)
+	}
+	var ids []string
+	for _, record := range deleteErr.Remaining {
+		ids = append(ids, record.Id)
+	}
+	sort.Strings(ids)
+	if want := []string{records[1].Id, records[3].Id}; strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("reported %v as not deleted, want %v", ids, want)
+	}
+	for _, record := range kept {
+		if !strings.Contains(err.Error(), fmt.Sprintf("%s (%s A 192.0.2.1)", record.Id, record.Hostname)) {
+			t.Errorf("error %q doesn't name record %s", err, record.Id)
+		}
+	}
+	if strings.Contains(err.Error(), records[0].Hostname+" ") {
+		t.Errorf("error %q names a deleted record", err)
+	}
+}
+
+func TestDeleteDnsRecordsApiError(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(0, 0))
+	ctx := context.Background()
+	records := seedHosts(t, transport, "example.com", 3)
+	if _, err := c.GetDnsRecords(ctx, "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		if action != "updateDnsRecords" {
+			return nil, nil
+		}
+		return jsonResponse(`{"action":"updateDnsRecords","status":"error","statuscode":5028,"shortmessage":"Validation Error.","longmessage":"Record does not exist."}`), nil
+	})
+
+	err := c.DeleteDnsRecords(ctx, "example.com", records)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 5028 {
+		t.Fatalf("got %v, want the APIError of the write", err)
+	}
+	if _, ok := c.dnsRecordsByDomain.get("example.com"); ok {
+		t.Error("records still cached after a failed delete")
+	}
+}
+
+func TestDeleteDnsRecordsNothing(t *testing.T) {
+	c, transport := newTestClient(t)
+	if err := c.DeleteDnsRecords(context.Background(), "example.com", nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := transport.count("updateDnsRecords"); n != 0 {
+		t.Errorf("sent %d updateDnsRecords calls for no records", n)
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// Tracks domains whose DNSSEC re-signing delay was already reported,
+// so writing many records to a signed zone reports it only once. Resources
+// of a run report concurrently, so it is guarded by mu.
+type dnssecNotice struct {
+	mu       sync.Mutex
+	disabled bool
+	notified map[string]bool
+}
+
+// SetDnssecNotice enables or disables DnssecNotice
+func (c *CCPClient) SetDnssecNotice(enabled bool) {
+	c.dnssecNotice.mu.Lock()
+	defer c.dnssecNotice.mu.Unlock()
+
+	c.dnssecNotice.disabled = !enabled
+}
+
+// Whether a notice for the domain is still due
+func (n *dnssecNotice) due(domainName string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return !n.disabled && !n.notified[domainName]
+}
+
+// Mark the domain as notified. Reports false if another caller did first.
+func (n *dnssecNotice) take(domainName string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.notified[domainName] {
+		return false
+	}
+	if n.notified == nil {
+		n.notified = make(map[string]bool)
+	}
+	n.notified[domainName] = true
+	return true
+}
+
+// DnssecNotice reports true the first time it is called for a domain with
+// DNSSEC enabled. The zone is read through the zone cache, so at most one
+// infoDnsZone request is sent per domain. Safe for concurrent use.
+func (c *CCPClient) DnssecNotice(ctx context.Context, domainName string) (bool, error) {
+	if !c.dnssecNotice.due(domainName) {
+		return false, nil
+	}
+
+	zone, err := c.GetDnsZone(ctx, domainName)
+	if err != nil {
+		return false, err
+	}
+	if !zone.DNSSecStatus {
+		return false, nil
+	}
+
+	return c.dnssecNotice.take(domainName), nil
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestDnssecNoticeOncePerSignedZone(t *testing.T) {
+	c, transport := newTestClient(t)
+	transport.backend.zone("signed.example").settings.DNSSecStatus = true
+
+	ctx := context.Background()
+	var mu sync.Mutex
+	notices := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			notify, err := c.DnssecNotice(ctx, "signed.example")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if notify {
+				mu.Lock()
+				notices++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if notices != 1 {
+		t.Errorf("expected exactly one notice for 50 writes to a signed zone, got %d", notices)
+	}
+}
+
+func TestDnssecNoticeUnsignedAndDisabled(t *testing.T) {
+	c, transport := newTestClient(t)
+	transport.backend.zone("signed.example").settings.DNSSecStatus = true
+	ctx := context.Background()
+
+	if notify, err := c.DnssecNotice(ctx, "unsigned.example"); err != nil || notify {
+		t.Errorf("expected no notice for an unsigned zone, got %v, %v", notify, err)
+	}
+
+	c.SetDnssecNotice(false)
+	if notify, err := c.DnssecNotice(ctx, "signed.example"); err != nil || notify {
+		t.Errorf("expected no notice while disabled, got %v, %v", notify, err)
+	}
+
+	c.SetDnssecNotice(true)
+	if notify, err := c.DnssecNotice(ctx, "signed.example"); err != nil || !notify {
+		t.Errorf("expected a notice once enabled again, got %v, %v", notify, err)
+	}
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"strings"
+	"sync"
+)
+
+// Resource types managing the DNSSEC status of domains while the provider
+// runs, to detect a zone and a dnssec resource setting it for the same domain.
+// Like planned records, claims don't outlive an operation.
+type dnssecOwners struct {
+	mu     sync.Mutex
+	owners map[string]string
+}
+
+// ClaimDnssec registers resourceType as managing the DNSSEC status of the
+// domain. If a resource of another type claimed the domain before, it returns
+// that type and false. Resources of the same type may claim a domain repeatedly,
+// as Terraform plans a replaced resource twice. Safe for concurrent use by parallel plans.
+func (c *CCPClient) ClaimDnssec(domainName string, resourceType string) (string, bool) {
+	domainName = strings.ToLower(strings.TrimSuffix(domainName, "."))
+
+	c.dnssecOwners.mu.Lock()
+	defer c.dnssecOwners.mu.Unlock()
+
+	if previous, ok := c.dnssecOwners.owners[domainName]; ok && previous != resourceType {
+		return previous, false
+	}
+	if c.dnssecOwners.owners == nil {
+		c.dnssecOwners.owners = make(map[string]string)
+	}
+	c.dnssecOwners.owners[domainName] = resourceType
+	return resourceType, true
+}
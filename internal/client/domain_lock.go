@@ -0,0 +1,46 @@
+package client
+
+import (
+	"strings"
+	"sync"
+)
+
+// Locks serializing the writes to a domain. updateDnsRecords calls of the same
+// domain can lose records when they overlap, and reads of a domain overlapping a
+// write could cache the records from before it. Different domains don't block each other.
+type domainLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.RWMutex
+}
+
+func newDomainLocks() *domainLocks {
+	return &domainLocks{locks: make(map[string]*sync.RWMutex)}
+}
+
+func (l *domainLocks) get(domainName string) *sync.RWMutex {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	name := strings.ToLower(domainName)
+	lock, ok := l.locks[name]
+	if !ok {
+		lock = &sync.RWMutex{}
+		l.locks[name] = lock
+	}
+	return lock
+}
+
+// Hold the domain exclusively while writing to it. Returns the unlock function.
+func (l *domainLocks) write(domainName string) func() {
+	lock := l.get(domainName)
+	lock.Lock()
+	return lock.Unlock
+}
+
+// Hold the domain while reading its records, waiting for writes in progress.
+// Returns the unlock function.
+func (l *domainLocks) read(domainName string) func() {
+	lock := l.get(domainName)
+	lock.RLock()
+	return lock.RUnlock
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+)
+
+// Text of an API response as valid UTF-8. Netcup sends some messages, like
+// German long messages, in ISO-8859-1, so invalid bytes are read as Latin-1.
+// Bytes that aren't printable Latin-1 either, like C1 controls, become U+FFFD.
+func validText(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+
+	var s strings.Builder
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError && size <= 1 {
+			if b[0] >= 0xA0 {
+				s.WriteRune(rune(b[0]))
+			} else {
+				s.WriteRune(utf8.RuneError)
+			}
+			b = b[1:]
+			continue
+		}
+		s.Write(b[:size])
+		b = b[size:]
+	}
+	return s.String()
+}
+
+// Decode the messages of a response that isn't valid UTF-8 again from the raw
+// body. encoding/json replaces invalid bytes with U+FFFD, which loses umlauts
+// validText could restore.
+func (r *ResponseBody) repairMessages(body []byte) {
+	if utf8.Valid(body) {
+		return
+	}
+
+	var raw struct {
+		ShortMessage json.RawMessage `json:"shortmessage"`
+		LongMessage  json.RawMessage `json:"longmessage"`
+	}
+	if json.Unmarshal(body, &raw) != nil {
+		return
+	}
+	r.ShortMessage = repairedMessage(raw.ShortMessage, r.ShortMessage)
+	r.LongMessage = repairedMessage(raw.LongMessage, r.LongMessage)
+}
+
+func repairedMessage(raw json.RawMessage, decoded string) string {
+	var message string
+	if len(raw) == 0 || json.Unmarshal([]byte(validText(raw)), &message) != nil {
+		return decoded
+	}
+	return message
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestValidText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{"ascii", []byte("Login failed."), "Login failed."},
+		{"utf-8", []byte("Die Daten sind ungültig."), "Die Daten sind ungültig."},
+		{"latin-1", []byte("Die Daten sind ung\xfcltig. Gro\xdf"), "Die Daten sind ungültig. Groß"},
+		{"utf-8 mixed with latin-1", []byte("gültig, ung\xfcltig"), "gültig, ungültig"},
+		{"c1 control", []byte("a\x85b"), "a�b"},
+		{"truncated utf-8", []byte("ung\xc3"), "ungÃ"},
+		{"nbsp", []byte("a\xa0b"), "a b"},
+	}
+	for _, tt := range tests {
+		got := validText(tt.in)
+		if got != tt.want {
+			t.Errorf("%s: validText(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("%s: validText(%q) = %q isn't valid UTF-8", tt.name, tt.in, got)
+		}
+	}
+}
+
+// Respond to every request after the login with a captured response of testdata/responses
+func respondWithCaptured(t *testing.T, transport *scriptedTransport, status int, name string) {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "responses", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if utf8.Valid(body) {
+		t.Fatalf("%s is valid UTF-8, the test needs a Latin-1 payload", name)
+	}
+	transport.setIntercept(func(req *http.Request, action string, count int, _ []byte) (*http.Response, error) {
+		return &http.Response{
+			Status:     http.StatusText(status),
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"text/html; charset=ISO-8859-1"}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}, nil
+	})
+}
+
+func TestLatin1APIErrorMessages(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(0, 0))
+	respondWithCaptured(t, transport, http.StatusOK, "infoDnsRecords_latin1.json")
+
+	_, err := c.GetDnsRecords(context.Background(), "example.com")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want an APIError", err)
+	}
+	if want := "Validierungsfehler für Domain."; apiErr.ShortMessage != want {
+		t.Errorf("short message %q, want %q", apiErr.ShortMessage, want)
+	}
+	if want := "Die übergebenen Daten sind ungültig. Bitte prüfen Sie die Groß- und Kleinschreibung."; apiErr.LongMessage != want {
+		t.Errorf("long message %q, want %q", apiErr.LongMessage, want)
+	}
+	if !utf8.ValidString(err.Error()) || strings.ContainsRune(err.Error(), utf8.RuneError) {
+		t.Errorf("error %q isn't clean UTF-8", err)
+	}
+}
+
+// Retried statuscodes decode their messages the same way
+func TestLatin1RetryableAPIErrorMessages(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(1, 0), WithRetryStatusCodes([]int{5028}, nil))
+	respondWithCaptured(t, transport, http.StatusOK, "infoDnsRecords_latin1.json")
+
+	_, err := c.GetDnsRecords(context.Background(), "example.com")
+	if n := transport.count("infoDnsRecords"); n != 2 {
+		t.Errorf("sent %d infoDnsRecords requests, want a retry", n)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !strings.Contains(apiErr.LongMessage, "ungültig") {
+		t.Errorf("got %v, want the long message with umlauts", err)
+	}
+}
+
+func TestLatin1HTTPErrorBody(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(0, 0))
+	respondWithCaptured(t, transport, http.StatusBadGateway, "html_bad_gateway_latin1.html")
+
+	_, err := c.GetDnsRecords(context.Background(), "example.com")
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("got %v, want an HTTPError", err)
+	}
+	if !strings.Contains(httpErr.Body, "vorübergehend nicht verfügbar") || !strings.Contains(httpErr.Body, "später") {
+		t.Errorf("body %q lost its umlauts", httpErr.Body)
+	}
+	if !utf8.ValidString(err.Error()) {
+		t.Errorf("error %q isn't valid UTF-8", err)
+	}
+}
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Statuscodes of the CCP API with special handling. The API reports many
+// unrelated errors with them, so they are told apart by their messages too.
+const (
+	// also reported for invalid request data, next to expired sessions
+	StatusSessionExpired = 4001
+	// reported for validation errors like invalid credentials, and for the rate limit
+	StatusValidationError = 4013
+)
+
+// Message fragments of rate limited requests, compared in lowercase. The API
+// answers them with statuscode 4013 and "More than 180 requests per minute.",
+// see testdata/responses/login_rate_limited.json.
+var rateLimitMessages = []string{"requests per minute", "too many requests"}
+
+// ErrRecordNotFound is wrapped by errors of lookups finding no record, e.g.
+// because it was deleted in the customer control panel
+var ErrRecordNotFound = errors.New("could not find DNS record")
+
+// Error reported by the CCP API in the body of a response
+type APIError struct {
+	Action       string
+	StatusCode   int
+	ShortMessage string
+	LongMessage  string
+}
+
+func (e *APIError) Error() string {
+	if e.LongMessage != "" {
+		return fmt.Sprintf("%s failed with statuscode %d: %s (%s)", e.Action, e.StatusCode, e.ShortMessage, e.LongMessage)
+	}
+	return fmt.Sprintf("%s failed with statuscode %d: %s", e.Action, e.StatusCode, e.ShortMessage)
+}
+
+// Whether the API rejected the request because of its rate limit
+func (e *APIError) IsRateLimited() bool {
+	// an account locked after too many failed logins stays locked when retried
+	return containsAny(strings.ToLower(e.ShortMessage+" "+e.LongMessage), rateLimitMessages)
+}
+
+// HTTP response with a status other than 200 OK
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("status: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// Reasons a login can fail
+type LoginErrorKind int
+
+const (
+	LoginErrorUnknown LoginErrorKind = iota
+	LoginErrorCredentials
+	LoginErrorThrottled
+	LoginErrorUnreachable
+	LoginErrorAPINotActivated
+)
+
+// Message fragments of failed logins, compared in lowercase
+var (
+	notActivatedMessages = []string{"not activated", "not enabled", "not active", "inactive", "deactivated", "disabled"}
+	blockedMessages      = []string{"locked", "blocked", "too many failed"}
+	credentialMessages   = []string{"invalid", "wrong", "incorrect", "not found", "unknown", "authentication", "password", "apikey", "api key", "customer"}
+)
+
+// ClassifyLoginError tells apart the common reasons for a failed login
+func ClassifyLoginError(err error) LoginErrorKind {
+	var rateLimitErr *RateLimitError
+	var apiErr *APIError
+	var httpErr *HTTPError
+	var decodeErr *DecodeError
+	var netErr net.Error
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &rateLimitErr):
+		return LoginErrorThrottled
+	case errors.As(err, &apiErr):
+		message := strings.ToLower(apiErr.ShortMessage + " " + apiErr.LongMessage)
+		switch {
+		case apiErr.IsRateLimited() || containsAny(message, blockedMessages):
+			return LoginErrorThrottled
+		case containsAny(message, notActivatedMessages):
+			return LoginErrorAPINotActivated
+		case containsAny(message, credentialMessages):
+			return LoginErrorCredentials
+		default:
+			return LoginErrorUnknown
+		}
+	case errors.As(err, &httpErr), errors.As(err, &decodeErr), errors.As(err, &netErr), errors.As(err, &urlErr):
+		return LoginErrorUnreachable
+	default:
+		return LoginErrorUnknown
+	}
+}
+
+func containsAny(s string, fragments []string) bool {
+	for _, fragment := range fragments {
+		if strings.Contains(s, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// Response body that could not be decoded, e.g. an HTML error page or truncated JSON
+type DecodeError struct {
+	Action string
+	Reason string
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("could not decode %s response: %s: %s", e.Action, e.Reason, e.Err)
+	}
+	return fmt.Sprintf("could not decode %s response: %s", e.Action, e.Reason)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Err returns an *APIError if the response does not report success
+func (r ResponseBody) Err() error {
+	if r.Status == "success" {
+		return nil
+	}
+	return &APIError{
+		Action:       r.Action,
+		StatusCode:   r.StatusCode,
+		ShortMessage: r.ShortMessage,
+		LongMessage:  r.LongMessage,
+	}
+}
+
+type rawResponse struct {
+	ResponseBody
+	ResponseData json.RawMessage `json:"responsedata"`
+}
+
+// decodeResponse checks the status of a response before decoding its
+// responsedata into data. Failed responses carry an empty string as
+// responsedata, so they can't be decoded into the typed response structs.
+// Every failure is returned as *APIError or *DecodeError.
+func decodeResponse(action string, body []byte, data interface{}) error {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return &DecodeError{Action: action, Reason: "empty response body"}
+	}
+	if trimmed[0] != '{' {
+		reason := "response is not a JSON object"
+		if trimmed[0] == '<' {
+			reason = "received HTML instead of JSON"
+		}
+		return &DecodeError{Action: action, Reason: reason}
+	}
+
+	res := rawResponse{}
+	err := json.Unmarshal(trimmed, &res)
+	if err != nil {
+		return &DecodeError{Action: action, Reason: classifyJSONError(err), Err: err}
+	}
+	res.repairMessages(trimmed)
+
+	if res.Action == "" {
+		res.Action = action
+	}
+	if err := res.Err(); err != nil {
+		return err
+	}
+
+	if data == nil || isEmptyResponseData(res.ResponseData) {
+		return nil
+	}
+	err = json.Unmarshal(res.ResponseData, data)
+	if err != nil {
+		return &DecodeError{Action: action, Reason: "unexpected responsedata", Err: err}
+	}
+	return nil
+}
+
+func classifyJSONError(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr):
+		return "unexpected field type"
+	case errors.As(err, &syntaxErr) && syntaxErr.Error() == "unexpected end of JSON input":
+		return "truncated JSON"
+	default:
+		return "invalid JSON"
+	}
+}
+
+// The API returns "" or null as responsedata when there is nothing to return
+func isEmptyResponseData(data json.RawMessage) bool {
+	d := bytes.TrimSpace(data)
+	return len(d) == 0 || bytes.Equal(d, []byte(`""`)) || bytes.Equal(d, []byte("null"))
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Log in against a login response of testdata/responses, sent with the HTTP status
+func loginWithResponse(t *testing.T, status int, name string) error {
+	t.Helper()
+
+	body, err := os.ReadFile(filepath.Join("testdata", "responses", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := newTestTransport()
+	transport.setIntercept(func(req *http.Request, action string, count int, _ []byte) (*http.Response, error) {
+		return &http.Response{
+			Status:     http.StatusText(status),
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}, nil
+	})
+	c := newClient(WithMemoryBackend(), withTransport(transport), WithRetries(0, 0))
+	return c.login(context.Background(), "12345", "key", "password")
+}
+
+func TestClassifyLoginError(t *testing.T) {
+	tests := []struct {
+		response string
+		status   int
+		want     LoginErrorKind
+	}{
+		{"login_invalid_credentials.json", http.StatusOK, LoginErrorCredentials},
+		{"login_api_not_activated.json", http.StatusOK, LoginErrorAPINotActivated},
+		{"login_account_locked.json", http.StatusOK, LoginErrorThrottled},
+		{"login_rate_limited.json", http.StatusOK, LoginErrorThrottled},
+		{"login_internal_error.json", http.StatusOK, LoginErrorUnknown},
+		{"html_bad_gateway.html", http.StatusBadGateway, LoginErrorUnreachable},
+		{"empty.json", http.StatusOK, LoginErrorUnreachable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.response, func(t *testing.T) {
+			err := loginWithResponse(t, tt.status, tt.response)
+			if err == nil {
+				t.Fatal("login succeeded")
+			}
+			if got := ClassifyLoginError(err); got != tt.want {
+				t.Errorf("classified %q as %d, want %d", err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyLoginErrorWithoutResponse(t *testing.T) {
+	c := newClient(WithEndpoint("https://127.0.0.1:1/run/webservice/servers/endpoint.php?JSON"), WithRetries(0, 0))
+	err := c.login(context.Background(), "12345", "key", "password")
+	if got := ClassifyLoginError(err); got != LoginErrorUnreachable {
+		t.Errorf("classified %q as %d, want unreachable", err, got)
+	}
+
+	if got := ClassifyLoginError(errors.New("something else")); got != LoginErrorUnknown {
+		t.Errorf("classified an unrelated error as %d, want unknown", got)
+	}
+}
+
+// Retrying a locked login would only keep the account locked
+func TestLockedLoginNotRetried(t *testing.T) {
+	transport := newTestTransport()
+	transport.setIntercept(func(req *http.Request, action string, count int, _ []byte) (*http.Response, error) {
+		return jsonResponse(`{"action":"login","status":"error","statuscode":4000,"shortmessage":"Login failed.","longmessage":"The account is locked because of too many failed login attempts."}`), nil
+	})
+	c := newClient(WithMemoryBackend(), withTransport(transport))
+
+	err := c.login(context.Background(), "12345", "key", "password")
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		t.Errorf("locked login reported as rate limited: %s", err)
+	}
+	if logins := transport.count("login"); logins != 1 {
+		t.Errorf("sent %d logins for a locked account, want 1", logins)
+	}
+}
+
+func TestIsRateLimited(t *testing.T) {
+	tests := []struct {
+		err  APIError
+		want bool
+	}{
+		{APIError{StatusCode: StatusValidationError, ShortMessage: "Validation Error.", LongMessage: "More than 180 requests per minute. Please wait and retry later."}, true},
+		{APIError{StatusCode: StatusValidationError, ShortMessage: "Validation Error.", LongMessage: "The apikey or apipassword is invalid."}, false},
+		{APIError{StatusCode: StatusValidationError}, false},
+		{APIError{StatusCode: 4000, LongMessage: "Too many requests, please wait."}, true},
+		{APIError{StatusCode: 4000, LongMessage: "The account is locked because of too many failed login attempts."}, false},
+		{APIError{StatusCode: 5028, ShortMessage: "Validation Error."}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.err.IsRateLimited(); got != tt.want {
+			t.Errorf("IsRateLimited of %+v = %t, want %t", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/svetob/terraform-provider-netcupdns/internal/dnstypes"
+)
+
+// Criteria to look up records of a zone. Empty fields match any value.
+// Hostnames are compared case-insensitively, with "@" and the bare domain
+// name both matching the apex. Destinations are compared like
+// dnstypes.NormalizeDestination, so quoted TXT values match their value.
+type RecordFilter struct {
+	Hostname    string
+	Type        string
+	Destination string
+	Priority    string
+}
+
+// NormalizeHostname lowercases a hostname relative to its zone and represents
+// the apex (empty, "@" or the bare domainname) as "@". A single trailing dot is
+// dropped, and absolute names like "www.example.com." are made relative to the zone.
+func NormalizeHostname(hostname, domainName string) string {
+	h := strings.ToLower(hostname)
+	zone := strings.ToLower(strings.TrimSuffix(domainName, "."))
+	if strings.HasSuffix(h, ".") {
+		h = strings.TrimSuffix(strings.TrimSuffix(h, "."), "."+zone)
+	}
+	if h == "" || h == "@" || h == zone {
+		return "@"
+	}
+	return h
+}
+
+// RecordIdentity is the key of a record without priority. Hostnames are
+// normalized like NormalizeHostname, types are case-insensitive and destinations
+// are normalized like dnstypes.NormalizeDestination.
+func RecordIdentity(hostname, recordType, destination, domainName string) string {
+	return NormalizeHostname(hostname, domainName) + "|" + strings.ToUpper(recordType) + "|" + dnstypes.NormalizeDestination(recordType, destination)
+}
+
+// OutsideZone reports whether hostname is an absolute name with a trailing dot
+// that doesn't belong to the zone, e.g. "www.example.org." in "example.com".
+// Shorter names like "www." are taken as relative names with a stray dot.
+func OutsideZone(hostname, domainName string) bool {
+	if !strings.HasSuffix(hostname, ".") {
+		return false
+	}
+	name := strings.ToLower(strings.TrimSuffix(hostname, "."))
+	zone := strings.ToLower(strings.TrimSuffix(domainName, "."))
+	if name == zone || strings.HasSuffix(name, "."+zone) {
+		return false
+	}
+	return strings.Count(name, ".") >= strings.Count(zone, ".")
+}
+
+func (f RecordFilter) matches(record DnsRecord, domainName string) bool {
+	if f.Hostname != "" && NormalizeHostname(record.Hostname, domainName) != NormalizeHostname(f.Hostname, domainName) {
+		return false
+	}
+	if f.Type != "" && !strings.EqualFold(record.Type, f.Type) {
+		return false
+	}
+	if f.Destination != "" && dnstypes.NormalizeDestination(record.Type, record.Destination) != dnstypes.NormalizeDestination(record.Type, f.Destination) {
+		return false
+	}
+	if f.Priority != "" && record.Priority != f.Priority {
+		return false
+	}
+	return true
+}
+
+// GetDnsRecordsFiltered returns the records of a zone matching the filter, in
+// API order. The records are read through the cache and returned as copies.
+func (c *CCPClient) GetDnsRecordsFiltered(ctx context.Context, domainName string, filter RecordFilter) ([]DnsRecord, error) {
+	zone, err := c.zoneRecords(ctx, domainName)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := zone.records
+	if hostname := NormalizeHostname(filter.Hostname, domainName); filter.Hostname != "" && hostname != "@" && filter.Type != "" {
+		// the apex may be stored in several spellings, so only other names use the index
+		candidates = zone.findByName(hostname, filter.Type)
+	}
+
+	matches := []DnsRecord{}
+	for _, record := range candidates {
+		if filter.matches(record, domainName) {
+			matches = append(matches, record)
+		}
+	}
+	return matches, nil
+}
+
+// GetDnsRecordByFields returns the single record matching the filter
+func (c *CCPClient) GetDnsRecordByFields(ctx context.Context, domainName string, filter RecordFilter) (*DnsRecord, error) {
+	matches, err := c.GetDnsRecordsFiltered(ctx, domainName, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w with hostname %s, type %s and destination %s for domain %s", ErrRecordNotFound, filter.Hostname, filter.Type, filter.Destination, domainName)
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, fmt.Errorf("found %d DNS records with hostname %s, type %s and destination %s for domain %s", len(matches), filter.Hostname, filter.Type, filter.Destination, domainName)
+	}
+}
@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRecordFilterMatchesDestination(t *testing.T) {
+	tests := []struct {
+		name   string
+		record DnsRecord
+		filter RecordFilter
+		want   bool
+	}{
+		{"quoted TXT", DnsRecord{Hostname: "@", Type: "TXT", Destination: `"v=spf1 -all"`}, RecordFilter{Destination: "v=spf1 -all"}, true},
+		{"chunked TXT", DnsRecord{Hostname: "@", Type: "TXT", Destination: `"a" "b"`}, RecordFilter{Destination: "ab"}, true},
+		{"padded A", DnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"}, RecordFilter{Destination: " 192.0.2.1 "}, true},
+		{"CAA quotes", DnsRecord{Hostname: "@", Type: "CAA", Destination: `0 issue "letsencrypt.org"`}, RecordFilter{Destination: "0 issue letsencrypt.org"}, false},
+		{"other destination", DnsRecord{Hostname: "@", Type: "TXT", Destination: `"a"`}, RecordFilter{Destination: "b"}, false},
+		{"apex hostname", DnsRecord{Hostname: "Example.com", Type: "A", Destination: "192.0.2.1"}, RecordFilter{Hostname: "@", Type: "a"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matches(tt.record, "example.com"); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Zone with records of every dimension a filter looks at, the apex spelled in several ways
+func filterZone(t *testing.T) (*CCPClient, *scriptedTransport) {
+	t.Helper()
+
+	c, transport := newTestClient(t)
+	seedRecords(t, transport, "example.com",
+		DnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+		DnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.2"},
+		DnsRecord{Hostname: "WWW", Type: "AAAA", Destination: "2001:db8::1"},
+		DnsRecord{Hostname: "@", Type: "A", Destination: "192.0.2.1"},
+		DnsRecord{Hostname: "example.com", Type: "TXT", Destination: `"v=spf1 -all"`},
+		DnsRecord{Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+		DnsRecord{Hostname: "@", Type: "MX", Priority: "20", Destination: "mail.example.com"},
+		DnsRecord{Hostname: "mail", Type: "A", Destination: "192.0.2.1"},
+	)
+	return c, transport
+}
+
+// hostname/type/destination/priority of records, sorted
+func recordSummaries(records []DnsRecord) []string {
+	summaries := []string{}
+	for _, record := range records {
+		summaries = append(summaries, strings.Join([]string{record.Hostname, record.Type, record.Destination, record.Priority}, "/"))
+	}
+	sort.Strings(summaries)
+	return summaries
+}
+
+func TestGetDnsRecordsFiltered(t *testing.T) {
+	c, transport := filterZone(t)
+
+	tests := []struct {
+		name   string
+		filter RecordFilter
+		want   []string
+	}{
+		{"hostname", RecordFilter{Hostname: "www"}, []string{"www/A/192.0.2.1/0", "www/A/192.0.2.2/0", "www/AAAA/2001:db8::1/0"}},
+		{"hostname case-insensitive", RecordFilter{Hostname: "Www"}, []string{"www/A/192.0.2.1/0", "www/A/192.0.2.2/0", "www/AAAA/2001:db8::1/0"}},
+		{"absolute hostname", RecordFilter{Hostname: "mail.example.com."}, []string{"mail/A/192.0.2.1/0"}},
+		{"apex as @", RecordFilter{Hostname: "@"}, []string{"@/A/192.0.2.1/0", "@/MX/mail.example.com/10", "@/MX/mail.example.com/20", `example.com/TXT/"v=spf1 -all"/0`}},
+		{"apex as domain name", RecordFilter{Hostname: "Example.com"}, []string{"@/A/192.0.2.1/0", "@/MX/mail.example.com/10", "@/MX/mail.example.com/20", `example.com/TXT/"v=spf1 -all"/0`}},
+		{"type", RecordFilter{Type: "aaaa"}, []string{"www/AAAA/2001:db8::1/0"}},
+		{"destination", RecordFilter{Destination: "192.0.2.1"}, []string{"@/A/192.0.2.1/0", "mail/A/192.0.2.1/0", "www/A/192.0.2.1/0"}},
+		{"normalized destination", RecordFilter{Destination: "v=spf1 -all"}, []string{`example.com/TXT/"v=spf1 -all"/0`}},
+		{"priority", RecordFilter{Priority: "20"}, []string{"@/MX/mail.example.com/20"}},
+		{"hostname and type", RecordFilter{Hostname: "WWW", Type: "A"}, []string{"www/A/192.0.2.1/0", "www/A/192.0.2.2/0"}},
+		{"apex and type", RecordFilter{Hostname: "@", Type: "TXT"}, []string{`example.com/TXT/"v=spf1 -all"/0`}},
+		{"hostname, type and destination", RecordFilter{Hostname: "www", Type: "A", Destination: "192.0.2.2"}, []string{"www/A/192.0.2.2/0"}},
+		{"type and destination", RecordFilter{Type: "A", Destination: "192.0.2.1"}, []string{"@/A/192.0.2.1/0", "mail/A/192.0.2.1/0", "www/A/192.0.2.1/0"}},
+		{"all fields", RecordFilter{Hostname: "@", Type: "MX", Destination: "mail.example.com", Priority: "10"}, []string{"@/MX/mail.example.com/10"}},
+		{"no match", RecordFilter{Hostname: "www", Type: "MX"}, []string{}},
+		{"unknown hostname", RecordFilter{Hostname: "ftp", Type: "A"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := c.GetDnsRecordsFiltered(context.Background(), "example.com", tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if records == nil {
+				t.Error("got nil, want an empty list without matches")
+			}
+			if got := recordSummaries(records); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("empty filter", func(t *testing.T) {
+		records, err := c.GetDnsRecordsFiltered(context.Background(), "example.com", RecordFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 8 {
+			t.Errorf("got %d records, want all 8", len(records))
+		}
+	})
+
+	if reads := transport.count("infoDnsRecords"); reads != 1 {
+		t.Errorf("read the zone %d times, want the filters to use the cached zone", reads)
+	}
+}
+
+// Callers may change the records they get without changing the cached zone
+func TestGetDnsRecordsFilteredReturnsCopy(t *testing.T) {
+	c, _ := filterZone(t)
+	ctx := context.Background()
+	filter := RecordFilter{Hostname: "mail", Type: "A"}
+
+	records, err := c.GetDnsRecordsFiltered(ctx, "example.com", filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records[0].Destination = "198.51.100.1"
+
+	again, err := c.GetDnsRecordsFiltered(ctx, "example.com", filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recordSummaries(again); !reflect.DeepEqual(got, []string{"mail/A/192.0.2.1/0"}) {
+		t.Errorf("cached zone changed by a caller to %q", got)
+	}
+}
+
+func TestGetDnsRecordByFields(t *testing.T) {
+	c, _ := filterZone(t)
+	ctx := context.Background()
+
+	record, err := c.GetDnsRecordByFields(ctx, "example.com", RecordFilter{Hostname: "www", Type: "A", Destination: "192.0.2.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Destination != "192.0.2.2" || record.Id == "" {
+		t.Errorf("got %+v, want the record of 192.0.2.2", record)
+	}
+
+	if _, err := c.GetDnsRecordByFields(ctx, "example.com", RecordFilter{Hostname: "ftp", Type: "A"}); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("got %v, want ErrRecordNotFound", err)
+	}
+
+	_, err = c.GetDnsRecordByFields(ctx, "example.com", RecordFilter{Hostname: "www", Type: "A"})
+	if err == nil || errors.Is(err, ErrRecordNotFound) || !strings.Contains(err.Error(), "found 2 DNS records") {
+		t.Errorf("got %v, want an error naming the 2 matching records", err)
+	}
+}
+
+func TestNormalizeHostname(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     string
+	}{
+		{"", "@"},
+		{"@", "@"},
+		{"example.com", "@"},
+		{"Example.COM.", "@"},
+		{"www", "www"},
+		{"WWW", "www"},
+		{"www.", "www"},
+		{"www.example.com.", "www"},
+		{"a.b.example.com.", "a.b"},
+		{"www.example.com", "www.example.com"},
+		{"www..", "www."},
+	}
+	for _, tt := range tests {
+		if got := NormalizeHostname(tt.hostname, "example.com"); got != tt.want {
+			t.Errorf("NormalizeHostname(%q) = %q, want %q", tt.hostname, got, tt.want)
+		}
+	}
+}
+
+func TestOutsideZone(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     bool
+	}{
+		{"www", false},
+		{"www.", false},
+		{"example.com.", false},
+		{"www.example.com.", false},
+		{"WWW.Example.COM.", false},
+		{"www.example.org.", true},
+		{"notexample.com.", true},
+		{"www.example.com", false},
+	}
+	for _, tt := range tests {
+		if got := OutsideZone(tt.hostname, "example.com"); got != tt.want {
+			t.Errorf("OutsideZone(%q) = %t, want %t", tt.hostname, got, tt.want)
+		}
+	}
+}
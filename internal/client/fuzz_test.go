@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Add the captured responses in testdata/responses whose name starts with prefix
+func addResponseCorpus(f *testing.F, prefixes ...string) {
+	f.Helper()
+
+	files, err := filepath.Glob(filepath.Join("testdata", "responses", "*"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		name := filepath.Base(file)
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				body, err := os.ReadFile(file)
+				if err != nil {
+					f.Fatal(err)
+				}
+				f.Add(body)
+				break
+			}
+		}
+	}
+}
+
+// Whether err is one of the errors the client documents for failed responses
+func classified(err error) bool {
+	var apiErr *APIError
+	var decodeErr *DecodeError
+	return errors.As(err, &apiErr) || errors.As(err, &decodeErr)
+}
+
+func FuzzParseDnsRecordsResponse(f *testing.F) {
+	addResponseCorpus(f, "infoDnsRecords_", "html_", "empty")
+	f.Add([]byte(`{"status":"success","responsedata":{"dnsrecords":null}}`))
+	f.Add([]byte(`{"status":"success","responsedata":{"dnsrecords":[{"id":1}]}}`))
+	f.Add([]byte(`{"status":"success","responsedata":[]}`))
+	f.Add([]byte(`{"status":"error","statuscode":"5029","shortmessage":7}`))
+	f.Add([]byte(`{"status":"success","responsedata":{"dnsrecords":[{"unknown":{"nested":[[{}]]}}]}}`))
+
+	c := newClient()
+	c.SetStrictDecoding(true)
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		records := DnsRecordSet{}
+		err := decodeResponse("infoDnsRecords", body, &records)
+		if err != nil && !classified(err) {
+			t.Errorf("decodeResponse returned the unclassified error %T: %s", err, err)
+		}
+
+		// the strict check walks the unknown fields of successful responses
+		err = c.decode(context.Background(), "infoDnsRecords", body, &DnsRecordSet{})
+		if err != nil && !classified(err) {
+			t.Errorf("decode returned the unclassified error %T: %s", err, err)
+		}
+	})
+}
+
+func FuzzParseLoginResponse(f *testing.F) {
+	addResponseCorpus(f, "login_", "html_", "empty")
+	f.Add([]byte(`{"status":"success","responsedata":{"apisessionid":12}}`))
+	f.Add([]byte(`{"status":"success","responsedata":{"apisessionid":""}}`))
+	f.Add([]byte(`{"status":"success","responsedata":"session"}`))
+	f.Add([]byte(`{"action":"login","status":"error","statuscode":4013,"longmessage":"ü\xff"}`))
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		transport := newTestTransport()
+		transport.intercept = func(req *http.Request, action string, count int, _ []byte) (*http.Response, error) {
+			return jsonResponse(string(body)), nil
+		}
+		c := newClient(WithMemoryBackend(), WithRetries(0, 0), withTransport(transport))
+
+		err := c.login(context.Background(), "12345", "key", "password")
+		if err != nil {
+			var rateLimitErr *RateLimitError
+			if !classified(err) && !errors.As(err, &rateLimitErr) {
+				t.Errorf("login returned the unclassified error %T: %s", err, err)
+			}
+			return
+		}
+		if c.auth().SessionId == "" {
+			t.Error("login succeeded without a session id")
+		}
+	})
+}
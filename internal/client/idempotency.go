@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+)
+
+// Create that failed without a response and appeared in the zone only partly.
+// It is neither retried nor adopted, as either could leave duplicate or missing records.
+type UnconfirmedCreateError struct {
+	DomainName string
+	Missing    []NewDnsRecord
+	Err        error
+}
+
+func (e *UnconfirmedCreateError) Error() string {
+	return fmt.Sprintf("create of DNS records for domain %s failed without response and appeared only partly, missing %s: %s",
+		e.DomainName, formatNewRecords(e.Missing), e.Err)
+}
+
+func (e *UnconfirmedCreateError) Unwrap() error {
+	return e.Err
+}
+
+// Whether a request failed without a response in a way worth retrying, like a
+// timeout or a dropped connection. The request may have been processed anyway.
+func transientError(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Records a request creates. Reads, updates and deletes are keyed by id, so
+// repeating them is safe, but repeating a create that was processed duplicates records.
+func requestedCreates(param interface{}) (string, []NewDnsRecord) {
+	switch request := param.(type) {
+	case CreateDnsRecordsRequest:
+		return request.DomainName, request.DnsRecordSet.DnsRecords
+	case UpdateDnsRecordsRequest:
+		var creates []NewDnsRecord
+		for _, record := range request.DnsRecordSet.DnsRecords {
+			if record.Id == "" && !record.DeleteRecord {
+				creates = append(creates, NewDnsRecord{
+					Hostname:    record.Hostname,
+					Type:        record.Type,
+					Priority:    record.Priority,
+					Destination: record.Destination,
+				})
+			}
+		}
+		return request.DomainName, creates
+	}
+	return "", nil
+}
+
+// Read the zone after a create failed without response to tell whether it was
+// processed. Only records missing from before count as created, so records
+// like the requested ones that existed already aren't taken for them. Returns
+// the infoDnsRecords response in place of the lost updateDnsRecords response
+// if all records appeared, nil if none did.
+func (c *CCPClient) createdRecords(ctx context.Context, domainName string, before *zoneRecords, creates []NewDnsRecord, sendErr error) ([]byte, error) {
+	body, err := c.doRequestContext(ctx, "infoDnsRecords", DomainInfoRequest{
+		AuthData:   c.auth(),
+		DomainName: domainName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordSet := DnsRecordSet{}
+	err = c.decode(ctx, "infoDnsRecords", body, &recordSet)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = matchNewRecords(domainName, before.records, recordSet.DnsRecords, creates)
+	var matchErr *MatchError
+	switch {
+	case err == nil:
+		return body, nil
+	case !errors.As(err, &matchErr):
+		return nil, err
+	case len(matchErr.Missing) == len(creates):
+		return nil, nil
+	}
+	return nil, &UnconfirmedCreateError{DomainName: domainName, Missing: append(matchErr.Missing, matchErr.Ambiguous...), Err: sendErr}
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Pass the first updateDnsRecords request to the backend, but fail it without
+// response like a timeout after the API processed it. edit may change the
+// records the backend processes.
+func processThenFail(transport *scriptedTransport, edit func(records []interface{}) []interface{}) {
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		if action != "updateDnsRecords" || count != 1 {
+			return nil, nil
+		}
+		if edit != nil {
+			var request map[string]interface{}
+			_ = json.Unmarshal(body, &request)
+			recordSet := request["param"].(map[string]interface{})["dnsrecordset"].(map[string]interface{})
+			recordSet["dnsrecords"] = edit(recordSet["dnsrecords"].([]interface{}))
+			body, _ = json.Marshal(request)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		res, err := transport.backend.RoundTrip(req)
+		if err == nil {
+			res.Body.Close()
+		}
+		return nil, io.ErrUnexpectedEOF
+	})
+}
+
+// Fail the first updateDnsRecords request without passing it to the backend
+func failUnprocessed(transport *scriptedTransport) {
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		if action == "updateDnsRecords" && count == 1 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, nil
+	})
+}
+
+func zoneRecordCount(t *testing.T, transport *scriptedTransport, domainName string) int {
+	t.Helper()
+	transport.backend.mu.Lock()
+	defer transport.backend.mu.Unlock()
+	return len(transport.backend.zone(domainName).records)
+}
+
+var testRecord = NewDnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"}
+
+func TestCreateProcessedBeforeTimeout(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(2, time.Millisecond))
+	processThenFail(transport, nil)
+
+	record, err := c.CreateDnsRecord(context.Background(), "example.com", testRecord)
+	if err != nil {
+		t.Fatalf("CreateDnsRecord failed: %s", err)
+	}
+	if n := transport.count("updateDnsRecords"); n != 1 {
+		t.Errorf("sent %d updateDnsRecords calls, want 1 as the create was processed", n)
+	}
+	if n := zoneRecordCount(t, transport, "example.com"); n != 1 {
+		t.Errorf("zone has %d records, want exactly 1", n)
+	}
+	if record.Id == "" {
+		t.Errorf("created record %v has no id", record)
+	}
+}
+
+func TestCreateNotProcessedBeforeTimeout(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(2, time.Millisecond))
+	failUnprocessed(transport)
+
+	if _, err := c.CreateDnsRecord(context.Background(), "example.com", testRecord); err != nil {
+		t.Fatalf("CreateDnsRecord failed: %s", err)
+	}
+	if n := transport.count("updateDnsRecords"); n != 2 {
+		t.Errorf("sent %d updateDnsRecords calls, want 2 as the create is retried", n)
+	}
+	if n := zoneRecordCount(t, transport, "example.com"); n != 1 {
+		t.Errorf("zone has %d records, want exactly 1", n)
+	}
+}
+
+// An identical record existing before the create isn't taken for the created one
+func TestCreateTimeoutWithIdenticalRecord(t *testing.T) {
+	t.Run("not processed", func(t *testing.T) {
+		c, transport := newTestClient(t, WithRetries(2, time.Millisecond))
+		existing := seedRecords(t, transport, "example.com", DnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"})
+		failUnprocessed(transport)
+
+		record, err := c.CreateDnsRecord(context.Background(), "example.com", testRecord)
+		if err != nil {
+			t.Fatalf("CreateDnsRecord failed: %s", err)
+		}
+		if n := transport.count("updateDnsRecords"); n != 2 {
+			t.Errorf("sent %d updateDnsRecords calls, want 2 as the create is retried", n)
+		}
+		if record.Id == existing[0].Id {
+			t.Errorf("create returned the existing record %s", existing[0].Id)
+		}
+		if n := zoneRecordCount(t, transport, "example.com"); n != 2 {
+			t.Errorf("zone has %d records, want 2", n)
+		}
+	})
+
+	t.Run("processed", func(t *testing.T) {
+		c, transport := newTestClient(t, WithRetries(2, time.Millisecond))
+		existing := seedRecords(t, transport, "example.com", DnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"})
+		processThenFail(transport, nil)
+
+		record, err := c.CreateDnsRecord(context.Background(), "example.com", testRecord)
+		if err != nil {
+			t.Fatalf("CreateDnsRecord failed: %s", err)
+		}
+		if n := transport.count("updateDnsRecords"); n != 1 {
+			t.Errorf("sent %d updateDnsRecords calls, want 1", n)
+		}
+		if record.Id == existing[0].Id {
+			t.Errorf("create returned the existing record %s", existing[0].Id)
+		}
+		if n := zoneRecordCount(t, transport, "example.com"); n != 2 {
+			t.Errorf("zone has %d records, want 2", n)
+		}
+	})
+}
+
+func TestCreatePartlyProcessedBeforeTimeout(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(2, time.Millisecond))
+	processThenFail(transport, func(records []interface{}) []interface{} {
+		return records[:1]
+	})
+
+	_, err := c.CreateDnsRecords(context.Background(), "example.com", []NewDnsRecord{
+		testRecord,
+		{Hostname: "api", Type: "A", Destination: "192.0.2.2"},
+	})
+	var unconfirmed *UnconfirmedCreateError
+	if !errors.As(err, &unconfirmed) {
+		t.Fatalf("got error %v, want an UnconfirmedCreateError", err)
+	}
+	if len(unconfirmed.Missing) != 1 || unconfirmed.Missing[0].Hostname != "api" {
+		t.Errorf("missing %v, want the api record", unconfirmed.Missing)
+	}
+	if n := transport.count("updateDnsRecords"); n != 1 {
+		t.Errorf("sent %d updateDnsRecords calls, want 1 as a partly processed create isn't retried", n)
+	}
+}
+
+// Without a snapshot of the zone a create failed without response can't be
+// told apart and isn't retried
+func TestCreateWithoutSnapshotNotRetried(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(2, time.Millisecond))
+	failUnprocessed(transport)
+
+	_, err := c.doRequestContext(context.Background(), "updateDnsRecords", CreateDnsRecordsRequest{
+		DomainInfoRequest: DomainInfoRequest{AuthData: c.auth(), DomainName: "example.com"},
+		DnsRecordSet:      NewDnsRecordSet{DnsRecords: []NewDnsRecord{testRecord}},
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("got error %v, want the failure of the request", err)
+	}
+	if n := transport.count("updateDnsRecords"); n != 1 {
+		t.Errorf("sent %d updateDnsRecords calls, want 1", n)
+	}
+}
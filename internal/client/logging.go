@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Name of the tflog subsystem API requests are logged to. Its level is set
+// separately with TF_LOG_PROVIDER_NETCUP_API, e.g. to TRACE to see the bodies.
+const LogSubsystem = "netcup_api"
+
+// Log a warning to the API subsystem, e.g. about a retried request
+func logWarn(ctx context.Context, message string, fields map[string]interface{}) {
+	ctx = tflog.NewSubsystem(ctx, LogSubsystem)
+	tflog.SubsystemWarn(ctx, LogSubsystem, message, fields)
+}
+
+func logInfo(ctx context.Context, message string, fields map[string]interface{}) {
+	ctx = tflog.NewSubsystem(ctx, LogSubsystem)
+	tflog.SubsystemInfo(ctx, LogSubsystem, message, fields)
+}
+
+// Log an exchange with the API, the outcome on DEBUG and the bodies on TRACE.
+// Session ids, API keys and passwords are redacted like in dumps.
+func logExchange(ctx context.Context, action string, request []byte, statusCode int, response []byte, err error, elapsed time.Duration) {
+	ctx = tflog.NewSubsystem(ctx, LogSubsystem)
+	fields := map[string]interface{}{
+		"action":      action,
+		"duration_ms": elapsed.Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemDebug(ctx, LogSubsystem, "API request failed", fields)
+		return
+	}
+
+	fields["status_code"] = statusCode
+	tflog.SubsystemDebug(ctx, LogSubsystem, "API request", fields)
+	tflog.SubsystemTrace(ctx, LogSubsystem, "API request bodies", map[string]interface{}{
+		"action":   action,
+		"request":  string(redactJSON(request)),
+		"response": string(redactJSON(response)),
+	})
+}
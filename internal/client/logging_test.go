@@ -0,0 +1,85 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+// Log entries of the netcup_api subsystem written to output
+func subsystemEntries(t *testing.T, output *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	entries, err := tflogtest.MultilineJSONDecode(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subsystem []map[string]interface{}
+	for _, entry := range entries {
+		if entry["@module"] == "provider."+LogSubsystem {
+			subsystem = append(subsystem, entry)
+		}
+	}
+	return subsystem
+}
+
+func findEntry(entries []map[string]interface{}, message string) map[string]interface{} {
+	for _, entry := range entries {
+		if entry["@message"] == message {
+			return entry
+		}
+	}
+	return nil
+}
+
+func TestRequestsLoggedToSubsystem(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	c, _ := newTestClient(t)
+	if err := c.login(ctx, "12345", "key", "secret-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := subsystemEntries(t, &output)
+	request := findEntry(entries, "API request")
+	if request == nil {
+		t.Fatalf("expected the request to be logged to %s, got %v", LogSubsystem, entries)
+	}
+	if request["@level"] != "debug" || request["action"] != "login" || request["status_code"] != float64(200) {
+		t.Errorf("unexpected request entry %v", request)
+	}
+	for field := range request {
+		switch field {
+		case "@level", "@message", "@module", "@timestamp", "@caller", "action", "duration_ms", "status_code":
+		default:
+			t.Errorf("unexpected field %s in request entry", field)
+		}
+	}
+
+	bodies := findEntry(entries, "API request bodies")
+	if bodies == nil || bodies["@level"] != "trace" {
+		t.Fatalf("expected the bodies to be logged on TRACE, got %v", bodies)
+	}
+	if strings.Contains(bodies["request"].(string), "secret-password") {
+		t.Errorf("password not redacted from logged request %s", bodies["request"])
+	}
+}
+
+func TestRetriesLoggedToSubsystem(t *testing.T) {
+	c, transport := newTestClient(t)
+	expireSession(transport, c.auth().SessionId)
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	if _, err := c.GetDnsRecords(ctx, "example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := findEntry(subsystemEntries(t, &output), "API session expired, logging in again")
+	if entry == nil || entry["@level"] != "warn" || entry["action"] != "infoDnsRecords" {
+		t.Errorf("expected a warning about the renewed session in %s, got %v", LogSubsystem, entry)
+	}
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error of a bulk write whose requested records could not be told apart in the response
+type MatchError struct {
+	Missing   []NewDnsRecord
+	Ambiguous []NewDnsRecord
+}
+
+func (e *MatchError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "records did not appear after write: "+formatNewRecords(e.Missing))
+	}
+	if len(e.Ambiguous) > 0 {
+		parts = append(parts, "records matched ambiguously: "+formatNewRecords(e.Ambiguous))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func formatNewRecords(records []NewDnsRecord) string {
+	formatted := make([]string, 0, len(records))
+	for _, record := range records {
+		formatted = append(formatted, fmt.Sprintf("%s %s %s", record.Hostname, record.Type, record.Destination))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// Composite key of a record, see RecordIdentity. Requests without priority are looked up without it.
+func matchKey(domainName, hostname, recordType, destination, priority string) string {
+	return RecordIdentity(hostname, recordType, destination, domainName) + "|" + priority
+}
+
+func (r NewDnsRecord) matchKey(domainName string) string {
+	return matchKey(domainName, r.Hostname, r.Type, r.Destination, r.Priority)
+}
+
+// matchNewRecords resolves every requested record to a record that appeared
+// in after but was not part of before, in a single pass over both sets.
+// A key matching more new records than were requested with it is ambiguous.
+func matchNewRecords(domainName string, before, after []DnsRecord, requested []NewDnsRecord) ([]DnsRecord, error) {
+	existing := make(map[string]bool, len(before))
+	for _, record := range before {
+		existing[record.Id] = true
+	}
+
+	appeared := make(map[string][]DnsRecord)
+	for _, record := range after {
+		if existing[record.Id] {
+			continue
+		}
+		full := matchKey(domainName, record.Hostname, record.Type, record.Destination, record.Priority)
+		appeared[full] = append(appeared[full], record)
+		partial := matchKey(domainName, record.Hostname, record.Type, record.Destination, "")
+		if partial != full {
+			appeared[partial] = append(appeared[partial], record)
+		}
+	}
+
+	wanted := make(map[string]int)
+	for _, record := range requested {
+		wanted[record.matchKey(domainName)]++
+	}
+
+	matchErr := &MatchError{}
+	used := make(map[string]bool)
+	matched := make([]DnsRecord, 0, len(requested))
+	for _, record := range requested {
+		key := record.matchKey(domainName)
+		candidates := appeared[key]
+		if len(candidates) > wanted[key] {
+			matchErr.Ambiguous = append(matchErr.Ambiguous, record)
+			continue
+		}
+
+		found := false
+		for _, candidate := range candidates {
+			if !used[candidate.Id] {
+				used[candidate.Id] = true
+				matched = append(matched, candidate)
+				found = true
+				break
+			}
+		}
+		if !found {
+			matchErr.Missing = append(matchErr.Missing, record)
+		}
+	}
+
+	if len(matchErr.Missing) > 0 || len(matchErr.Ambiguous) > 0 {
+		return nil, matchErr
+	}
+	return matched, nil
+}
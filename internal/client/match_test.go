@@ -0,0 +1,173 @@
+package client
+
+import (
+	"errors"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Records requested by the random batches, with overlapping and duplicate
+// entries and hostnames and types written in different ways
+var matchPool = []NewDnsRecord{
+	{Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+	{Hostname: "WWW", Type: "a", Destination: "192.0.2.1"},
+	{Hostname: "www.example.com.", Type: "A", Destination: "192.0.2.2"},
+	{Hostname: "@", Type: "A", Destination: "192.0.2.1"},
+	{Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+	{Hostname: "@", Type: "MX", Priority: "20", Destination: "mail.example.com"},
+	{Hostname: "mail", Type: "txt", Destination: `"v=spf1 -all"`},
+	{Hostname: "mail", Type: "TXT", Destination: "v=spf1 -all"},
+}
+
+// Record as listed by the API after it was written
+func written(record NewDnsRecord, id int) DnsRecord {
+	priority := record.Priority
+	if priority == "" {
+		priority = "0"
+	}
+	return DnsRecord{
+		Id:          strconv.Itoa(id),
+		Hostname:    NormalizeHostname(record.Hostname, "example.com"),
+		Type:        strings.ToUpper(record.Type),
+		Priority:    priority,
+		Destination: record.Destination,
+		State:       "yes",
+	}
+}
+
+// Random zone and batch: the zone already has some of the requested records,
+// the batch requests some records more than once, and after lists the zone
+// with one new record per request in random order
+type matchCase struct {
+	before    []DnsRecord
+	requested []NewDnsRecord
+	after     []DnsRecord
+	// ids of the records written for each request
+	newIds map[string]bool
+}
+
+func randomMatchCase(r *rand.Rand) matchCase {
+	var c matchCase
+	id := 0
+	for i := r.Intn(10); i > 0; i-- {
+		id++
+		c.before = append(c.before, written(matchPool[r.Intn(len(matchPool))], id))
+	}
+	c.after = append(c.after, c.before...)
+	c.newIds = make(map[string]bool)
+	for i := 1 + r.Intn(12); i > 0; i-- {
+		record := matchPool[r.Intn(len(matchPool))]
+		c.requested = append(c.requested, record)
+		id++
+		c.after = append(c.after, written(record, id))
+		c.newIds[strconv.Itoa(id)] = true
+	}
+	r.Shuffle(len(c.after), func(i, j int) { c.after[i], c.after[j] = c.after[j], c.after[i] })
+	return c
+}
+
+func countKey(records []NewDnsRecord, key string) int {
+	n := 0
+	for _, record := range records {
+		if record.matchKey("example.com") == key {
+			n++
+		}
+	}
+	return n
+}
+
+// Every request resolves to a record of its own that appeared with the write
+func TestMatchNewRecordsProperties(t *testing.T) {
+	for seed := int64(0); seed < 500; seed++ {
+		c := randomMatchCase(rand.New(rand.NewSource(seed)))
+
+		matched, err := matchNewRecords("example.com", c.before, c.after, c.requested)
+		if err != nil {
+			t.Fatalf("seed %d: %s", seed, err)
+		}
+		if len(matched) != len(c.requested) {
+			t.Fatalf("seed %d: matched %d records for %d requests", seed, len(matched), len(c.requested))
+		}
+		seen := make(map[string]bool)
+		for i, record := range matched {
+			if !c.newIds[record.Id] {
+				t.Errorf("seed %d: request %+v matched record %s, which was not written by the batch", seed, c.requested[i], record.Id)
+			}
+			if seen[record.Id] {
+				t.Errorf("seed %d: record %s matched by more than one request", seed, record.Id)
+			}
+			seen[record.Id] = true
+			if !c.requested[i].Matches(record, "example.com") {
+				t.Errorf("seed %d: request %+v matched the different record %+v", seed, c.requested[i], record)
+			}
+		}
+	}
+}
+
+// A record that didn't appear is reported as missing, and only that one
+func TestMatchNewRecordsMissingProperty(t *testing.T) {
+	for seed := int64(0); seed < 500; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		c := randomMatchCase(r)
+
+		var dropped DnsRecord
+		after := make([]DnsRecord, 0, len(c.after))
+		for _, i := range r.Perm(len(c.after)) {
+			if record := c.after[i]; c.newIds[record.Id] && dropped.Id == "" {
+				dropped = record
+				continue
+			}
+			after = append(after, c.after[i])
+		}
+
+		_, err := matchNewRecords("example.com", c.before, after, c.requested)
+		var matchErr *MatchError
+		if !errors.As(err, &matchErr) {
+			t.Fatalf("seed %d: got %v, want a MatchError", seed, err)
+		}
+		if len(matchErr.Missing) != 1 || len(matchErr.Ambiguous) != 0 {
+			t.Fatalf("seed %d: got missing %v and ambiguous %v, want a single missing record", seed, matchErr.Missing, matchErr.Ambiguous)
+		}
+		if missing := matchErr.Missing[0]; !missing.Matches(dropped, "example.com") {
+			t.Errorf("seed %d: reported %+v missing, but %+v didn't appear", seed, missing, dropped)
+		}
+	}
+}
+
+// A record written by someone else with the same key as requested records
+// makes all of them ambiguous, and none is matched to a guessed id
+func TestMatchNewRecordsAmbiguousProperty(t *testing.T) {
+	for seed := int64(0); seed < 500; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		c := randomMatchCase(r)
+
+		request := c.requested[r.Intn(len(c.requested))]
+		key := request.matchKey("example.com")
+		after := append(append([]DnsRecord(nil), c.after...), written(request, 1000))
+
+		_, err := matchNewRecords("example.com", c.before, after, c.requested)
+		var matchErr *MatchError
+		if !errors.As(err, &matchErr) {
+			t.Fatalf("seed %d: got %v, want a MatchError", seed, err)
+		}
+		if len(matchErr.Missing) != 0 {
+			t.Errorf("seed %d: got missing records %v", seed, matchErr.Missing)
+		}
+		if want := countKey(c.requested, key); len(matchErr.Ambiguous) != want || countKey(matchErr.Ambiguous, key) != want {
+			t.Errorf("seed %d: got ambiguous %v, want the %d requests of %+v", seed, matchErr.Ambiguous, want, request)
+		}
+	}
+}
+
+func TestMatchErrorMessage(t *testing.T) {
+	err := &MatchError{
+		Missing:   []NewDnsRecord{{Hostname: "www", Type: "A", Destination: "192.0.2.1"}},
+		Ambiguous: []NewDnsRecord{{Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"}},
+	}
+	want := "records did not appear after write: www A 192.0.2.1; records matched ambiguously: @ MX mail.example.com"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
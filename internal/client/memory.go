@@ -0,0 +1,263 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Endpoint of clients using the memory backend
+const MemoryURL = "memory://"
+
+// Statuscode of requests the memory backend rejects
+const memoryErrorStatusCode = 5000
+
+// Record types the memory backend stores a priority for
+var memoryPriorityTypes = map[string]bool{"MX": true, "SRV": true}
+
+// Zones of the memory backend, shared by all clients of the process like the real API
+var memory = newMemoryBackend()
+
+// WithMemoryBackend sends all requests to an in-memory fake of the API instead of
+// Netcup, e.g. to run configurations in CI without network and credentials.
+// Zones are created on first use and live as long as the process.
+func WithMemoryBackend() Option {
+	return func(c *CCPClient) {
+		c.hostURL = MemoryURL
+		c.httpClient.Transport = memory
+	}
+}
+
+// NewMemoryBackend returns a separate in-memory fake of the API with zones of
+// its own, e.g. to answer the requests of a test server.
+func NewMemoryBackend() http.RoundTripper {
+	return newMemoryBackend()
+}
+
+// In-memory fake of the actions of the CCP API the client uses. Ids are
+// assigned in ascending order, so runs against an empty backend are deterministic.
+type memoryBackend struct {
+	mu       sync.Mutex
+	zones    map[string]*memoryZone
+	lastId   int
+	sessions int
+	requests int
+}
+
+type memoryZone struct {
+	settings DnsZone
+	serial   int
+	records  []DnsRecord
+}
+
+type memoryRequest struct {
+	Action string `json:"action"`
+	Param  struct {
+		DomainName   string       `json:"domainname"`
+		DnsRecordSet DnsRecordSet `json:"dnsrecordset"`
+		DnsZone      DnsZone      `json:"dnszone"`
+	} `json:"param"`
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{zones: make(map[string]*memoryZone)}
+}
+
+func (m *memoryBackend) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests++
+	var request memoryRequest
+	var response map[string]interface{}
+	if err := json.Unmarshal(body, &request); err != nil {
+		response = m.failure("", "Invalid request", err.Error())
+	} else {
+		response = m.handle(req.Context(), request)
+	}
+
+	rb, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(rb)),
+		Request:    req,
+	}, nil
+}
+
+func (m *memoryBackend) handle(ctx context.Context, request memoryRequest) map[string]interface{} {
+	switch request.Action {
+	case "login":
+		m.sessions++
+		logWarn(ctx, "Using the in-memory backend, no real DNS records are read or changed", nil)
+		return m.success(request.Action, SessionData{SessionId: "memory-session-" + strconv.Itoa(m.sessions)})
+	case "logout":
+		return m.success(request.Action, "")
+	}
+
+	// like domains of other accounts, domains of the reserved top-level domain .invalid don't exist
+	if strings.HasSuffix(strings.ToLower(request.Param.DomainName), ".invalid") {
+		return m.failure(request.Action, "Domain not found.", "The domain "+request.Param.DomainName+" doesn't belong to the account.")
+	}
+
+	switch request.Action {
+	case "infoDnsZone":
+		return m.success(request.Action, m.zone(request.Param.DomainName).info())
+	case "updateDnsZone":
+		zone := m.zone(request.Param.DomainName)
+		settings := request.Param.DnsZone
+		for _, value := range []string{settings.TTL, settings.Refresh, settings.Retry, settings.Expire} {
+			if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+				return m.failure(request.Action, "Validation Error.", "TTL, refresh, retry and expire of a zone must be positive numbers.")
+			}
+		}
+		zone.settings.TTL = settings.TTL
+		zone.settings.Refresh = settings.Refresh
+		zone.settings.Retry = settings.Retry
+		zone.settings.Expire = settings.Expire
+		zone.settings.DNSSecStatus = settings.DNSSecStatus
+		zone.serial++
+		return m.success(request.Action, zone.info())
+	case "infoDnsRecords":
+		zone := m.zone(request.Param.DomainName)
+		return m.success(request.Action, DnsRecordSet{DnsRecords: zone.records})
+	case "updateDnsRecords":
+		zone := m.zone(request.Param.DomainName)
+		records, err := m.update(zone.records, request.Param.DnsRecordSet.DnsRecords)
+		if err != "" {
+			return m.failure(request.Action, "Validation Error.", err)
+		}
+		zone.records = records
+		zone.serial++
+		return m.success(request.Action, DnsRecordSet{DnsRecords: zone.records})
+	}
+	return m.failure(request.Action, "Unknown action", "The memory backend doesn't support the action "+request.Action+".")
+}
+
+// Apply an update to a copy of the records of a zone, so a rejected update changes nothing
+func (m *memoryBackend) update(current []DnsRecord, changes []DnsRecord) ([]DnsRecord, string) {
+	records := append([]DnsRecord(nil), current...)
+	lastId := m.lastId
+	for _, change := range changes {
+		if change.Hostname == "" || change.Type == "" || change.Destination == "" {
+			return nil, "Hostname, type and destination of a record must not be empty."
+		}
+		// like the API, hostnames are stored lower-case and destinations trimmed
+		change.Hostname = strings.ToLower(change.Hostname)
+		change.Destination = strings.TrimSpace(change.Destination)
+
+		if change.Id == "" {
+			lastId++
+			change.Id = strconv.Itoa(lastId)
+			change.State = "yes"
+			change.Priority = memoryPriority(change.Type, change.Priority, "")
+			records = append(records, change)
+			continue
+		}
+
+		i := memoryRecordIndex(records, change.Id)
+		if i < 0 {
+			return nil, "The DNS record with id " + change.Id + " does not exist."
+		}
+		if change.DeleteRecord {
+			records = append(records[:i], records[i+1:]...)
+			continue
+		}
+		change.State = "yes"
+		change.Priority = memoryPriority(change.Type, change.Priority, records[i].Priority)
+		records[i] = change
+	}
+
+	m.lastId = lastId
+	sort.SliceStable(records, func(i, j int) bool {
+		a, _ := strconv.Atoi(records[i].Id)
+		b, _ := strconv.Atoi(records[j].Id)
+		return a < b
+	})
+	return records, ""
+}
+
+// Priority stored for a record, which like the API is zeroed for types other than MX and SRV
+// and kept from the prior record if none is given
+func memoryPriority(recordType string, priority string, prior string) string {
+	switch {
+	case !memoryPriorityTypes[strings.ToUpper(recordType)]:
+		return "0"
+	case priority != "":
+		return priority
+	case prior != "":
+		return prior
+	}
+	return "0"
+}
+
+func memoryRecordIndex(records []DnsRecord, id string) int {
+	for i, record := range records {
+		if record.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Zone of a domain, created empty on first use
+func (m *memoryBackend) zone(domainName string) *memoryZone {
+	name := strings.ToLower(domainName)
+	zone, ok := m.zones[name]
+	if !ok {
+		zone = &memoryZone{
+			settings: DnsZone{Name: domainName, TTL: "86400", Refresh: "28800", Retry: "7200", Expire: "1209600"},
+			serial:   2024010100,
+		}
+		m.zones[name] = zone
+	}
+	return zone
+}
+
+func (z *memoryZone) info() DnsZone {
+	info := z.settings
+	info.Serial = strconv.Itoa(z.serial)
+	return info
+}
+
+func (m *memoryBackend) success(action string, data interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"serverrequestid": "memory-" + strconv.Itoa(m.requests),
+		"clientrequestid": "",
+		"action":          action,
+		"status":          "success",
+		"statuscode":      2000,
+		"shortmessage":    action + " finished in memory",
+		"longmessage":     "",
+		"responsedata":    data,
+	}
+}
+
+func (m *memoryBackend) failure(action string, shortMessage string, longMessage string) map[string]interface{} {
+	return map[string]interface{}{
+		"serverrequestid": "memory-" + strconv.Itoa(m.requests),
+		"clientrequestid": "",
+		"action":          action,
+		"status":          "error",
+		"statuscode":      memoryErrorStatusCode,
+		"shortmessage":    shortMessage,
+		"longmessage":     longMessage,
+		"responsedata":    "",
+	}
+}
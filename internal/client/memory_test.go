@@ -0,0 +1,227 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Transport in front of a memory backend counting the actions it receives.
+// If intercept returns a response or error, it is used instead of the backend's.
+type scriptedTransport struct {
+	backend   *memoryBackend
+	mu        sync.Mutex
+	actions   map[string]int
+	intercept func(req *http.Request, action string, count int, body []byte) (*http.Response, error)
+}
+
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var request memoryRequest
+	_ = json.Unmarshal(body, &request)
+
+	s.mu.Lock()
+	s.actions[request.Action]++
+	count := s.actions[request.Action]
+	intercept := s.intercept
+	s.mu.Unlock()
+
+	if intercept != nil {
+		if res, err := intercept(req, request.Action, count, body); res != nil || err != nil {
+			return res, err
+		}
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return s.backend.RoundTrip(req)
+}
+
+// Number of requests sent with action
+func (s *scriptedTransport) count(action string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.actions[action]
+}
+
+// Set the intercept function, safe while requests are sent
+func (s *scriptedTransport) setIntercept(intercept func(req *http.Request, action string, count int, body []byte) (*http.Response, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.intercept = intercept
+}
+
+// Response of the API with the given body
+func jsonResponse(body string) *http.Response {
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+	}
+}
+
+// Logged in client against a fresh memory backend, so tests don't share zones
+func newTestClient(t testing.TB, opts ...Option) (*CCPClient, *scriptedTransport) {
+	t.Helper()
+
+	transport := &scriptedTransport{backend: newMemoryBackend(), actions: make(map[string]int)}
+	c := newClient(append([]Option{WithMemoryBackend()}, opts...)...)
+	c.httpClient.Transport = transport
+	if err := c.login(context.Background(), "12345", "key", "password"); err != nil {
+		t.Fatalf("login failed: %s", err)
+	}
+	return c, transport
+}
+
+// Add records to a zone of the backend without going through the client
+func seedRecords(t testing.TB, transport *scriptedTransport, domainName string, records ...DnsRecord) []DnsRecord {
+	t.Helper()
+
+	backend := transport.backend
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+
+	zone := backend.zone(domainName)
+	updated, err := backend.update(zone.records, records)
+	if err != "" {
+		t.Fatalf("seeding records failed: %s", err)
+	}
+	zone.records = updated
+	return append([]DnsRecord(nil), updated...)
+}
+
+// The memory backend assigns ids in ascending order and normalizes records like the API
+func TestMemoryBackendCrud(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+
+	before, err := c.RefreshDnsZone(ctx, "example.com")
+	if err != nil {
+		t.Fatalf("RefreshDnsZone failed: %s", err)
+	}
+
+	created, err := c.CreateDnsRecords(ctx, "example.com", []NewDnsRecord{
+		{Hostname: "WWW", Type: "A", Destination: "192.0.2.1"},
+		{Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+		{Hostname: "_sip._tcp", Type: "SRV", Priority: "5", Destination: "10 5060 sip.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("CreateDnsRecords failed: %s", err)
+	}
+	want := []DnsRecord{
+		{Id: "1", Hostname: "www", Type: "A", Priority: "0", Destination: "192.0.2.1", State: "yes"},
+		{Id: "2", Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com", State: "yes"},
+		{Id: "3", Hostname: "_sip._tcp", Type: "SRV", Priority: "5", Destination: "10 5060 sip.example.com", State: "yes"},
+	}
+	if !reflect.DeepEqual(created, want) {
+		t.Errorf("created %+v, want %+v", created, want)
+	}
+
+	// an update without a priority keeps the stored one
+	updated, err := c.UpdateDnsRecord(ctx, "example.com", DnsRecord{Id: "2", Hostname: "@", Type: "MX", Destination: "backup.example.com"})
+	if err != nil {
+		t.Fatalf("UpdateDnsRecord failed: %s", err)
+	}
+	if updated.Priority != "10" || updated.Destination != "backup.example.com" {
+		t.Errorf("updated %+v", updated)
+	}
+
+	if err := c.DeleteDnsRecord(ctx, "example.com", created[0]); err != nil {
+		t.Fatalf("DeleteDnsRecord failed: %s", err)
+	}
+	records, err := c.GetDnsRecords(ctx, "example.com")
+	if err != nil {
+		t.Fatalf("GetDnsRecords failed: %s", err)
+	}
+	if got := recordIds(records); !equalStrings(got, []string{"2", "3"}) {
+		t.Errorf("records %v remain, want 2 and 3", got)
+	}
+
+	// ids aren't reused after a delete
+	next, err := c.CreateDnsRecord(ctx, "example.com", NewDnsRecord{Hostname: "api", Type: "A", Destination: "192.0.2.2"})
+	if err != nil {
+		t.Fatalf("CreateDnsRecord failed: %s", err)
+	}
+	if next.Id != "4" {
+		t.Errorf("created id %s, want 4", next.Id)
+	}
+
+	after, err := c.RefreshDnsZone(ctx, "example.com")
+	if err != nil {
+		t.Fatalf("RefreshDnsZone failed: %s", err)
+	}
+	if b, _ := strconv.Atoi(before.Serial); after.Serial != strconv.Itoa(b+4) {
+		t.Errorf("serial %s after 4 writes, was %s", after.Serial, before.Serial)
+	}
+}
+
+// A rejected update changes no record of the zone, like the API
+func TestMemoryBackendRejectsUpdates(t *testing.T) {
+	c, transport := newTestClient(t)
+	ctx := context.Background()
+	seeded := seedRecords(t, transport, "example.com",
+		DnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+		DnsRecord{Hostname: "api", Type: "A", Destination: "192.0.2.2"})
+
+	_, err := c.ChangeDnsRecords(ctx, "example.com", RecordChanges{Update: []DnsRecord{
+		{Id: seeded[0].Id, Hostname: "www", Type: "A", Destination: "192.0.2.9"},
+		{Id: "999", Hostname: "gone", Type: "A", Destination: "192.0.2.3"},
+	}})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !strings.Contains(apiErr.LongMessage, "999") {
+		t.Fatalf("got %v, want an APIError naming the unknown id", err)
+	}
+	records, err := c.GetDnsRecords(ctx, "example.com")
+	if err != nil {
+		t.Fatalf("GetDnsRecords failed: %s", err)
+	}
+	if !reflect.DeepEqual(records, seeded) {
+		t.Errorf("records %+v after a rejected update, want %+v", records, seeded)
+	}
+
+	_, err = c.CreateDnsRecord(ctx, "example.com", NewDnsRecord{Hostname: "www", Type: "A"})
+	if !errors.As(err, &apiErr) {
+		t.Errorf("got %v for a record without destination, want an APIError", err)
+	}
+
+	// domains of .invalid don't belong to the account
+	if _, err := c.GetDnsRecords(ctx, "example.invalid"); !errors.As(err, &apiErr) || apiErr.ShortMessage != "Domain not found." {
+		t.Errorf("got %v, want Domain not found.", err)
+	}
+}
+
+// Separate clients of the process see the same zones, like sessions of one account
+func TestMemoryBackendSharedByClients(t *testing.T) {
+	ctx := context.Background()
+	first, err := NewCCPClient(ctx, "12345", "key", "password", WithMemoryBackend())
+	if err != nil {
+		t.Fatalf("NewCCPClient failed: %s", err)
+	}
+	second, err := NewCCPClient(ctx, "12345", "key", "password", WithMemoryBackend())
+	if err != nil {
+		t.Fatalf("NewCCPClient failed: %s", err)
+	}
+
+	created, err := first.CreateDnsRecord(ctx, "shared.example", NewDnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"})
+	if err != nil {
+		t.Fatalf("CreateDnsRecord failed: %s", err)
+	}
+	record, err := second.GetDnsRecordById(ctx, "shared.example", created.Id)
+	if err != nil {
+		t.Fatalf("GetDnsRecordById failed: %s", err)
+	}
+	if record.Destination != "192.0.2.1" {
+		t.Errorf("second client read %+v", record)
+	}
+}
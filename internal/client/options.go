@@ -0,0 +1,87 @@
+package client
+
+import "time"
+
+// Option configures a CCPClient created by NewCCPClient
+type Option func(*CCPClient)
+
+// WithEndpoint sends requests to url instead of HostURL, e.g. to a test server
+func WithEndpoint(url string) Option {
+	return func(c *CCPClient) {
+		c.hostURL = url
+	}
+}
+
+// WithRequestTimeout limits the time a single request may take. Values below
+// 1 keep DefaultRequestTimeout.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(c *CCPClient) {
+		if timeout > 0 {
+			c.httpClient.Timeout = timeout
+		}
+	}
+}
+
+// WithRecordCacheSize limits the number of domains whose records are cached.
+// Values below 1 keep DefaultRecordCacheSize.
+func WithRecordCacheSize(size int) Option {
+	return func(c *CCPClient) {
+		if size > 0 {
+			c.dnsRecordsByDomain = newRecordCache(size)
+		}
+	}
+}
+
+// WithMaxConcurrentRequests limits the number of requests sent at the same time.
+// Values below 1 keep DefaultMaxConcurrentRequests.
+func WithMaxConcurrentRequests(max int) Option {
+	return func(c *CCPClient) {
+		if max > 0 {
+			c.limiter = make(chan struct{}, max)
+		}
+	}
+}
+
+// WithRetries retries failed requests up to maxRetries times, doubling the
+// delay between attempts from baseDelay on. A maxRetries of 0 disables retries,
+// negative values and a baseDelay below 1 keep the defaults.
+func WithRetries(maxRetries int, baseDelay time.Duration) Option {
+	return func(c *CCPClient) {
+		if maxRetries >= 0 {
+			c.retry.maxRetries = maxRetries
+		}
+		if baseDelay > 0 {
+			c.retry.backoff.initial = baseDelay
+			if baseDelay > c.retry.backoff.max {
+				c.retry.backoff.max = baseDelay
+			}
+		}
+	}
+}
+
+// WithRetryStatusCodes retries requests failing with the statuscodes of retryOn
+// in addition to rate limited ones, and never retries those of neverRetry.
+// Statuscodes are HTTP status codes like 503 or API statuscodes like 4013.
+func WithRetryStatusCodes(retryOn, neverRetry []int) Option {
+	return func(c *CCPClient) {
+		c.retry.retryOn = statusCodeSet(retryOn)
+		c.retry.neverRetry = statusCodeSet(neverRetry)
+	}
+}
+
+// WithZoneLockedStatusCodes treats changes failing with the API statuscodes of codes
+// as blocked by another change of the zone and retries them longer than rate
+// limited requests. Netcup documents no statuscode for a locked zone.
+func WithZoneLockedStatusCodes(codes []int) Option {
+	return func(c *CCPClient) {
+		c.retry.zoneLocked = statusCodeSet(codes)
+	}
+}
+
+func statusCodeSet(codes []int) map[int]bool {
+	set := make(map[int]bool, len(codes))
+	for _, code := range codes {
+		set[code] = true
+	}
+	return set
+}
@@ -0,0 +1,30 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEndpointAndRequestTimeoutOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		endpoint string
+		timeout  time.Duration
+	}{
+		{"defaults", nil, HostURL, DefaultRequestTimeout},
+		{"endpoint", []Option{WithEndpoint("https://sandbox.example.com/endpoint.php?JSON")}, "https://sandbox.example.com/endpoint.php?JSON", DefaultRequestTimeout},
+		{"request timeout", []Option{WithRequestTimeout(time.Minute)}, HostURL, time.Minute},
+		{"zero request timeout", []Option{WithRequestTimeout(0)}, HostURL, DefaultRequestTimeout},
+		{"negative request timeout", []Option{WithRequestTimeout(-time.Second)}, HostURL, DefaultRequestTimeout},
+	}
+	for _, tt := range tests {
+		c := newClient(tt.opts...)
+		if c.hostURL != tt.endpoint {
+			t.Errorf("%s: endpoint %s, want %s", tt.name, c.hostURL, tt.endpoint)
+		}
+		if c.httpClient.Timeout != tt.timeout {
+			t.Errorf("%s: request timeout %s, want %s", tt.name, c.httpClient.Timeout, tt.timeout)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package client
+
+import "sync"
+
+// Records planned by resources while the provider runs, to detect two
+// resources of one configuration declaring the same record. Terraform starts
+// the provider anew for every plan and apply, so claims don't outlive an operation.
+type plannedRecords struct {
+	mu      sync.Mutex
+	claimed map[string]string
+}
+
+// ClaimPlannedRecord registers the record with key for owner, the id of the
+// claiming resource or empty for one that is yet to be created. If key was
+// claimed by another owner before, it returns that owner and false. Claims
+// without owner always conflict with earlier claims. Safe for concurrent use by parallel plans.
+func (c *CCPClient) ClaimPlannedRecord(key string, owner string) (string, bool) {
+	c.planned.mu.Lock()
+	defer c.planned.mu.Unlock()
+
+	if previous, ok := c.planned.claimed[key]; ok && (previous != owner || owner == "") {
+		return previous, false
+	}
+	if c.planned.claimed == nil {
+		c.planned.claimed = make(map[string]string)
+	}
+	c.planned.claimed[key] = owner
+	return owner, true
+}
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// Tests of this file are meant to run with -race. New cache or session state
+// should be exercised through concurrently, so races show up in these tests.
+
+// Run fn from n goroutines at once and wait for all of them
+func concurrently(n int, fn func(i int)) {
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			fn(i)
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
+
+func containsRecord(records []DnsRecord, id string) bool {
+	for _, record := range records {
+		if record.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// A read after a write of the same goroutine must see the write, although
+// other goroutines fill the cache of the domain all the time
+func TestConcurrentWritesNeverFollowedByStaleReads(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+	domains := []string{"one.example", "two.example", "three.example"}
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for _, domain := range domains {
+		domain := domain
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if _, err := c.GetDnsRecords(ctx, domain); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	concurrently(45, func(i int) {
+		domain := domains[i%len(domains)]
+		created, err := c.CreateDnsRecord(ctx, domain, NewDnsRecord{Hostname: fmt.Sprintf("host%d", i), Type: "A", Destination: "192.0.2.1"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		records, err := c.GetDnsRecords(ctx, domain)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !containsRecord(records, created.Id) {
+			t.Errorf("record %s of %s missing from the records read after its creation", created.Id, domain)
+		}
+
+		update := *created
+		update.Destination = "192.0.2.2"
+		if _, err := c.UpdateDnsRecord(ctx, domain, update); err != nil {
+			t.Error(err)
+			return
+		}
+		record, err := c.GetDnsRecordById(ctx, domain, created.Id)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if record.Destination != "192.0.2.2" {
+			t.Errorf("record %s of %s read with destination %s after its update", created.Id, domain, record.Destination)
+		}
+
+		if err := c.DeleteDnsRecord(ctx, domain, *record); err != nil {
+			t.Error(err)
+			return
+		}
+		records, err = c.GetDnsRecords(ctx, domain)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if containsRecord(records, created.Id) {
+			t.Errorf("record %s of %s still read after its deletion", created.Id, domain)
+		}
+	})
+
+	close(done)
+	readers.Wait()
+
+	for _, domain := range domains {
+		records, err := c.GetDnsRecords(ctx, domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 0 {
+			t.Errorf("%s has %d records left, want none", domain, len(records))
+		}
+	}
+}
+
+// Reads of a cached zone share the cached records, which must not be changed by later writes
+func TestCachedRecordsNotChangedByWrites(t *testing.T) {
+	c, transport := newTestClient(t)
+	ctx := context.Background()
+	seeded := seedRecords(t, transport, "example.com", DnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"})
+
+	before, err := c.GetDnsRecords(ctx, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	concurrently(20, func(i int) {
+		if i%2 == 0 {
+			update := seeded[0]
+			update.Destination = fmt.Sprintf("192.0.2.%d", i+10)
+			if _, err := c.UpdateDnsRecord(ctx, "example.com", update); err != nil {
+				t.Error(err)
+			}
+			return
+		}
+		records, err := c.GetDnsRecords(ctx, "example.com")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, record := range records {
+			_ = record.Destination
+		}
+	})
+
+	if before[0].Destination != "192.0.2.1" {
+		t.Errorf("records read before the updates changed to destination %s", before[0].Destination)
+	}
+}
+
+// Reads, creates and deletes failing with an expired session log in again only once
+func TestConcurrentSessionExpiryLogsInOnce(t *testing.T) {
+	c, transport := newTestClient(t)
+	ctx := context.Background()
+
+	var seeded []DnsRecord
+	for i := 0; i < 15; i++ {
+		seeded = append(seeded, seedRecords(t, transport, fmt.Sprintf("delete%d.example", i), DnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"})...)
+	}
+	expireSession(transport, c.auth().SessionId)
+
+	concurrently(45, func(i int) {
+		n := i / 3
+		var err error
+		switch i % 3 {
+		case 0:
+			_, err = c.GetDnsRecords(ctx, fmt.Sprintf("read%d.example", n))
+		case 1:
+			_, err = c.CreateDnsRecord(ctx, fmt.Sprintf("create%d.example", n), NewDnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"})
+		case 2:
+			err = c.DeleteDnsRecord(ctx, fmt.Sprintf("delete%d.example", n), seeded[n])
+		}
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	if logins := transport.count("login"); logins != 2 {
+		t.Errorf("expected the initial login and exactly one renewal, got %d logins", logins)
+	}
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// Request budget of the CCP API per customer
+const (
+	RateLimitBudget                  = 180
+	RateLimitWindow                  = time.Minute
+	DefaultRateLimitWarningThreshold = 0.8
+)
+
+// Counts requests within a rolling window. Shared by every resource using the
+// client and by the clients sharing its session, as the budget is per customer.
+type requestCounter struct {
+	mu       sync.Mutex
+	window   time.Duration
+	requests []time.Time
+	now      func() time.Time
+}
+
+func newRequestCounter(window time.Duration) *requestCounter {
+	return &requestCounter{
+		window: window,
+		now:    time.Now,
+	}
+}
+
+func (c *requestCounter) add() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests = append(c.prune(), c.now())
+}
+
+// Drop requests that left the window. Must be called with the lock held.
+func (c *requestCounter) prune() []time.Time {
+	cutoff := c.now().Add(-c.window)
+	i := 0
+	for i < len(c.requests) && !c.requests[i].After(cutoff) {
+		i++
+	}
+	return c.requests[i:]
+}
+
+// Number of requests within the window
+func (c *requestCounter) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests = c.prune()
+	return len(c.requests)
+}
+
+// Warning threshold of a client, set per provider configuration
+type rateLimitWarning struct {
+	mu        sync.Mutex
+	threshold float64
+	warned    bool
+}
+
+// take reports true the first time count crosses the threshold. It reports
+// again only after the count dropped below.
+func (w *rateLimitWarning) take(count int, budget int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if float64(count) < w.threshold*float64(budget) {
+		w.warned = false
+		return false
+	}
+	if w.warned {
+		return false
+	}
+	w.warned = true
+	return true
+}
+
+// SetRateLimitWarningThreshold sets the share of RateLimitBudget after which
+// NearRateLimit reports, e.g. 0.8 for 80%
+func (c *CCPClient) SetRateLimitWarningThreshold(threshold float64) {
+	c.rateLimitWarning.mu.Lock()
+	defer c.rateLimitWarning.mu.Unlock()
+
+	c.rateLimitWarning.threshold = threshold
+}
+
+// NearRateLimit reports the requests sent within RateLimitWindow once they
+// cross the warning threshold, so callers warn a single time per crossing
+func (c *CCPClient) NearRateLimit() (int, bool) {
+	count := c.requests.count()
+	return count, c.rateLimitWarning.take(count, RateLimitBudget)
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Clock of a request counter, moved by the test. It starts at the current
+// time, after the login of the test client was counted.
+type fakeClock struct {
+	current time.Time
+}
+
+func (f *fakeClock) now() time.Time {
+	return f.current
+}
+
+// Read n zones that aren't cached, one request each, and count the warnings reported
+func readZonesCountingWarnings(t *testing.T, c *CCPClient, prefix string, n int) (warnings int, countAtWarning int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := c.GetDnsRecords(context.Background(), fmt.Sprintf("%s%d.example", prefix, i)); err != nil {
+			t.Fatal(err)
+		}
+		if count, near := c.NearRateLimit(); near {
+			warnings++
+			countAtWarning = count
+		}
+	}
+	return warnings, countAtWarning
+}
+
+func TestNearRateLimitWarnsOnce(t *testing.T) {
+	c, _ := newTestClient(t)
+	clock := &fakeClock{current: time.Now()}
+	c.requests.now = clock.now
+	c.SetRateLimitWarningThreshold(0.1)
+
+	warnings, count := readZonesCountingWarnings(t, c, "zone", 40)
+	if warnings != 1 {
+		t.Fatalf("got %d warnings for 40 requests, want exactly one", warnings)
+	}
+	if threshold := RateLimitBudget / 10; count != threshold {
+		t.Errorf("warned at %d requests, want the threshold %d", count, threshold)
+	}
+}
+
+func TestNearRateLimitWarnsAgainAfterWindow(t *testing.T) {
+	c, _ := newTestClient(t)
+	clock := &fakeClock{current: time.Now()}
+	c.requests.now = clock.now
+	c.SetRateLimitWarningThreshold(0.1)
+
+	if warnings, _ := readZonesCountingWarnings(t, c, "first", 20); warnings != 1 {
+		t.Fatalf("got %d warnings, want one", warnings)
+	}
+
+	// the requests left the window, so the count is below the threshold again
+	clock.current = clock.current.Add(RateLimitWindow + time.Second)
+	if count, near := c.NearRateLimit(); near || count != 0 {
+		t.Fatalf("got %d requests, near %t after the window passed, want none", count, near)
+	}
+
+	if warnings, _ := readZonesCountingWarnings(t, c, "second", 20); warnings != 1 {
+		t.Errorf("got %d warnings after the count dropped, want one again", warnings)
+	}
+}
+
+func TestNearRateLimitBelowThreshold(t *testing.T) {
+	c, _ := newTestClient(t)
+	c.requests.now = (&fakeClock{current: time.Now()}).now
+
+	// the default threshold is 80% of the budget
+	warnings, _ := readZonesCountingWarnings(t, c, "zone", int(DefaultRateLimitWarningThreshold*RateLimitBudget)-2)
+	if warnings != 0 {
+		t.Errorf("got %d warnings below the default threshold, want none", warnings)
+	}
+}
+
+// The budget is per customer, so clients sharing a session count the requests of each other
+func TestRequestCounterSharedBySession(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry()
+	transport := newTestTransport()
+
+	first, err := registry.Acquire(ctx, "12345", "key", "password", WithMemoryBackend(), withTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := registry.Acquire(ctx, "12345", "key", "password", WithMemoryBackend(), withTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer registry.Close(ctx)
+	second.SetRateLimitWarningThreshold(0.1)
+
+	for i := 0; i < RateLimitBudget/10; i++ {
+		if _, err := first.GetDnsRecords(ctx, fmt.Sprintf("zone%d.example", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	count, near := second.NearRateLimit()
+	if !near {
+		t.Errorf("got %d requests and no warning from the other configuration, want a warning", count)
+	}
+	if _, near := first.NearRateLimit(); near {
+		t.Error("configuration with the default threshold warned")
+	}
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+)
+
+// Sessions shares the API session between provider configurations of the
+// same process, e.g. aliased provider blocks using identical credentials
+var Sessions = NewRegistry()
+
+// Registry of API sessions keyed by endpoint and credentials. Every Acquire
+// returns a client of its own with its options and settings, only the session
+// and the state of the account are shared: the per-domain write locks, the
+// request counter of the rate limit and the caches of the zones.
+// Sessions with different credentials are never shared.
+type Registry struct {
+	mu      sync.Mutex
+	entries map[string]*registryEntry
+}
+
+type registryEntry struct {
+	// client the session was created with, its account is shared with the others
+	owner *CCPClient
+	refs  int
+	// held while logging in, so a slow login only blocks configurations of the same credentials
+	login    chan struct{}
+	loggedIn bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*registryEntry)}
+}
+
+// Acquire returns a client configured with opts that uses the session logged
+// in with the given credentials, logging in only if no such session exists
+// yet. Every Acquire must be paired with a Release.
+func (r *Registry) Acquire(ctx context.Context, customerNumber, apiKey, apiPassword string, opts ...Option) (*CCPClient, error) {
+	c := newClient(opts...)
+	key := registryKey(c.hostURL, customerNumber, apiKey, apiPassword)
+
+	r.mu.Lock()
+	entry, ok := r.entries[key]
+	if !ok {
+		entry = &registryEntry{owner: c, login: make(chan struct{}, 1)}
+		r.entries[key] = entry
+	}
+	entry.refs++
+	r.mu.Unlock()
+
+	if entry.owner != c {
+		c.shareAccount(entry.owner)
+	}
+
+	select {
+	case entry.login <- struct{}{}:
+	case <-ctx.Done():
+		r.drop(key, entry)
+		return nil, ctx.Err()
+	}
+	defer func() { <-entry.login }()
+
+	if !entry.loggedIn {
+		err := c.login(ctx, customerNumber, apiKey, apiPassword)
+		if err != nil {
+			r.drop(key, entry)
+			return nil, err
+		}
+		entry.loggedIn = true
+	}
+	return c, nil
+}
+
+// Drop a reference that didn't result in a client, e.g. after a failed login
+func (r *Registry) drop(key string, entry *registryEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.refs--
+	if entry.refs == 0 && r.entries[key] == entry {
+		delete(r.entries, key)
+	}
+}
+
+// Release drops a reference to a session and logs it out once the last
+// reference is released
+func (r *Registry) Release(ctx context.Context, c *CCPClient) error {
+	r.mu.Lock()
+	var released *registryEntry
+	for key, entry := range r.entries {
+		if entry.owner.session != c.session {
+			continue
+		}
+		entry.refs--
+		if entry.refs == 0 {
+			delete(r.entries, key)
+			released = entry
+		}
+		break
+	}
+	r.mu.Unlock()
+
+	if released == nil || !released.loggedIn {
+		return nil
+	}
+	return c.Logout(ctx)
+}
+
+// Close logs out every session regardless of its references, e.g. when the
+// provider process shuts down
+func (r *Registry) Close(ctx context.Context) error {
+	r.mu.Lock()
+	entries := r.entries
+	r.entries = make(map[string]*registryEntry)
+	r.mu.Unlock()
+
+	var errs []error
+	for _, entry := range entries {
+		if !entry.loggedIn {
+			continue
+		}
+		if err := entry.owner.Logout(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Use the session and the account state of shared, a client logged in with
+// the same credentials at the same endpoint. The shared record cache grows to
+// the size configured for c.
+func (c *CCPClient) shareAccount(shared *CCPClient) {
+	c.session = shared.session
+	c.domains = shared.domains
+	c.requests = shared.requests
+	c.dnsZonesByDomain = shared.dnsZonesByDomain
+	shared.dnsRecordsByDomain.grow(c.dnsRecordsByDomain.capacity())
+	c.dnsRecordsByDomain = shared.dnsRecordsByDomain
+}
+
+// Secrets are part of the key only as hash, so they are not kept in the registry
+func registryKey(hostURL, customerNumber, apiKey, apiPassword string) string {
+	secret := sha256.Sum256([]byte(apiKey + "\x00" + apiPassword))
+	return hostURL + "|" + customerNumber + "|" + hex.EncodeToString(secret[:])
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Send the requests of clients created by a registry to transport
+func withTransport(transport http.RoundTripper) Option {
+	return func(c *CCPClient) {
+		c.httpClient.Transport = transport
+	}
+}
+
+func newTestTransport() *scriptedTransport {
+	return &scriptedTransport{backend: newMemoryBackend(), actions: make(map[string]int)}
+}
+
+func TestRegistrySharesSessionKeepsOptions(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry()
+	transport := newTestTransport()
+
+	first, err := registry.Acquire(ctx, "12345", "key", "password", WithMemoryBackend(), withTransport(transport), WithRequestTimeout(time.Minute), WithRetries(1, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := registry.Acquire(ctx, "12345", "key", "password", WithMemoryBackend(), withTransport(transport), WithRequestTimeout(2*time.Minute), WithRetries(7, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if logins := transport.count("login"); logins != 1 {
+		t.Errorf("expected a single login for two configurations with the same credentials, got %d", logins)
+	}
+	if first == second {
+		t.Fatal("expected each configuration to get a client of its own")
+	}
+	if first.auth().SessionId != second.auth().SessionId {
+		t.Errorf("expected a shared session, got %q and %q", first.auth().SessionId, second.auth().SessionId)
+	}
+	if first.httpClient.Timeout != time.Minute || second.httpClient.Timeout != 2*time.Minute {
+		t.Errorf("expected the request timeouts of each configuration, got %s and %s", first.httpClient.Timeout, second.httpClient.Timeout)
+	}
+	if first.retry.maxRetries != 1 || second.retry.maxRetries != 7 {
+		t.Errorf("expected the retries of each configuration, got %d and %d", first.retry.maxRetries, second.retry.maxRetries)
+	}
+
+	first.SetSkipRefresh(true)
+	second.SetRateLimitWarningThreshold(0.1)
+	if second.SkipRefresh() {
+		t.Error("skip_refresh of one configuration changed the other")
+	}
+	if first.rateLimitWarning.threshold != DefaultRateLimitWarningThreshold {
+		t.Error("rate limit warning threshold of one configuration changed the other")
+	}
+
+	// writes of either configuration invalidate the records cached by the other
+	if _, err := first.GetDnsRecords(ctx, "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.CreateDnsRecord(ctx, "example.com", NewDnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"}); err != nil {
+		t.Fatal(err)
+	}
+	records, err := first.GetDnsRecords(ctx, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Errorf("expected the record created by the other configuration, got %v", records)
+	}
+
+	if err := registry.Release(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if logouts := transport.count("logout"); logouts != 0 {
+		t.Errorf("expected no logout while the session is still used, got %d", logouts)
+	}
+	if err := registry.Release(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	if logouts := transport.count("logout"); logouts != 1 {
+		t.Errorf("expected a logout after the last release, got %d", logouts)
+	}
+}
+
+func TestRegistryDifferentCredentialsNotShared(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry()
+	transport := newTestTransport()
+
+	first, err := registry.Acquire(ctx, "12345", "key", "password", WithMemoryBackend(), withTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := registry.Acquire(ctx, "12345", "other-key", "password", WithMemoryBackend(), withTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logins := transport.count("login"); logins != 2 {
+		t.Errorf("expected a login per credentials, got %d", logins)
+	}
+	if first.auth().SessionId == second.auth().SessionId {
+		t.Error("expected separate sessions for different credentials")
+	}
+}
+
+func TestRegistrySlowLoginBlocksOnlySameCredentials(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry()
+	transport := newTestTransport()
+	release := make(chan struct{})
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		if action == "login" && strings.Contains(string(body), `"slow"`) {
+			select {
+			case <-release:
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+		return nil, nil
+	})
+	defer close(release)
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := registry.Acquire(ctx, "slow", "key", "password", WithMemoryBackend(), withTransport(transport))
+		slow <- err
+	}()
+
+	// wait for the slow login to be sent
+	for transport.count("login") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := registry.Acquire(ctx, "fast", "key", "password", WithMemoryBackend(), withTransport(transport))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("a slow login blocked the login of other credentials")
+	}
+
+	// configurations of the slow credentials wait, but give up with their context
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err := registry.Acquire(waitCtx, "slow", "key", "password", WithMemoryBackend(), withTransport(transport))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected waiting for the login to end with the context, got %v", err)
+	}
+}
+
+func TestRegistryFailedLoginNotShared(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry()
+	transport := newTestTransport()
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		if action == "login" && count == 1 {
+			return jsonResponse(`{"action":"login","status":"error","statuscode":4013,"shortmessage":"Too many requests","longmessage":"","responsedata":""}`), nil
+		}
+		return nil, nil
+	})
+
+	if _, err := registry.Acquire(ctx, "12345", "key", "password", WithMemoryBackend(), withTransport(transport), WithRetries(0, 0)); err == nil {
+		t.Fatal("expected the first login to fail")
+	}
+	c, err := registry.Acquire(ctx, "12345", "key", "password", WithMemoryBackend(), withTransport(transport), WithRetries(0, 0))
+	if err != nil {
+		t.Fatalf("expected a new login after a failed one, got %s", err)
+	}
+	if c.auth().SessionId == "" {
+		t.Error("expected a session after the second login")
+	}
+}
+
+// Close logs out every session when the provider shuts down, also after a logout fails
+func TestRegistryCloseLogsOutEverySession(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry()
+	transport := newTestTransport()
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		if action == "logout" && strings.Contains(string(body), `"customernumber":"22222"`) {
+			return jsonResponse(`{"action":"logout","status":"error","statuscode":4001,"shortmessage":"Session expired","longmessage":"","responsedata":""}`), nil
+		}
+		return nil, nil
+	})
+
+	for _, customerNumber := range []string{"11111", "22222", "33333"} {
+		if _, err := registry.Acquire(ctx, customerNumber, "key", "password", WithMemoryBackend(), withTransport(transport), WithRetries(0, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a session still referenced twice is logged out as well
+	if _, err := registry.Acquire(ctx, "11111", "key", "password", WithMemoryBackend(), withTransport(transport)); err != nil {
+		t.Fatal(err)
+	}
+
+	err := registry.Close(ctx)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.ShortMessage != "Session expired" {
+		t.Errorf("got %v, want the failed logout", err)
+	}
+	if logouts := transport.count("logout"); logouts != 3 {
+		t.Errorf("expected a logout of each of the 3 sessions, got %d", logouts)
+	}
+
+	if err := registry.Close(ctx); err != nil {
+		t.Errorf("second Close failed: %s", err)
+	}
+	if logouts := transport.count("logout"); logouts != 3 {
+		t.Errorf("expected no logouts of a closed registry, got %d in total", logouts)
+	}
+}
@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+)
+
+// Options of ReplaceAllRecords
+type ReplaceOptions struct {
+	// Live records matching any of the filters are neither updated nor deleted
+	Exclude []RecordFilter
+	// Compute the result without writing anything
+	DryRun bool
+	// Maximum number of records per updateDnsRecords call, 0 sends each kind of change in one call
+	BatchSize int
+	// If set, live records not matching the filter are left alone, e.g. to
+	// replace only the records of one type at one name
+	Scope *RecordFilter
+}
+
+// Changes made, or with DryRun to be made, by ReplaceAllRecords. Created
+// records carry their new id unless DryRun is set.
+type ReplaceResult struct {
+	Created []DnsRecord
+	Updated []DnsRecord
+	Deleted []DnsRecord
+	Skipped []DnsRecord
+}
+
+// Whether ReplaceAllRecords has anything to write
+func (r ReplaceResult) HasChanges() bool {
+	return len(r.Created) > 0 || len(r.Updated) > 0 || len(r.Deleted) > 0
+}
+
+// ReplaceAllRecords makes the records of a zone, or of opts.Scope, equal to
+// desired, except for records matched by opts.Exclude. Desired records with an id update the live
+// record with that id, the others are matched with live records by hostname,
+// type and destination, so only a changed priority turns them into an update.
+func (c *CCPClient) ReplaceAllRecords(ctx context.Context, domainName string, desired []DnsRecord, opts ReplaceOptions) (ReplaceResult, error) {
+	// the diff must not change before it is written
+	defer c.domains.write(domainName)()
+
+	zone, err := c.lockedZoneRecords(ctx, domainName)
+	if err != nil {
+		return ReplaceResult{}, err
+	}
+
+	live := zone.records
+	if opts.Scope != nil {
+		live = make([]DnsRecord, 0, len(zone.records))
+		for _, record := range zone.records {
+			if opts.Scope.matches(record, domainName) {
+				live = append(live, record)
+			}
+		}
+	}
+
+	result := diffRecords(domainName, live, desired, opts.Exclude)
+	if opts.DryRun || !result.HasChanges() {
+		return result, nil
+	}
+
+	// flush cache for this domain to be sure we're not faking an incorrect state
+	c.dnsRecordsByDomain.invalidate(domainName)
+
+	written := zone.records
+	deletes := make([]DnsRecord, 0, len(result.Deleted))
+	for _, record := range result.Deleted {
+		record.DeleteRecord = true
+		deletes = append(deletes, record)
+	}
+	for _, changes := range [][]DnsRecord{deletes, result.Updated, result.Created} {
+		for _, batch := range batches(changes, opts.BatchSize) {
+			written, err = c.writeDnsRecords(ctx, domainName, newZoneRecords(written), batch)
+			if err != nil {
+				return ReplaceResult{}, err
+			}
+		}
+	}
+
+	if len(result.Created) > 0 {
+		requested := make([]NewDnsRecord, 0, len(result.Created))
+		for _, record := range result.Created {
+			requested = append(requested, NewDnsRecord{
+				Hostname:    record.Hostname,
+				Type:        record.Type,
+				Priority:    record.Priority,
+				Destination: record.Destination,
+			})
+		}
+		// the last response holds every record of the zone
+		result.Created, err = matchNewRecords(domainName, zone.records, written, requested)
+		if err != nil {
+			return ReplaceResult{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// Send records to updateDnsRecords and return the records of the zone after the write.
+// before holds the records of the zone before the write, see doCreateRequest.
+func (c *CCPClient) writeDnsRecords(ctx context.Context, domainName string, before *zoneRecords, records []DnsRecord) ([]DnsRecord, error) {
+	body, err := c.doCreateRequest(ctx, "updateDnsRecords", UpdateDnsRecordsRequest{
+		DomainInfoRequest: DomainInfoRequest{
+			AuthData:   c.auth(),
+			DomainName: domainName,
+		},
+		DnsRecordSet: DnsRecordSet{DnsRecords: records},
+	}, before)
+	if err != nil {
+		return nil, err
+	}
+
+	recordSet := DnsRecordSet{}
+	err = c.decode(ctx, "updateDnsRecords", body, &recordSet)
+	if err != nil {
+		return nil, err
+	}
+	return recordSet.DnsRecords, nil
+}
+
+func batches(records []DnsRecord, size int) [][]DnsRecord {
+	if len(records) == 0 {
+		return nil
+	}
+	if size <= 0 || size >= len(records) {
+		return [][]DnsRecord{records}
+	}
+
+	var result [][]DnsRecord
+	for start := 0; start < len(records); start += size {
+		end := start + size
+		if end > len(records) {
+			end = len(records)
+		}
+		result = append(result, records[start:end])
+	}
+	return result
+}
+
+// Key of a record without priority, see RecordIdentity
+func replaceKey(record DnsRecord, domainName string) string {
+	return RecordIdentity(record.Hostname, record.Type, record.Destination, domainName)
+}
+
+func excluded(record DnsRecord, domainName string, exclude []RecordFilter) bool {
+	for _, filter := range exclude {
+		if filter.matches(record, domainName) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compute the changes turning live into desired. Updated records carry the
+// id of the live record, created records carry no id.
+func diffRecords(domainName string, live, desired []DnsRecord, exclude []RecordFilter) ReplaceResult {
+	result := ReplaceResult{}
+
+	liveById := make(map[string]DnsRecord, len(live))
+	liveByKey := make(map[string][]DnsRecord)
+	for _, record := range live {
+		if excluded(record, domainName, exclude) {
+			result.Skipped = append(result.Skipped, record)
+			continue
+		}
+		liveById[record.Id] = record
+		key := replaceKey(record, domainName)
+		liveByKey[key] = append(liveByKey[key], record)
+	}
+
+	kept := make(map[string]bool)
+	var unmatched []DnsRecord
+	// records addressed by id claim their live record first
+	for _, record := range desired {
+		if excluded(record, domainName, exclude) {
+			continue
+		}
+		current, ok := liveById[record.Id]
+		if record.Id == "" || !ok || kept[record.Id] {
+			unmatched = append(unmatched, record)
+			continue
+		}
+		kept[current.Id] = true
+		if !sameRecord(current, record, domainName) {
+			record.DeleteRecord = false
+			result.Updated = append(result.Updated, record)
+		}
+	}
+
+	for _, record := range unmatched {
+		var current *DnsRecord
+		for i, candidate := range liveByKey[replaceKey(record, domainName)] {
+			if !kept[candidate.Id] {
+				current = &liveByKey[replaceKey(record, domainName)][i]
+				break
+			}
+		}
+		if current == nil {
+			record.Id = ""
+			record.DeleteRecord = false
+			if NormalizeHostname(record.Hostname, domainName) == "@" {
+				record.Hostname = "@"
+			}
+			result.Created = append(result.Created, record)
+			continue
+		}
+
+		kept[current.Id] = true
+		if record.Priority != "" && record.Priority != current.Priority {
+			updated := *current
+			updated.Priority = record.Priority
+			result.Updated = append(result.Updated, updated)
+		}
+	}
+
+	for _, record := range live {
+		if _, ok := liveById[record.Id]; ok && !kept[record.Id] {
+			result.Deleted = append(result.Deleted, record)
+		}
+	}
+	return result
+}
+
+// An empty desired priority accepts any live priority
+func sameRecord(live, desired DnsRecord, domainName string) bool {
+	if replaceKey(live, domainName) != replaceKey(desired, domainName) {
+		return false
+	}
+	return desired.Priority == "" || desired.Priority == live.Priority
+}
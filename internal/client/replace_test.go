@@ -0,0 +1,316 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"testing"
+)
+
+// Ids of records, sorted
+func recordIds(records []DnsRecord) []string {
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.Id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDiffRecords(t *testing.T) {
+	live := []DnsRecord{
+		{Id: "1", Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+		{Id: "2", Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+		{Id: "3", Hostname: "@", Type: "TXT", Destination: `"v=spf1 -all"`},
+		{Id: "4", Hostname: "old", Type: "CNAME", Destination: "www"},
+	}
+
+	tests := []struct {
+		name    string
+		desired []DnsRecord
+		exclude []RecordFilter
+		created int
+		updated []string
+		deleted []string
+		skipped []string
+	}{
+		{
+			name:    "unchanged",
+			desired: live,
+		},
+		{
+			name: "matched by hostname, type and destination",
+			desired: []DnsRecord{
+				{Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+				{Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+				{Hostname: "@", Type: "TXT", Destination: `"v=spf1 -all"`},
+				{Hostname: "old", Type: "CNAME", Destination: "www"},
+			},
+		},
+		{
+			name: "case and apex spellings",
+			desired: []DnsRecord{
+				{Hostname: "WWW", Type: "a", Destination: "192.0.2.1"},
+				{Hostname: "example.com", Type: "mx", Priority: "10", Destination: "mail.example.com"},
+				{Hostname: "", Type: "TXT", Destination: `"v=spf1 -all"`},
+				{Hostname: "old.example.com.", Type: "CNAME", Destination: "www"},
+			},
+		},
+		{
+			name: "normalized destinations",
+			desired: []DnsRecord{
+				{Hostname: "www", Type: "A", Destination: " 192.0.2.1 "},
+				{Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+				{Hostname: "@", Type: "TXT", Destination: "v=spf1 -all"},
+				{Hostname: "old", Type: "CNAME", Destination: "www"},
+			},
+		},
+		{
+			name: "empty priority accepts the live priority",
+			desired: []DnsRecord{
+				{Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+				{Hostname: "@", Type: "MX", Destination: "mail.example.com"},
+				{Hostname: "@", Type: "TXT", Destination: `"v=spf1 -all"`},
+				{Hostname: "old", Type: "CNAME", Destination: "www"},
+			},
+		},
+		{
+			name: "changed priority updates in place",
+			desired: []DnsRecord{
+				{Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+				{Hostname: "@", Type: "MX", Priority: "20", Destination: "mail.example.com"},
+				{Hostname: "@", Type: "TXT", Destination: `"v=spf1 -all"`},
+				{Hostname: "old", Type: "CNAME", Destination: "www"},
+			},
+			updated: []string{"2"},
+		},
+		{
+			name: "changed destination replaces the record",
+			desired: []DnsRecord{
+				{Hostname: "www", Type: "A", Destination: "192.0.2.2"},
+				{Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+				{Hostname: "@", Type: "TXT", Destination: `"v=spf1 -all"`},
+				{Hostname: "old", Type: "CNAME", Destination: "www"},
+			},
+			created: 1,
+			deleted: []string{"1"},
+		},
+		{
+			name: "addressed by id",
+			desired: []DnsRecord{
+				{Id: "1", Hostname: "www", Type: "A", Destination: "192.0.2.2"},
+				{Id: "2", Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+				{Id: "3", Hostname: "@", Type: "TXT", Destination: `"v=spf1 -all"`},
+				{Id: "4", Hostname: "old", Type: "CNAME", Destination: "www"},
+			},
+			updated: []string{"1"},
+		},
+		{
+			name: "unknown id is created",
+			desired: []DnsRecord{
+				{Id: "99", Hostname: "new", Type: "A", Destination: "192.0.2.9"},
+			},
+			created: 1,
+			deleted: []string{"1", "2", "3", "4"},
+		},
+		{
+			name: "duplicates match one live record each",
+			desired: []DnsRecord{
+				{Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+				{Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+			},
+			created: 1,
+			deleted: []string{"2", "3", "4"},
+		},
+		{
+			name: "excluded records are skipped",
+			desired: []DnsRecord{
+				{Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+			},
+			exclude: []RecordFilter{{Type: "mx"}, {Hostname: "@", Type: "TXT"}},
+			deleted: []string{"4"},
+			skipped: []string{"2", "3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := diffRecords("example.com", live, tt.desired, tt.exclude)
+
+			if len(result.Created) != tt.created {
+				t.Errorf("created %d records, want %d: %v", len(result.Created), tt.created, result.Created)
+			}
+			for _, record := range result.Created {
+				if record.Id != "" {
+					t.Errorf("created record %v carries an id", record)
+				}
+			}
+			if got := recordIds(result.Updated); !equalStrings(got, tt.updated) {
+				t.Errorf("updated %v, want %v", got, tt.updated)
+			}
+			if got := recordIds(result.Deleted); !equalStrings(got, tt.deleted) {
+				t.Errorf("deleted %v, want %v", got, tt.deleted)
+			}
+			if got := recordIds(result.Skipped); !equalStrings(got, tt.skipped) {
+				t.Errorf("skipped %v, want %v", got, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestDiffRecordsCreatesApexAsAt(t *testing.T) {
+	result := diffRecords("example.com", nil, []DnsRecord{{Hostname: "Example.com", Type: "A", Destination: "192.0.2.1"}}, nil)
+	if len(result.Created) != 1 || result.Created[0].Hostname != "@" {
+		t.Fatalf("created %v, want one record at @", result.Created)
+	}
+}
+
+func TestReplaceAllRecordsDryRun(t *testing.T) {
+	c, transport := newTestClient(t)
+	seedRecords(t, transport, "example.com",
+		DnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+	)
+
+	result, err := c.ReplaceAllRecords(context.Background(), "example.com", []DnsRecord{
+		{Hostname: "api", Type: "A", Destination: "192.0.2.2"},
+	}, ReplaceOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ReplaceAllRecords failed: %s", err)
+	}
+	if len(result.Created) != 1 || len(result.Deleted) != 1 {
+		t.Errorf("got %+v, want one create and one delete", result)
+	}
+	if n := transport.count("updateDnsRecords"); n != 0 {
+		t.Errorf("dry run sent %d updateDnsRecords calls", n)
+	}
+}
+
+func TestReplaceAllRecordsBatches(t *testing.T) {
+	c, transport := newTestClient(t)
+	seedRecords(t, transport, "example.com",
+		DnsRecord{Hostname: "a", Type: "A", Destination: "192.0.2.1"},
+		DnsRecord{Hostname: "b", Type: "A", Destination: "192.0.2.2"},
+		DnsRecord{Hostname: "c", Type: "A", Destination: "192.0.2.3"},
+	)
+
+	desired := []DnsRecord{
+		{Hostname: "a", Type: "A", Destination: "192.0.2.1"},
+		{Hostname: "d", Type: "A", Destination: "192.0.2.4"},
+		{Hostname: "e", Type: "TXT", Destination: `"quoted"`},
+		{Hostname: "f", Type: "A", Destination: " 192.0.2.6 "},
+	}
+	result, err := c.ReplaceAllRecords(context.Background(), "example.com", desired, ReplaceOptions{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("ReplaceAllRecords failed: %s", err)
+	}
+	// one call for the two deletes, two calls for the three creates
+	if n := transport.count("updateDnsRecords"); n != 3 {
+		t.Errorf("sent %d updateDnsRecords calls, want 3", n)
+	}
+	if len(result.Created) != 3 || len(result.Deleted) != 2 {
+		t.Fatalf("got %+v, want three creates and two deletes", result)
+	}
+	for _, record := range result.Created {
+		if record.Id == "" {
+			t.Errorf("created record %v has no id", record)
+		}
+	}
+
+	records, err := c.GetDnsRecords(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("GetDnsRecords failed: %s", err)
+	}
+	if len(records) != 4 {
+		t.Errorf("zone has %d records, want 4: %v", len(records), records)
+	}
+
+	// replacing again with the same records changes nothing
+	result, err = c.ReplaceAllRecords(context.Background(), "example.com", desired, ReplaceOptions{})
+	if err != nil {
+		t.Fatalf("ReplaceAllRecords failed: %s", err)
+	}
+	if result.HasChanges() {
+		t.Errorf("second replace has changes: %+v", result)
+	}
+}
+
+// With a scope only the records of one name and type are replaced
+func TestReplaceAllRecordsScope(t *testing.T) {
+	c, transport := newTestClient(t)
+	seeded := seedRecords(t, transport, "example.com",
+		DnsRecord{Hostname: "_sip._udp", Type: "SRV", Priority: "10", Destination: "60 5060 sip1.example.com."},
+		DnsRecord{Hostname: "_sip._udp", Type: "SRV", Priority: "10", Destination: "40 5060 sip2.example.com."},
+		DnsRecord{Hostname: "_sip._udp", Type: "TXT", Destination: "note"},
+		DnsRecord{Hostname: "_sip._tcp", Type: "SRV", Priority: "10", Destination: "0 5060 sip1.example.com."},
+	)
+
+	result, err := c.ReplaceAllRecords(context.Background(), "example.com", []DnsRecord{
+		{Id: seeded[0].Id, Hostname: "_sip._udp", Type: "SRV", Priority: "10", Destination: "80 5061 sip1.example.com."},
+		{Hostname: "_sip._udp", Type: "SRV", Priority: "20", Destination: "0 5060 sip3.example.com."},
+	}, ReplaceOptions{Scope: &RecordFilter{Hostname: "_sip._udp", Type: "SRV"}})
+	if err != nil {
+		t.Fatalf("ReplaceAllRecords failed: %s", err)
+	}
+	// one call each for the delete, the update and the create
+	if n := transport.count("updateDnsRecords"); n != 3 {
+		t.Errorf("sent %d updateDnsRecords calls, want 3", n)
+	}
+	if got := recordIds(result.Updated); !equalStrings(got, []string{seeded[0].Id}) {
+		t.Errorf("updated %v, want %s", got, seeded[0].Id)
+	}
+	if got := recordIds(result.Deleted); !equalStrings(got, []string{seeded[1].Id}) {
+		t.Errorf("deleted %v, want %s", got, seeded[1].Id)
+	}
+	if len(result.Created) != 1 || result.Created[0].Id == "" {
+		t.Errorf("created %+v, want one record with an id", result.Created)
+	}
+
+	records, err := c.GetDnsRecords(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("GetDnsRecords failed: %s", err)
+	}
+	want := []string{seeded[0].Id, seeded[2].Id, seeded[3].Id, result.Created[0].Id}
+	sort.Strings(want)
+	if got := recordIds(records); !equalStrings(got, want) {
+		t.Errorf("zone has records %v, want %v", got, want)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	records := make([]DnsRecord, 5)
+	tests := []struct {
+		size int
+		want []int
+	}{
+		{0, []int{5}},
+		{2, []int{2, 2, 1}},
+		{5, []int{5}},
+		{10, []int{5}},
+	}
+	for _, tt := range tests {
+		got := batches(records, tt.size)
+		if len(got) != len(tt.want) {
+			t.Errorf("batches(5, %d) = %d batches, want %d", tt.size, len(got), len(tt.want))
+			continue
+		}
+		for i := range got {
+			if len(got[i]) != tt.want[i] {
+				t.Errorf("batches(5, %d)[%d] has %d records, want %d", tt.size, i, len(got[i]), tt.want[i])
+			}
+		}
+	}
+	if got := batches(nil, 2); got != nil {
+		t.Errorf("batches(nil) = %v, want nil", got)
+	}
+}
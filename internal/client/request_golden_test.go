@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of the tests")
+
+// Compare got with testdata/<name>, or write it there with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file, create it with -update: %s", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("request bodies differ from %s, run with -update if the change is intended:\n%s", path, got)
+	}
+}
+
+// Records the bodies of the requests sent through a transport, indented
+type requestRecorder struct {
+	mu     sync.Mutex
+	bodies [][]byte
+}
+
+func (r *requestRecorder) intercept(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		return nil, err
+	}
+	indented.WriteByte('\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, indented.Bytes())
+	return nil, nil
+}
+
+// Bodies recorded since the last call
+func (r *requestRecorder) take() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	bodies := bytes.Join(r.bodies, nil)
+	r.bodies = nil
+	return bodies
+}
+
+// The JSON sent for each flow is what Netcup validates, so changes to it must be deliberate
+func TestRequestBodiesGolden(t *testing.T) {
+	ctx := context.Background()
+	recorder := &requestRecorder{}
+	transport := newTestTransport()
+	transport.setIntercept(recorder.intercept)
+	c := newClient(WithMemoryBackend(), withTransport(transport))
+
+	var created []DnsRecord
+	flows := []struct {
+		name string
+		run  func() error
+	}{
+		{"login", func() error {
+			return c.login(ctx, "12345", "key", "password")
+		}},
+		{"info_dns_zone", func() error {
+			_, err := c.GetDnsZone(ctx, "example.com")
+			return err
+		}},
+		{"info_dns_records", func() error {
+			_, err := c.GetDnsRecords(ctx, "example.com")
+			return err
+		}},
+		{"create", func() error {
+			var err error
+			created, err = c.CreateDnsRecords(ctx, "example.com", []NewDnsRecord{
+				{Hostname: "www", Type: "A", Destination: "192.0.2.1"},
+				{Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+			})
+			return err
+		}},
+		{"update", func() error {
+			update := created[0]
+			update.Destination = "192.0.2.2"
+			_, err := c.UpdateDnsRecord(ctx, "example.com", update)
+			return err
+		}},
+		{"delete", func() error {
+			return c.DeleteDnsRecord(ctx, "example.com", created[1])
+		}},
+		{"update_dns_zone", func() error {
+			_, err := c.UpdateDnsZone(ctx, "example.com", DnsZone{Name: "example.com", TTL: "300", Refresh: "28800", Retry: "7200", Expire: "1209600"})
+			return err
+		}},
+		{"logout", func() error {
+			return c.Logout(ctx)
+		}},
+	}
+
+	for _, flow := range flows {
+		if err := flow.run(); err != nil {
+			t.Fatalf("%s failed: %s", flow.name, err)
+		}
+		checkGolden(t, filepath.Join("requests", flow.name+".json"), recorder.take())
+	}
+}
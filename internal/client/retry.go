@@ -0,0 +1,126 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// How often and how long rate limited requests are retried. Requests are
+// additionally retried on the statuscodes of retryOn, and never on those of neverRetry.
+// Changes rejected with a statuscode of zoneLocked are blocked by a locked zone
+// and retried longer, see locked.
+type retryPolicy struct {
+	maxRetries int
+	backoff    backoff
+	retryOn    map[int]bool
+	neverRetry map[int]bool
+	zoneLocked map[int]bool
+
+	lockedRetries int
+	lockedBackoff backoff
+}
+
+var defaultRetryPolicy = retryPolicy{
+	maxRetries: 5,
+	backoff:    backoff{initial: time.Second, max: 30 * time.Second, factor: 2, jitter: true},
+
+	lockedRetries: 8,
+	lockedBackoff: backoff{initial: 5 * time.Second, max: time.Minute, factor: 2, jitter: true},
+}
+
+// Policy for changes blocked by another change of the zone. Netcup processes
+// zone changes for a while, so they are retried less often but for longer.
+func (p retryPolicy) locked() retryPolicy {
+	locked := p
+	locked.maxRetries = p.lockedRetries
+	locked.backoff = p.lockedBackoff
+	return locked
+}
+
+// Request the API kept rejecting because of its rate limit
+type RateLimitError struct {
+	Action   string
+	Attempts int
+	Elapsed  time.Duration
+	Err      error
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s rate limited after %d attempts over %s: %s", e.Action, e.Attempts, e.Elapsed.Round(time.Millisecond), e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// Change the API kept rejecting because another change to the zone was still processed
+type ZoneLockedError struct {
+	Action   string
+	Attempts int
+	Elapsed  time.Duration
+	Err      error
+}
+
+func (e *ZoneLockedError) Error() string {
+	return fmt.Sprintf("%s blocked by a concurrent change of the zone after %d attempts over %s: %s", e.Action, e.Attempts, e.Elapsed.Round(time.Millisecond), e.Err)
+}
+
+func (e *ZoneLockedError) Unwrap() error {
+	return e.Err
+}
+
+// Return the error of a response to retry, nil for any other response.
+// Statuscodes are HTTP status codes or statuscodes of the API response.
+func (p retryPolicy) retryableError(statusCode int, body []byte, action string) error {
+	if statusCode != http.StatusOK {
+		if p.retryable(statusCode, statusCode == http.StatusTooManyRequests) {
+			return &HTTPError{StatusCode: statusCode, Body: validText(body)}
+		}
+		return nil
+	}
+
+	res := ResponseBody{}
+	if json.Unmarshal(body, &res) != nil {
+		return nil
+	}
+	res.repairMessages(body)
+	if res.Action == "" {
+		res.Action = action
+	}
+	apiErr, ok := res.Err().(*APIError)
+	if ok && p.retryable(apiErr.StatusCode, apiErr.IsRateLimited() || p.zoneLocked[apiErr.StatusCode]) {
+		return apiErr
+	}
+	return nil
+}
+
+func (p retryPolicy) retryable(statusCode int, builtin bool) bool {
+	if p.neverRetry[statusCode] {
+		return false
+	}
+	return builtin || p.retryOn[statusCode]
+}
+
+// Whether err, as returned by retryableError, is caused by the rate limit
+func isRateLimited(err error) bool {
+	var apiErr *APIError
+	var httpErr *HTTPError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.IsRateLimited()
+	case errors.As(err, &httpErr):
+		return httpErr.StatusCode == http.StatusTooManyRequests
+	default:
+		return false
+	}
+}
+
+// Whether err, as returned by retryableError, is caused by a locked zone.
+// Netcup documents no statuscode for it, so only configured ones are known.
+func (p retryPolicy) isZoneLocked(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && p.zoneLocked[apiErr.StatusCode]
+}
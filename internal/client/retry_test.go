@@ -0,0 +1,315 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Error response of the API for action with the given statuscode and message
+func apiErrorResponse(action string, statusCode int, message string) *http.Response {
+	return jsonResponse(fmt.Sprintf(`{"serverrequestid":"s","clientrequestid":"","action":%q,"status":"error","statuscode":%d,"shortmessage":%q,"longmessage":"","responsedata":""}`,
+		action, statusCode, message))
+}
+
+// Answer the first n requests of action with a rate limit error
+func rateLimitFirst(transport *scriptedTransport, action string, n int) {
+	transport.setIntercept(func(req *http.Request, a string, count int, body []byte) (*http.Response, error) {
+		if a == action && count <= n {
+			return apiErrorResponse(action, StatusValidationError, "More than 180 requests per minute. Please wait and retry later."), nil
+		}
+		return nil, nil
+	})
+}
+
+func TestRateLimitedRequestRetried(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(3, time.Millisecond))
+	rateLimitFirst(transport, "infoDnsRecords", 2)
+
+	if _, err := c.GetDnsRecords(context.Background(), "example.com"); err != nil {
+		t.Fatalf("GetDnsRecords failed: %s", err)
+	}
+	if n := transport.count("infoDnsRecords"); n != 3 {
+		t.Errorf("sent %d infoDnsRecords requests, want 3", n)
+	}
+}
+
+func TestRateLimitGivesUp(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(2, time.Millisecond))
+	rateLimitFirst(transport, "infoDnsRecords", 100)
+
+	_, err := c.GetDnsRecords(context.Background(), "example.com")
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("got error %v, want a RateLimitError", err)
+	}
+	if rateLimitErr.Attempts != 3 || rateLimitErr.Action != "infoDnsRecords" {
+		t.Errorf("got %d attempts of %s, want 3 of infoDnsRecords", rateLimitErr.Attempts, rateLimitErr.Action)
+	}
+	if n := transport.count("infoDnsRecords"); n != 3 {
+		t.Errorf("sent %d infoDnsRecords requests, want 3", n)
+	}
+}
+
+// A throttled create is neither duplicated nor reported as created
+func TestRateLimitedCreate(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(1, time.Millisecond))
+	rateLimitFirst(transport, "updateDnsRecords", 100)
+
+	_, err := c.CreateDnsRecord(context.Background(), "example.com", NewDnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"})
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("got error %v, want a RateLimitError", err)
+	}
+	if n := zoneRecordCount(t, transport, "example.com"); n != 0 {
+		t.Errorf("zone has %d records, want none", n)
+	}
+}
+
+func TestNoRetriesWithoutRateLimit(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(3, time.Millisecond))
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		if action == "infoDnsRecords" {
+			return apiErrorResponse(action, 5029, "Can not get DNS records for zone. Domain not found."), nil
+		}
+		return nil, nil
+	})
+
+	_, err := c.GetDnsRecords(context.Background(), "example.com")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 5029 {
+		t.Fatalf("got error %v, want the API error 5029", err)
+	}
+	if n := transport.count("infoDnsRecords"); n != 1 {
+		t.Errorf("sent %d infoDnsRecords requests, want 1", n)
+	}
+}
+
+// Validation errors share the statuscode of the rate limit, but fail the same way when retried
+func TestValidationErrorNotRetried(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(3, time.Millisecond))
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		if action == "infoDnsRecords" {
+			return apiErrorResponse(action, StatusValidationError, "Validation Error."), nil
+		}
+		return nil, nil
+	})
+
+	_, err := c.GetDnsRecords(context.Background(), "example.com")
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		t.Errorf("validation error reported as rate limited: %s", err)
+	}
+	if n := transport.count("infoDnsRecords"); n != 1 {
+		t.Errorf("sent %d infoDnsRecords requests, want 1", n)
+	}
+}
+
+func TestRetryOnConfiguredStatusCode(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(3, time.Millisecond), WithRetryStatusCodes([]int{http.StatusServiceUnavailable}, nil))
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		if action == "infoDnsRecords" && count == 1 {
+			res := jsonResponse("unavailable")
+			res.StatusCode = http.StatusServiceUnavailable
+			return res, nil
+		}
+		return nil, nil
+	})
+
+	if _, err := c.GetDnsRecords(context.Background(), "example.com"); err != nil {
+		t.Fatalf("GetDnsRecords failed: %s", err)
+	}
+	if n := transport.count("infoDnsRecords"); n != 2 {
+		t.Errorf("sent %d infoDnsRecords requests, want 2", n)
+	}
+}
+
+func TestNeverRetryRateLimit(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(3, time.Millisecond), WithRetryStatusCodes(nil, []int{StatusValidationError}))
+	rateLimitFirst(transport, "infoDnsRecords", 100)
+
+	if _, err := c.GetDnsRecords(context.Background(), "example.com"); err == nil {
+		t.Fatal("GetDnsRecords succeeded against a rate limited API")
+	}
+	if n := transport.count("infoDnsRecords"); n != 1 {
+		t.Errorf("sent %d infoDnsRecords requests, want 1", n)
+	}
+}
+
+// Ending ctx while waiting for the next attempt reports the rate limit, not the cancellation
+func TestRateLimitRetryCancelled(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(5, time.Hour))
+	rateLimitFirst(transport, "infoDnsRecords", 100)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, err := c.GetDnsRecords(ctx, "example.com")
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("got error %v, want a RateLimitError", err)
+	}
+	if rateLimitErr.Attempts != 1 {
+		t.Errorf("got %d attempts, want 1", rateLimitErr.Attempts)
+	}
+	if elapsed := time.Since(started); elapsed > 10*time.Second {
+		t.Errorf("cancelled retry took %s", elapsed)
+	}
+}
+
+// Statuscode of a locked zone in the tests. Netcup documents none, so it is
+// configured like a user would with the code their responses show.
+const testZoneLockedStatus = 4026
+
+func zoneLockedFirst(transport *scriptedTransport, n int) {
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		if action == "updateDnsRecords" && count <= n {
+			return apiErrorResponse(action, testZoneLockedStatus, "Zone update failed"), nil
+		}
+		return nil, nil
+	})
+}
+
+func TestZoneLockedRetriedWithLockedPolicy(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(0, time.Millisecond), WithZoneLockedStatusCodes([]int{testZoneLockedStatus}))
+	c.retry.lockedRetries = 2
+	c.retry.lockedBackoff = backoff{initial: time.Millisecond, max: time.Millisecond, factor: 2}
+	zoneLockedFirst(transport, 10)
+
+	_, err := c.CreateDnsRecord(context.Background(), "example.com", NewDnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"})
+	var lockedErr *ZoneLockedError
+	if !errors.As(err, &lockedErr) {
+		t.Fatalf("got error %v, want a ZoneLockedError", err)
+	}
+	// no retries of the default policy, but those of the locked policy
+	if lockedErr.Attempts != 3 {
+		t.Errorf("got %d attempts, want 3", lockedErr.Attempts)
+	}
+	if n := transport.count("updateDnsRecords"); n != 3 {
+		t.Errorf("sent %d updateDnsRecords requests, want 3", n)
+	}
+}
+
+func TestZoneLockedSucceedsOnceFree(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(0, time.Millisecond), WithZoneLockedStatusCodes([]int{testZoneLockedStatus}))
+	c.retry.lockedBackoff = backoff{initial: time.Millisecond, max: time.Millisecond, factor: 2}
+	zoneLockedFirst(transport, 2)
+
+	record, err := c.CreateDnsRecord(context.Background(), "example.com", NewDnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"})
+	if err != nil {
+		t.Fatalf("CreateDnsRecord failed: %s", err)
+	}
+	if record.Id == "" {
+		t.Errorf("created record %v has no id", record)
+	}
+	if n := transport.count("updateDnsRecords"); n != 3 {
+		t.Errorf("sent %d updateDnsRecords requests, want 3", n)
+	}
+	if n := zoneRecordCount(t, transport, "example.com"); n != 1 {
+		t.Errorf("zone has %d records, want 1", n)
+	}
+}
+
+func TestZoneLockedStatusCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		requests int
+	}{
+		{
+			name:     "not configured",
+			requests: 1,
+		},
+		{
+			name:     "configured",
+			opts:     []Option{WithZoneLockedStatusCodes([]int{testZoneLockedStatus})},
+			requests: 2,
+		},
+		{
+			name:     "never retried",
+			opts:     []Option{WithZoneLockedStatusCodes([]int{testZoneLockedStatus}), WithRetryStatusCodes(nil, []int{testZoneLockedStatus})},
+			requests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, transport := newTestClient(t, append([]Option{WithRetries(0, time.Millisecond)}, tt.opts...)...)
+			c.retry.lockedRetries = 1
+			c.retry.lockedBackoff = backoff{initial: time.Millisecond, max: time.Millisecond, factor: 2}
+			zoneLockedFirst(transport, 10)
+
+			_, err := c.CreateDnsRecord(context.Background(), "example.com", NewDnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"})
+			if err == nil {
+				t.Fatal("CreateDnsRecord succeeded, want an error")
+			}
+			var lockedErr *ZoneLockedError
+			if locked := errors.As(err, &lockedErr); locked != (tt.requests > 1) {
+				t.Errorf("got error %v, want a ZoneLockedError: %t", err, tt.requests > 1)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != testZoneLockedStatus {
+				t.Errorf("got error %v, want the APIError of statuscode %d", err, testZoneLockedStatus)
+			}
+			if n := transport.count("updateDnsRecords"); n != tt.requests {
+				t.Errorf("sent %d updateDnsRecords requests, want %d", n, tt.requests)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := backoff{initial: time.Second, max: 5 * time.Second, factor: 2}
+	delays := []time.Duration{b.initial}
+	for i := 0; i < 4; i++ {
+		delays = append(delays, b.next(delays[len(delays)-1]))
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Errorf("delay %d = %s, want %s", i, delays[i], want[i])
+		}
+	}
+
+	if got := b.wait(4 * time.Second); got != 4*time.Second {
+		t.Errorf("wait without jitter = %s, want 4s", got)
+	}
+	b.jitter = true
+	for i := 0; i < 100; i++ {
+		if got := b.wait(4 * time.Second); got < 2*time.Second || got > 4*time.Second {
+			t.Fatalf("wait with jitter = %s, want between 2s and 4s", got)
+		}
+	}
+}
+
+func TestRetryUntil(t *testing.T) {
+	b := backoff{initial: time.Millisecond, max: time.Millisecond, factor: 2}
+
+	attempts := 0
+	err := retryUntil(context.Background(), b, func() (bool, error) {
+		attempts++
+		return attempts == 3, nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("got %d attempts and error %v, want 3 and none", attempts, err)
+	}
+
+	failure := errors.New("failed")
+	err = retryUntil(context.Background(), b, func() (bool, error) {
+		return false, failure
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("got error %v, want the error of the attempt", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = retryUntil(ctx, b, func() (bool, error) {
+		return false, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// Credentials and API session of a client. Netcup ends sessions after 15
+// minutes without requests, so the credentials are kept to log in again.
+type session struct {
+	mu          sync.RWMutex
+	credentials LoginData
+	authData    AuthData
+	// held while logging in again, so concurrent requests failing with the
+	// same expired session log in only once
+	renew chan struct{}
+}
+
+// Session of the client to send with requests
+func (c *CCPClient) auth() AuthData {
+	c.session.mu.RLock()
+	defer c.session.mu.RUnlock()
+	return c.session.authData
+}
+
+func (c *CCPClient) setSession(credentials LoginData, sessionId string) {
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+	c.session.credentials = credentials
+	c.session.authData = AuthData{
+		CustomerNumber: credentials.CustomerNumber,
+		APIKey:         credentials.APIKey,
+		SessionId:      sessionId,
+	}
+}
+
+// Log in again after a request failed with the expired session id, unless
+// another request already did. Returns the session id to retry with. Waiting
+// for another request logging in gives up when ctx is cancelled.
+func (c *CCPClient) renewSession(ctx context.Context, expired string) (string, error) {
+	select {
+	case c.session.renew <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-c.session.renew }()
+
+	c.session.mu.RLock()
+	current, credentials := c.session.authData.SessionId, c.session.credentials
+	c.session.mu.RUnlock()
+	if current != expired {
+		return current, nil
+	}
+
+	err := c.login(ctx, credentials.CustomerNumber, credentials.APIKey, credentials.APIPassword)
+	if err != nil {
+		return "", err
+	}
+	return c.auth().SessionId, nil
+}
+
+// Whether a response reports that the session of the request expired. The API
+// reports invalid request data with the same statuscode, e.g.
+// testdata/responses/infoDnsRecords_german.json, which a new session won't fix.
+func sessionExpired(body []byte) bool {
+	res := ResponseBody{}
+	if json.Unmarshal(body, &res) != nil || res.Status == "success" || res.StatusCode != StatusSessionExpired {
+		return false
+	}
+	return strings.Contains(strings.ToLower(res.ShortMessage+" "+res.LongMessage), "session")
+}
+
+type encodedRequest struct {
+	Action string                     `json:"action"`
+	Param  map[string]json.RawMessage `json:"param"`
+}
+
+// Session id an encoded request was sent with, empty if it has none
+func requestSession(rb []byte) string {
+	request := encodedRequest{}
+	if json.Unmarshal(rb, &request) != nil {
+		return ""
+	}
+	var sessionId string
+	if json.Unmarshal(request.Param["apisessionid"], &sessionId) != nil {
+		return ""
+	}
+	return sessionId
+}
+
+// Replace the session id of an encoded request
+func withSession(rb []byte, sessionId string) ([]byte, error) {
+	request := encodedRequest{}
+	err := json.Unmarshal(rb, &request)
+	if err != nil {
+		return nil, err
+	}
+	request.Param["apisessionid"], err = json.Marshal(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(request)
+}
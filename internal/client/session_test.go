@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const expiredSessionResponse = `{"serverrequestid":"1","clientrequestid":"","action":"infoDnsRecords","status":"error","statuscode":4001,"shortmessage":"The session id is not in a valid format.","longmessage":"","responsedata":""}`
+
+// Expire the current session: requests sent with it fail with statuscode 4001 until a new login
+func expireSession(transport *scriptedTransport, expired string) {
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		if action != "login" && requestSession(body) == expired {
+			return jsonResponse(expiredSessionResponse), nil
+		}
+		return nil, nil
+	})
+}
+
+func TestExpiredSessionRenewedOnceByConcurrentRequests(t *testing.T) {
+	c, transport := newTestClient(t)
+	ctx := context.Background()
+	expireSession(transport, c.auth().SessionId)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.RefreshDnsZone(ctx, "example.com"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if logins := transport.count("login"); logins != 2 {
+		t.Errorf("expected the initial login and exactly one renewal, got %d logins", logins)
+	}
+}
+
+// Invalid data is reported with the statuscode of expired sessions too, and isn't retried with a new session
+func TestValidationErrorKeepsSession(t *testing.T) {
+	c, transport := newTestClient(t)
+	body, err := os.ReadFile(filepath.Join("testdata", "responses", "infoDnsRecords_german.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport.setIntercept(func(req *http.Request, action string, count int, _ []byte) (*http.Response, error) {
+		if action == "infoDnsRecords" {
+			return jsonResponse(string(body)), nil
+		}
+		return nil, nil
+	})
+
+	_, err = c.GetDnsRecords(context.Background(), "example.com")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != StatusSessionExpired {
+		t.Fatalf("got error %v, want the API error %d", err, StatusSessionExpired)
+	}
+	if logins := transport.count("login"); logins != 1 {
+		t.Errorf("sent %d logins, want only the initial one", logins)
+	}
+	if n := transport.count("infoDnsRecords"); n != 1 {
+		t.Errorf("sent %d infoDnsRecords requests, want 1", n)
+	}
+}
+
+func TestLoginCancelledWithContext(t *testing.T) {
+	transport := &scriptedTransport{backend: newMemoryBackend(), actions: make(map[string]int)}
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	c := newClient(WithMemoryBackend())
+	c.httpClient.Transport = transport
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	err := c.login(ctx, "12345", "key", "password")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the login to end with the context, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("login kept running for %s after the context ended", elapsed)
+	}
+}
+
+func TestRenewalCancelledWithContext(t *testing.T) {
+	c, transport := newTestClient(t)
+	expireSession(transport, c.auth().SessionId)
+
+	// another request is logging in again
+	c.session.renew <- struct{}{}
+	defer func() { <-c.session.renew }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.RefreshDnsZone(ctx, "example.com")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected waiting for the renewal to end with the context, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package client
+
+import "sync"
+
+// Refresh behavior set by the provider configuration and read by every resource
+type refreshSettings struct {
+	mu            sync.RWMutex
+	skip          bool
+	driftWarnings bool
+}
+
+// SetSkipRefresh makes resources keep their prior state on refresh instead of reading the API
+func (c *CCPClient) SetSkipRefresh(skip bool) {
+	c.refresh.mu.Lock()
+	defer c.refresh.mu.Unlock()
+
+	c.refresh.skip = skip
+}
+
+// SkipRefresh reports whether resources keep their prior state on refresh
+func (c *CCPClient) SkipRefresh() bool {
+	c.refresh.mu.RLock()
+	defer c.refresh.mu.RUnlock()
+
+	return c.refresh.skip
+}
+
+// SetDriftWarnings makes resources warn about values changed outside of Terraform on refresh
+func (c *CCPClient) SetDriftWarnings(enabled bool) {
+	c.refresh.mu.Lock()
+	defer c.refresh.mu.Unlock()
+
+	c.refresh.driftWarnings = enabled
+}
+
+// DriftWarnings reports whether resources warn about drift on refresh
+func (c *CCPClient) DriftWarnings() bool {
+	c.refresh.mu.RLock()
+	defer c.refresh.mu.RUnlock()
+
+	return c.refresh.driftWarnings
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Logs fields of API responses the models don't know, once per field.
+// Unknown fields never fail a request.
+type strictDecoding struct {
+	mu       sync.Mutex
+	enabled  bool
+	reported map[string]bool
+}
+
+// Strict decoding is enabled by default when the provider logs at TRACE level
+func strictDecodingFromEnv() bool {
+	for _, name := range []string{"TF_LOG", "TF_LOG_PROVIDER"} {
+		if strings.EqualFold(os.Getenv(name), "trace") {
+			return true
+		}
+	}
+	return false
+}
+
+// SetStrictDecoding enables logging a warning for each field of an API
+// response that is not represented in the models
+func (c *CCPClient) SetStrictDecoding(enabled bool) {
+	c.strict.mu.Lock()
+	defer c.strict.mu.Unlock()
+
+	c.strict.enabled = enabled
+}
+
+// decode decodes a response like decodeResponse and reports unknown fields if enabled
+func (c *CCPClient) decode(ctx context.Context, action string, body []byte, data interface{}) error {
+	err := decodeResponse(action, body, data)
+	if err == nil {
+		c.strict.check(ctx, action, body, data)
+	}
+	return err
+}
+
+func (s *strictDecoding) check(ctx context.Context, action string, body []byte, data interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.enabled {
+		return
+	}
+
+	var generic map[string]interface{}
+	if json.Unmarshal(body, &generic) != nil {
+		return
+	}
+
+	var unknown []string
+	unknownFields(action, generic, reflect.TypeOf(rawResponse{}), &unknown)
+	if data != nil {
+		if responseData, ok := generic["responsedata"].(map[string]interface{}); ok {
+			unknownFields(action+".responsedata", responseData, reflect.TypeOf(data), &unknown)
+		}
+	}
+
+	sort.Strings(unknown)
+	for _, field := range unknown {
+		if s.reported[field] {
+			continue
+		}
+		if s.reported == nil {
+			s.reported = make(map[string]bool)
+		}
+		s.reported[field] = true
+		logWarn(ctx, "The API returned an unknown field, the provider may need an update to handle it", map[string]interface{}{"field": field})
+	}
+}
+
+// Collect the keys of value that have no field in t, descending into nested objects and lists
+func unknownFields(prefix string, value interface{}, t reflect.Type, unknown *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if t.Kind() != reflect.Struct {
+			return
+		}
+		fields := jsonFields(t)
+		for key, nested := range v {
+			field, ok := fields[strings.ToLower(key)]
+			if !ok {
+				*unknown = append(*unknown, prefix+"."+key)
+				continue
+			}
+			unknownFields(prefix+"."+key, nested, field, unknown)
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return
+		}
+		for _, nested := range v {
+			unknownFields(prefix+"[]", nested, t.Elem(), unknown)
+		}
+	}
+}
+
+// Types of the fields of a struct by lowercase JSON name, including embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for embedded, embeddedType := range jsonFields(field.Type) {
+				fields[embedded] = embeddedType
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+	return fields
+}
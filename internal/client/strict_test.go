@@ -0,0 +1,126 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+// Response to infoDnsRecords with fields the models don't know, and a record
+// missing the optional fields state and deleterecord
+const extraFieldsResponse = `{"serverrequestid":"s","clientrequestid":"","action":"infoDnsRecords","status":"success","statuscode":2000,` +
+	`"shortmessage":"ok","longmessage":"","maintenance":false,` +
+	`"responsedata":{"zoneversion":"7","dnsrecords":[` +
+	`{"id":"1","hostname":"www","type":"A","priority":"0","destination":"192.0.2.1","deleterecord":false,"state":"yes","ttl":"300"},` +
+	`{"id":"2","hostname":"@","type":"MX","priority":"10","destination":"mail.example.com"}]}}`
+
+// Fields of the unknown field warnings logged to output
+func unknownFieldWarnings(t *testing.T, output *bytes.Buffer) []string {
+	t.Helper()
+	var fields []string
+	for _, entry := range subsystemEntries(t, output) {
+		if entry["@level"] == "warn" && entry["@message"] == "The API returned an unknown field, the provider may need an update to handle it" {
+			fields = append(fields, entry["field"].(string))
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func respondWithExtraFields(transport *scriptedTransport) {
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		if action == "infoDnsRecords" {
+			return jsonResponse(extraFieldsResponse), nil
+		}
+		return nil, nil
+	})
+}
+
+func TestStrictDecodingWarnsOncePerField(t *testing.T) {
+	c, transport := newTestClient(t)
+	c.SetStrictDecoding(true)
+	respondWithExtraFields(transport)
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	for _, domain := range []string{"example.com", "example.org"} {
+		records, err := c.GetDnsRecords(ctx, domain)
+		if err != nil {
+			t.Fatalf("GetDnsRecords(%s) failed: %s", domain, err)
+		}
+		if len(records) != 2 || records[1].State != "" || records[1].Priority != "10" {
+			t.Errorf("GetDnsRecords(%s) decoded %+v", domain, records)
+		}
+	}
+	if n := transport.count("infoDnsRecords"); n != 2 {
+		t.Fatalf("sent %d infoDnsRecords requests, want 2", n)
+	}
+
+	want := []string{
+		"infoDnsRecords.maintenance",
+		"infoDnsRecords.responsedata.dnsrecords[].ttl",
+		"infoDnsRecords.responsedata.zoneversion",
+	}
+	if got := unknownFieldWarnings(t, &output); !reflect.DeepEqual(got, want) {
+		t.Errorf("warned about %q, want %q once each", got, want)
+	}
+}
+
+func TestStrictDecodingDisabled(t *testing.T) {
+	c, transport := newTestClient(t)
+	c.SetStrictDecoding(false)
+	respondWithExtraFields(transport)
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	if _, err := c.GetDnsRecords(ctx, "example.com"); err != nil {
+		t.Fatalf("GetDnsRecords failed: %s", err)
+	}
+	if got := unknownFieldWarnings(t, &output); len(got) != 0 {
+		t.Errorf("warned about %q with strict decoding disabled", got)
+	}
+}
+
+// Responses without unknown fields, like the ones of the memory backend, log no warnings
+func TestStrictDecodingKnownFields(t *testing.T) {
+	c, transport := newTestClient(t)
+	c.SetStrictDecoding(true)
+	seedRecords(t, transport, "example.com", DnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"})
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	if _, err := c.GetDnsRecords(ctx, "example.com"); err != nil {
+		t.Fatalf("GetDnsRecords failed: %s", err)
+	}
+	if _, err := c.CreateDnsRecord(ctx, "example.com", NewDnsRecord{Hostname: "api", Type: "A", Destination: "192.0.2.2"}); err != nil {
+		t.Fatalf("CreateDnsRecord failed: %s", err)
+	}
+	if got := unknownFieldWarnings(t, &output); len(got) != 0 {
+		t.Errorf("warned about %q for known fields", got)
+	}
+}
+
+func TestStrictDecodingFromEnv(t *testing.T) {
+	tests := []struct {
+		log, logProvider string
+		want             bool
+	}{
+		{"", "", false},
+		{"DEBUG", "", false},
+		{"TRACE", "", true},
+		{"", "trace", true},
+		{"INFO", "TRACE", true},
+	}
+	for _, tt := range tests {
+		t.Setenv("TF_LOG", tt.log)
+		t.Setenv("TF_LOG_PROVIDER", tt.logProvider)
+		if got := strictDecodingFromEnv(); got != tt.want {
+			t.Errorf("TF_LOG=%q TF_LOG_PROVIDER=%q: strict decoding %t, want %t", tt.log, tt.logProvider, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// The zone returned by updateDnsZone is cached, so the next read sends no request
+func TestUpdateDnsZone(t *testing.T) {
+	c, transport := newTestClient(t)
+	ctx := context.Background()
+
+	zone, err := c.GetDnsZone(ctx, "example.com")
+	if err != nil {
+		t.Fatalf("GetDnsZone failed: %s", err)
+	}
+	settings := *zone
+	settings.TTL = "300"
+	updated, err := c.UpdateDnsZone(ctx, "example.com", settings)
+	if err != nil {
+		t.Fatalf("UpdateDnsZone failed: %s", err)
+	}
+	if updated.TTL != "300" || updated.Refresh != zone.Refresh || updated.Serial == zone.Serial {
+		t.Errorf("updated zone %+v, was %+v", updated, zone)
+	}
+
+	cached, err := c.GetDnsZone(ctx, "example.com")
+	if err != nil {
+		t.Fatalf("GetDnsZone failed: %s", err)
+	}
+	if *cached != *updated {
+		t.Errorf("read %+v after the update, want %+v", cached, updated)
+	}
+	if n := transport.count("infoDnsZone"); n != 1 {
+		t.Errorf("sent %d infoDnsZone requests, want the update cached", n)
+	}
+}
+
+// A rejected update leaves the zone as it was and isn't cached
+func TestUpdateDnsZoneRejected(t *testing.T) {
+	c, transport := newTestClient(t)
+	ctx := context.Background()
+
+	zone, err := c.GetDnsZone(ctx, "example.com")
+	if err != nil {
+		t.Fatalf("GetDnsZone failed: %s", err)
+	}
+	settings := *zone
+	settings.TTL = "0"
+	var apiErr *APIError
+	if _, err := c.UpdateDnsZone(ctx, "example.com", settings); !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want an APIError", err)
+	}
+
+	read, err := c.GetDnsZone(ctx, "example.com")
+	if err != nil {
+		t.Fatalf("GetDnsZone failed: %s", err)
+	}
+	if *read != *zone {
+		t.Errorf("read %+v after a rejected update, want %+v", read, zone)
+	}
+	if n := transport.count("infoDnsZone"); n != 2 {
+		t.Errorf("sent %d infoDnsZone requests, want the zone read again", n)
+	}
+}
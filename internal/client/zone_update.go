@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Time to wait for the zone serial by default
+const DefaultZoneUpdateTimeout = 5 * time.Minute
+
+// Zone serial did not increase within the timeout
+var ErrZoneNotUpdated = errors.New("zone serial did not increase")
+
+type zoneUpdateWait struct {
+	mu      sync.RWMutex
+	enabled bool
+	timeout time.Duration
+	// delays between polls of the zone
+	backoff backoff
+}
+
+// SetZoneUpdateWait enables waiting for the zone serial to increase after writes
+func (c *CCPClient) SetZoneUpdateWait(enabled bool, timeout time.Duration) {
+	c.zoneUpdateWait.mu.Lock()
+	defer c.zoneUpdateWait.mu.Unlock()
+
+	c.zoneUpdateWait.enabled = enabled
+	c.zoneUpdateWait.timeout = timeout
+}
+
+// Whether to wait for the zone serial and for how long
+func (w *zoneUpdateWait) get() (bool, time.Duration) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.enabled, w.timeout
+}
+
+// ZoneSerialBeforeWrite reads the current serial of the zone if waiting for
+// zone updates is enabled, bypassing the zone cache
+func (c *CCPClient) ZoneSerialBeforeWrite(ctx context.Context, domainName string) (string, bool, error) {
+	if enabled, _ := c.zoneUpdateWait.get(); !enabled {
+		return "", false, nil
+	}
+
+	zone, err := c.RefreshDnsZone(ctx, domainName)
+	if err != nil {
+		return "", false, err
+	}
+	return zone.Serial, true, nil
+}
+
+// WaitForZoneUpdate polls the zone until its serial is greater than previous.
+// It gives up after the configured timeout or when ctx expires.
+func (c *CCPClient) WaitForZoneUpdate(ctx context.Context, domainName string, previous string) error {
+	_, timeout := c.zoneUpdateWait.get()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := retryUntil(ctx, c.zoneUpdateWait.backoff, func() (bool, error) {
+		zone, err := c.RefreshDnsZone(ctx, domainName)
+		if err != nil {
+			return false, err
+		}
+		return serialIncreased(previous, zone.Serial), nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return fmt.Errorf("%w from %s for domain %s: %s", ErrZoneNotUpdated, previous, domainName, err)
+	}
+	return err
+}
+
+// Serials are numbers like 2024010101, anything unparsable counts as changed when it differs
+func serialIncreased(previous, current string) bool {
+	p, errPrevious := strconv.ParseUint(previous, 10, 64)
+	n, errCurrent := strconv.ParseUint(current, 10, 64)
+	if errPrevious != nil || errCurrent != nil {
+		return previous != current
+	}
+	return n > p
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Answers infoDnsZone with the serials in order, repeating the last one, and
+// counts the polls
+type serialScript struct {
+	mu      sync.Mutex
+	serials []string
+	polls   int
+}
+
+func (s *serialScript) intercept(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+	if action != "infoDnsZone" {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	serial := s.serials[len(s.serials)-1]
+	if s.polls < len(s.serials) {
+		serial = s.serials[s.polls]
+	}
+	s.polls++
+	return jsonResponse(fmt.Sprintf(`{"action":"infoDnsZone","status":"success","statuscode":2000,"responsedata":{"name":"example.com","ttl":"86400","serial":%q,"refresh":"28800","retry":"7200","expire":"1209600","dnssecstatus":false}}`, serial)), nil
+}
+
+func (s *serialScript) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.polls
+}
+
+// Client waiting up to timeout for zone updates, polling every millisecond
+func newZoneUpdateClient(t *testing.T, timeout time.Duration, serials ...string) (*CCPClient, *serialScript) {
+	t.Helper()
+
+	c, transport := newTestClient(t)
+	c.SetZoneUpdateWait(true, timeout)
+	c.zoneUpdateWait.backoff = backoff{initial: time.Millisecond, max: time.Millisecond, factor: 1}
+	script := &serialScript{serials: serials}
+	transport.setIntercept(script.intercept)
+	return c, script
+}
+
+func TestZoneSerialBeforeWriteDisabled(t *testing.T) {
+	c, transport := newTestClient(t)
+	serial, wait, err := c.ZoneSerialBeforeWrite(context.Background(), "example.com")
+	if err != nil || wait || serial != "" {
+		t.Errorf("got serial %q, wait %t, error %v without wait_for_zone_update, want nothing", serial, wait, err)
+	}
+	if polls := transport.count("infoDnsZone"); polls != 0 {
+		t.Errorf("read the zone %d times without wait_for_zone_update", polls)
+	}
+}
+
+func TestWaitForZoneUpdate(t *testing.T) {
+	c, script := newZoneUpdateClient(t, time.Minute, "2024010100", "2024010100", "2024010100", "2024010101")
+	ctx := context.Background()
+
+	serial, wait, err := c.ZoneSerialBeforeWrite(ctx, "example.com")
+	if err != nil || !wait || serial != "2024010100" {
+		t.Fatalf("got serial %q, wait %t, error %v, want 2024010100", serial, wait, err)
+	}
+	if err := c.WaitForZoneUpdate(ctx, "example.com", serial); err != nil {
+		t.Fatal(err)
+	}
+	if polls := script.count(); polls != 4 {
+		t.Errorf("read the zone %d times, want until the serial increased after 4", polls)
+	}
+}
+
+func TestWaitForZoneUpdateTimeout(t *testing.T) {
+	c, script := newZoneUpdateClient(t, 50*time.Millisecond, "2024010100")
+
+	started := time.Now()
+	err := c.WaitForZoneUpdate(context.Background(), "example.com", "2024010100")
+	if !errors.Is(err, ErrZoneNotUpdated) {
+		t.Fatalf("got %v, want ErrZoneNotUpdated", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("gave up after %s, want after the timeout of 50ms", elapsed)
+	}
+	if script.count() < 2 {
+		t.Errorf("read the zone %d times before giving up, want it polled", script.count())
+	}
+}
+
+// The timeout of a resource operation ends the wait before the configured timeout
+func TestWaitForZoneUpdateOperationTimeout(t *testing.T) {
+	c, _ := newZoneUpdateClient(t, time.Hour, "2024010100")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	if err := c.WaitForZoneUpdate(ctx, "example.com", "2024010100"); !errors.Is(err, ErrZoneNotUpdated) {
+		t.Fatalf("got %v, want ErrZoneNotUpdated", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("waited %s, want the operation timeout of 50ms", elapsed)
+	}
+}
+
+func TestWaitForZoneUpdateReadFails(t *testing.T) {
+	c, transport := newTestClient(t, WithRetries(0, 0))
+	c.SetZoneUpdateWait(true, time.Minute)
+	transport.setIntercept(func(req *http.Request, action string, count int, body []byte) (*http.Response, error) {
+		return jsonResponse(`{"action":"infoDnsZone","status":"error","statuscode":5029,"shortmessage":"Can not get DNS zone.","longmessage":"The zone could not be read."}`), nil
+	})
+
+	err := c.WaitForZoneUpdate(context.Background(), "example.com", "2024010100")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || errors.Is(err, ErrZoneNotUpdated) {
+		t.Errorf("got %v, want the APIError of reading the zone", err)
+	}
+}
+
+// Writes to the mock increase the serial, which is read past the zone cache
+func TestWaitForZoneUpdateMemoryBackend(t *testing.T) {
+	c, _ := newTestClient(t)
+	c.SetZoneUpdateWait(true, time.Minute)
+	ctx := context.Background()
+	if _, err := c.GetDnsZone(ctx, "example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	serial, _, err := c.ZoneSerialBeforeWrite(ctx, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateDnsRecord(ctx, "example.com", NewDnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WaitForZoneUpdate(ctx, "example.com", serial); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSerialIncreased(t *testing.T) {
+	tests := []struct {
+		previous, current string
+		want              bool
+	}{
+		{"2024010100", "2024010101", true},
+		{"2024010100", "2024010100", false},
+		{"2024010101", "2024010100", false},
+		{"999", "1000", true},
+		{"", "2024010100", true},
+		{"abc", "abc", false},
+		{"abc", "abd", true},
+	}
+	for _, test := range tests {
+		if got := serialIncreased(test.previous, test.current); got != test.want {
+			t.Errorf("serialIncreased(%q, %q) = %t, want %t", test.previous, test.current, got, test.want)
+		}
+	}
+}
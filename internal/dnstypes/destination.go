@@ -0,0 +1,110 @@
+package dnstypes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = DestinationType{}
+	_ basetypes.StringValuableWithSemanticEquals = Destination{}
+)
+
+// DestinationType is a string type for record destinations. Values differing
+// only in surrounding whitespace are semantically equal, as the API trims it.
+type DestinationType struct {
+	basetypes.StringType
+}
+
+func (t DestinationType) String() string {
+	return "dnstypes.DestinationType"
+}
+
+func (t DestinationType) ValueType(_ context.Context) attr.Value {
+	return Destination{}
+}
+
+func (t DestinationType) Equal(o attr.Type) bool {
+	other, ok := o.(DestinationType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t DestinationType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return Destination{StringValue: in}, nil
+}
+
+func (t DestinationType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	return Destination{StringValue: stringValue}, nil
+}
+
+// Destination is a value of DestinationType
+type Destination struct {
+	basetypes.StringValue
+}
+
+func NewDestinationValue(value string) Destination {
+	return Destination{StringValue: basetypes.NewStringValue(value)}
+}
+
+func NewDestinationNull() Destination {
+	return Destination{StringValue: basetypes.NewStringNull()}
+}
+
+func (v Destination) Type(_ context.Context) attr.Type {
+	return DestinationType{}
+}
+
+func (v Destination) Equal(o attr.Value) bool {
+	other, ok := o.(Destination)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v Destination) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(Destination)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got %T. Please report this to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	// the type of the record isn't known here, resources compare TXT values with NormalizeDestination
+	return strings.TrimSpace(v.ValueString()) == strings.TrimSpace(newValue.ValueString()), diags
+}
+
+// NormalizeDestination returns the form destinations of a record type are
+// compared in. The API trims them, and returns TXT values quoted and split
+// into character-strings, so TXT values compare by their logical value, see
+// ParseTXTValue. Quotes of other types, e.g. the value of a CAA record, are kept.
+func NormalizeDestination(recordType string, destination string) string {
+	d := strings.TrimSpace(destination)
+	if strings.EqualFold(recordType, "TXT") {
+		return ParseTXTValue(d)
+	}
+	return d
+}
@@ -0,0 +1,47 @@
+package dnstypes
+
+import "testing"
+
+func TestNormalizeDestination(t *testing.T) {
+	tests := []struct {
+		name        string
+		recordType  string
+		destination string
+		want        string
+	}{
+		{"padded A", "A", " 192.0.2.1 ", "192.0.2.1"},
+		{"quoted TXT", "TXT", `"v=spf1 -all"`, "v=spf1 -all"},
+		{"lower case TXT", "txt", `"v=spf1 -all"`, "v=spf1 -all"},
+		{"chunked TXT", "TXT", `"v=DKIM1; k=rsa; " "p=MIGf"`, "v=DKIM1; k=rsa; p=MIGf"},
+		{"padded quoted TXT", "TXT", `  "value"  `, "value"},
+		{"plain TXT", "TXT", "value", "value"},
+		{"CAA keeps quotes", "CAA", `0 issue "letsencrypt.org"`, `0 issue "letsencrypt.org"`},
+		{"quoted CAA value", "CAA", `"letsencrypt.org"`, `"letsencrypt.org"`},
+		{"TLSA", "TLSA", " 3 1 1 abcdef ", "3 1 1 abcdef"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeDestination(tt.recordType, tt.destination); got != tt.want {
+				t.Errorf("NormalizeDestination(%q, %q) = %q, want %q", tt.recordType, tt.destination, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDestinationSemanticEquals(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"192.0.2.1", "192.0.2.1", true},
+		{" 192.0.2.1 ", "192.0.2.1", true},
+		{"mail.example.com\n", "mail.example.com", true},
+		{"mail.example.com", "Mail.example.com", false},
+		{"mail.example.com", "mail.example.com.", false},
+		// TXT values are compared with NormalizeDestination by the resources, which know the type
+		{`"v=spf1 -all"`, "v=spf1 -all", false},
+	}
+	for _, tt := range tests {
+		checkSemanticEquals(t, NewDestinationValue(tt.a), NewDestinationValue(tt.b), tt.want)
+	}
+}
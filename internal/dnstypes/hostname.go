@@ -0,0 +1,108 @@
+package dnstypes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = HostnameType{}
+	_ basetypes.StringValuableWithSemanticEquals = Hostname{}
+)
+
+// HostnameType is a string type for hostnames relative to a zone. Values
+// differing only in case or a trailing dot are semantically equal, as are
+// "" and "@" for the zone apex.
+type HostnameType struct {
+	basetypes.StringType
+}
+
+func (t HostnameType) String() string {
+	return "dnstypes.HostnameType"
+}
+
+func (t HostnameType) ValueType(_ context.Context) attr.Value {
+	return Hostname{}
+}
+
+func (t HostnameType) Equal(o attr.Type) bool {
+	other, ok := o.(HostnameType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t HostnameType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return Hostname{StringValue: in}, nil
+}
+
+func (t HostnameType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	return Hostname{StringValue: stringValue}, nil
+}
+
+// Hostname is a value of HostnameType
+type Hostname struct {
+	basetypes.StringValue
+}
+
+func NewHostnameValue(value string) Hostname {
+	return Hostname{StringValue: basetypes.NewStringValue(value)}
+}
+
+func NewHostnameNull() Hostname {
+	return Hostname{StringValue: basetypes.NewStringNull()}
+}
+
+func (v Hostname) Type(_ context.Context) attr.Type {
+	return HostnameType{}
+}
+
+func (v Hostname) Equal(o attr.Value) bool {
+	other, ok := o.(Hostname)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v Hostname) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(Hostname)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got %T. Please report this to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	return NormalizeHostname(v.ValueString()) == NormalizeHostname(newValue.ValueString()), diags
+}
+
+// NormalizeHostname returns the form hostnames are compared in: lowercase,
+// without trailing dot and with the apex as "@"
+func NormalizeHostname(hostname string) string {
+	h := strings.ToLower(strings.TrimSuffix(hostname, "."))
+	if h == "" {
+		return "@"
+	}
+	return h
+}
@@ -0,0 +1,47 @@
+package dnstypes
+
+import "testing"
+
+func TestNormalizeHostname(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     string
+	}{
+		{"", "@"},
+		{".", "@"},
+		{"@", "@"},
+		{"www", "www"},
+		{"WWW", "www"},
+		{"www.", "www"},
+		{"Mail.Example.com.", "mail.example.com"},
+		{"*.Dev", "*.dev"},
+		{"_DMARC", "_dmarc"},
+		{"www..", "www."},
+	}
+	for _, tt := range tests {
+		if got := NormalizeHostname(tt.hostname); got != tt.want {
+			t.Errorf("NormalizeHostname(%q) = %q, want %q", tt.hostname, got, tt.want)
+		}
+	}
+}
+
+func TestHostnameSemanticEquals(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"www", "www", true},
+		{"www", "WWW", true},
+		{"www", "www.", true},
+		{"Www.Dev.", "www.dev", true},
+		{"", "@", true},
+		{"@", "@", true},
+		{"www", "www2", false},
+		{"www", "@", false},
+		{"*.dev", "*.DEV.", true},
+		{"_acme-challenge", "_acme_challenge", false},
+	}
+	for _, tt := range tests {
+		checkSemanticEquals(t, NewHostnameValue(tt.a), NewHostnameValue(tt.b), tt.want)
+	}
+}
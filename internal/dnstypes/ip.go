@@ -0,0 +1,181 @@
+package dnstypes
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = IPv4Type{}
+	_ basetypes.StringValuableWithSemanticEquals = IPv4{}
+	_ basetypes.StringTypable                    = IPv6Type{}
+	_ basetypes.StringValuableWithSemanticEquals = IPv6{}
+)
+
+// IPv4Type is a string type for IPv4 addresses
+type IPv4Type struct {
+	basetypes.StringType
+}
+
+func (t IPv4Type) String() string {
+	return "dnstypes.IPv4Type"
+}
+
+func (t IPv4Type) ValueType(_ context.Context) attr.Value {
+	return IPv4{}
+}
+
+func (t IPv4Type) Equal(o attr.Type) bool {
+	other, ok := o.(IPv4Type)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t IPv4Type) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return IPv4{StringValue: in}, nil
+}
+
+func (t IPv4Type) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	stringValue, err := stringFromTerraform(ctx, t.StringType, in)
+	if err != nil {
+		return nil, err
+	}
+	return IPv4{StringValue: stringValue}, nil
+}
+
+// IPv4 is a value of IPv4Type
+type IPv4 struct {
+	basetypes.StringValue
+}
+
+func NewIPv4Value(value string) IPv4 {
+	return IPv4{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v IPv4) Type(_ context.Context) attr.Type {
+	return IPv4Type{}
+}
+
+func (v IPv4) Equal(o attr.Value) bool {
+	other, ok := o.(IPv4)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v IPv4) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(IPv4)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got %T. Please report this to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	return addressesEqual(v.ValueString(), newValue.ValueString()), diags
+}
+
+// IPv6Type is a string type for IPv6 addresses. Different spellings of the
+// same address, like 2001:DB8:0::1 and 2001:db8::1, are semantically equal.
+type IPv6Type struct {
+	basetypes.StringType
+}
+
+func (t IPv6Type) String() string {
+	return "dnstypes.IPv6Type"
+}
+
+func (t IPv6Type) ValueType(_ context.Context) attr.Value {
+	return IPv6{}
+}
+
+func (t IPv6Type) Equal(o attr.Type) bool {
+	other, ok := o.(IPv6Type)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t IPv6Type) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return IPv6{StringValue: in}, nil
+}
+
+func (t IPv6Type) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	stringValue, err := stringFromTerraform(ctx, t.StringType, in)
+	if err != nil {
+		return nil, err
+	}
+	return IPv6{StringValue: stringValue}, nil
+}
+
+// IPv6 is a value of IPv6Type
+type IPv6 struct {
+	basetypes.StringValue
+}
+
+func NewIPv6Value(value string) IPv6 {
+	return IPv6{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v IPv6) Type(_ context.Context) attr.Type {
+	return IPv6Type{}
+}
+
+func (v IPv6) Equal(o attr.Value) bool {
+	other, ok := o.(IPv6)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v IPv6) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(IPv6)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got %T. Please report this to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	return addressesEqual(v.ValueString(), newValue.ValueString()), diags
+}
+
+// Unparsable addresses are only equal when spelled the same
+func addressesEqual(a, b string) bool {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return addrA == addrB
+}
+
+func stringFromTerraform(ctx context.Context, t basetypes.StringType, in tftypes.Value) (basetypes.StringValue, error) {
+	attrValue, err := t.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return basetypes.StringValue{}, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return basetypes.StringValue{}, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return stringValue, nil
+}
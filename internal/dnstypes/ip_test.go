@@ -0,0 +1,41 @@
+package dnstypes
+
+import "testing"
+
+func TestIPv4SemanticEquals(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"192.0.2.1", "192.0.2.1", true},
+		{"192.0.2.1", "192.0.2.2", false},
+		{"192.0.2.1", "::ffff:192.0.2.1", false},
+		{"192.0.2.01", "192.0.2.1", false},
+		{"not an address", "not an address", true},
+		{"not an address", "192.0.2.1", false},
+	}
+	for _, tt := range tests {
+		checkSemanticEquals(t, NewIPv4Value(tt.a), NewIPv4Value(tt.b), tt.want)
+	}
+}
+
+func TestIPv6SemanticEquals(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2001:db8::1", "2001:db8::1", true},
+		{"2001:DB8::1", "2001:db8::1", true},
+		{"2001:db8:0:0:0:0:0:1", "2001:db8::1", true},
+		{"2001:0db8:0000::0001", "2001:db8::1", true},
+		{"2001:db8::1", "2001:db8::2", false},
+		{"::ffff:192.0.2.1", "::ffff:c000:201", true},
+		{"::1", "::", false},
+		{"fe80::1%eth0", "fe80::1", false},
+		{"2001:db8::g", "2001:db8::g", true},
+		{"2001:db8::g", "2001:DB8::G", false},
+	}
+	for _, tt := range tests {
+		checkSemanticEquals(t, NewIPv6Value(tt.a), NewIPv6Value(tt.b), tt.want)
+	}
+}
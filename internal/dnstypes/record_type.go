@@ -0,0 +1,97 @@
+package dnstypes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = RecordTypeType{}
+	_ basetypes.StringValuableWithSemanticEquals = RecordType{}
+)
+
+// RecordTypeType is a string type for record types like A or MX. Values
+// differing only in case are semantically equal.
+type RecordTypeType struct {
+	basetypes.StringType
+}
+
+func (t RecordTypeType) String() string {
+	return "dnstypes.RecordTypeType"
+}
+
+func (t RecordTypeType) ValueType(_ context.Context) attr.Value {
+	return RecordType{}
+}
+
+func (t RecordTypeType) Equal(o attr.Type) bool {
+	other, ok := o.(RecordTypeType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t RecordTypeType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return RecordType{StringValue: in}, nil
+}
+
+func (t RecordTypeType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	return RecordType{StringValue: stringValue}, nil
+}
+
+// RecordType is a value of RecordTypeType
+type RecordType struct {
+	basetypes.StringValue
+}
+
+func NewRecordTypeValue(value string) RecordType {
+	return RecordType{StringValue: basetypes.NewStringValue(value)}
+}
+
+func NewRecordTypeNull() RecordType {
+	return RecordType{StringValue: basetypes.NewStringNull()}
+}
+
+func (v RecordType) Type(_ context.Context) attr.Type {
+	return RecordTypeType{}
+}
+
+func (v RecordType) Equal(o attr.Value) bool {
+	other, ok := o.(RecordType)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v RecordType) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(RecordType)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got %T. Please report this to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	return strings.EqualFold(v.ValueString(), newValue.ValueString()), diags
+}
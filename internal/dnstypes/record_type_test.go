@@ -0,0 +1,20 @@
+package dnstypes
+
+import "testing"
+
+func TestRecordTypeSemanticEquals(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"A", "A", true},
+		{"a", "A", true},
+		{"Mx", "MX", true},
+		{"openpgpkey", "OPENPGPKEY", true},
+		{"A", "AAAA", false},
+		{"TXT", " TXT", false},
+	}
+	for _, tt := range tests {
+		checkSemanticEquals(t, NewRecordTypeValue(tt.a), NewRecordTypeValue(tt.b), tt.want)
+	}
+}
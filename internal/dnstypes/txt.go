@@ -0,0 +1,71 @@
+package dnstypes
+
+import (
+	"strings"
+)
+
+// Maximum length of a single TXT character-string (RFC 1035 section 3.3)
+const txtChunkSize = 255
+
+// ParseTXTValue decodes a TXT destination into its logical value. The API returns values either
+// plain or as one or more quoted character-strings, e.g. `"v=DKIM1; k=rsa; " "p=MIGf..."`,
+// which are joined without separator. Values that are not fully quoted are returned unchanged.
+func ParseTXTValue(destination string) string {
+	s := strings.TrimSpace(destination)
+	if !strings.HasPrefix(s, `"`) || !strings.HasSuffix(s, `"`) || len(s) < 2 {
+		return destination
+	}
+
+	var value strings.Builder
+	for len(s) > 0 {
+		if s[0] != '"' {
+			return destination
+		}
+		chunk, rest, ok := readQuotedString(s[1:])
+		if !ok {
+			return destination
+		}
+		value.WriteString(chunk)
+		s = strings.TrimLeft(rest, " \t")
+	}
+	return value.String()
+}
+
+// Read a quoted character-string up to its closing quote, resolving backslash escapes
+func readQuotedString(s string) (string, string, bool) {
+	var chunk strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 >= len(s) {
+				return "", "", false
+			}
+			i++
+			chunk.WriteByte(s[i])
+		case '"':
+			return chunk.String(), s[i+1:], true
+		default:
+			chunk.WriteByte(s[i])
+		}
+	}
+	return "", "", false
+}
+
+// FormatTXTValue encodes a logical TXT value as quoted character-strings of at most 255 bytes each
+func FormatTXTValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+
+	var chunks []string
+	for len(value) > 0 {
+		n := len(value)
+		if n > txtChunkSize {
+			n = txtChunkSize
+		}
+		chunk := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value[:n])
+		chunks = append(chunks, `"`+chunk+`"`)
+		value = value[n:]
+	}
+	return strings.Join(chunks, " ")
+}
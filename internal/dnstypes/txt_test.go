@@ -0,0 +1,61 @@
+package dnstypes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTXTValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		want        string
+	}{
+		{"plain", "v=spf1 -all", "v=spf1 -all"},
+		{"quoted", `"v=spf1 -all"`, "v=spf1 -all"},
+		{"padded", `  "v=spf1 -all" `, "v=spf1 -all"},
+		{"chunks joined without separator", `"v=DKIM1; k=rsa; " "p=MIGf"`, "v=DKIM1; k=rsa; p=MIGf"},
+		{"chunks separated by tabs", "\"a\"\t\t\"b\"", "ab"},
+		{"escaped quotes", `"say \"hi\""`, `say "hi"`},
+		{"escaped backslash", `"a\\b"`, `a\b`},
+		{"empty string", `""`, ""},
+		{"quoted in the middle", `v="a"`, `v="a"`},
+		{"unterminated", `"a" "b`, `"a" "b`},
+		{"text between chunks", `"a" b "c"`, `"a" b "c"`},
+		{"trailing backslash", `"a\"`, `"a\"`},
+		{"single quote", `"`, `"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseTXTValue(tt.destination); got != tt.want {
+				t.Errorf("ParseTXTValue(%q) = %q, want %q", tt.destination, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTXTValue(t *testing.T) {
+	long := strings.Repeat("a", 255) + strings.Repeat("b", 255) + "c"
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"empty", "", `""`},
+		{"short", "v=spf1 -all", `"v=spf1 -all"`},
+		{"escaped", `say "hi" \o/`, `"say \"hi\" \\o/"`},
+		{"chunked", long, `"` + strings.Repeat("a", 255) + `" "` + strings.Repeat("b", 255) + `" "c"`},
+		{"exactly one chunk", strings.Repeat("a", 255), `"` + strings.Repeat("a", 255) + `"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatTXTValue(tt.value)
+			if got != tt.want {
+				t.Errorf("FormatTXTValue(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+			if parsed := ParseTXTValue(got); parsed != tt.value {
+				t.Errorf("ParseTXTValue(FormatTXTValue(%q)) = %q", tt.value, parsed)
+			}
+		})
+	}
+}
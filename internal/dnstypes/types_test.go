@@ -0,0 +1,109 @@
+package dnstypes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// The custom types of the package and a constructor of their values
+var customTypes = []struct {
+	typ      basetypes.StringTypable
+	newValue func(string) basetypes.StringValuableWithSemanticEquals
+}{
+	{IPv4Type{}, func(s string) basetypes.StringValuableWithSemanticEquals { return NewIPv4Value(s) }},
+	{IPv6Type{}, func(s string) basetypes.StringValuableWithSemanticEquals { return NewIPv6Value(s) }},
+	{HostnameType{}, func(s string) basetypes.StringValuableWithSemanticEquals { return NewHostnameValue(s) }},
+	{RecordTypeType{}, func(s string) basetypes.StringValuableWithSemanticEquals { return NewRecordTypeValue(s) }},
+	{DestinationType{}, func(s string) basetypes.StringValuableWithSemanticEquals { return NewDestinationValue(s) }},
+}
+
+func TestCustomTypesFromTerraform(t *testing.T) {
+	ctx := context.Background()
+	for _, custom := range customTypes {
+		t.Run(custom.typ.String(), func(t *testing.T) {
+			for _, in := range []tftypes.Value{
+				tftypes.NewValue(tftypes.String, "value"),
+				tftypes.NewValue(tftypes.String, nil),
+				tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+			} {
+				value, err := custom.typ.ValueFromTerraform(ctx, in)
+				if err != nil {
+					t.Fatalf("ValueFromTerraform(%s) failed: %s", in, err)
+				}
+				if !value.Type(ctx).Equal(custom.typ) {
+					t.Errorf("value of %s has type %s", in, value.Type(ctx))
+				}
+				back, err := value.ToTerraformValue(ctx)
+				if err != nil || !back.Equal(in) {
+					t.Errorf("value of %s converts back to %s, %v", in, back, err)
+				}
+			}
+
+			if _, err := custom.typ.ValueFromTerraform(ctx, tftypes.NewValue(tftypes.Number, 1)); err == nil {
+				t.Error("ValueFromTerraform accepted a number")
+			}
+
+			value, diags := custom.typ.ValueFromString(ctx, basetypes.NewStringValue("value"))
+			if diags.HasError() || !value.Type(ctx).Equal(custom.typ) {
+				t.Errorf("ValueFromString returned %T, %v", value, diags)
+			}
+			if _, ok := custom.typ.ValueType(ctx).(basetypes.StringValuableWithSemanticEquals); !ok {
+				t.Errorf("ValueType is %T, without semantic equality", custom.typ.ValueType(ctx))
+			}
+		})
+	}
+}
+
+func TestCustomTypesEqual(t *testing.T) {
+	ctx := context.Background()
+	for i, a := range customTypes {
+		for j, b := range customTypes {
+			if got := a.typ.Equal(b.typ); got != (i == j) {
+				t.Errorf("%s equal to %s: %t", a.typ, b.typ, got)
+			}
+			if got := a.newValue("x").Equal(b.newValue("x")); got != (i == j) {
+				t.Errorf("value of %s equal to value of %s: %t", a.typ, b.typ, got)
+			}
+		}
+		if a.typ.Equal(basetypes.StringType{}) {
+			t.Errorf("%s equal to the plain string type", a.typ)
+		}
+		var value attr.Value = a.newValue("x")
+		if value.Equal(basetypes.NewStringValue("x")) {
+			t.Errorf("value of %s equal to a plain string", a.typ)
+		}
+		if !a.newValue("x").Type(ctx).Equal(a.typ) {
+			t.Errorf("value of %s has type %s", a.typ, a.newValue("x").Type(ctx))
+		}
+	}
+}
+
+// Comparing with a value of another type is a bug of the provider, reported as such
+func TestCustomTypesSemanticEqualsOtherType(t *testing.T) {
+	ctx := context.Background()
+	for _, custom := range customTypes {
+		equal, diags := custom.newValue("x").StringSemanticEquals(ctx, basetypes.NewStringValue("x"))
+		if equal || !diags.HasError() {
+			t.Errorf("%s compared with a plain string: %t, %v", custom.typ, equal, diags)
+		}
+	}
+}
+
+// Check the semantic equality of old and new, in both directions
+func checkSemanticEquals(t *testing.T, old, new basetypes.StringValuableWithSemanticEquals, want bool) {
+	t.Helper()
+	ctx := context.Background()
+	for _, pair := range [][2]basetypes.StringValuableWithSemanticEquals{{old, new}, {new, old}} {
+		equal, diags := pair[0].StringSemanticEquals(ctx, pair[1])
+		if diags.HasError() {
+			t.Fatalf("StringSemanticEquals failed: %v", diags)
+		}
+		if equal != want {
+			t.Errorf("%s semantically equal to %s: %t, want %t", pair[0], pair[1], equal, want)
+		}
+	}
+}
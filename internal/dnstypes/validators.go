@@ -0,0 +1,134 @@
+package dnstypes
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// Validates IPv4 addresses
+func IPv4Validator() validator.String {
+	return addressValidator{description: "value must be an IPv4 address", is4: true}
+}
+
+// Validates IPv6 addresses
+func IPv6Validator() validator.String {
+	return addressValidator{description: "value must be an IPv6 address"}
+}
+
+type addressValidator struct {
+	description string
+	is4         bool
+}
+
+func (v addressValidator) Description(_ context.Context) string {
+	return v.description
+}
+
+func (v addressValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v addressValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	addr, err := netip.ParseAddr(req.ConfigValue.ValueString())
+	if err == nil && addr.Zone() == "" && (v.is4 && addr.Is4() || !v.is4 && addr.Is6() && !addr.Is4In6()) {
+		return
+	}
+	resp.Diagnostics.AddAttributeError(req.Path, "Invalid IP address", v.Description(ctx)+", got \""+req.ConfigValue.ValueString()+"\"")
+}
+
+// Validates hostnames relative to a zone: "@", or dot separated labels of
+// letters, digits, hyphens and underscores, optionally starting with a "*" label
+func HostnameValidator() validator.String {
+	return hostnameValidator{}
+}
+
+type hostnameValidator struct{}
+
+func (v hostnameValidator) Description(_ context.Context) string {
+	return "value must be \"@\" or a hostname of labels with letters, digits, hyphens and underscores"
+}
+
+func (v hostnameValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v hostnameValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if reason := hostnameError(req.ConfigValue.ValueString()); reason != "" {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid hostname", reason)
+	}
+}
+
+// Reason a hostname is invalid, empty if it is valid
+func hostnameError(hostname string) string {
+	h := strings.TrimSuffix(hostname, ".")
+	if h == "" || h == "@" {
+		return ""
+	}
+	if len(h) > 253 {
+		return "hostname \"" + hostname + "\" is longer than 253 characters"
+	}
+
+	for i, label := range strings.Split(h, ".") {
+		if label == "*" && i == 0 {
+			continue
+		}
+		if label == "" {
+			return "hostname \"" + hostname + "\" contains an empty label"
+		}
+		if len(label) > 63 {
+			return "label \"" + label + "\" of hostname \"" + hostname + "\" is longer than 63 characters"
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return "label \"" + label + "\" of hostname \"" + hostname + "\" starts or ends with a hyphen"
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return "hostname \"" + hostname + "\" contains the invalid character " + string(r)
+			}
+		}
+	}
+	return ""
+}
+
+// Record types the Netcup API supports
+var supportedRecordTypes = []string{"A", "AAAA", "MX", "CNAME", "TXT", "NS", "SRV", "CAA", "TLSA", "DS", "OPENPGPKEY", "SMIMEA", "SSHFP"}
+
+// Validates record types against supportedRecordTypes, ignoring case like
+// the semantic equality of RecordType
+func RecordTypeValidator() validator.String {
+	return recordTypeValidator{}
+}
+
+type recordTypeValidator struct{}
+
+func (v recordTypeValidator) Description(_ context.Context) string {
+	return "value must be one of " + strings.Join(supportedRecordTypes, ", ")
+}
+
+func (v recordTypeValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v recordTypeValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	for _, recordType := range supportedRecordTypes {
+		if strings.EqualFold(req.ConfigValue.ValueString(), recordType) {
+			return
+		}
+	}
+	resp.Diagnostics.AddAttributeError(req.Path, "Unsupported record type", v.Description(ctx)+", got \""+req.ConfigValue.ValueString()+"\"")
+}
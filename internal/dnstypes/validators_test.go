@@ -0,0 +1,111 @@
+package dnstypes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Summary of the error of validating value, empty if it is valid
+func validate(t *testing.T, v validator.String, value types.String) string {
+	t.Helper()
+
+	resp := &validator.StringResponse{}
+	v.ValidateString(context.Background(), validator.StringRequest{Path: path.Root("value"), ConfigValue: value}, resp)
+	if resp.Diagnostics.ErrorsCount() > 1 {
+		t.Fatalf("got %d errors for %s, want at most one", resp.Diagnostics.ErrorsCount(), value)
+	}
+	for _, d := range resp.Diagnostics.Errors() {
+		return d.Summary() + ": " + d.Detail()
+	}
+	return ""
+}
+
+func checkValidator(t *testing.T, v validator.String, valid []string, invalid map[string]string) {
+	t.Helper()
+
+	for _, value := range valid {
+		if err := validate(t, v, types.StringValue(value)); err != "" {
+			t.Errorf("%q rejected: %s", value, err)
+		}
+	}
+	for value, reason := range invalid {
+		err := validate(t, v, types.StringValue(value))
+		if err == "" {
+			t.Errorf("%q accepted", value)
+		} else if !strings.Contains(err, reason) {
+			t.Errorf("%q rejected with %q, want %q", value, err, reason)
+		}
+	}
+	for _, value := range []types.String{types.StringNull(), types.StringUnknown()} {
+		if err := validate(t, v, value); err != "" {
+			t.Errorf("%s rejected: %s", value, err)
+		}
+	}
+	if v.Description(context.Background()) == "" || v.MarkdownDescription(context.Background()) == "" {
+		t.Error("validator has no description")
+	}
+}
+
+func TestIPv4Validator(t *testing.T) {
+	checkValidator(t, IPv4Validator(),
+		[]string{"192.0.2.1", "0.0.0.0", "255.255.255.255"},
+		map[string]string{
+			"2001:db8::1":      "Invalid IP address: value must be an IPv4 address",
+			"::ffff:192.0.2.1": "IPv4 address",
+			"192.0.2":          "IPv4 address",
+			"192.0.2.256":      "IPv4 address",
+			"192.0.2.01":       "IPv4 address",
+			" 192.0.2.1":       "IPv4 address",
+			"example.com":      `got "example.com"`,
+			"":                 "IPv4 address",
+		},
+	)
+}
+
+func TestIPv6Validator(t *testing.T) {
+	checkValidator(t, IPv6Validator(),
+		[]string{"2001:db8::1", "2001:DB8:0:0:0:0:0:1", "::1", "::"},
+		map[string]string{
+			"192.0.2.1":        "Invalid IP address: value must be an IPv6 address",
+			"::ffff:192.0.2.1": "IPv6 address",
+			"fe80::1%eth0":     "IPv6 address",
+			"2001:db8::g":      "IPv6 address",
+			"2001:db8:::1":     "IPv6 address",
+			"":                 `got ""`,
+		},
+	)
+}
+
+func TestHostnameValidator(t *testing.T) {
+	checkValidator(t, HostnameValidator(),
+		[]string{"@", "", "www", "www.", "WWW", "mail.dev", "*", "*.dev", "_dmarc", "_acme-challenge.www", "xn--bcher-kva", "a1-b2", strings.Repeat("a", 63)},
+		map[string]string{
+			"www..dev":                       "contains an empty label",
+			".www":                           "contains an empty label",
+			"-www":                           "starts or ends with a hyphen",
+			"www-":                           "starts or ends with a hyphen",
+			"dev.*":                          "contains the invalid character *",
+			"www dev":                        "contains the invalid character  ",
+			"bücher":                         "contains the invalid character ü",
+			"www/dev":                        "contains the invalid character /",
+			strings.Repeat("a", 64):          "longer than 63 characters",
+			strings.Repeat("a.", 127) + "aa": "longer than 253 characters",
+		},
+	)
+}
+
+func TestRecordTypeValidator(t *testing.T) {
+	valid := append([]string{"a", "Mx", "txt"}, supportedRecordTypes...)
+	checkValidator(t, RecordTypeValidator(), valid, map[string]string{
+		"PTR":   "Unsupported record type",
+		"SOA":   "value must be one of A, AAAA",
+		" A":    `got " A"`,
+		"":      "Unsupported record type",
+		"A AAA": "Unsupported record type",
+	})
+}
@@ -0,0 +1,193 @@
+// Package export renders existing records as Terraform configuration.
+package export
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+type Options struct {
+	// Only export records of these types. Exports all types if empty.
+	Types []string
+	// Emit one resource with for_each over a locals map instead of one resource per record
+	ForEach bool
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// Render writes resource and import blocks for the records of a domain
+func Render(w io.Writer, domainname string, records []client.DnsRecord, opts Options) error {
+	records = filterRecords(records, opts.Types)
+	names := resourceNames(records)
+
+	var b strings.Builder
+	if opts.ForEach {
+		renderForEach(&b, domainname, records, names)
+	} else {
+		renderResources(&b, domainname, records, names)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func renderResources(b *strings.Builder, domainname string, records []client.DnsRecord, names []string) {
+	for i, record := range records {
+		attrs := [][2]string{
+			{"domainname", quote(domainname)},
+			{"hostname", quote(record.Hostname)},
+			{"type", quote(record.Type)},
+			{"destination", quote(record.Destination)},
+		}
+		if hasPriority(record) {
+			attrs = append(attrs, [2]string{"priority", quote(record.Priority)})
+		}
+
+		writeBlock(b, fmt.Sprintf("resource \"netcupdns_record\" %s", quote(names[i])), attrs)
+		b.WriteString("\n")
+		writeBlock(b, "import", [][2]string{
+			{"to", "netcupdns_record." + names[i]},
+			{"id", quote(domainname + "/" + record.Id)},
+		})
+		b.WriteString("\n")
+	}
+}
+
+func renderForEach(b *strings.Builder, domainname string, records []client.DnsRecord, names []string) {
+	b.WriteString("locals {\n  netcupdns_records = {\n")
+	// keys are aligned like terraform fmt aligns them
+	width := 0
+	for _, name := range names {
+		if len(quote(name)) > width {
+			width = len(quote(name))
+		}
+	}
+	for i, record := range records {
+		priority := "null"
+		if hasPriority(record) {
+			priority = quote(record.Priority)
+		}
+		fmt.Fprintf(b, "    %-*s = { hostname = %s, type = %s, destination = %s, priority = %s }\n",
+			width, quote(names[i]), quote(record.Hostname), quote(record.Type), quote(record.Destination), priority)
+	}
+	b.WriteString("  }\n}\n\n")
+
+	writeBlock(b, "resource \"netcupdns_record\" \"this\"", [][2]string{
+		{"for_each", "local.netcupdns_records"},
+		{"", ""},
+		{"domainname", quote(domainname)},
+		{"hostname", "each.value.hostname"},
+		{"type", "each.value.type"},
+		{"destination", "each.value.destination"},
+		{"priority", "each.value.priority"},
+	})
+
+	for i, record := range records {
+		b.WriteString("\n")
+		writeBlock(b, "import", [][2]string{
+			{"to", fmt.Sprintf("netcupdns_record.this[%s]", quote(names[i]))},
+			{"id", quote(domainname + "/" + record.Id)},
+		})
+	}
+}
+
+// Write a block with aligned attributes like terraform fmt does. An empty key starts a new alignment group.
+func writeBlock(b *strings.Builder, header string, attrs [][2]string) {
+	b.WriteString(header + " {\n")
+	for start := 0; start < len(attrs); {
+		end := start
+		width := 0
+		for end < len(attrs) && attrs[end][0] != "" {
+			if len(attrs[end][0]) > width {
+				width = len(attrs[end][0])
+			}
+			end++
+		}
+		for _, attr := range attrs[start:end] {
+			fmt.Fprintf(b, "  %-*s = %s\n", width, attr[0], attr[1])
+		}
+		if end < len(attrs) {
+			b.WriteString("\n")
+			end++
+		}
+		start = end
+	}
+	b.WriteString("}\n")
+}
+
+func filterRecords(records []client.DnsRecord, types []string) []client.DnsRecord {
+	var filtered []client.DnsRecord
+	for _, record := range records {
+		if len(types) == 0 || containsFold(types, record.Type) {
+			filtered = append(filtered, record)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		a, b := filtered[i], filtered[j]
+		if a.Hostname != b.Hostname {
+			return a.Hostname < b.Hostname
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Destination < b.Destination
+	})
+	return filtered
+}
+
+// Unique Terraform names like "www_a", "apex_mx", "wildcard_cname" or "www_a_2" for repeated names
+func resourceNames(records []client.DnsRecord) []string {
+	names := make([]string, len(records))
+	seen := make(map[string]int)
+	for i, record := range records {
+		hostname := record.Hostname
+		if hostname == "@" {
+			hostname = "apex"
+		}
+		hostname = strings.ReplaceAll(hostname, "*", "wildcard")
+		name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(hostname+"_"+record.Type), "_"), "_")
+		if name == "" || (name[0] >= '0' && name[0] <= '9') {
+			name = "r_" + name
+		}
+
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s_%d", name, seen[name])
+		}
+		names[i] = name
+	}
+	return names
+}
+
+func hasPriority(record client.DnsRecord) bool {
+	t := strings.ToUpper(record.Type)
+	return record.Priority != "" && (t == "MX" || t == "SRV")
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Quote a string as HCL literal, escaping template sequences
+func quote(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"${", "$${",
+		"%{", "%%{",
+	)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+// Tests of this file run against the real API only with NETCUP_ACC=1, the
+// NETCUP_* credentials and NETCUP_TEST_DOMAIN, a zone reserved for the tests.
+// They create records at random hostnames of acceptanceTestHostname and delete
+// them when done. Records of aborted runs are removed with -sweep.
+
+// Pause before every step, to stay far below the rate limit of the API
+const acceptanceTestPause = 2 * time.Second
+
+// Credentials and the test zone of the environment, skipping the test without NETCUP_ACC
+type acceptanceEnv struct {
+	customerNumber, apiKey, apiPassword string
+	domain                              string
+}
+
+func acceptanceTestEnv(t *testing.T) acceptanceEnv {
+	t.Helper()
+	if os.Getenv("NETCUP_ACC") != "1" {
+		t.Skip("acceptance tests run against the real API with NETCUP_ACC=1")
+	}
+	env := acceptanceEnv{
+		customerNumber: os.Getenv("NETCUP_CUSTOMER_NUMBER"),
+		apiKey:         os.Getenv("NETCUP_API_KEY"),
+		apiPassword:    os.Getenv("NETCUP_API_PASSWORD"),
+		domain:         os.Getenv("NETCUP_TEST_DOMAIN"),
+	}
+	if env.customerNumber == "" || env.apiKey == "" || env.apiPassword == "" || env.domain == "" {
+		t.Fatal("acceptance tests need NETCUP_CUSTOMER_NUMBER, NETCUP_API_KEY, NETCUP_API_PASSWORD and NETCUP_TEST_DOMAIN")
+	}
+	return env
+}
+
+// Provider of a new Terraform run against the real API, sending one request at a time
+func (env acceptanceEnv) provider(t *testing.T) *testProvider {
+	t.Helper()
+	time.Sleep(acceptanceTestPause)
+	p := startTestProvider(t)
+	p.checkDiags("configure", p.configure(attrs{
+		"customer_number": env.customerNumber, "key": env.apiKey, "password": env.apiPassword,
+		"max_concurrent_requests": 1, "max_retries": 8, "retry_base_delay": "5s",
+	}))
+	return p
+}
+
+// Delete the records at hostname when the test ends, also if it fails
+func (env acceptanceEnv) cleanup(t *testing.T, hostname string) {
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		c, err := client.NewCCPClient(ctx, env.customerNumber, env.apiKey, env.apiPassword)
+		if err != nil {
+			t.Errorf("cleanup of %s: %s, remove its records with -sweep", hostname, err)
+			return
+		}
+		defer c.Logout(ctx)
+		leftovers, err := c.GetDnsRecordsFiltered(ctx, env.domain, client.RecordFilter{Hostname: hostname})
+		if err == nil && len(leftovers) > 0 {
+			t.Logf("deleting %d records left at %s", len(leftovers), hostname)
+			err = c.DeleteDnsRecords(ctx, env.domain, leftovers)
+		}
+		if err != nil {
+			t.Errorf("cleanup of %s: %s, remove its records with -sweep", hostname, err)
+		}
+	})
+}
+
+// Create, update, import and destroy a record of each common type
+func TestAccDnsRecordLifecycle(t *testing.T) {
+	env := acceptanceTestEnv(t)
+
+	tests := []struct {
+		recordType                string
+		priority, updatedPriority string
+		destination, updated      string
+	}{
+		{"A", "", "", "192.0.2.1", "192.0.2.2"},
+		{"AAAA", "", "", "2001:db8::1", "2001:db8::2"},
+		{"CNAME", "", "", "example.com.", "www.example.com."},
+		{"MX", "10", "20", "mail.example.com", "mail2.example.com"},
+		{"TXT", "", "", "v=tf-acc-test1", "v=tf-acc-test2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.recordType, func(t *testing.T) {
+			hostname := acceptanceTestHostname()
+			env.cleanup(t, hostname)
+			config := attrs{"domainname": env.domain, "hostname": hostname, "type": tt.recordType, "destination": tt.destination}
+			if tt.priority != "" {
+				config["priority"] = tt.priority
+			}
+			// every step is a new run, which reads the zone from the API again
+			var p *testProvider
+			next := func() *testProvider {
+				if p != nil {
+					p.close()
+				}
+				p = env.provider(t)
+				return p
+			}
+
+			p = next()
+			created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+			id := attrString(t, created.State, "id")
+			if planned := p.plan("netcupdns_record", created.State, created.Private, config); !planned.Equal(created.State) {
+				t.Errorf("plan after create isn't empty:\nplanned %s\nstate   %s", planned, created.State)
+			}
+
+			config["destination"] = tt.updated
+			if tt.updatedPriority != "" {
+				config["priority"] = tt.updatedPriority
+			}
+			p = next()
+			updated := p.apply("netcupdns_record", created.State, created.Private, config)
+			if got := attrString(t, updated.State, "id"); got != id {
+				t.Errorf("update replaced record %s by %s", id, got)
+			}
+
+			p = next()
+			imported, diags := p.importState("netcupdns_record", env.domain+"/"+id)
+			p.checkDiags("import", diags)
+			for _, name := range []string{"id", "domainname", "hostname", "type", "priority", "destination"} {
+				if got, want := attrString(t, imported, name), attrString(t, updated.State, name); got != want {
+					t.Errorf("import: %s = %q, want %q", name, got, want)
+				}
+			}
+			if planned := p.plan("netcupdns_record", imported, nil, config); !planned.Equal(imported) {
+				t.Errorf("plan after import isn't empty:\nplanned %s\nstate   %s", planned, imported)
+			}
+
+			p = next()
+			if destroyed := p.apply("netcupdns_record", updated.State, updated.Private, nil); !destroyed.State.IsNull() {
+				t.Errorf("destroy left state %s", destroyed.State)
+			}
+			p = next()
+			refreshed, diags := p.read("netcupdns_record", updated.State, updated.Private)
+			p.checkDiags("refresh after destroy", diags)
+			if !refreshed.IsNull() {
+				t.Errorf("record %s still read after destroy: %s", id, refreshed)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// Shapes of the credentials Netcup hands out. API keys and passwords are
+// generated as letters and digits of about 30 characters.
+var (
+	customerNumberPattern = regexp.MustCompile(`^[0-9]+$`)
+	apiKeyPattern         = regexp.MustCompile(`^[A-Za-z0-9]{20,64}$`)
+)
+
+// Credentials of mock providers. The memory backend accepts any login.
+const (
+	mockCustomerNumber = "0"
+	mockApiKey         = "mock"
+	mockApiPassword    = "mock"
+)
+
+// A credential of the provider configuration and where it was read from
+type credential struct {
+	Attribute string
+	Env       string
+	FromEnv   bool
+	Value     string
+	// Expected shape, nil if any value without whitespace is plausible
+	Pattern *regexp.Regexp
+	// Description of the expected shape for diagnostics
+	Shape string
+}
+
+func (c credential) source() string {
+	if c.FromEnv {
+		return "The environment variable " + c.Env
+	}
+	return "The attribute " + c.Attribute
+}
+
+// Check the shapes of the credentials before logging in, as Netcup locks the
+// API after repeated failed logins. Secrets are never part of the diagnostics.
+func validateCredentials(credentials []credential, diags *diag.Diagnostics) {
+	for _, c := range credentials {
+		switch {
+		case strings.ContainsAny(c.Value, " \t\r\n"):
+			diags.AddAttributeError(
+				path.Root(c.Attribute),
+				"Invalid "+c.Attribute,
+				c.source()+" contains whitespace or a line break, likely copied along with the value. Remove it before logging in.",
+			)
+		case c.Pattern != nil && !c.Pattern.MatchString(c.Value):
+			diags.AddAttributeError(
+				path.Root(c.Attribute),
+				"Invalid "+c.Attribute,
+				c.source()+" doesn't look like a "+c.Shape+". Check that the values of customer_number, key and password aren't swapped.",
+			)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// Credentials as the provider configuration checks them, with valid values
+func testCredentials(fromEnv bool, customerNumber, key, password string) []credential {
+	return []credential{
+		{Attribute: "customer_number", Env: "NETCUP_CUSTOMER_NUMBER", FromEnv: fromEnv, Value: customerNumber,
+			Pattern: customerNumberPattern, Shape: "customer number"},
+		{Attribute: "key", Env: "NETCUP_API_KEY", FromEnv: fromEnv, Value: key,
+			Pattern: apiKeyPattern, Shape: "Netcup API key"},
+		{Attribute: "password", Env: "NETCUP_API_PASSWORD", FromEnv: fromEnv, Value: password},
+	}
+}
+
+func TestValidateCredentials(t *testing.T) {
+	const (
+		number   = "12345"
+		key      = "abcdefghij0123456789ABCDEF"
+		password = "secret-password"
+	)
+
+	tests := []struct {
+		name                          string
+		customerNumber, key, password string
+		fromEnv                       bool
+		attribute                     string
+		source                        string
+	}{
+		{name: "valid", customerNumber: number, key: key, password: password},
+		{name: "customer number with letters", customerNumber: "12345a", key: key, password: password,
+			attribute: "customer_number", source: "The attribute customer_number"},
+		{name: "customer number with a dash", customerNumber: "123-45", key: key, password: password,
+			attribute: "customer_number", source: "The attribute customer_number"},
+		{name: "customer number with a leading space", customerNumber: " 12345", key: key, password: password,
+			attribute: "customer_number", source: "The attribute customer_number"},
+		{name: "customer number with a trailing newline", customerNumber: "12345\n", key: key, password: password,
+			fromEnv: true, attribute: "customer_number", source: "The environment variable NETCUP_CUSTOMER_NUMBER"},
+		{name: "key too short", customerNumber: number, key: "abc123", password: password,
+			attribute: "key", source: "The attribute key"},
+		{name: "key too long", customerNumber: number, key: strings.Repeat("a", 65), password: password,
+			attribute: "key", source: "The attribute key"},
+		{name: "key with special characters", customerNumber: number, key: "abcdefghij-0123456789_ABCDEF", password: password,
+			attribute: "key", source: "The attribute key"},
+		{name: "key with a tab", customerNumber: number, key: key + "\t", password: password,
+			fromEnv: true, attribute: "key", source: "The environment variable NETCUP_API_KEY"},
+		{name: "key with a carriage return", customerNumber: number, key: key + "\r\n", password: password,
+			attribute: "key", source: "The attribute key"},
+		{name: "password with a space", customerNumber: number, key: key, password: "secret password",
+			attribute: "password", source: "The attribute password"},
+		{name: "password with a newline", customerNumber: number, key: key, password: password + "\n",
+			fromEnv: true, attribute: "password", source: "The environment variable NETCUP_API_PASSWORD"},
+	}
+
+	for _, tt := range tests {
+		var diags diag.Diagnostics
+		validateCredentials(testCredentials(tt.fromEnv, tt.customerNumber, tt.key, tt.password), &diags)
+		if tt.attribute == "" {
+			if diags.HasError() {
+				t.Errorf("%s: got errors %v", tt.name, diags.Errors())
+			}
+			continue
+		}
+
+		errors := diags.Errors()
+		if len(errors) != 1 {
+			t.Errorf("%s: got %d errors %v, want one", tt.name, len(errors), errors)
+			continue
+		}
+		d, ok := errors[0].(diag.DiagnosticWithPath)
+		if !ok || !d.Path().Equal(path.Root(tt.attribute)) {
+			t.Errorf("%s: error %q isn't at attribute %s", tt.name, errors[0].Summary(), tt.attribute)
+		}
+		if got, want := errors[0].Summary(), "Invalid "+tt.attribute; got != want {
+			t.Errorf("%s: summary %q, want %q", tt.name, got, want)
+		}
+		if detail := errors[0].Detail(); !strings.HasPrefix(detail, tt.source+" ") {
+			t.Errorf("%s: detail %q doesn't name the source %q", tt.name, detail, tt.source)
+		}
+		for _, secret := range []string{tt.key, tt.password} {
+			if strings.Contains(errors[0].Detail(), strings.TrimSpace(secret)) {
+				t.Errorf("%s: detail %q contains a secret", tt.name, errors[0].Detail())
+			}
+		}
+	}
+}
+
+// Malformed credentials fail the configuration without contacting the API
+func TestConfigureMalformedCredentialsSkipLogin(t *testing.T) {
+	server := newLoginServer(t)
+	for _, config := range []attrs{
+		{"customer_number": "12345 ", "key": "abcdefghij0123456789ABCDEF", "password": "secret-password"},
+		{"customer_number": "12345", "key": "secret-password", "password": "abcdefghij0123456789ABCDEF"},
+		{"customer_number": "12345", "key": "abcdefghij0123456789ABCDEF", "password": "secret-password\n"},
+	} {
+		config["endpoint"] = server.URL
+		p := startTestProvider(t)
+		if diags := p.configure(config); !hasErrors(diags) {
+			t.Errorf("configure with %v succeeded", config)
+		}
+	}
+	if logins := server.Logins(); len(logins) > 0 {
+		t.Errorf("logged in with malformed credentials %+v", logins)
+	}
+}
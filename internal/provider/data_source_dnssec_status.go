@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ datasource.DataSource              = &dnssecStatusDataSource{}
+	_ datasource.DataSourceWithConfigure = &dnssecStatusDataSource{}
+)
+
+func NewDnssecStatusDataSource() datasource.DataSource {
+	return &dnssecStatusDataSource{}
+}
+
+type dnssecStatusDataSource struct {
+	client *client.CCPClient
+}
+
+func (d *dnssecStatusDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dnssec_status"
+}
+
+func (d *dnssecStatusDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads whether DNSSEC is enabled for a zone, e.g. to assert it in `check` blocks or preconditions.",
+		Attributes: map[string]schema.Attribute{
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the zone.",
+			},
+			"enabled": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether DNSSEC is enabled for the zone.",
+			},
+		},
+	}
+}
+
+func (d *dnssecStatusDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*client.CCPClient)
+}
+
+func (d *dnssecStatusDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer warnNearRateLimit(d.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(d.client, "reading the zone", &resp.Diagnostics) {
+		return
+	}
+
+	var config DnssecStatus
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	zone, err := d.client.GetDnsZone(ctx, config.Domainname.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading zone",
+			"Could not read zone "+config.Domainname.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	config.Enabled = types.BoolValue(zone.DNSSecStatus)
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestDnssecStatusDataSource(t *testing.T) {
+	ctx := context.Background()
+	disabled := testDomain(t)
+	enabled := "signed-" + disabled
+
+	// DNSSEC enabled in the customer control panel
+	c := mockClient(t)
+	zone, err := c.GetDnsZone(ctx, enabled)
+	if err != nil {
+		t.Fatalf("GetDnsZone failed: %s", err)
+	}
+	zone.DNSSecStatus = true
+	if _, err := c.UpdateDnsZone(ctx, enabled, *zone); err != nil {
+		t.Fatalf("UpdateDnsZone failed: %s", err)
+	}
+
+	p := newTestProvider(t, nil)
+	for domain, want := range map[string]bool{disabled: false, enabled: true} {
+		state, diags := p.readDataSource("netcupdns_dnssec_status", attrs{"domainname": domain})
+		p.checkDiags("read of "+domain, diags)
+		var got bool
+		if err := attrValue(t, state, "enabled").As(&got); err != nil {
+			t.Fatalf("enabled is no bool: %s", err)
+		}
+		if got != want {
+			t.Errorf("enabled of %s = %t, want %t", domain, got, want)
+		}
+	}
+
+	_, diags := p.readDataSource("netcupdns_dnssec_status", attrs{"domainname": "missing.invalid"})
+	d := firstError(diags)
+	if d == nil || d.Summary != "Error reading zone" {
+		t.Fatalf("got error %v, want an error reading the zone", d)
+	}
+	if d.Attribute == nil || d.Attribute.String() != tftypes.NewAttributePath().WithAttributeName("domainname").String() {
+		t.Errorf("error is for attribute %v, want domainname", d.Attribute)
+	}
+}
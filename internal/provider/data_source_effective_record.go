@@ -0,0 +1,239 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ datasource.DataSource              = &effectiveRecordDataSource{}
+	_ datasource.DataSourceWithConfigure = &effectiveRecordDataSource{}
+)
+
+// Maximum number of CNAMEs followed, resolvers give up after a similar number
+const maxCnameDepth = 16
+
+// Record types an effective record can be looked up for
+var effectiveRecordTypes = map[string]bool{"A": true, "AAAA": true, "TXT": true}
+
+func NewEffectiveRecordDataSource() datasource.DataSource {
+	return &effectiveRecordDataSource{}
+}
+
+type effectiveRecordDataSource struct {
+	client *client.CCPClient
+}
+
+func (d *effectiveRecordDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_effective_record"
+}
+
+func (d *effectiveRecordDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Follows CNAME records within the zone to the records a name ultimately points to, e.g. to see where `www` ends up. " +
+			"Only the records of the zone are read, a chain leaving the zone ends at its first external target. " +
+			"Fails on CNAME loops and chains longer than 16 CNAMEs.",
+		Attributes: map[string]schema.Attribute{
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the records.",
+			},
+			"hostname": schema.StringAttribute{
+				Required:    true,
+				Description: "Name to start at. Use '@' for root of domain. Matched case-insensitively.",
+			},
+			"type": schema.StringAttribute{
+				Required:    true,
+				Description: "Type of the final records: A, AAAA or TXT.",
+			},
+			"chain": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Hostnames visited in order, starting with hostname. Each further hostname is the target of a CNAME of the one before.",
+			},
+			"final_hostname": schema.StringAttribute{
+				Computed:    true,
+				Description: "Last hostname of the chain in the zone.",
+			},
+			"external": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether the chain leaves the zone. The records are empty then.",
+			},
+			"external_target": schema.StringAttribute{
+				Computed:    true,
+				Description: "Target outside the zone of the last CNAME if external is true, otherwise null.",
+			},
+			"records": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Records of type at final_hostname. Empty if there are none or the chain leaves the zone.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:    true,
+							Description: "Unique ID of the record. Provided from Netcup-API",
+						},
+						"priority": schema.StringAttribute{
+							Computed:    true,
+							Description: "Priority of the record.",
+						},
+						"destination": schema.StringAttribute{
+							Computed:    true,
+							Description: "Target of the record.",
+						},
+					},
+				},
+			},
+			"destinations": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Destinations of the records, in the order of records.",
+			},
+		},
+	}
+}
+
+func (d *effectiveRecordDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*client.CCPClient)
+}
+
+func (d *effectiveRecordDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer warnNearRateLimit(d.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(d.client, "reading the records", &resp.Diagnostics) {
+		return
+	}
+
+	var config EffectiveRecordData
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	recordType := strings.ToUpper(config.Type.ValueString())
+	if !effectiveRecordTypes[recordType] {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("type"),
+			"Unsupported record type",
+			"Effective records can be looked up for type A, AAAA or TXT, got "+config.Type.ValueString()+".",
+		)
+		return
+	}
+
+	domainname := config.Domainname.ValueString()
+	records, err := d.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{})
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	chain, err := followCnames(records, domainname, config.Hostname.ValueString(), recordType)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("hostname"),
+			"Error following CNAME records",
+			err.Error(),
+		)
+		return
+	}
+
+	config.Chain = make([]types.String, 0, len(chain.Hostnames))
+	for _, hostname := range chain.Hostnames {
+		config.Chain = append(config.Chain, types.StringValue(hostname))
+	}
+	config.FinalHostname = types.StringValue(chain.Hostnames[len(chain.Hostnames)-1])
+	config.External = types.BoolValue(chain.ExternalTarget != "")
+	config.ExternalTarget = types.StringNull()
+	if chain.ExternalTarget != "" {
+		config.ExternalTarget = types.StringValue(chain.ExternalTarget)
+	}
+	config.Records = make([]RecordSetMember, 0, len(chain.Records))
+	config.Destinations = make([]types.String, 0, len(chain.Records))
+	for _, record := range chain.Records {
+		config.Records = append(config.Records, RecordSetMember{
+			ID:          types.StringValue(record.Id),
+			Priority:    types.StringValue(record.Priority),
+			Destination: types.StringValue(record.Destination),
+		})
+		config.Destinations = append(config.Destinations, types.StringValue(record.Destination))
+	}
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
+
+// CNAMEs followed from a hostname and the records found at the end
+type cnameChain struct {
+	Hostnames []string
+	// Target outside the zone, empty if the chain stays inside
+	ExternalTarget string
+	Records        []client.DnsRecord
+}
+
+// Follow the CNAME records of a zone from hostname until records of
+// recordType, a name without CNAME or a target outside the zone is reached.
+// Records of recordType at a name take precedence over a CNAME of it.
+func followCnames(records []client.DnsRecord, domainname string, hostname string, recordType string) (cnameChain, error) {
+	name := normalizeHostname(hostname, domainname)
+	chain := cnameChain{Hostnames: []string{name}}
+	visited := map[string]bool{name: true}
+
+	for {
+		var matches, cnames []client.DnsRecord
+		for _, record := range records {
+			if normalizeHostname(record.Hostname, domainname) != name {
+				continue
+			}
+			switch {
+			case strings.EqualFold(record.Type, recordType):
+				matches = append(matches, record)
+			case strings.EqualFold(record.Type, "CNAME"):
+				cnames = append(cnames, record)
+			}
+		}
+
+		switch {
+		case len(matches) > 0 || len(cnames) == 0:
+			sortDnsRecords(matches)
+			chain.Records = matches
+			return chain, nil
+		case len(cnames) > 1:
+			return chain, fmt.Errorf("%s has %d CNAME records in domain %s, it must have exactly one to be followed", name, len(cnames), domainname)
+		}
+
+		target := cnames[0].Destination
+		next, inZone := "@", target == "@"
+		if !inZone {
+			next, inZone = relativeHostname(target, domainname)
+		}
+		if !inZone {
+			chain.ExternalTarget = strings.ToLower(strings.TrimSuffix(target, "."))
+			return chain, nil
+		}
+
+		chain.Hostnames = append(chain.Hostnames, next)
+		if visited[next] {
+			return chain, fmt.Errorf("CNAME loop in domain %s: %s", domainname, strings.Join(chain.Hostnames, " -> "))
+		}
+		if len(chain.Hostnames) > maxCnameDepth+1 {
+			return chain, fmt.Errorf("more than %d CNAMEs followed from %s in domain %s: %s", maxCnameDepth, chain.Hostnames[0], domainname, strings.Join(chain.Hostnames, " -> "))
+		}
+		visited[next] = true
+		name = next
+	}
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+func TestEffectiveRecordDataSource(t *testing.T) {
+	domain := testDomain(t)
+	seedZone(t, domain,
+		attrs{"hostname": "@", "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": "@", "type": "A", "destination": "192.0.2.2"},
+		attrs{"hostname": "@", "type": "TXT", "destination": "v=spf1 -all"},
+		attrs{"hostname": "www", "type": "CNAME", "destination": "web." + domain + "."},
+		attrs{"hostname": "web", "type": "CNAME", "destination": "lb." + domain + "."},
+		attrs{"hostname": "lb", "type": "AAAA", "destination": "2001:db8::1"},
+		attrs{"hostname": "shop", "type": "CNAME", "destination": domain + "."},
+		attrs{"hostname": "cdn", "type": "CNAME", "destination": "static." + domain + "."},
+		attrs{"hostname": "static", "type": "CNAME", "destination": "Edge.Example.NET."},
+		// a record of the type takes precedence over the CNAME of the same name
+		attrs{"hostname": "mixed", "type": "A", "destination": "192.0.2.3"},
+		attrs{"hostname": "mixed", "type": "CNAME", "destination": "lb." + domain + "."},
+		attrs{"hostname": "a", "type": "CNAME", "destination": "b." + domain + "."},
+		attrs{"hostname": "b", "type": "CNAME", "destination": "c." + domain + "."},
+		attrs{"hostname": "c", "type": "CNAME", "destination": "a." + domain + "."},
+	)
+
+	tests := []struct {
+		name         string
+		hostname     string
+		recordType   string
+		chain        []string
+		destinations []string
+		external     string
+	}{
+		{name: "direct", hostname: "@", recordType: "A", chain: []string{"@"}, destinations: []string{"192.0.2.1", "192.0.2.2"}},
+		{name: "direct TXT", hostname: domain, recordType: "txt", chain: []string{"@"}, destinations: []string{"v=spf1 -all"}},
+		{name: "multi-hop", hostname: "WWW", recordType: "AAAA", chain: []string{"www", "web", "lb"}, destinations: []string{"2001:db8::1"}},
+		{name: "to the root", hostname: "shop", recordType: "A", chain: []string{"shop", "@"}, destinations: []string{"192.0.2.1", "192.0.2.2"}},
+		{name: "no records of the type", hostname: "www", recordType: "A", chain: []string{"www", "web", "lb"}, destinations: []string{}},
+		{name: "external exit", hostname: "cdn", recordType: "A", chain: []string{"cdn", "static"}, destinations: []string{}, external: "edge.example.net"},
+		{name: "record before CNAME", hostname: "mixed", recordType: "A", chain: []string{"mixed"}, destinations: []string{"192.0.2.3"}},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		state, diags := p.readDataSource("netcupdns_effective_record", attrs{"domainname": domain, "hostname": tt.hostname, "type": tt.recordType})
+		p.checkDiags(tt.name, diags)
+		if got := attrStrings(t, state, "chain"); !reflect.DeepEqual(got, tt.chain) {
+			t.Errorf("%s: chain %q, want %q", tt.name, got, tt.chain)
+		}
+		if got, want := attrString(t, state, "final_hostname"), tt.chain[len(tt.chain)-1]; got != want {
+			t.Errorf("%s: final hostname %s, want %s", tt.name, got, want)
+		}
+		if got := attrStrings(t, state, "destinations"); !reflect.DeepEqual(got, tt.destinations) {
+			t.Errorf("%s: destinations %q, want %q", tt.name, got, tt.destinations)
+		}
+		if got := len(elementsOf(t, attrValue(t, state, "records"))); got != len(tt.destinations) {
+			t.Errorf("%s: %d records, want %d", tt.name, got, len(tt.destinations))
+		}
+		external, target := "false", "<null>"
+		if tt.external != "" {
+			external, target = "true", tt.external
+		}
+		if got := attrString(t, state, "external"); got != external {
+			t.Errorf("%s: external %s, want %s", tt.name, got, external)
+		}
+		if got := attrString(t, state, "external_target"); got != target {
+			t.Errorf("%s: external target %s, want %s", tt.name, got, target)
+		}
+	}
+
+	_, diags := p.readDataSource("netcupdns_effective_record", attrs{"domainname": domain, "hostname": "b", "type": "A"})
+	want := "CNAME loop in domain " + domain + ": b -> c -> a -> b"
+	if d := firstError(diags); d == nil || d.Summary != "Error following CNAME records" || d.Detail != want {
+		t.Errorf("got errors %v, want the cycle %q", summaries(diags, tfprotov6.DiagnosticSeverityError), want)
+	}
+
+	_, diags = p.readDataSource("netcupdns_effective_record", attrs{"domainname": domain, "hostname": "www", "type": "MX"})
+	if d := firstError(diags); d == nil || d.Summary != "Unsupported record type" {
+		t.Errorf("got errors %v, want Unsupported record type", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+}
+
+func TestFollowCnamesLimits(t *testing.T) {
+	const domain = "example.com"
+	var records []client.DnsRecord
+	for i := 0; i <= maxCnameDepth; i++ {
+		records = append(records, client.DnsRecord{Hostname: fmt.Sprintf("h%d", i), Type: "CNAME", Destination: fmt.Sprintf("h%d.%s.", i+1, domain)})
+	}
+	records = append(records, client.DnsRecord{Hostname: fmt.Sprintf("h%d", maxCnameDepth+1), Type: "A", Destination: "192.0.2.1"})
+
+	// the longest chain followed
+	chain, err := followCnames(records, domain, "h1", "A")
+	if err != nil || len(chain.Hostnames) != maxCnameDepth+1 || len(chain.Records) != 1 {
+		t.Errorf("chain of %d CNAMEs: got %+v, %v", maxCnameDepth, chain, err)
+	}
+
+	_, err = followCnames(records, domain, "h0", "A")
+	if err == nil || !strings.HasPrefix(err.Error(), fmt.Sprintf("more than %d CNAMEs followed from h0", maxCnameDepth)) {
+		t.Errorf("chain of %d CNAMEs: got %v, want an error", maxCnameDepth+1, err)
+	}
+
+	records = append(records, client.DnsRecord{Hostname: "h5", Type: "CNAME", Destination: "other.example.net."})
+	_, err = followCnames(records, domain, "h3", "A")
+	if err == nil || !strings.Contains(err.Error(), "h5 has 2 CNAME records") {
+		t.Errorf("name with two CNAMEs: got %v, want an error", err)
+	}
+}
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ datasource.DataSource              = &mxRecordsDataSource{}
+	_ datasource.DataSourceWithConfigure = &mxRecordsDataSource{}
+)
+
+func NewMxRecordsDataSource() datasource.DataSource {
+	return &mxRecordsDataSource{}
+}
+
+type mxRecordsDataSource struct {
+	client *client.CCPClient
+}
+
+func (d *mxRecordsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_mx_records"
+}
+
+func (d *mxRecordsDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads the MX records of a name, sorted by priority.",
+		Attributes: map[string]schema.Attribute{
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the records.",
+			},
+			"hostname": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name of the records. Defaults to '@', the root of the domain.",
+			},
+			"records": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "MX records sorted by priority, then destination.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"priority": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Priority of the record.",
+						},
+						"destination": schema.StringAttribute{
+							Computed:    true,
+							Description: "Mail server of the record.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *mxRecordsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*client.CCPClient)
+}
+
+func (d *mxRecordsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer warnNearRateLimit(d.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(d.client, "reading the records", &resp.Diagnostics) {
+		return
+	}
+
+	var config MxRecords
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := config.Domainname.ValueString()
+	hostname := "@"
+	if !config.Hostname.IsNull() {
+		hostname = config.Hostname.ValueString()
+	}
+
+	matches, err := lookupDnsRecords(ctx, d.client, domainname, client.RecordFilter{Hostname: hostname, Type: "MX"})
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	config.Records = make([]MxRecord, 0, len(matches))
+	for _, record := range matches {
+		priority, err := strconv.ParseInt(priorityOrZero(record.Priority), 10, 64)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unexpected record value",
+				"Could not parse priority "+strconv.Quote(record.Priority)+" of MX record "+record.Id+": "+err.Error(),
+			)
+			return
+		}
+		config.Records = append(config.Records, MxRecord{
+			Priority:    types.Int64Value(priority),
+			Destination: types.StringValue(record.Destination),
+		})
+	}
+	sort.SliceStable(config.Records, func(i, j int) bool {
+		a, b := config.Records[i], config.Records[j]
+		if a.Priority.ValueInt64() != b.Priority.ValueInt64() {
+			return a.Priority.ValueInt64() < b.Priority.ValueInt64()
+		}
+		return a.Destination.ValueString() < b.Destination.ValueString()
+	})
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
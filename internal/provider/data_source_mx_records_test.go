@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+// Records of a MX records data source as "priority destination" in their order
+func mxRecords(t *testing.T, p *testProvider, config attrs) []string {
+	t.Helper()
+	state, diags := p.readDataSource("netcupdns_mx_records", config)
+	p.checkDiags("read", diags)
+	records := []string{}
+	for _, record := range elementsOf(t, attrValue(t, state, "records")) {
+		records = append(records, attrString(t, record, "priority")+" "+attrString(t, record, "destination"))
+	}
+	return records
+}
+
+func TestMxRecordsDataSource(t *testing.T) {
+	domain := testDomain(t)
+	withoutMx := "nomx-" + domain
+	seedZone(t, domain,
+		attrs{"hostname": "@", "type": "MX", "priority": "100", "destination": "last.example.com"},
+		attrs{"hostname": "@", "type": "MX", "priority": "5", "destination": "first.example.com"},
+		attrs{"hostname": "@", "type": "MX", "priority": "20", "destination": "b.example.com"},
+		attrs{"hostname": "@", "type": "MX", "priority": "20", "destination": "a.example.com"},
+		attrs{"hostname": "@", "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": "lists", "type": "MX", "priority": "10", "destination": "lists.example.com"},
+	)
+	seedZone(t, withoutMx, attrs{"hostname": "@", "type": "A", "destination": "192.0.2.1"})
+
+	tests := []struct {
+		name   string
+		config attrs
+		want   []string
+	}{
+		{
+			name:   "sorted by numeric priority, then destination",
+			config: attrs{"domainname": domain},
+			want:   []string{"5 first.example.com", "20 a.example.com", "20 b.example.com", "100 last.example.com"},
+		},
+		{
+			name:   "explicit root",
+			config: attrs{"domainname": domain, "hostname": "@"},
+			want:   []string{"5 first.example.com", "20 a.example.com", "20 b.example.com", "100 last.example.com"},
+		},
+		{
+			name:   "hostname",
+			config: attrs{"domainname": domain, "hostname": "Lists"},
+			want:   []string{"10 lists.example.com"},
+		},
+		{
+			name:   "domain without MX",
+			config: attrs{"domainname": withoutMx},
+			want:   []string{},
+		},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		if got := mxRecords(t, p, tt.config); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// The records are read from the zone already fetched by other data sources
+func TestMxRecordsDataSourceSharesZoneFetch(t *testing.T) {
+	domain := testDomain(t)
+	seedZone(t, domain, attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"})
+
+	p := newTestProvider(t, nil)
+	_, diags := p.readDataSource("netcupdns_records", attrs{"domainname": domain})
+	p.checkDiags("read of records", diags)
+
+	_, err := mockClient(t).CreateDnsRecord(context.Background(), domain, client.NewDnsRecord{
+		Hostname: "@", Type: "MX", Priority: "20", Destination: "backup.example.com",
+	})
+	if err != nil {
+		t.Fatalf("CreateDnsRecord failed: %s", err)
+	}
+
+	if got := mxRecords(t, p, attrs{"domainname": domain}); strings.Join(got, ",") != "10 mail.example.com" {
+		t.Errorf("got %q, want the records of the cached zone", got)
+	}
+	records, err := mockClient(t).GetDnsRecords(context.Background(), domain)
+	if err != nil {
+		t.Fatalf("GetDnsRecords failed: %s", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("zone has %d records, want 2", len(records))
+	}
+}
@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/svetob/terraform-provider-netcupdns/internal/resolver"
+)
+
+var (
+	_ datasource.DataSource                   = &propagationStatusDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &propagationStatusDataSource{}
+)
+
+func NewPropagationStatusDataSource() datasource.DataSource {
+	return &propagationStatusDataSource{}
+}
+
+type propagationStatusDataSource struct{}
+
+func (d *propagationStatusDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_propagation_status"
+}
+
+func (d *propagationStatusDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Checks whether several resolvers serve the expected answers for a name, e.g. in `check` blocks after apply. " +
+			"Resolvers failing to answer or answering NXDOMAIN are reported in `results` and don't fail the read.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Fully qualified name to query, e.g. www.example.com.",
+			},
+			"type": schema.StringAttribute{
+				Required:    true,
+				Description: "Record type to query: A, AAAA, CNAME, MX, NS, SRV or TXT.",
+			},
+			"expected": schema.ListAttribute{
+				Required:    true,
+				ElementType: types.StringType,
+				MarkdownDescription: "Expected answers in the format of `netcupdns_resolve`, e.g. `10 mail.example.com.` for MX. " +
+					"The order doesn't matter. An empty list expects the name to have no records of the type.",
+			},
+			"resolvers": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Resolvers (host or host:port) to query, e.g. 1.1.1.1 and 8.8.8.8. Defaults to the authoritative nameservers of the name.",
+			},
+			"consistency_threshold": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Number of resolvers that must serve the expected answers for consistent to be true. Defaults to all resolvers.",
+			},
+			"timeout": schema.StringAttribute{
+				Optional:    true,
+				Description: "Timeout of the lookups as duration, e.g. \"10s\". Defaults to 5s.",
+			},
+			"results": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Answer of each resolver, in the order of resolvers.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"nameserver": schema.StringAttribute{
+							Computed:    true,
+							Description: "Resolver that was queried.",
+						},
+						"answers": schema.ListAttribute{
+							Computed:    true,
+							ElementType: types.StringType,
+							Description: "Sorted answers of the resolver.",
+						},
+						"nxdomain": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the resolver reported the name as non-existing.",
+						},
+						"error": schema.StringAttribute{
+							Computed:    true,
+							Description: "Error of the lookup, e.g. SERVFAIL or a timeout. Null if the resolver answered.",
+						},
+						"matches": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the answers equal the expected answers.",
+						},
+					},
+				},
+			},
+			"consistent": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether at least consistency_threshold resolvers serve the expected answers.",
+			},
+			"percentage": schema.Float64Attribute{
+				Computed:    true,
+				Description: "Share of resolvers serving the expected answers, from 0 to 100.",
+			},
+		},
+	}
+}
+
+func (d *propagationStatusDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config DnsPropagationStatus
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.Timeout.IsNull() && !config.Timeout.IsUnknown() {
+		if _, err := time.ParseDuration(config.Timeout.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("timeout"),
+				"Invalid timeout",
+				"The timeout must be a duration like \"10s\": "+err.Error(),
+			)
+		}
+	}
+
+	if !config.ConsistencyThreshold.IsNull() && !config.ConsistencyThreshold.IsUnknown() && config.ConsistencyThreshold.ValueInt64() < 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("consistency_threshold"),
+			"Invalid consistency threshold",
+			"The consistency threshold must be at least 1",
+		)
+	}
+}
+
+func (d *propagationStatusDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config DnsPropagationStatus
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := defaultResolveTimeout
+	if !config.Timeout.IsNull() {
+		timeout, _ = time.ParseDuration(config.Timeout.ValueString())
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	checker := resolver.Checker{Threshold: int(config.ConsistencyThreshold.ValueInt64())}
+	for _, r := range config.Resolvers {
+		checker.Resolvers = append(checker.Resolvers, r.ValueString())
+	}
+
+	name := config.Name.ValueString()
+	recordType := config.Type.ValueString()
+	expected := make([]string, 0, len(config.Expected))
+	for _, value := range config.Expected {
+		expected = append(expected, expectedAnswer(recordType, value.ValueString()))
+	}
+
+	status, err := checker.Check(lookupCtx, name, recordType, expected)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("resolvers"),
+			"Error discovering nameservers",
+			"No resolvers were given and the authoritative nameservers could not be discovered: "+err.Error(),
+		)
+		return
+	}
+
+	config.Results = make([]PropagationResult, 0, len(status.Resolvers))
+	for _, r := range status.Resolvers {
+		result := PropagationResult{
+			Nameserver: types.StringValue(r.Nameserver),
+			Answers:    []types.String{},
+			NXDomain:   types.BoolValue(false),
+			Error:      types.StringNull(),
+			Matches:    types.BoolValue(r.Matches),
+		}
+		if r.Err != nil {
+			tflog.Debug(ctx, "DNS lookup failed", map[string]interface{}{"name": name, "nameserver": r.Nameserver, "error": r.Err.Error()})
+			result.Error = types.StringValue(r.Err.Error())
+		} else {
+			for _, answer := range r.Result.Answers {
+				result.Answers = append(result.Answers, types.StringValue(answer))
+			}
+			result.NXDomain = types.BoolValue(r.Result.NXDomain)
+		}
+		config.Results = append(config.Results, result)
+	}
+	config.Consistent = types.BoolValue(status.Consistent())
+	config.Percentage = types.Float64Value(status.Percentage())
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Render an expected value the way resolver.Lookup renders answers: names
+// lowercase and absolute, addresses in their canonical form
+func expectedAnswer(recordType, value string) string {
+	switch strings.ToUpper(recordType) {
+	case "A", "AAAA":
+		if ip := net.ParseIP(value); ip != nil {
+			return ip.String()
+		}
+		return value
+	case "CNAME", "NS", "MX", "SRV":
+		fields := strings.Fields(strings.ToLower(value))
+		if len(fields) == 0 {
+			return value
+		}
+		fields[len(fields)-1] = resolver.Fqdn(fields[len(fields)-1])
+		return strings.Join(fields, " ")
+	default:
+		return value
+	}
+}
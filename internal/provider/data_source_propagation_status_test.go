@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/svetob/terraform-provider-netcupdns/internal/resolver/resolvertest"
+)
+
+// A resolver still serving the old value makes the record inconsistent
+func TestPropagationStatusDivergentResolvers(t *testing.T) {
+	updated := newDnsServer(t, resolvertest.Record{Name: "www.example.test", Type: "A", Data: "192.0.2.2"})
+	stale := newDnsServer(t, resolvertest.Record{Name: "www.example.test", Type: "A", Data: "192.0.2.1"})
+
+	tests := []struct {
+		name       string
+		threshold  interface{}
+		consistent string
+	}{
+		{"all resolvers", nil, "false"},
+		{"threshold reached", 1, "true"},
+		{"threshold missed", 2, "false"},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		state, diags := p.readDataSource("netcupdns_propagation_status", attrs{
+			"name":                  "www.example.test",
+			"type":                  "A",
+			"expected":              []interface{}{"192.0.2.2"},
+			"resolvers":             []interface{}{updated.Addr, stale.Addr},
+			"consistency_threshold": tt.threshold,
+		})
+		p.checkDiags(tt.name, diags)
+
+		results := elementsOf(t, attrValue(t, state, "results"))
+		if len(results) != 2 {
+			t.Fatalf("%s: got %d results, want 2", tt.name, len(results))
+		}
+		for i, want := range []struct{ nameserver, answers, matches string }{
+			{updated.Addr, "192.0.2.2", "true"},
+			{stale.Addr, "192.0.2.1", "false"},
+		} {
+			result := results[i]
+			if got := attrString(t, result, "nameserver"); got != want.nameserver {
+				t.Errorf("%s: result %d is of %s, want %s", tt.name, i, got, want.nameserver)
+			}
+			if got := strings.Join(attrStrings(t, result, "answers"), ","); got != want.answers {
+				t.Errorf("%s: %s answered %q, want %q", tt.name, want.nameserver, got, want.answers)
+			}
+			if got := attrString(t, result, "matches"); got != want.matches {
+				t.Errorf("%s: %s matches %s, want %s", tt.name, want.nameserver, got, want.matches)
+			}
+			if got := attrString(t, result, "error"); got != "<null>" {
+				t.Errorf("%s: %s failed with %s", tt.name, want.nameserver, got)
+			}
+		}
+		if got := attrString(t, state, "consistent"); got != tt.consistent {
+			t.Errorf("%s: consistent %s, want %s", tt.name, got, tt.consistent)
+		}
+		if got := attrString(t, state, "percentage"); got != "50" {
+			t.Errorf("%s: percentage %s, want 50", tt.name, got)
+		}
+	}
+}
+
+// NXDOMAIN, SERVFAIL and timeouts are reported per resolver without failing the read
+func TestPropagationStatusFailingResolvers(t *testing.T) {
+	served := newDnsServer(t, resolvertest.Record{Name: "mail.example.test", Type: "MX", Data: "10 mx.example.test"})
+	nxdomain := newDnsServer(t)
+	servfail := newDnsServer(t)
+	servfail.SetServerFailure(true)
+	silent := newDnsServer(t)
+	silent.SetSilent(true)
+
+	p := newTestProvider(t, nil)
+	state, diags := p.readDataSource("netcupdns_propagation_status", attrs{
+		"name":      "mail.example.test",
+		"type":      "MX",
+		"expected":  []interface{}{"10 MX.example.test"},
+		"resolvers": []interface{}{served.Addr, nxdomain.Addr, servfail.Addr, silent.Addr},
+		"timeout":   "500ms",
+	})
+	p.checkDiags("read", diags)
+
+	results := elementsOf(t, attrValue(t, state, "results"))
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+	for i, want := range []struct {
+		name, nxdomain, matches string
+		failed                  bool
+	}{
+		{"served", "false", "true", false},
+		{"nxdomain", "true", "false", false},
+		{"servfail", "false", "false", true},
+		{"silent", "false", "false", true},
+	} {
+		result := results[i]
+		if got := attrString(t, result, "nxdomain"); got != want.nxdomain {
+			t.Errorf("%s: nxdomain %s, want %s", want.name, got, want.nxdomain)
+		}
+		if got := attrString(t, result, "matches"); got != want.matches {
+			t.Errorf("%s: matches %s, want %s", want.name, got, want.matches)
+		}
+		if failed := attrString(t, result, "error") != "<null>"; failed != want.failed {
+			t.Errorf("%s: error %s, want an error: %t", want.name, attrString(t, result, "error"), want.failed)
+		}
+	}
+	if got := attrString(t, state, "consistent"); got != "false" {
+		t.Errorf("consistent %s, want false", got)
+	}
+	if got := attrString(t, state, "percentage"); got != "25" {
+		t.Errorf("percentage %s, want 25", got)
+	}
+}
+
+// An empty expected list checks that the name is gone, which NXDOMAIN confirms
+func TestPropagationStatusExpectedAbsent(t *testing.T) {
+	server := newDnsServer(t)
+
+	p := newTestProvider(t, nil)
+	state, diags := p.readDataSource("netcupdns_propagation_status", attrs{
+		"name":      "deleted.example.test",
+		"type":      "A",
+		"expected":  []interface{}{},
+		"resolvers": []interface{}{server.Addr},
+	})
+	p.checkDiags("read", diags)
+	if got := attrString(t, state, "consistent"); got != "true" {
+		t.Errorf("consistent %s, want true", got)
+	}
+}
+
+func TestPropagationStatusInvalidConfig(t *testing.T) {
+	p := newTestProvider(t, nil)
+	for _, tt := range []struct {
+		config  attrs
+		summary string
+	}{
+		{attrs{"timeout": "soon"}, "Invalid timeout"},
+		{attrs{"consistency_threshold": 0}, "Invalid consistency threshold"},
+	} {
+		tt.config["name"], tt.config["type"], tt.config["expected"] = "www.example.test", "A", []interface{}{"192.0.2.1"}
+		_, diags := p.readDataSource("netcupdns_propagation_status", tt.config)
+		if d := firstError(diags); d == nil || d.Summary != tt.summary {
+			t.Errorf("got errors %v, want %s", summaries(diags, tfprotov6.DiagnosticSeverityError), tt.summary)
+		}
+	}
+}
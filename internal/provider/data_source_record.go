@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ datasource.DataSource              = &recordDataSource{}
+	_ datasource.DataSourceWithConfigure = &recordDataSource{}
+)
+
+func NewRecordDataSource() datasource.DataSource {
+	return &recordDataSource{}
+}
+
+type recordDataSource struct {
+	client *client.CCPClient
+}
+
+func (d *recordDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_record"
+}
+
+func (d *recordDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Looks up a single existing DNS-Record, e.g. one managed outside of Terraform.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Unique ID of the record. Provided from Netcup-API",
+			},
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the record.",
+			},
+			"hostname": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the record. Use '@' for root of domain. Matched case-insensitively.",
+			},
+			"type": schema.StringAttribute{
+				Required:    true,
+				Description: "Type of Record like A or MX.",
+			},
+			"priority": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Only match records with this priority.",
+			},
+			"destination": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Only match records with this destination.",
+			},
+			"state": schema.StringAttribute{
+				Computed:    true,
+				Description: "State of the record as reported by the API, e.g. `yes` once it is live.",
+			},
+			"first_match": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Return the first record (in hostname, type, priority, destination order) instead of failing when several records match.",
+			},
+		},
+	}
+}
+
+func (d *recordDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*client.CCPClient)
+}
+
+func (d *recordDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer warnNearRateLimit(d.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(d.client, "reading the record", &resp.Diagnostics) {
+		return
+	}
+
+	var config DnsRecordLookup
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := config.Domainname.ValueString()
+	matches, err := lookupDnsRecords(ctx, d.client, domainname, client.RecordFilter{
+		Hostname:    config.Hostname.ValueString(),
+		Type:        config.Type.ValueString(),
+		Destination: config.Destination.ValueString(),
+		Priority:    config.Priority.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	criteria := fmt.Sprintf("domainname=%s hostname=%s type=%s", domainname, config.Hostname.ValueString(), config.Type.ValueString())
+	if !config.Destination.IsNull() {
+		criteria += " destination=" + config.Destination.ValueString()
+	}
+	if !config.Priority.IsNull() {
+		criteria += " priority=" + config.Priority.ValueString()
+	}
+
+	if len(matches) == 0 {
+		resp.Diagnostics.AddError(
+			"No matching record found",
+			"No record matches "+criteria+".",
+		)
+		return
+	}
+
+	if len(matches) > 1 && !config.FirstMatch.ValueBool() {
+		resp.Diagnostics.AddError(
+			"Multiple matching records found",
+			fmt.Sprintf("%d records match %s. Add destination or priority to narrow the search, or set first_match = true:\n%s",
+				len(matches), criteria, formatRecordCandidates(matches)),
+		)
+		return
+	}
+
+	record := matches[0]
+	tflog.Trace(ctx, "Got DNS Record", map[string]interface{}{"id": record.Id, "hostname": record.Hostname, "type": record.Type})
+
+	config.ID = types.StringValue(record.Id)
+	config.Priority = types.StringValue(record.Priority)
+	config.Destination = types.StringValue(record.Destination)
+	config.State = types.StringValue(record.State)
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
+
+// One line per record, for diagnostics listing ambiguous matches
+func formatRecordCandidates(records []client.DnsRecord) string {
+	lines := make([]string, 0, len(records))
+	for _, record := range records {
+		line := fmt.Sprintf("  - id=%s hostname=%s type=%s destination=%s", record.Id, record.Hostname, record.Type, record.Destination)
+		if record.Priority != "" {
+			line += " priority=" + record.Priority
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
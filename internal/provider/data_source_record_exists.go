@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ datasource.DataSource              = &recordExistsDataSource{}
+	_ datasource.DataSourceWithConfigure = &recordExistsDataSource{}
+)
+
+func NewRecordExistsDataSource() datasource.DataSource {
+	return &recordExistsDataSource{}
+}
+
+type recordExistsDataSource struct {
+	client *client.CCPClient
+}
+
+func (d *recordExistsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_record_exists"
+}
+
+func (d *recordExistsDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Checks whether records exist at a name without failing when none do, e.g. for `precondition` blocks.",
+		Attributes: map[string]schema.Attribute{
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the records.",
+			},
+			"hostname": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the records. Use '@' for root of domain. Matched case-insensitively.",
+			},
+			"type": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only count records of this type.",
+			},
+			"destination": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only count records with this destination.",
+			},
+			"exists": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether at least one record matches.",
+			},
+			"matches": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Number of matching records.",
+			},
+		},
+	}
+}
+
+func (d *recordExistsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*client.CCPClient)
+}
+
+func (d *recordExistsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer warnNearRateLimit(d.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(d.client, "reading the records", &resp.Diagnostics) {
+		return
+	}
+
+	var config DnsRecordExists
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := config.Domainname.ValueString()
+	matches, err := lookupDnsRecords(ctx, d.client, domainname, client.RecordFilter{
+		Hostname:    config.Hostname.ValueString(),
+		Type:        config.Type.ValueString(),
+		Destination: config.Destination.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	config.Exists = types.BoolValue(len(matches) > 0)
+	config.Matches = types.Int64Value(int64(len(matches)))
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
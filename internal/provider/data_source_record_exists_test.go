@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestRecordExists(t *testing.T) {
+	domain := testDomain(t)
+	p := newTestProvider(t, nil)
+	for _, record := range []attrs{
+		{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+		{"hostname": "www", "type": "AAAA", "destination": "2001:db8::1"},
+		{"hostname": "www", "type": "TXT", "destination": "one"},
+		{"hostname": "www", "type": "TXT", "destination": "two"},
+	} {
+		record["domainname"] = domain
+		p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, record)
+	}
+
+	tests := []struct {
+		name    string
+		config  attrs
+		matches int64
+	}{
+		{"zero", attrs{"hostname": "api"}, 0},
+		{"zero of type", attrs{"hostname": "www", "type": "MX"}, 0},
+		{"one", attrs{"hostname": "www", "type": "A"}, 1},
+		{"one by destination", attrs{"hostname": "WWW", "type": "TXT", "destination": `"two"`}, 1},
+		{"many", attrs{"hostname": "www"}, 4},
+		{"many of type", attrs{"hostname": "www", "type": "txt"}, 2},
+	}
+	// the provider fails the test it was created for, so cases don't run as subtests
+	for _, tt := range tests {
+		tt.config["domainname"] = domain
+		state, diags := p.readDataSource("netcupdns_record_exists", tt.config)
+		p.checkDiags(tt.name, diags)
+
+		var exists bool
+		if err := attrValue(t, state, "exists").As(&exists); err != nil {
+			t.Fatal(err)
+		}
+		var matches big.Float
+		if err := attrValue(t, state, "matches").As(&matches); err != nil {
+			t.Fatal(err)
+		}
+		if n, _ := matches.Int64(); n != tt.matches {
+			t.Errorf("%s: matches = %d, want %d", tt.name, n, tt.matches)
+		}
+		if exists != (tt.matches > 0) {
+			t.Errorf("%s: exists = %v with %d matches", tt.name, exists, tt.matches)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ datasource.DataSource              = &recordIdDataSource{}
+	_ datasource.DataSourceWithConfigure = &recordIdDataSource{}
+)
+
+func NewRecordIdDataSource() datasource.DataSource {
+	return &recordIdDataSource{}
+}
+
+type recordIdDataSource struct {
+	client *client.CCPClient
+}
+
+func (d *recordIdDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_record_id"
+}
+
+func (d *recordIdDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Resolves the Netcup id of an existing DNS-Record together with the identifiers accepted by `import` blocks of `netcupdns_record`.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Unique ID of the record. Provided from Netcup-API",
+			},
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the record.",
+			},
+			"hostname": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the record. Use '@' for root of domain. Matched case-insensitively.",
+			},
+			"type": schema.StringAttribute{
+				Required:    true,
+				Description: "Type of Record like A or MX.",
+			},
+			"destination": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Only match records with this destination.",
+			},
+			"import_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Import identifier in the `<domainname>/<id>` format.",
+			},
+			"import_id_by_fields": schema.StringAttribute{
+				Computed:    true,
+				Description: "Import identifier in the `<domainname>/<hostname>/<type>/<destination>` format.",
+			},
+		},
+	}
+}
+
+func (d *recordIdDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*client.CCPClient)
+}
+
+func (d *recordIdDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer warnNearRateLimit(d.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(d.client, "reading the record", &resp.Diagnostics) {
+		return
+	}
+
+	var config DnsRecordId
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := config.Domainname.ValueString()
+	matches, err := lookupDnsRecords(ctx, d.client, domainname, client.RecordFilter{
+		Hostname:    config.Hostname.ValueString(),
+		Type:        config.Type.ValueString(),
+		Destination: config.Destination.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	if len(matches) == 0 {
+		resp.Diagnostics.AddError(
+			"No matching record found",
+			fmt.Sprintf("No record matches hostname=%s type=%s in domain %s.", config.Hostname.ValueString(), config.Type.ValueString(), domainname),
+		)
+		return
+	}
+
+	if len(matches) > 1 {
+		resp.Diagnostics.AddError(
+			"Multiple matching records found",
+			fmt.Sprintf("%d records match hostname=%s type=%s in domain %s. Set destination to pick one:\n%s",
+				len(matches), config.Hostname.ValueString(), config.Type.ValueString(), domainname, formatRecordCandidates(matches)),
+		)
+		return
+	}
+
+	record := matches[0]
+	config.ID = types.StringValue(record.Id)
+	config.Destination = types.StringValue(record.Destination)
+	config.ImportId = types.StringValue(domainname + "/" + record.Id)
+	config.ImportIdByFields = types.StringValue(domainname + "/" + record.Hostname + "/" + record.Type + "/" + record.Destination)
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// The import ids of the data source import the record like an import block would
+func TestRecordIdDataSourceImport(t *testing.T) {
+	domain := testDomain(t)
+	config := attrs{"domainname": domain, "hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"}
+	seedZone(t, domain,
+		attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+		attrs{"hostname": "@", "type": "MX", "priority": "20", "destination": "backup.example.com"},
+	)
+
+	p := newTestProvider(t, nil)
+	lookup, diags := p.readDataSource("netcupdns_record_id", attrs{"domainname": domain, "hostname": domain + ".", "type": "mx", "destination": "mail.example.com"})
+	p.checkDiags("read", diags)
+	id := attrString(t, lookup, "id")
+
+	for _, attribute := range []string{"import_id", "import_id_by_fields"} {
+		importId := attrString(t, lookup, attribute)
+		p = newTestProvider(t, nil)
+		imported, diags := p.importState("netcupdns_record", importId)
+		p.checkDiags("import of "+importId, diags)
+		if got := attrString(t, imported, "id"); got != id {
+			t.Errorf("%s %s imported record %s, want %s", attribute, importId, got, id)
+		}
+		if planned := p.plan("netcupdns_record", imported, nil, config); !planned.Equal(imported) {
+			t.Errorf("plan after import of %s isn't empty:\nplanned %s\nstate   %s", importId, planned, imported)
+		}
+	}
+}
+
+func TestRecordIdDataSourceMatches(t *testing.T) {
+	domain := testDomain(t)
+	seedZone(t, domain,
+		attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": "www", "type": "A", "destination": "192.0.2.2"},
+	)
+
+	tests := []struct {
+		config attrs
+		err    string
+	}{
+		{attrs{"hostname": "www", "type": "A"}, "Multiple matching records found"},
+		{attrs{"hostname": "api", "type": "A"}, "No matching record found"},
+		{attrs{"hostname": "WWW", "type": "A", "destination": "192.0.2.2"}, ""},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		tt.config["domainname"] = domain
+		state, diags := p.readDataSource("netcupdns_record_id", tt.config)
+		if tt.err != "" {
+			if d := firstError(diags); d == nil || d.Summary != tt.err {
+				t.Errorf("%v: got errors %v, want %s", tt.config, summaries(diags, tfprotov6.DiagnosticSeverityError), tt.err)
+			}
+			continue
+		}
+		p.checkDiags("read", diags)
+		if got, want := attrString(t, state, "import_id_by_fields"), domain+"/www/A/192.0.2.2"; got != want {
+			t.Errorf("import_id_by_fields = %s, want %s", got, want)
+		}
+	}
+}
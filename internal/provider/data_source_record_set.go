@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ datasource.DataSource              = &recordSetDataSource{}
+	_ datasource.DataSourceWithConfigure = &recordSetDataSource{}
+)
+
+func NewRecordSetDataSource() datasource.DataSource {
+	return &recordSetDataSource{}
+}
+
+type recordSetDataSource struct {
+	client *client.CCPClient
+}
+
+func (d *recordSetDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_record_set"
+}
+
+func (d *recordSetDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads all records of a type at one name, e.g. round-robin A records or the NS set of a delegation.",
+		Attributes: map[string]schema.Attribute{
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the records.",
+			},
+			"hostname": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the records. Use '@' for root of domain. Matched case-insensitively.",
+			},
+			"type": schema.StringAttribute{
+				Required:    true,
+				Description: "Type of Record like A or NS.",
+			},
+			"allow_empty": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Return an empty set instead of failing when no record matches. Defaults to true.",
+			},
+			"records": schema.SetNestedAttribute{
+				Computed:    true,
+				Description: "Matching records.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:    true,
+							Description: "Unique ID of the record. Provided from Netcup-API",
+						},
+						"priority": schema.StringAttribute{
+							Computed:    true,
+							Description: "Priority of the record.",
+						},
+						"destination": schema.StringAttribute{
+							Computed:    true,
+							Description: "Target of the record.",
+						},
+					},
+				},
+			},
+			"destinations": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Destinations of the matching records, sorted.",
+			},
+		},
+	}
+}
+
+func (d *recordSetDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*client.CCPClient)
+}
+
+func (d *recordSetDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer warnNearRateLimit(d.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(d.client, "reading the records", &resp.Diagnostics) {
+		return
+	}
+
+	var config DnsRecordSetData
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := config.Domainname.ValueString()
+	matches, err := lookupDnsRecords(ctx, d.client, domainname, client.RecordFilter{
+		Hostname: config.Hostname.ValueString(),
+		Type:     config.Type.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Destination < matches[j].Destination
+	})
+
+	if len(matches) == 0 && !config.AllowEmpty.IsNull() && !config.AllowEmpty.ValueBool() {
+		resp.Diagnostics.AddError(
+			"No matching record found",
+			fmt.Sprintf("No record matches hostname=%s type=%s in domain %s and allow_empty is false.", config.Hostname.ValueString(), config.Type.ValueString(), domainname),
+		)
+		return
+	}
+
+	config.Records = make([]RecordSetMember, 0, len(matches))
+	config.Destinations = make([]types.String, 0, len(matches))
+	for _, record := range matches {
+		config.Records = append(config.Records, RecordSetMember{
+			ID:          types.StringValue(record.Id),
+			Priority:    types.StringValue(record.Priority),
+			Destination: types.StringValue(record.Destination),
+		})
+		config.Destinations = append(config.Destinations, types.StringValue(record.Destination))
+	}
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// Records of a record set data source as sorted "priority destination"
+func dataSourceRecords(t *testing.T, state tftypes.Value) []string {
+	t.Helper()
+	records := []string{}
+	for _, record := range elementsOf(t, attrValue(t, state, "records")) {
+		if attrString(t, record, "id") == "" {
+			t.Errorf("record %s has no id", record)
+		}
+		records = append(records, attrString(t, record, "priority")+" "+attrString(t, record, "destination"))
+	}
+	sort.Strings(records)
+	return records
+}
+
+func TestRecordSetDataSource(t *testing.T) {
+	domain := testDomain(t)
+	seedZone(t, domain,
+		attrs{"hostname": "www", "type": "A", "destination": "192.0.2.3"},
+		attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": "www", "type": "A", "destination": "192.0.2.2"},
+		attrs{"hostname": "www", "type": "AAAA", "destination": "2001:db8::1"},
+		attrs{"hostname": "sub", "type": "NS", "destination": "ns2.example.com"},
+		attrs{"hostname": "sub", "type": "NS", "destination": "ns1.example.com"},
+		attrs{"hostname": "@", "type": "MX", "priority": "20", "destination": "backup.example.com"},
+		attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+	)
+
+	tests := []struct {
+		name         string
+		config       attrs
+		destinations []string
+		records      []string
+	}{
+		{
+			name:         "round-robin A records",
+			config:       attrs{"hostname": "www", "type": "A"},
+			destinations: []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"},
+			records:      []string{"0 192.0.2.1", "0 192.0.2.2", "0 192.0.2.3"},
+		},
+		{
+			name:         "hostname and type matched case-insensitively",
+			config:       attrs{"hostname": "WWW", "type": "aaaa"},
+			destinations: []string{"2001:db8::1"},
+			records:      []string{"0 2001:db8::1"},
+		},
+		{
+			name:         "delegation",
+			config:       attrs{"hostname": "sub", "type": "NS"},
+			destinations: []string{"ns1.example.com", "ns2.example.com"},
+			records:      []string{"0 ns1.example.com", "0 ns2.example.com"},
+		},
+		{
+			name:         "priorities",
+			config:       attrs{"hostname": "@", "type": "MX"},
+			destinations: []string{"backup.example.com", "mail.example.com"},
+			records:      []string{"10 mail.example.com", "20 backup.example.com"},
+		},
+		{
+			name:         "empty by default",
+			config:       attrs{"hostname": "missing", "type": "A"},
+			destinations: []string{},
+			records:      []string{},
+		},
+		{
+			name:         "empty allowed",
+			config:       attrs{"hostname": "www", "type": "TXT", "allow_empty": true},
+			destinations: []string{},
+			records:      []string{},
+		},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		tt.config["domainname"] = domain
+		state, diags := p.readDataSource("netcupdns_record_set", tt.config)
+		p.checkDiags(tt.name, diags)
+		if got := attrStrings(t, state, "destinations"); strings.Join(got, ",") != strings.Join(tt.destinations, ",") {
+			t.Errorf("%s: destinations %q, want %q", tt.name, got, tt.destinations)
+		}
+		if got := dataSourceRecords(t, state); strings.Join(got, ",") != strings.Join(tt.records, ",") {
+			t.Errorf("%s: records %q, want %q", tt.name, got, tt.records)
+		}
+	}
+}
+
+func TestRecordSetDataSourceErrors(t *testing.T) {
+	domain := testDomain(t)
+	seedZone(t, domain, attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"})
+
+	tests := []struct {
+		name    string
+		config  attrs
+		summary string
+	}{
+		{"empty not allowed", attrs{"domainname": domain, "hostname": "missing", "type": "A", "allow_empty": false}, "No matching record found"},
+		{"unknown domain", attrs{"domainname": "missing.invalid", "hostname": "www", "type": "A"}, "Error reading records"},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		_, diags := p.readDataSource("netcupdns_record_set", tt.config)
+		if d := firstError(diags); d == nil || d.Summary != tt.summary {
+			t.Errorf("%s: got errors %v, want %s", tt.name, summaries(diags, tfprotov6.DiagnosticSeverityError), tt.summary)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+func TestRecordDataSource(t *testing.T) {
+	domain := testDomain(t)
+	seedZone(t, domain,
+		attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": "@", "type": "A", "destination": "192.0.2.2"},
+		attrs{"hostname": "@", "type": "MX", "priority": "20", "destination": "mx2.example.com"},
+		attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mx1.example.com"},
+	)
+
+	tests := []struct {
+		name        string
+		config      attrs
+		destination string
+		err         string
+	}{
+		{"hostname", attrs{"hostname": "www", "type": "A"}, "192.0.2.1", ""},
+		{"mixed-case hostname and type", attrs{"hostname": "WWW", "type": "a"}, "192.0.2.1", ""},
+		{"apex as @", attrs{"hostname": "@", "type": "A"}, "192.0.2.2", ""},
+		{"apex as domainname", attrs{"hostname": domain + ".", "type": "A"}, "192.0.2.2", ""},
+		{"absolute hostname", attrs{"hostname": "www." + domain + ".", "type": "A"}, "192.0.2.1", ""},
+		{"hostname with trailing dot", attrs{"hostname": "www.", "type": "A"}, "192.0.2.1", ""},
+		{"by priority", attrs{"hostname": "@", "type": "MX", "priority": "20"}, "mx2.example.com", ""},
+		{"by destination", attrs{"hostname": "@", "type": "MX", "destination": "mx1.example.com"}, "mx1.example.com", ""},
+		{"first match", attrs{"hostname": "@", "type": "MX", "first_match": true}, "mx1.example.com", ""},
+		{"several matches", attrs{"hostname": "@", "type": "MX"}, "", "Multiple matching records found"},
+		{"no match", attrs{"hostname": "api", "type": "A"}, "", "No matching record found"},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		tt.config["domainname"] = domain
+		state, diags := p.readDataSource("netcupdns_record", tt.config)
+		if tt.err != "" {
+			if d := firstError(diags); d == nil || d.Summary != tt.err {
+				t.Errorf("%s: got errors %v, want %s", tt.name, summaries(diags, tfprotov6.DiagnosticSeverityError), tt.err)
+			}
+			continue
+		}
+		p.checkDiags(tt.name, diags)
+		if got := attrString(t, state, "destination"); got != tt.destination {
+			t.Errorf("%s: destination = %s, want %s", tt.name, got, tt.destination)
+		}
+		if id := attrString(t, state, "id"); id == "" || id == "<null>" {
+			t.Errorf("%s: record has no id", tt.name)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ datasource.DataSource                   = &recordsDataSource{}
+	_ datasource.DataSourceWithConfigure      = &recordsDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &recordsDataSource{}
+)
+
+func NewRecordsDataSource() datasource.DataSource {
+	return &recordsDataSource{}
+}
+
+type recordsDataSource struct {
+	client *client.CCPClient
+}
+
+func (d *recordsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_records"
+}
+
+// Attributes of a record as returned by data sources
+func dnsRecordDataAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Computed:    true,
+			Description: "Unique ID of the record. Provided from Netcup-API",
+		},
+		"hostname": schema.StringAttribute{
+			Computed:    true,
+			Description: "Name of the record.",
+		},
+		"type": schema.StringAttribute{
+			Computed:    true,
+			Description: "Type of the record.",
+		},
+		"priority": schema.StringAttribute{
+			Computed:    true,
+			Description: "Priority of the record.",
+		},
+		"destination": schema.StringAttribute{
+			Computed:    true,
+			Description: "Target of the record.",
+		},
+		"state": schema.StringAttribute{
+			Computed:    true,
+			Description: "State of the record as reported by the API, e.g. `yes` once it is live.",
+		},
+	}
+}
+
+func (d *recordsDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the DNS-Records of one or several zones, optionally filtered. Records are ordered by hostname, type, priority and destination, numerically by priority and case-insensitively by name, with the zones in the order of `domainnames`.",
+		Attributes: map[string]schema.Attribute{
+			"domainname": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Domainname of the zone. Exactly one of `domainname` and `domainnames` must be set.",
+			},
+			"domainnames": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Domainnames of several zones, which are read in parallel. Exactly one of `domainname` and `domainnames` must be set.",
+			},
+			"fail_on_error": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Fail the read if a zone of `domainnames` can't be read. If `false`, the failure is shown as warning, " +
+					"reported in `errors` and the records of the other zones are returned. Defaults to `true`",
+			},
+			"type": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return records of this type, e.g. A or MX.",
+			},
+			"hostname": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return records with this name. Use '@' for root of domain.",
+			},
+			"hostname_regex": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return records whose name matches this regular expression.",
+			},
+			"records": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Records matching the filters.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: domainDnsRecordDataAttributes(),
+				},
+			},
+			"records_by_key": schema.MapNestedAttribute{
+				Computed: true,
+				MarkdownDescription: "Records matching the filters keyed by `<hostname>/<type>`, or `<domainname>/<hostname>/<type>` if `domainnames` is set. " +
+					"If several records share a key, the key refers to the first of them " +
+					"and each one is additionally available as `<key>/<n>`, counting from 0 in the order of `records`.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: domainDnsRecordDataAttributes(),
+				},
+			},
+			"errors": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Error per domainname that couldn't be read. Only filled if `fail_on_error` is `false`.",
+			},
+		},
+	}
+}
+
+// Attributes of a record annotated with the domain it belongs to
+func domainDnsRecordDataAttributes() map[string]schema.Attribute {
+	attributes := dnsRecordDataAttributes()
+	attributes["domainname"] = schema.StringAttribute{
+		Computed:    true,
+		Description: "Domainname of the zone of the record.",
+	}
+	return attributes
+}
+
+func (d *recordsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*client.CCPClient)
+}
+
+func (d *recordsDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var domainname types.String
+	var domainnames types.List
+	diags := req.Config.GetAttribute(ctx, path.Root("domainname"), &domainname)
+	resp.Diagnostics.Append(diags...)
+	diags = req.Config.GetAttribute(ctx, path.Root("domainnames"), &domainnames)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if domainname.IsNull() == domainnames.IsNull() && !domainname.IsUnknown() && !domainnames.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Invalid domain selection",
+			"Exactly one of domainname and domainnames must be set",
+		)
+	}
+
+	var hostnameRegex types.String
+	diags = req.Config.GetAttribute(ctx, path.Root("hostname_regex"), &hostnameRegex)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() || hostnameRegex.IsNull() || hostnameRegex.IsUnknown() {
+		return
+	}
+
+	if _, err := regexp.Compile(hostnameRegex.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("hostname_regex"),
+			"Invalid regular expression",
+			"The hostname_regex value is not a valid regular expression: "+err.Error(),
+		)
+	}
+}
+
+func (d *recordsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer warnNearRateLimit(d.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(d.client, "reading the records", &resp.Diagnostics) {
+		return
+	}
+
+	var config DnsRecords
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var hostnameRegex *regexp.Regexp
+	if !config.HostnameRegex.IsNull() {
+		// ValidateConfig can't check a regex that was unknown during validation
+		var err error
+		hostnameRegex, err = regexp.Compile(config.HostnameRegex.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("hostname_regex"),
+				"Invalid regular expression",
+				"The hostname_regex value is not a valid regular expression: "+err.Error(),
+			)
+			return
+		}
+	}
+	keep := func(record client.DnsRecord, domainname string) bool {
+		if !config.Type.IsNull() && !strings.EqualFold(record.Type, config.Type.ValueString()) {
+			return false
+		}
+		if !config.Hostname.IsNull() && !hostnamesEqual(record.Hostname, config.Hostname.ValueString(), domainname) {
+			return false
+		}
+		return hostnameRegex == nil || hostnameRegex.MatchString(record.Hostname)
+	}
+
+	multiDomain := config.Domainnames != nil
+	domainnames := []string{config.Domainname.ValueString()}
+	if multiDomain {
+		domainnames = make([]string, 0, len(config.Domainnames))
+		for _, domainname := range config.Domainnames {
+			domainnames = append(domainnames, domainname.ValueString())
+		}
+	}
+
+	zones := d.readZones(ctx, domainnames)
+	failOnError := config.FailOnError.IsNull() || config.FailOnError.ValueBool()
+
+	config.Records = []DomainDnsRecordData{}
+	config.Errors = map[string]string{}
+	var keys []string
+	for i, zone := range zones {
+		if zone.err != nil {
+			attribute := path.Root("domainname")
+			if multiDomain {
+				attribute = path.Root("domainnames").AtListIndex(i)
+			}
+			detail := "Could not read records of domain " + zone.domainname + ": " + zone.err.Error()
+			switch {
+			case multiDomain && !failOnError:
+				resp.Diagnostics.AddAttributeWarning(attribute, "Error reading records", detail)
+				config.Errors[zone.domainname] = zone.err.Error()
+			case !addRateLimitError(&resp.Diagnostics, "Netcup throttled the request to read records of domain "+zone.domainname+".", zone.err):
+				resp.Diagnostics.AddAttributeError(attribute, "Error reading records", detail)
+			}
+			continue
+		}
+
+		var matches []client.DnsRecord
+		for _, record := range zone.records {
+			if keep(record, zone.domainname) {
+				matches = append(matches, record)
+			}
+		}
+		sortDnsRecords(matches)
+
+		tflog.Trace(ctx, "Got DNS Records", map[string]interface{}{"domainname": zone.domainname, "count": len(matches)})
+
+		for _, record := range matches {
+			config.Records = append(config.Records, newDomainDnsRecordData(zone.domainname, record))
+			key := record.Hostname + "/" + record.Type
+			if multiDomain {
+				key = zone.domainname + "/" + key
+			}
+			keys = append(keys, key)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	config.RecordsByKey = dnsRecordsByKey(keys, config.Records)
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Records of a zone read by readZones
+type zoneRead struct {
+	domainname string
+	records    []client.DnsRecord
+	err        error
+}
+
+// Read the zones in parallel, in the order of domainnames. The number of requests
+// in flight is bounded by the max_concurrent_requests limit of the client.
+func (d *recordsDataSource) readZones(ctx context.Context, domainnames []string) []zoneRead {
+	zones := make([]zoneRead, len(domainnames))
+	var wg sync.WaitGroup
+	for i, domainname := range domainnames {
+		wg.Add(1)
+		go func(i int, domainname string) {
+			defer wg.Done()
+			records, err := d.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{})
+			zones[i] = zoneRead{domainname: domainname, records: records, err: err}
+		}(i, domainname)
+	}
+	wg.Wait()
+	return zones
+}
+
+// Key records by the given keys, adding "<key>/<n>" for keys shared by several records
+func dnsRecordsByKey(keys []string, records []DomainDnsRecordData) map[string]DomainDnsRecordData {
+	counts := make(map[string]int)
+	for _, key := range keys {
+		counts[key]++
+	}
+
+	byKey := make(map[string]DomainDnsRecordData, len(records))
+	seen := make(map[string]int)
+	for i, key := range keys {
+		n := seen[key]
+		seen[key]++
+
+		if n == 0 {
+			byKey[key] = records[i]
+		}
+		if counts[key] > 1 {
+			byKey[fmt.Sprintf("%s/%d", key, n)] = records[i]
+		}
+	}
+	return byKey
+}
+
+func newDnsRecordData(record client.DnsRecord) DnsRecordData {
+	return DnsRecordData{
+		ID:          types.StringValue(record.Id),
+		Hostname:    types.StringValue(record.Hostname),
+		Type:        types.StringValue(record.Type),
+		Priority:    types.StringValue(record.Priority),
+		Destination: types.StringValue(record.Destination),
+		State:       types.StringValue(record.State),
+	}
+}
+
+func newDomainDnsRecordData(domainname string, record client.DnsRecord) DomainDnsRecordData {
+	return DomainDnsRecordData{
+		Domainname:  types.StringValue(domainname),
+		ID:          types.StringValue(record.Id),
+		Hostname:    types.StringValue(record.Hostname),
+		Type:        types.StringValue(record.Type),
+		Priority:    types.StringValue(record.Priority),
+		Destination: types.StringValue(record.Destination),
+		State:       types.StringValue(record.State),
+	}
+}
+
+// Sort records by hostname, type, priority and destination so outputs don't churn
+// when the API returns them in a different order. Names and types are compared
+// case-insensitively and priorities numerically, so "5" sorts before "10".
+func sortDnsRecords(records []client.DnsRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if ha, hb := strings.ToLower(a.Hostname), strings.ToLower(b.Hostname); ha != hb {
+			return ha < hb
+		}
+		if ta, tb := strings.ToUpper(a.Type), strings.ToUpper(b.Type); ta != tb {
+			return ta < tb
+		}
+		if pa, pb := priorityOrder(a.Priority), priorityOrder(b.Priority); pa != pb {
+			return pa < pb
+		}
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if a.Destination != b.Destination {
+			return a.Destination < b.Destination
+		}
+		return a.Id < b.Id
+	})
+}
+
+// Numeric value of a priority for sorting, priorities that are no number sort last
+func priorityOrder(priority string) int64 {
+	value, err := strconv.ParseInt(priorityOrZero(priority), 10, 64)
+	if err != nil {
+		return math.MaxInt64
+	}
+	return value
+}
@@ -0,0 +1,358 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// Create records in a new zone of the mock
+func seedZone(t *testing.T, domain string, records ...attrs) {
+	t.Helper()
+	p := newTestProvider(t, nil)
+	defer p.close()
+	for _, record := range records {
+		record["domainname"] = domain
+		p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, record)
+	}
+}
+
+// hostname/type of every record of the records attribute
+func recordNames(t *testing.T, state tftypes.Value) []string {
+	t.Helper()
+	names := []string{}
+	for _, record := range elementsOf(t, attrValue(t, state, "records")) {
+		names = append(names, attrString(t, record, "hostname")+"/"+attrString(t, record, "type"))
+	}
+	return names
+}
+
+func TestRecordsDataSourceFilters(t *testing.T) {
+	domain := testDomain(t)
+	seedZone(t, domain,
+		attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": "api", "type": "A", "destination": "192.0.2.2"},
+		attrs{"hostname": "www", "type": "AAAA", "destination": "2001:db8::1"},
+		attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+		attrs{"hostname": "Api-v2", "type": "CNAME", "destination": "api"},
+	)
+
+	tests := []struct {
+		name   string
+		config attrs
+		want   []string
+	}{
+		{"unfiltered", attrs{}, []string{"@/MX", "api/A", "api-v2/CNAME", "www/A", "www/AAAA"}},
+		{"type", attrs{"type": "a"}, []string{"api/A", "www/A"}},
+		{"hostname", attrs{"hostname": "WWW"}, []string{"www/A", "www/AAAA"}},
+		{"apex hostname", attrs{"hostname": domain}, []string{"@/MX"}},
+		{"hostname with trailing dot", attrs{"hostname": "www."}, []string{"www/A", "www/AAAA"}},
+		{"absolute hostname", attrs{"hostname": "www." + domain + "."}, []string{"www/A", "www/AAAA"}},
+		{"absolute apex", attrs{"hostname": domain + "."}, []string{"@/MX"}},
+		{"hostname regex", attrs{"hostname_regex": "^api"}, []string{"api/A", "api-v2/CNAME"}},
+		{"combined", attrs{"type": "A", "hostname_regex": "^a"}, []string{"api/A"}},
+		{"no match", attrs{"type": "TXT"}, []string{}},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		tt.config["domainname"] = domain
+		state, diags := p.readDataSource("netcupdns_records", tt.config)
+		p.checkDiags(tt.name, diags)
+		if got := recordNames(t, state); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got records %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// reading again returns the same order
+	first, _ := p.readDataSource("netcupdns_records", attrs{"domainname": domain})
+	second, _ := p.readDataSource("netcupdns_records", attrs{"domainname": domain})
+	if !first.Equal(second) {
+		t.Errorf("reads differ:\n%s\n%s", first, second)
+	}
+}
+
+func TestRecordsDataSourceInvalidRegex(t *testing.T) {
+	p := newTestProvider(t, nil)
+	config := attrs{"domainname": testDomain(t), "hostname_regex": "(["}
+
+	_, diags := p.readDataSource("netcupdns_records", config)
+	if d := firstError(diags); d == nil || d.Summary != "Invalid regular expression" {
+		t.Errorf("validation got diagnostics %v, want an invalid regular expression error", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+
+	// a regex unknown during validation is only known on read
+	resp, err := p.server.ReadDataSource(context.Background(), &tfprotov6.ReadDataSourceRequest{
+		TypeName: "netcupdns_records",
+		Config:   p.dynamicValue(p.dataSourceSchema("netcupdns_records"), config),
+	})
+	if err != nil {
+		t.Fatalf("ReadDataSource failed: %s", err)
+	}
+	d := firstError(resp.Diagnostics)
+	if d == nil || d.Summary != "Invalid regular expression" {
+		t.Fatalf("read got diagnostics %v, want an invalid regular expression error", summaries(resp.Diagnostics, tfprotov6.DiagnosticSeverityError))
+	}
+	if d.Attribute == nil || d.Attribute.String() != tftypes.NewAttributePath().WithAttributeName("hostname_regex").String() {
+		t.Errorf("error is for attribute %v, want hostname_regex", d.Attribute)
+	}
+}
+
+// Destination of every record of records_by_key by key
+func recordsByKey(t *testing.T, state tftypes.Value) map[string]string {
+	t.Helper()
+	var byKey map[string]tftypes.Value
+	if err := attrValue(t, state, "records_by_key").As(&byKey); err != nil {
+		t.Fatalf("records_by_key is no map: %s", err)
+	}
+	destinations := make(map[string]string, len(byKey))
+	for key, record := range byKey {
+		destinations[key] = attrString(t, record, "destination")
+	}
+	return destinations
+}
+
+func TestRecordsDataSourceByKey(t *testing.T) {
+	domain := testDomain(t)
+	other := "other-" + domain
+	seedZone(t, domain,
+		attrs{"hostname": "www", "type": "A", "destination": "192.0.2.2"},
+		attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+	)
+	seedZone(t, other, attrs{"hostname": "www", "type": "A", "destination": "192.0.2.3"})
+
+	p := newTestProvider(t, nil)
+	state, diags := p.readDataSource("netcupdns_records", attrs{"domainname": domain})
+	p.checkDiags("read", diags)
+	want := map[string]string{
+		"www/A":   "192.0.2.1",
+		"www/A/0": "192.0.2.1",
+		"www/A/1": "192.0.2.2",
+		"@/MX":    "mail.example.com",
+	}
+	if got := recordsByKey(t, state); !reflect.DeepEqual(got, want) {
+		t.Errorf("records_by_key = %v, want %v", got, want)
+	}
+
+	state, diags = p.readDataSource("netcupdns_records", attrs{"domainnames": []interface{}{domain, other}, "type": "A"})
+	p.checkDiags("read of several zones", diags)
+	want = map[string]string{
+		domain + "/www/A":   "192.0.2.1",
+		domain + "/www/A/0": "192.0.2.1",
+		domain + "/www/A/1": "192.0.2.2",
+		other + "/www/A":    "192.0.2.3",
+	}
+	if got := recordsByKey(t, state); !reflect.DeepEqual(got, want) {
+		t.Errorf("records_by_key of several zones = %v, want %v", got, want)
+	}
+}
+
+func TestRecordsDataSourceSeveralZones(t *testing.T) {
+	first, second := "first."+testDomain(t), "second."+testDomain(t)
+	seedZone(t, first, attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"}, attrs{"hostname": "api", "type": "A", "destination": "192.0.2.2"})
+	seedZone(t, second, attrs{"hostname": "www", "type": "A", "destination": "192.0.2.3"})
+
+	p := newTestProvider(t, nil)
+	state, diags := p.readDataSource("netcupdns_records", attrs{"domainnames": []interface{}{second, first}})
+	p.checkDiags("read", diags)
+
+	// zones in the order of domainnames, each record annotated with its zone
+	var got []string
+	for _, record := range elementsOf(t, attrValue(t, state, "records")) {
+		got = append(got, attrString(t, record, "domainname")+" "+attrString(t, record, "hostname")+" "+attrString(t, record, "destination"))
+	}
+	want := []string{second + " www 192.0.2.3", first + " api 192.0.2.2", first + " www 192.0.2.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got records %v, want %v", got, want)
+	}
+	if errors := attrValue(t, state, "errors"); !errors.IsKnown() || errors.IsNull() {
+		t.Errorf("errors = %s, want an empty map", errors)
+	}
+
+	_, diags = p.readDataSource("netcupdns_records", attrs{"domainname": first, "domainnames": []interface{}{second}})
+	if d := firstError(diags); d == nil || d.Summary != "Invalid domain selection" {
+		t.Errorf("both set got errors %v", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+}
+
+// A zone that can't be read fails the read, or with fail_on_error = false is
+// reported as warning and in errors while the other zones are still returned
+func TestRecordsDataSourcePartialFailure(t *testing.T) {
+	readable := testDomain(t)
+	missing := strings.TrimSuffix(readable, ".example") + ".invalid"
+	seedZone(t, readable, attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"})
+	p := newTestProvider(t, nil)
+	domainnames := []interface{}{readable, missing}
+
+	for _, failOnError := range []interface{}{nil, true} {
+		_, diags := p.readDataSource("netcupdns_records", attrs{"domainnames": domainnames, "fail_on_error": failOnError})
+		d := firstError(diags)
+		if d == nil || d.Summary != "Error reading records" || !strings.Contains(d.Detail, missing) {
+			t.Fatalf("fail_on_error = %v got errors %v, want the error of %s", failOnError, summaries(diags, tfprotov6.DiagnosticSeverityError), missing)
+		}
+		if want := tftypes.NewAttributePath().WithAttributeName("domainnames").WithElementKeyInt(1); d.Attribute == nil || !d.Attribute.Equal(want) {
+			t.Errorf("error is for attribute %v, want %v", d.Attribute, want)
+		}
+	}
+
+	state, diags := p.readDataSource("netcupdns_records", attrs{"domainnames": domainnames, "fail_on_error": false})
+	if hasErrors(diags) {
+		t.Fatalf("got errors %v", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+	if warnings := summaries(diags, tfprotov6.DiagnosticSeverityWarning); !reflect.DeepEqual(warnings, []string{"Error reading records"}) {
+		t.Errorf("got warnings %v, want one for %s", warnings, missing)
+	}
+	if got := recordNames(t, state); !reflect.DeepEqual(got, []string{"www/A"}) {
+		t.Errorf("got records %v, want the record of %s", got, readable)
+	}
+	var errors map[string]tftypes.Value
+	if err := attrValue(t, state, "errors").As(&errors); err != nil {
+		t.Fatal(err)
+	}
+	if len(errors) != 1 || errors[missing].IsNull() {
+		t.Errorf("errors = %v, want the error of %s only", errors, missing)
+	}
+}
+
+// The zones are read in parallel, but never with more than max_concurrent_requests requests at once
+func TestRecordsDataSourceConcurrencyBound(t *testing.T) {
+	server := newLoginServer(t)
+	var mu sync.Mutex
+	inFlight, highest, reads := 0, 0, 0
+	server.onRequest = func(action string) {
+		if action != "infoDnsRecords" {
+			return
+		}
+		mu.Lock()
+		inFlight++
+		reads++
+		if inFlight > highest {
+			highest = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}
+
+	p := startTestProvider(t)
+	p.checkDiags("configure", p.configure(attrs{
+		"endpoint": server.URL, "customer_number": "46565", "key": "abcdefghijklmnopqrstuvwxyz", "password": "password",
+		"max_concurrent_requests": 2,
+	}))
+	var domainnames []interface{}
+	for i := 0; i < 8; i++ {
+		domainnames = append(domainnames, fmt.Sprintf("zone%d.%s", i, testDomain(t)))
+	}
+	_, diags := p.readDataSource("netcupdns_records", attrs{"domainnames": domainnames})
+	p.checkDiags("read", diags)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if reads != len(domainnames) {
+		t.Errorf("read %d zones, want %d", reads, len(domainnames))
+	}
+	if highest != 2 {
+		t.Errorf("%d zones read at the same time, want 2", highest)
+	}
+
+	diags = p.configure(attrs{"mock": true, "max_concurrent_requests": 0})
+	if d := firstError(diags); d == nil || d.Summary != "Invalid number of concurrent requests" {
+		t.Errorf("got errors %v, want Invalid number of concurrent requests", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+}
+
+// The API lists records in any order, the data sources always in the same one
+func TestDataSourcesOrderShuffledZone(t *testing.T) {
+	zone := []map[string]interface{}{
+		{"id": "1", "hostname": "www", "type": "A", "priority": "0", "destination": "192.0.2.2"},
+		{"id": "2", "hostname": "Api", "type": "A", "priority": "0", "destination": "192.0.2.1"},
+		{"id": "3", "hostname": "www", "type": "AAAA", "priority": "0", "destination": "2001:db8::1"},
+		{"id": "4", "hostname": "@", "type": "MX", "priority": "10", "destination": "mx2.example.com"},
+		{"id": "5", "hostname": "@", "type": "MX", "priority": "5", "destination": "mx1.example.com"},
+		{"id": "6", "hostname": "@", "type": "MX", "priority": "10", "destination": "mx0.example.com"},
+		{"id": "7", "hostname": "www", "type": "A", "priority": "0", "destination": "192.0.2.1"},
+		{"id": "8", "hostname": "api", "type": "TXT", "priority": "0", "destination": "v=2"},
+		{"id": "9", "hostname": "api", "type": "TXT", "priority": "0", "destination": "v=1"},
+	}
+	server := newLoginServer(t)
+	random := rand.New(rand.NewSource(1))
+	var mu sync.Mutex
+	server.respond = func(action string, body []byte) map[string]interface{} {
+		if action != "infoDnsRecords" {
+			return nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		shuffled := make([]interface{}, 0, len(zone))
+		for _, i := range random.Perm(len(zone)) {
+			record := map[string]interface{}{"deleterecord": false, "state": "yes"}
+			for name, value := range zone[i] {
+				record[name] = value
+			}
+			shuffled = append(shuffled, record)
+		}
+		return map[string]interface{}{
+			"action": action, "status": "success", "statuscode": 2000, "shortmessage": "ok",
+			"responsedata": map[string]interface{}{"dnsrecords": shuffled},
+		}
+	}
+
+	tests := []struct {
+		dataSource string
+		config     attrs
+		list       string
+		fields     []string
+		want       []string
+	}{
+		{"netcupdns_records", attrs{}, "records", []string{"hostname", "type", "priority", "destination"}, []string{
+			"@ MX 5 mx1.example.com", "@ MX 10 mx0.example.com", "@ MX 10 mx2.example.com",
+			"Api A 0 192.0.2.1", "api TXT 0 v=1", "api TXT 0 v=2",
+			"www A 0 192.0.2.1", "www A 0 192.0.2.2", "www AAAA 0 2001:db8::1",
+		}},
+		{"netcupdns_mx_records", attrs{}, "records", []string{"priority", "destination"}, []string{
+			"5 mx1.example.com", "10 mx0.example.com", "10 mx2.example.com",
+		}},
+		{"netcupdns_record_set", attrs{"hostname": "www", "type": "A"}, "destinations", nil, []string{"192.0.2.1", "192.0.2.2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dataSource, func(t *testing.T) {
+			tt.config["domainname"] = "shuffled.example"
+			for run := 0; run < 5; run++ {
+				p := startTestProvider(t)
+				p.checkDiags("configure", p.configure(attrs{
+					"endpoint": server.URL, "customer_number": "48300", "key": "shuffledkey0123456789", "password": "password",
+				}))
+				state, diags := p.readDataSource(tt.dataSource, tt.config)
+				p.checkDiags("read", diags)
+				p.close()
+
+				var got []string
+				if tt.fields == nil {
+					got = attrStrings(t, state, tt.list)
+				} else {
+					for _, element := range elementsOf(t, attrValue(t, state, tt.list)) {
+						values := make([]string, 0, len(tt.fields))
+						for _, field := range tt.fields {
+							values = append(values, attrString(t, element, field))
+						}
+						got = append(got, strings.Join(values, " "))
+					}
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("run %d: got %q, want %q", run, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+	"github.com/svetob/terraform-provider-netcupdns/internal/dnstypes"
+	"github.com/svetob/terraform-provider-netcupdns/internal/resolver"
+)
+
+const defaultResolveTimeout = 5 * time.Second
+
+var (
+	_ datasource.DataSource                   = &resolveDataSource{}
+	_ datasource.DataSourceWithConfigure      = &resolveDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &resolveDataSource{}
+)
+
+func NewResolveDataSource() datasource.DataSource {
+	return &resolveDataSource{}
+}
+
+type resolveDataSource struct {
+	client *client.CCPClient
+}
+
+func (d *resolveDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_resolve"
+}
+
+func (d *resolveDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Performs a live DNS query, e.g. to check that the nameservers serve what the API reports. " +
+			"A non-existing name does not fail the read but returns no answers with `nxdomain` set.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Fully qualified name to query, e.g. www.example.com.",
+			},
+			"type": schema.StringAttribute{
+				Required:    true,
+				Description: "Record type to query: A, AAAA, CNAME, MX, NS, SRV or TXT.",
+			},
+			"domainname": schema.StringAttribute{
+				Optional:    true,
+				Description: "Zone containing the name. When set, matches_api compares the answers with the records reported by the API.",
+			},
+			"nameservers": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Nameservers (host or host:port) to query in order. Defaults to the authoritative nameservers of the zone.",
+			},
+			"timeout": schema.StringAttribute{
+				Optional:    true,
+				Description: "Timeout of the lookup as duration, e.g. \"10s\". Defaults to 5s.",
+			},
+			"answers": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Sorted answers. MX answers are rendered as \"<preference> <host>\", SRV answers as \"<priority> <weight> <port> <target>\".",
+			},
+			"nxdomain": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether the name does not exist.",
+			},
+			"nameserver": schema.StringAttribute{
+				Computed:    true,
+				Description: "Nameserver that answered.",
+			},
+			"matches_api": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether the answers equal the records reported by the API. Only set when domainname is given.",
+			},
+		},
+	}
+}
+
+func (d *resolveDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*client.CCPClient)
+}
+
+func (d *resolveDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var timeout types.String
+	diags := req.Config.GetAttribute(ctx, path.Root("timeout"), &timeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || timeout.IsNull() || timeout.IsUnknown() {
+		return
+	}
+
+	if _, err := time.ParseDuration(timeout.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("timeout"),
+			"Invalid timeout",
+			"The timeout must be a duration like \"10s\": "+err.Error(),
+		)
+	}
+}
+
+func (d *resolveDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer warnNearRateLimit(d.client, &resp.Diagnostics)
+
+	var config DnsResolve
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := defaultResolveTimeout
+	if !config.Timeout.IsNull() {
+		timeout, _ = time.ParseDuration(config.Timeout.ValueString())
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	name := config.Name.ValueString()
+	var nameservers []string
+	for _, ns := range config.Nameservers {
+		nameservers = append(nameservers, ns.ValueString())
+	}
+	if len(nameservers) == 0 {
+		zone := name
+		if !config.Domainname.IsNull() {
+			zone = config.Domainname.ValueString()
+		}
+		var err error
+		nameservers, err = resolver.Authoritative(lookupCtx, zone)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("nameservers"),
+				"Error discovering nameservers",
+				"No nameservers were given and the authoritative ones could not be discovered: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	var result *resolver.Result
+	var lastErr error
+	for _, ns := range nameservers {
+		result, lastErr = resolver.Lookup(lookupCtx, name, config.Type.ValueString(), ns)
+		if lastErr == nil {
+			break
+		}
+		tflog.Debug(ctx, "DNS lookup failed", map[string]interface{}{"name": name, "nameserver": ns, "error": lastErr.Error()})
+	}
+	if lastErr != nil {
+		resp.Diagnostics.AddError(
+			"Error resolving name",
+			"Could not resolve "+name+" "+config.Type.ValueString()+" using "+strings.Join(nameservers, ", ")+": "+lastErr.Error(),
+		)
+		return
+	}
+
+	config.Answers = make([]types.String, 0, len(result.Answers))
+	for _, answer := range result.Answers {
+		config.Answers = append(config.Answers, types.StringValue(answer))
+	}
+	config.NXDomain = types.BoolValue(result.NXDomain)
+	config.Nameserver = types.StringValue(result.Nameserver)
+	config.MatchesApi = types.BoolNull()
+
+	if !config.Domainname.IsNull() {
+		matches, ok := d.matchesApi(ctx, config.Domainname.ValueString(), name, config.Type.ValueString(), result.Answers, resp)
+		if !ok {
+			return
+		}
+		config.MatchesApi = types.BoolValue(matches)
+	}
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Compare answers with the records of the zone in the answer format of the resolver
+func (d *resolveDataSource) matchesApi(ctx context.Context, domainname, name, recordType string, answers []string, resp *datasource.ReadResponse) (bool, bool) {
+	if addNotConfiguredError(d.client, "reading the records", &resp.Diagnostics) {
+		return false, false
+	}
+
+	hostname, ok := relativeHostname(name, domainname)
+	if !ok {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Name outside of zone",
+			"The name "+name+" is not part of the zone "+domainname+".",
+		)
+		return false, false
+	}
+
+	records, err := lookupDnsRecords(ctx, d.client, domainname, client.RecordFilter{Hostname: hostname, Type: recordType})
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return false, false
+	}
+
+	var expected []string
+	for _, record := range records {
+		expected = append(expected, resolverAnswer(record, domainname))
+	}
+	sort.Strings(expected)
+
+	if len(expected) != len(answers) {
+		return false, true
+	}
+	for i := range expected {
+		if expected[i] != answers[i] {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+// Render a record the way resolver.Lookup renders answers
+func resolverAnswer(record client.DnsRecord, domainname string) string {
+	target := func(t string) string {
+		if t == "@" {
+			return resolver.Fqdn(strings.ToLower(domainname))
+		}
+		return strings.ToLower(absoluteTarget(t))
+	}
+
+	switch strings.ToUpper(record.Type) {
+	case "A", "AAAA":
+		if ip := net.ParseIP(record.Destination); ip != nil {
+			return ip.String()
+		}
+		return record.Destination
+	case "CNAME", "NS":
+		return target(record.Destination)
+	case "MX":
+		return priorityOrZero(record.Priority) + " " + target(record.Destination)
+	case "SRV":
+		fields := strings.Fields(record.Destination)
+		if len(fields) == 3 {
+			fields[2] = target(fields[2])
+		}
+		return priorityOrZero(record.Priority) + " " + strings.Join(fields, " ")
+	case "TXT":
+		return dnstypes.ParseTXTValue(record.Destination)
+	default:
+		return record.Destination
+	}
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/svetob/terraform-provider-netcupdns/internal/resolver/resolvertest"
+)
+
+func newDnsServer(t *testing.T, records ...resolvertest.Record) *resolvertest.Server {
+	t.Helper()
+	server, err := resolvertest.NewServer(records...)
+	if err != nil {
+		t.Fatalf("starting DNS server: %s", err)
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestResolveDataSource(t *testing.T) {
+	domain := testDomain(t)
+	seedZone(t, domain,
+		attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+		attrs{"hostname": "@", "type": "TXT", "destination": `"v=spf1 mx -all"`},
+	)
+	server := newDnsServer(t,
+		resolvertest.Record{Name: "www." + domain, Type: "A", Data: "192.0.2.1"},
+		resolvertest.Record{Name: domain, Type: "MX", Data: "10 mail.example.com"},
+		resolvertest.Record{Name: domain, Type: "TXT", Data: "v=spf1 -all"},
+	)
+
+	tests := []struct {
+		name       string
+		config     attrs
+		answers    []string
+		nxdomain   string
+		matchesApi string
+	}{
+		{
+			name:       "served like the API reports",
+			config:     attrs{"name": "www." + domain, "type": "A", "domainname": domain},
+			answers:    []string{"192.0.2.1"},
+			nxdomain:   "false",
+			matchesApi: "true",
+		},
+		{
+			name:       "absolute targets",
+			config:     attrs{"name": domain, "type": "MX", "domainname": domain},
+			answers:    []string{"10 mail.example.com."},
+			nxdomain:   "false",
+			matchesApi: "true",
+		},
+		{
+			name:       "served differently",
+			config:     attrs{"name": domain, "type": "TXT", "domainname": domain},
+			answers:    []string{"v=spf1 -all"},
+			nxdomain:   "false",
+			matchesApi: "false",
+		},
+		{
+			name:       "without domainname",
+			config:     attrs{"name": "www." + domain, "type": "A"},
+			answers:    []string{"192.0.2.1"},
+			nxdomain:   "false",
+			matchesApi: "<null>",
+		},
+		{
+			name:       "non-existing name",
+			config:     attrs{"name": "missing." + domain, "type": "A", "domainname": domain},
+			answers:    []string{},
+			nxdomain:   "true",
+			matchesApi: "true",
+		},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		tt.config["nameservers"] = []interface{}{server.Addr}
+		state, diags := p.readDataSource("netcupdns_resolve", tt.config)
+		p.checkDiags(tt.name, diags)
+		if got := attrStrings(t, state, "answers"); strings.Join(got, ",") != strings.Join(tt.answers, ",") {
+			t.Errorf("%s: answers %q, want %q", tt.name, got, tt.answers)
+		}
+		if got := attrString(t, state, "nxdomain"); got != tt.nxdomain {
+			t.Errorf("%s: nxdomain %s, want %s", tt.name, got, tt.nxdomain)
+		}
+		if got := attrString(t, state, "matches_api"); got != tt.matchesApi {
+			t.Errorf("%s: matches_api %s, want %s", tt.name, got, tt.matchesApi)
+		}
+		if got := attrString(t, state, "nameserver"); got != server.Addr {
+			t.Errorf("%s: nameserver %s, want %s", tt.name, got, server.Addr)
+		}
+	}
+}
+
+// The next nameserver is asked if the lookup with one fails
+func TestResolveDataSourceFallback(t *testing.T) {
+	closed := newDnsServer(t)
+	closed.Close()
+	server := newDnsServer(t, resolvertest.Record{Name: "www.example.test", Type: "A", Data: "192.0.2.1"})
+
+	p := newTestProvider(t, nil)
+	state, diags := p.readDataSource("netcupdns_resolve", attrs{
+		"name":        "www.example.test",
+		"type":        "A",
+		"nameservers": []interface{}{closed.Addr, server.Addr},
+	})
+	p.checkDiags("read", diags)
+	if got := attrString(t, state, "nameserver"); got != server.Addr {
+		t.Errorf("nameserver %s, want %s", got, server.Addr)
+	}
+}
+
+func TestResolveDataSourceTimeout(t *testing.T) {
+	server := newDnsServer(t)
+	server.SetSilent(true)
+
+	p := newTestProvider(t, nil)
+	_, diags := p.readDataSource("netcupdns_resolve", attrs{
+		"name":        "www.example.test",
+		"type":        "A",
+		"nameservers": []interface{}{server.Addr},
+		"timeout":     "200ms",
+	})
+	if d := firstError(diags); d == nil || d.Summary != "Error resolving name" {
+		t.Errorf("got errors %v, want Error resolving name", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+}
+
+func TestResolveDataSourceInvalidTimeout(t *testing.T) {
+	p := newTestProvider(t, nil)
+	_, diags := p.readDataSource("netcupdns_resolve", attrs{"name": "www.example.test", "type": "A", "timeout": "soon"})
+	if d := firstError(diags); d == nil || d.Summary != "Invalid timeout" {
+		t.Errorf("got errors %v, want Invalid timeout", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+}
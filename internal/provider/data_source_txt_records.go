@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+	"github.com/svetob/terraform-provider-netcupdns/internal/dnstypes"
+)
+
+var (
+	_ datasource.DataSource              = &txtRecordsDataSource{}
+	_ datasource.DataSourceWithConfigure = &txtRecordsDataSource{}
+)
+
+func NewTxtRecordsDataSource() datasource.DataSource {
+	return &txtRecordsDataSource{}
+}
+
+type txtRecordsDataSource struct {
+	client *client.CCPClient
+}
+
+func (d *txtRecordsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_txt_records"
+}
+
+func (d *txtRecordsDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the TXT records at a name with their values decoded, i.e. unquoted and with chunked character-strings joined.",
+		Attributes: map[string]schema.Attribute{
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the records.",
+			},
+			"hostname": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the records. Use '@' for root of domain. Matched case-insensitively.",
+			},
+			"values": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Decoded TXT values in the order of records.",
+			},
+			"records": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Raw TXT records as returned by the API, ordered by destination.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: dnsRecordDataAttributes(),
+				},
+			},
+		},
+	}
+}
+
+func (d *txtRecordsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*client.CCPClient)
+}
+
+func (d *txtRecordsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer warnNearRateLimit(d.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(d.client, "reading the records", &resp.Diagnostics) {
+		return
+	}
+
+	var config TxtRecords
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := config.Domainname.ValueString()
+	matches, err := lookupDnsRecords(ctx, d.client, domainname, client.RecordFilter{
+		Hostname: config.Hostname.ValueString(),
+		Type:     "TXT",
+	})
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	config.Values = make([]types.String, 0, len(matches))
+	config.Records = make([]DnsRecordData, 0, len(matches))
+	for _, record := range matches {
+		config.Values = append(config.Values, types.StringValue(dnstypes.ParseTXTValue(record.Destination)))
+		config.Records = append(config.Records, newDnsRecordData(record))
+	}
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
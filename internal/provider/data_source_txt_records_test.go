@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+func TestTxtRecordsDataSource(t *testing.T) {
+	key := strings.Repeat("MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8A", 10)
+	domain := testDomain(t)
+	// destinations as the API returns them, which the record resource would have normalized
+	_, err := mockClient(t).CreateDnsRecords(context.Background(), domain, []client.NewDnsRecord{
+		{Hostname: "selector1._domainkey", Type: "TXT", Destination: `"v=DKIM1; k=rsa; p=` + key[:200] + `" "` + key[200:] + `"`},
+		{Hostname: "@", Type: "TXT", Destination: `"v=spf1 mx -all"`},
+		{Hostname: "@", Type: "TXT", Destination: "google-site-verification=abc123"},
+		{Hostname: "@", Type: "TXT", Destination: `"say \"hi\""`},
+		{Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"},
+		{Hostname: "www", Type: "TXT", Destination: "other"},
+	})
+	if err != nil {
+		t.Fatalf("CreateDnsRecords failed: %s", err)
+	}
+
+	tests := []struct {
+		hostname string
+		values   []string
+		raw      []string
+	}{
+		{
+			hostname: "selector1._domainkey",
+			values:   []string{"v=DKIM1; k=rsa; p=" + key},
+			raw:      []string{`"v=DKIM1; k=rsa; p=` + key[:200] + `" "` + key[200:] + `"`},
+		},
+		{
+			hostname: "@",
+			values:   []string{`say "hi"`, "v=spf1 mx -all", "google-site-verification=abc123"},
+			raw:      []string{`"say \"hi\""`, `"v=spf1 mx -all"`, "google-site-verification=abc123"},
+		},
+		{
+			hostname: "SELECTOR1._domainkey",
+			values:   []string{"v=DKIM1; k=rsa; p=" + key},
+			raw:      []string{`"v=DKIM1; k=rsa; p=` + key[:200] + `" "` + key[200:] + `"`},
+		},
+		{
+			hostname: "missing",
+			values:   []string{},
+			raw:      []string{},
+		},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		state, diags := p.readDataSource("netcupdns_txt_records", attrs{"domainname": domain, "hostname": tt.hostname})
+		p.checkDiags(tt.hostname, diags)
+		if got := attrStrings(t, state, "values"); strings.Join(got, "|") != strings.Join(tt.values, "|") {
+			t.Errorf("%s: values %q, want %q", tt.hostname, got, tt.values)
+		}
+		raw := []string{}
+		for _, record := range elementsOf(t, attrValue(t, state, "records")) {
+			if got := attrString(t, record, "type"); got != "TXT" {
+				t.Errorf("%s: record of type %s", tt.hostname, got)
+			}
+			raw = append(raw, attrString(t, record, "destination"))
+		}
+		if strings.Join(raw, "|") != strings.Join(tt.raw, "|") {
+			t.Errorf("%s: records %q, want %q", tt.hostname, raw, tt.raw)
+		}
+	}
+}
+
+func TestTxtRecordsDataSourceUnknownDomain(t *testing.T) {
+	p := newTestProvider(t, nil)
+	_, diags := p.readDataSource("netcupdns_txt_records", attrs{"domainname": "missing.invalid", "hostname": "@"})
+	if d := firstError(diags); d == nil || d.Summary != "Error reading records" {
+		t.Errorf("got errors %v, want Error reading records", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ datasource.DataSource              = &zoneDataSource{}
+	_ datasource.DataSourceWithConfigure = &zoneDataSource{}
+)
+
+func NewZoneDataSource() datasource.DataSource {
+	return &zoneDataSource{}
+}
+
+type zoneDataSource struct {
+	client *client.CCPClient
+}
+
+func (d *zoneDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_zone"
+}
+
+func (d *zoneDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads the settings of a DNS-Zone. See [Netcup-API](https://ccp.netcup.net/run/webservice/servers/endpoint.php#Dnszone)",
+		Attributes: map[string]schema.Attribute{
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the zone.",
+			},
+			"ttl": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Default TTL of the zone in seconds.",
+			},
+			"serial": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Serial of the zone.",
+			},
+			"refresh": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Refresh interval of the zone in seconds.",
+			},
+			"retry": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Retry interval of the zone in seconds.",
+			},
+			"expire": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Expire time of the zone in seconds.",
+			},
+			"dnssec_enabled": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether DNSSEC is enabled for the zone.",
+			},
+		},
+	}
+}
+
+func (d *zoneDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*client.CCPClient)
+}
+
+func (d *zoneDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer warnNearRateLimit(d.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(d.client, "reading the zone", &resp.Diagnostics) {
+		return
+	}
+
+	var config DnsZone
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	zone, err := d.client.GetDnsZone(ctx, config.Domainname.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading zone",
+			"Could not read zone "+config.Domainname.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	tflog.Trace(ctx, "Got DNS Zone", map[string]interface{}{"domainname": zone.Name, "serial": zone.Serial})
+
+	state := DnsZone{
+		Domainname:    config.Domainname,
+		DnssecEnabled: types.BoolValue(zone.DNSSecStatus),
+	}
+
+	fields := []struct {
+		name   string
+		value  string
+		target *types.Int64
+	}{
+		{"ttl", zone.TTL, &state.TTL},
+		{"serial", zone.Serial, &state.Serial},
+		{"refresh", zone.Refresh, &state.Refresh},
+		{"retry", zone.Retry, &state.Retry},
+		{"expire", zone.Expire, &state.Expire},
+	}
+	for _, field := range fields {
+		n, err := strconv.ParseInt(field.value, 10, 64)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(field.name),
+				"Unexpected zone value",
+				"Could not parse "+field.name+" value "+strconv.Quote(field.value)+" returned by the API: "+err.Error(),
+			)
+			continue
+		}
+		*field.target = types.Int64Value(n)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ datasource.DataSource              = &zoneFileDataSource{}
+	_ datasource.DataSourceWithConfigure = &zoneFileDataSource{}
+)
+
+func NewZoneFileDataSource() datasource.DataSource {
+	return &zoneFileDataSource{}
+}
+
+type zoneFileDataSource struct {
+	client *client.CCPClient
+}
+
+func (d *zoneFileDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_zone_file"
+}
+
+func (d *zoneFileDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Renders the current records of a zone as RFC 1035 zone file text, e.g. for backups or secondary DNS systems.",
+		Attributes: map[string]schema.Attribute{
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the zone.",
+			},
+			"exclude_types": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Record types to leave out of the zone file, e.g. [\"TXT\"].",
+			},
+			"content": schema.StringAttribute{
+				Computed:    true,
+				Description: "Zone file text. Records are ordered by hostname, type, priority and destination.",
+			},
+		},
+	}
+}
+
+func (d *zoneFileDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*client.CCPClient)
+}
+
+func (d *zoneFileDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer warnNearRateLimit(d.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(d.client, "reading the zone", &resp.Diagnostics) {
+		return
+	}
+
+	var config DnsZoneFile
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := config.Domainname.ValueString()
+	zone, err := d.client.GetDnsZone(ctx, domainname)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading zone",
+			"Could not read zone "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	records, err := d.client.GetDnsRecords(ctx, domainname)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	excluded := make(map[string]bool)
+	for _, t := range config.ExcludeTypes {
+		excluded[strings.ToUpper(t.ValueString())] = true
+	}
+
+	var included []client.DnsRecord
+	for _, record := range records {
+		if !excluded[strings.ToUpper(record.Type)] {
+			included = append(included, record)
+		}
+	}
+	sortDnsRecords(included)
+
+	config.Content = types.StringValue(renderZoneFile(domainname, zone, included))
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
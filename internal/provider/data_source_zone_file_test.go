@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestZoneFileDataSourceGolden(t *testing.T) {
+	domain := testDomain(t)
+	seedZone(t, domain,
+		attrs{"hostname": "@", "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": "www", "type": "AAAA", "destination": "2001:db8::1"},
+		attrs{"hostname": "@", "type": "MX", "priority": "20", "destination": "backup.example.com"},
+		attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com."},
+		attrs{"hostname": "api", "type": "CNAME", "destination": "www"},
+		attrs{"hostname": "@", "type": "TXT", "destination": `"v=spf1 mx -all"`},
+		attrs{"hostname": "_dmarc", "type": "TXT", "destination": `v=DMARC1; p=reject; rua="mailto:dmarc@example.com"`},
+		attrs{"hostname": "dkim._domainkey", "type": "TXT", "destination": "v=DKIM1; k=rsa; p=" + strings.Repeat("A", 300)},
+		attrs{"hostname": "_sip._tcp", "type": "SRV", "priority": "10", "destination": "5 5060 sip.example.com"},
+		attrs{"hostname": "@", "type": "CAA", "destination": `0 issue "letsencrypt.org"`},
+	)
+
+	tests := []struct {
+		golden string
+		config attrs
+	}{
+		{"zone_file.golden", attrs{}},
+		{"zone_file_exclude_types.golden", attrs{"exclude_types": []interface{}{"mx", "TXT"}}},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		tt.config["domainname"] = domain
+		state, diags := p.readDataSource("netcupdns_zone_file", tt.config)
+		p.checkDiags(tt.golden, diags)
+		checkGolden(t, tt.golden, attrString(t, state, "content"))
+	}
+}
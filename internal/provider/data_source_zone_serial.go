@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ datasource.DataSource              = &zoneSerialDataSource{}
+	_ datasource.DataSourceWithConfigure = &zoneSerialDataSource{}
+)
+
+func NewZoneSerialDataSource() datasource.DataSource {
+	return &zoneSerialDataSource{}
+}
+
+type zoneSerialDataSource struct {
+	client *client.CCPClient
+}
+
+func (d *zoneSerialDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_zone_serial"
+}
+
+func (d *zoneSerialDataSource) Schema(_ context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads the current SOA serial of a zone, e.g. to trigger automation via `replace_triggered_by` whenever the zone changed.",
+		Attributes: map[string]schema.Attribute{
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the zone.",
+			},
+			"use_cache": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Use the zone information already read during this run instead of asking the API again. Defaults to false.",
+			},
+			"serial": schema.StringAttribute{
+				Computed:    true,
+				Description: "Serial of the zone as returned by the API.",
+			},
+			"serial_number": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Serial of the zone as number.",
+			},
+		},
+	}
+}
+
+func (d *zoneSerialDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*client.CCPClient)
+}
+
+func (d *zoneSerialDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer warnNearRateLimit(d.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(d.client, "reading the zone", &resp.Diagnostics) {
+		return
+	}
+
+	var config DnsZoneSerial
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := config.Domainname.ValueString()
+	var zone *client.DnsZone
+	var err error
+	if config.UseCache.ValueBool() {
+		zone, err = d.client.GetDnsZone(ctx, domainname)
+	} else {
+		zone, err = d.client.RefreshDnsZone(ctx, domainname)
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading zone",
+			"Could not read zone "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	serial, err := strconv.ParseInt(zone.Serial, 10, 64)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("serial_number"),
+			"Unexpected zone value",
+			"Could not parse serial value "+strconv.Quote(zone.Serial)+" returned by the API: "+err.Error(),
+		)
+		return
+	}
+
+	config.Serial = types.StringValue(zone.Serial)
+	config.SerialNumber = types.Int64Value(serial)
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
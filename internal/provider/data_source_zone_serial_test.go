@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+func TestZoneSerialDataSource(t *testing.T) {
+	domain := testDomain(t)
+	p := newTestProvider(t, nil)
+
+	serial := func(config attrs) string {
+		t.Helper()
+		config["domainname"] = domain
+		state, diags := p.readDataSource("netcupdns_zone_serial", config)
+		p.checkDiags("read", diags)
+		serial := attrString(t, state, "serial")
+		if number := attrString(t, state, "serial_number"); number != serial {
+			t.Errorf("serial_number %s differs from serial %s", number, serial)
+		}
+		return serial
+	}
+
+	before := serial(attrs{})
+	if before != "2024010100" {
+		t.Errorf("serial of a new zone is %s, want 2024010100", before)
+	}
+
+	// change made through the API mid-run
+	_, err := mockClient(t).CreateDnsRecord(context.Background(), domain, client.NewDnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"})
+	if err != nil {
+		t.Fatalf("CreateDnsRecord failed: %s", err)
+	}
+
+	after := serial(attrs{})
+	if n, _ := strconv.Atoi(before); after != strconv.Itoa(n+1) {
+		t.Errorf("serial after the change is %s, want the one after %s", after, before)
+	}
+	if got := serial(attrs{"use_cache": false}); got != after {
+		t.Errorf("serial without cache is %s, want %s", got, after)
+	}
+
+	_, err = mockClient(t).CreateDnsRecord(context.Background(), domain, client.NewDnsRecord{Hostname: "api", Type: "A", Destination: "192.0.2.2"})
+	if err != nil {
+		t.Fatalf("CreateDnsRecord failed: %s", err)
+	}
+	if got := serial(attrs{"use_cache": true}); got != after {
+		t.Errorf("cached serial is %s, want %s read before", got, after)
+	}
+	if got := serial(attrs{}); got == after {
+		t.Errorf("serial is still %s after the second change", got)
+	}
+}
+
+func TestZoneSerialDataSourceUnknownDomain(t *testing.T) {
+	p := newTestProvider(t, nil)
+	_, diags := p.readDataSource("netcupdns_zone_serial", attrs{"domainname": "missing.invalid"})
+	if d := firstError(diags); d == nil || d.Summary != "Error reading zone" {
+		t.Errorf("got errors %v, want Error reading zone", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+}
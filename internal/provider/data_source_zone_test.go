@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestZoneDataSource(t *testing.T) {
+	domain := testDomain(t)
+
+	p := newTestProvider(t, nil)
+	state, diags := p.readDataSource("netcupdns_zone", attrs{"domainname": domain})
+	p.checkDiags("read", diags)
+
+	// settings of a new zone of the mock
+	want := map[string]string{
+		"domainname": domain, "ttl": "86400", "refresh": "28800", "retry": "7200", "expire": "1209600", "serial": "2024010100",
+	}
+	for name, value := range want {
+		if got := attrString(t, state, name); got != value {
+			t.Errorf("%s = %s, want %s", name, got, value)
+		}
+	}
+	var dnssec bool
+	if err := attrValue(t, state, "dnssec_enabled").As(&dnssec); err != nil || dnssec {
+		t.Errorf("dnssec_enabled = %t (%v), want false", dnssec, err)
+	}
+}
+
+func TestZoneDataSourceUnknownDomain(t *testing.T) {
+	p := newTestProvider(t, nil)
+	_, diags := p.readDataSource("netcupdns_zone", attrs{"domainname": "missing.invalid"})
+	d := firstError(diags)
+	if d == nil || d.Summary != "Error reading zone" {
+		t.Fatalf("got error %v, want an error reading the zone", d)
+	}
+	if d.Attribute == nil || d.Attribute.String() != tftypes.NewAttributePath().WithAttributeName("domainname").String() {
+		t.Errorf("error is for attribute %v, want domainname", d.Attribute)
+	}
+}
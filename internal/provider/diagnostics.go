@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+// Identifies the record a diagnostic is about. Empty fields are left out of the detail.
+type recordContext struct {
+	Domainname  string
+	ID          string
+	Hostname    string
+	Type        string
+	Destination string
+}
+
+func newRecordContext(domainname string, record client.DnsRecord) recordContext {
+	return recordContext{
+		Domainname:  domainname,
+		ID:          record.Id,
+		Hostname:    record.Hostname,
+		Type:        record.Type,
+		Destination: record.Destination,
+	}
+}
+
+// Add an error about a single record, so a failed apply touching many records
+// tells which record failed without looking it up in the state
+func addRecordError(diags *diag.Diagnostics, summary string, action string, record recordContext, err error) {
+	if addRateLimitError(diags, "Netcup throttled the request to "+action+" DNS record.\n"+recordFields(record), err) {
+		return
+	}
+	if addZoneLockedError(diags, "Netcup rejected the request to "+action+" DNS record.\n"+recordFields(record), err) {
+		return
+	}
+	diags.AddError(summary, recordErrorDetail(action, record, err))
+}
+
+// Add a diagnostic with remediation if err is caused by the API rate limit.
+// Reports whether it did.
+func addRateLimitError(diags *diag.Diagnostics, request string, err error) bool {
+	var rateLimitErr *client.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		return false
+	}
+
+	diags.AddError(
+		"Netcup API rate limit exceeded",
+		fmt.Sprintf("%s\nThe request was retried %d times over %s, but the API kept rejecting it: %s\n\n"+
+			"To stay below the rate limit of %d requests per %s:\n"+
+			"  - lower the parallelism of terraform, e.g. terraform apply -parallelism=2\n"+
+			"  - limit the requests of the provider with max_concurrent_requests, e.g. max_concurrent_requests = 1\n"+
+			"  - manage many records with batching resources like netcupdns_record_set or netcupdns_autoconfig_mail\n"+
+			"  - wait a minute before running terraform again",
+			strings.TrimRight(request, "\n"), rateLimitErr.Attempts-1, rateLimitErr.Elapsed.Round(time.Second), rateLimitErr.Err,
+			client.RateLimitBudget, client.RateLimitWindow),
+	)
+	return true
+}
+
+// Add a diagnostic explaining a concurrent change of the zone if err is caused
+// by one. Reports whether it did.
+func addZoneLockedError(diags *diag.Diagnostics, request string, err error) bool {
+	var lockedErr *client.ZoneLockedError
+	if !errors.As(err, &lockedErr) {
+		return false
+	}
+
+	diags.AddError(
+		"Zone locked by a concurrent change",
+		fmt.Sprintf("%s\nAnother change to the zone, e.g. one made in the Netcup customer control panel or by another tool, was still being processed. "+
+			"The request was retried %d times over %s, but the zone stayed locked: %s\n\n"+
+			"Wait until the other change is done and run terraform again.",
+			strings.TrimRight(request, "\n"), lockedErr.Attempts-1, lockedErr.Elapsed.Round(time.Second), lockedErr.Err),
+	)
+	return true
+}
+
+func recordErrorDetail(action string, record recordContext, err error) string {
+	var b strings.Builder
+	b.WriteString("Could not " + action + " DNS record.\n")
+	b.WriteString(recordFields(record))
+
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		fmt.Fprintf(&b, "API status %d: %s", apiErr.StatusCode, apiErr.ShortMessage)
+		if apiErr.LongMessage != "" {
+			b.WriteString("\n" + apiErr.LongMessage)
+		}
+		return b.String()
+	}
+
+	b.WriteString(err.Error())
+	return b.String()
+}
+
+// One line per known field of the record
+func recordFields(record recordContext) string {
+	var b strings.Builder
+	fields := []struct{ name, value string }{
+		{"domainname", record.Domainname},
+		{"id", record.ID},
+		{"hostname", record.Hostname},
+		{"type", record.Type},
+		{"destination", record.Destination},
+	}
+	for _, field := range fields {
+		if field.value != "" {
+			fmt.Fprintf(&b, "  %-12s %s\n", field.name+":", field.value)
+		}
+	}
+	return b.String()
+}
+
+// Add an error if the provider has no client before an operation, which is the
+// case while its configuration contains values unknown until other resources
+// are applied. Reports whether it did.
+func addNotConfiguredError(c *client.CCPClient, operation string, diags *diag.Diagnostics) bool {
+	if c != nil {
+		return false
+	}
+
+	diags.AddError(
+		"Provider not configured",
+		"The provider hasn't been configured before "+operation+", because its configuration depends on values unknown until other resources are applied, "+
+			"e.g. a customer_number read from a secret managed in the same run. "+
+			"Apply the resources providing the values first, e.g. with terraform apply -target, or pass the credentials as variables or environment variables.",
+	)
+	return true
+}
+
+// Warn once when the requests of this run approach the API rate limit.
+// Deferred by every operation, so the warning is attached to the operation crossing the threshold.
+func warnNearRateLimit(c *client.CCPClient, diags *diag.Diagnostics) {
+	if c == nil {
+		return
+	}
+
+	count, near := c.NearRateLimit()
+	if !near {
+		return
+	}
+	diags.AddWarning(
+		"Approaching the Netcup API rate limit",
+		fmt.Sprintf("%d of %d allowed requests were sent within the last %s. "+
+			"Lower the parallelism of terraform (e.g. -parallelism=2) or manage related records with batching resources like netcupdns_autoconfig_mail "+
+			"to avoid being throttled by the API.", count, client.RateLimitBudget, client.RateLimitWindow),
+	)
+}
+
+// Warn once per domain when records are written to a DNSSEC-signed zone
+func warnDnssecZone(ctx context.Context, c *client.CCPClient, domainname string, diags *diag.Diagnostics) {
+	notice, err := c.DnssecNotice(ctx, domainname)
+	if err != nil {
+		// the warning is informational only, so a failed zone lookup must not fail the write
+		tflog.Debug(ctx, "Could not read DNSSEC status", map[string]interface{}{"domainname": domainname, "error": err.Error()})
+		return
+	}
+	if !notice {
+		return
+	}
+	diags.AddWarning(
+		"DNSSEC enabled for "+domainname,
+		"The zone "+domainname+" is signed with DNSSEC. Changed records are served only after Netcup re-signed the zone, "+
+			"so they may not resolve for a while after apply. Set dnssec_warning = false in the provider configuration to hide this warning.",
+	)
+}
+
+// Add an error per record a bulk delete left in the zone, or a single error if the request failed
+func addDeleteError(diags *diag.Diagnostics, domainname string, action string, err error) {
+	if addRateLimitError(diags, "Netcup throttled the request to "+action+" records of domain "+domainname+".", err) {
+		return
+	}
+	if addZoneLockedError(diags, "Netcup rejected the request to "+action+" records of domain "+domainname+".", err) {
+		return
+	}
+
+	var deleteErr *client.DeleteError
+	if !errors.As(err, &deleteErr) {
+		diags.AddError(
+			"Error deleting records",
+			"Could not "+action+" records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	for _, record := range deleteErr.Remaining {
+		addRecordError(diags, "Error deleting record", action, newRecordContext(domainname, record), errors.New("record still exists after deletion"))
+	}
+}
@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+func TestThrottledCreateDiagnostic(t *testing.T) {
+	var diags diag.Diagnostics
+	err := &client.RateLimitError{
+		Action:   "updateDnsRecords",
+		Attempts: 6,
+		Elapsed:  31*time.Second + 400*time.Millisecond,
+		Err:      &client.APIError{Action: "updateDnsRecords", StatusCode: client.StatusValidationError, ShortMessage: "Too many requests."},
+	}
+	addRecordError(&diags, "Error creating dns record", "create", recordContext{
+		Domainname:  "example.com",
+		Hostname:    "www",
+		Type:        "A",
+		Destination: "192.0.2.1",
+	}, err)
+
+	if len(diags) != 1 {
+		t.Fatalf("got %d diagnostics, want 1", len(diags))
+	}
+	if got, want := diags[0].Summary(), "Netcup API rate limit exceeded"; got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+	want := "Netcup throttled the request to create DNS record.\n" +
+		"  domainname:  example.com\n" +
+		"  hostname:    www\n" +
+		"  type:        A\n" +
+		"  destination: 192.0.2.1\n" +
+		"The request was retried 5 times over 31s, but the API kept rejecting it: " + err.Err.Error() + "\n\n" +
+		"To stay below the rate limit of " + strconv.Itoa(client.RateLimitBudget) + " requests per " + client.RateLimitWindow.String() + ":\n" +
+		"  - lower the parallelism of terraform, e.g. terraform apply -parallelism=2\n" +
+		"  - limit the requests of the provider with max_concurrent_requests, e.g. max_concurrent_requests = 1\n" +
+		"  - manage many records with batching resources like netcupdns_record_set or netcupdns_autoconfig_mail\n" +
+		"  - wait a minute before running terraform again"
+	if got := diags[0].Detail(); got != want {
+		t.Errorf("detail =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRecordErrorWithoutRateLimit(t *testing.T) {
+	var diags diag.Diagnostics
+	addRecordError(&diags, "Error creating dns record", "create", recordContext{Domainname: "example.com", Hostname: "www"}, errors.New("connection refused"))
+	if len(diags) != 1 || diags[0].Summary() != "Error creating dns record" {
+		t.Fatalf("got diagnostics %v, want the record error", diags)
+	}
+}
+
+// Check that the detail of the only error names every field of the record and the API's message
+func checkRecordError(t *testing.T, diags []*tfprotov6.Diagnostic, summary string, fields [][2]string, message string) {
+	t.Helper()
+
+	errs := summaries(diags, tfprotov6.DiagnosticSeverityError)
+	if len(errs) != 1 || errs[0] != summary {
+		t.Fatalf("got errors %q, want %q", errs, summary)
+	}
+	detail := firstError(diags).Detail
+	for _, field := range fields {
+		line := fmt.Sprintf("  %-12s %s\n", field[0]+":", field[1])
+		if !strings.Contains(detail, line) {
+			t.Errorf("detail misses %q:\n%s", line, detail)
+		}
+	}
+	if !strings.Contains(detail, message) {
+		t.Errorf("detail misses the API message %q:\n%s", message, detail)
+	}
+}
+
+func TestRecordDiagnosticsNameTheRecord(t *testing.T) {
+	domain := testDomain(t)
+	missing := strings.TrimSuffix(domain, ".example") + ".invalid"
+	config := attrs{"domainname": domain, "hostname": "www", "type": "A", "destination": "192.0.2.1"}
+
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+	id := attrString(t, created.State, "id")
+	p.close()
+
+	t.Run("create", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		defer p.close()
+		result := p.tryApply("netcupdns_record", nullState(p, "netcupdns_record"), nil, attrs{"domainname": missing, "hostname": "www", "type": "A", "destination": "192.0.2.1"})
+		checkRecordError(t, result.Diags, "Error creating dns record", [][2]string{
+			{"domainname", missing}, {"hostname", "www"}, {"type", "A"}, {"destination", "192.0.2.1"},
+		}, "Domain not found.")
+	})
+
+	t.Run("read", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		defer p.close()
+		_, diags := p.read("netcupdns_record", withAttr(t, created.State, "domainname", missing), created.Private)
+		checkRecordError(t, diags, "Error reading record", [][2]string{
+			{"domainname", missing}, {"id", id}, {"hostname", "www"}, {"type", "A"}, {"destination", "192.0.2.1"},
+		}, "Domain not found.")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		p := newTestProvider(t, nil)
+		defer p.close()
+		result := p.tryApply("netcupdns_record", withAttr(t, created.State, "domainname", missing), created.Private, nil)
+		checkRecordError(t, result.Diags, "Error deleting record", [][2]string{
+			{"domainname", missing}, {"id", id}, {"hostname", "www"}, {"type", "A"}, {"destination", "192.0.2.1"},
+		}, "Domain not found.")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		// deleted outside of Terraform after the refresh
+		c := mockClient(t)
+		defer c.Logout(context.Background())
+		record, err := c.GetDnsRecordById(context.Background(), domain, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p := newTestProvider(t, nil)
+		defer p.close()
+		p.plan("netcupdns_record", created.State, created.Private, config)
+		if err := c.DeleteDnsRecord(context.Background(), domain, *record); err != nil {
+			t.Fatal(err)
+		}
+
+		update := attrs{"destination": "192.0.2.2"}
+		for name, value := range config {
+			if _, ok := update[name]; !ok {
+				update[name] = value
+			}
+		}
+		result := p.tryApply("netcupdns_record", created.State, created.Private, update)
+		checkRecordError(t, result.Diags, "Error updating dns record", [][2]string{
+			{"domainname", domain}, {"id", id}, {"hostname", "www"}, {"type", "A"}, {"destination", "192.0.2.2"},
+		}, "does not exist")
+	})
+}
+
+// Resources share the request count of the client, and the warning is shown by
+// the single operation crossing the threshold
+func TestRateLimitWarningShownOnce(t *testing.T) {
+	// a customer of its own, so the requests of other tests don't count
+	p := newTestProvider(t, attrs{"customer_number": "46446", "rate_limit_warning_threshold": 0.1})
+	domain := testDomain(t)
+
+	warnings := 0
+	for i := 0; i < 30; i++ {
+		// records of different zones, so every create reads a zone that isn't cached
+		result := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, attrs{
+			"domainname": fmt.Sprintf("zone%d.%s", i, domain), "hostname": "www", "type": "A", "destination": "192.0.2.1",
+		})
+		for _, warning := range summaries(result.Diags, tfprotov6.DiagnosticSeverityWarning) {
+			if warning == "Approaching the Netcup API rate limit" {
+				warnings++
+			}
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("got %d rate limit warnings, want exactly one", warnings)
+	}
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+// An attribute whose value changed outside of Terraform
+type driftedValue struct {
+	Attribute string
+	Old       string
+	New       string
+}
+
+// Collect a drifted value if old and new differ
+func appendDrift(drift []driftedValue, attribute string, old string, new string, equal bool) []driftedValue {
+	if equal {
+		return drift
+	}
+	return append(drift, driftedValue{Attribute: attribute, Old: old, New: new})
+}
+
+// Warn about values changed outside of Terraform if drift_warnings is enabled.
+// Nothing is reported on the first read after create or import, as the prior
+// state wasn't read from the API yet.
+func warnDrift(ctx context.Context, c *client.CCPClient, private privateGetter, resource string, drift []driftedValue, diags *diag.Diagnostics) {
+	if c == nil || !c.DriftWarnings() || len(drift) == 0 {
+		return
+	}
+	force, d := private.GetKey(ctx, forceReadKey)
+	diags.Append(d...)
+	if len(force) > 0 {
+		return
+	}
+
+	lines := make([]string, 0, len(drift))
+	for _, value := range drift {
+		lines = append(lines, "  "+value.Attribute+": "+strconv.Quote(value.Old)+" -> "+strconv.Quote(value.New))
+	}
+	diags.AddWarning(
+		"Drift detected",
+		resource+" was changed outside of Terraform:\n"+strings.Join(lines, "\n"),
+	)
+}
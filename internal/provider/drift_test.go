@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// Details of the drift warnings of diags
+func driftWarnings(diags []*tfprotov6.Diagnostic) []string {
+	var details []string
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityWarning && d.Summary == "Drift detected" {
+			details = append(details, d.Detail)
+		}
+	}
+	return details
+}
+
+// Create a record and read it once, as Terraform does before the next plan
+func createAndRefresh(t *testing.T, config attrs) applied {
+	t.Helper()
+	p := newTestProvider(t, attrs{"drift_warnings": true})
+	defer p.close()
+	created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+	refreshed, private, diags := p.refresh("netcupdns_record", created.State, created.Private)
+	p.checkDiags("refresh after create", diags)
+	if details := driftWarnings(diags); len(details) > 0 {
+		t.Errorf("first refresh after create warned %q", details)
+	}
+	return applied{State: refreshed, Private: private}
+}
+
+func TestDriftWarnings(t *testing.T) {
+	domain := testDomain(t)
+	a := createAndRefresh(t, attrs{"domainname": domain, "hostname": "www", "type": "A", "destination": "192.0.2.1"})
+	mx := createAndRefresh(t, attrs{"domainname": domain, "hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"})
+	unchanged := createAndRefresh(t, attrs{"domainname": domain, "hostname": "API", "type": "aaaa", "destination": "2001:db8::1"})
+
+	mutateRecord(t, domain, attrString(t, a.State, "id"), "0", "192.0.2.9")
+	mutateRecord(t, domain, attrString(t, mx.State, "id"), "20", "backup.example.com")
+
+	tests := []struct {
+		name  string
+		prior applied
+		want  string
+	}{
+		{"destination", a, fmt.Sprintf("The DNS record %s of domain %s was changed outside of Terraform:\n"+
+			`  destination: "192.0.2.1" -> "192.0.2.9"`, attrString(t, a.State, "id"), domain)},
+		{"priority and destination", mx, fmt.Sprintf("The DNS record %s of domain %s was changed outside of Terraform:\n"+
+			`  priority: "10" -> "20"`+"\n"+
+			`  destination: "mail.example.com" -> "backup.example.com"`, attrString(t, mx.State, "id"), domain)},
+		// the API stores the hostname and type in another case, which isn't drift
+		{"unchanged", unchanged, ""},
+	}
+	for _, tt := range tests {
+		p := newTestProvider(t, attrs{"drift_warnings": true})
+		refreshed, diags := p.read("netcupdns_record", tt.prior.State, tt.prior.Private)
+		p.checkDiags(tt.name, diags)
+		details := driftWarnings(diags)
+		if tt.want == "" {
+			if len(details) > 0 {
+				t.Errorf("%s: warned %q", tt.name, details)
+			}
+			continue
+		}
+		if len(details) != 1 || details[0] != tt.want {
+			t.Errorf("%s: warned %q, want\n%s", tt.name, details, tt.want)
+		}
+		// the state is updated as without the warnings
+		if got := attrString(t, refreshed, "destination"); got == attrString(t, tt.prior.State, "destination") {
+			t.Errorf("%s: refresh kept destination %s", tt.name, got)
+		}
+
+		// disabled by default
+		p = newTestProvider(t, nil)
+		_, diags = p.read("netcupdns_record", tt.prior.State, tt.prior.Private)
+		if details := driftWarnings(diags); len(details) > 0 {
+			t.Errorf("%s: warned %q without drift_warnings", tt.name, details)
+		}
+		p.close()
+	}
+}
+
+// The first read after create and import has no prior state of the API to compare with
+func TestDriftWarningsSuppressedAfterCreateAndImport(t *testing.T) {
+	domain := testDomain(t)
+	p := newTestProvider(t, attrs{"drift_warnings": true})
+	created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil,
+		attrs{"domainname": domain, "hostname": "www", "type": "A", "destination": "192.0.2.1"})
+	id := attrString(t, created.State, "id")
+	p.close()
+	mutateRecord(t, domain, id, "0", "192.0.2.9")
+
+	p = newTestProvider(t, attrs{"drift_warnings": true})
+	refreshed, diags := p.read("netcupdns_record", created.State, created.Private)
+	p.checkDiags("refresh after create", diags)
+	if details := driftWarnings(diags); len(details) > 0 {
+		t.Errorf("first refresh after create warned %q", details)
+	}
+	if got := attrString(t, refreshed, "destination"); got != "192.0.2.9" {
+		t.Errorf("refresh read destination %s, want 192.0.2.9", got)
+	}
+
+	_, diags = p.importState("netcupdns_record", domain+"/"+id)
+	p.checkDiags("import", diags)
+	if details := driftWarnings(diags); len(details) > 0 {
+		t.Errorf("import warned %q", details)
+	}
+}
+
+func TestDriftWarningsRecordSet(t *testing.T) {
+	domain := testDomain(t)
+	config := recordSetConfig(domain,
+		attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+	)
+	p := newTestProvider(t, attrs{"drift_warnings": true})
+	created := p.apply("netcupdns_record_set", nullState(p, "netcupdns_record_set"), nil, config)
+	refreshed, private, diags := p.refresh("netcupdns_record_set", created.State, created.Private)
+	p.checkDiags("refresh after create", diags)
+	p.close()
+
+	for _, record := range elementsOf(t, attrValue(t, refreshed, "managed_records")) {
+		if attrString(t, record, "type") == "MX" {
+			mutateRecord(t, domain, attrString(t, record, "id"), "20", "mail.example.com")
+		}
+	}
+
+	p = newTestProvider(t, attrs{"drift_warnings": true})
+	_, diags = p.read("netcupdns_record_set", refreshed, private)
+	p.checkDiags("refresh", diags)
+	want := "The record set of domain " + domain + " was changed outside of Terraform:\n" +
+		`  records: "@ MX 10 mail.example.com, www A 0 192.0.2.1" -> "@ MX 20 mail.example.com, www A 0 192.0.2.1"`
+	if details := driftWarnings(diags); len(details) != 1 || details[0] != want {
+		t.Errorf("warned %q, want\n%s", details, want)
+	}
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = &mailPresetFunction{}
+)
+
+var mailPresetMXAttrTypes = map[string]attr.Type{
+	"priority":    types.Int64Type,
+	"destination": types.StringType,
+}
+
+func NewMailPresetFunction() function.Function {
+	return &mailPresetFunction{}
+}
+
+type mailPresetFunction struct{}
+
+// Result of mail_preset
+type mailPresetResult struct {
+	Name         types.String         `tfsdk:"name"`
+	Version      types.Int64          `tfsdk:"version"`
+	MX           []mailPresetMXResult `tfsdk:"mx"`
+	SPFInclude   types.String         `tfsdk:"spf_include"`
+	SPF          types.String         `tfsdk:"spf"`
+	Autodiscover types.String         `tfsdk:"autodiscover"`
+}
+
+type mailPresetMXResult struct {
+	Priority    types.Int64  `tfsdk:"priority"`
+	Destination types.String `tfsdk:"destination"`
+}
+
+func (f *mailPresetFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "mail_preset"
+}
+
+func (f *mailPresetFunction) Definition(_ context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Well-known mail records of a hosted mail provider",
+		MarkdownDescription: "Returns the MX servers, SPF include and autodiscover target of a hosted mail provider: " +
+			"`" + strings.Join(mailPresetNames(), "`, `") + "`. " +
+			"`microsoft365` derives its MX host from the domain, which must be passed as second argument. " +
+			"The result is an object with `name`, `version` of the preset table, `mx` (list of `priority` and `destination`), " +
+			"`spf_include`, `spf` (a complete SPF record including only this provider) and `autodiscover` (null if the provider uses none). " +
+			"Destinations end with a dot, like `netcupdns_record` expects them.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "provider_name",
+				Description: "Name of the mail provider, e.g. google_workspace.",
+			},
+		},
+		VariadicParameter: function.StringParameter{
+			Name:        "domain",
+			Description: "Domainname the mail is for, required by microsoft365.",
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"name":         types.StringType,
+				"version":      types.Int64Type,
+				"mx":           types.ListType{ElemType: types.ObjectType{AttrTypes: mailPresetMXAttrTypes}},
+				"spf_include":  types.StringType,
+				"spf":          types.StringType,
+				"autodiscover": types.StringType,
+			},
+		},
+	}
+}
+
+func (f *mailPresetFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var name string
+	var domains []string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &name, &domains))
+	if resp.Error != nil {
+		return
+	}
+
+	preset, ok := mailPresets[strings.ToLower(name)]
+	if !ok {
+		resp.Error = function.NewArgumentFuncError(0, "Unknown mail provider \""+name+"\", supported are: "+strings.Join(mailPresetNames(), ", ")+".")
+		return
+	}
+	if len(domains) > 1 {
+		resp.Error = function.NewArgumentFuncError(1, "Expected at most one domainname.")
+		return
+	}
+	domain := ""
+	if len(domains) == 1 {
+		domain = domains[0]
+	}
+	if preset.PerDomain && domain == "" {
+		resp.Error = function.NewArgumentFuncError(0, "The mail provider "+name+" derives its MX host from the domain, pass the domainname as second argument.")
+		return
+	}
+
+	result := mailPresetResult{
+		Name:         types.StringValue(strings.ToLower(name)),
+		Version:      types.Int64Value(mailPresetsVersion),
+		MX:           []mailPresetMXResult{},
+		SPFInclude:   types.StringValue(preset.SPFInclude),
+		SPF:          types.StringValue("v=spf1 include:" + preset.SPFInclude + " ~all"),
+		Autodiscover: types.StringNull(),
+	}
+	for _, mx := range preset.mxFor(domain) {
+		result.MX = append(result.MX, mailPresetMXResult{
+			Priority:    types.Int64Value(mx.Priority),
+			Destination: types.StringValue(mx.Destination),
+		})
+	}
+	if preset.Autodiscover != "" {
+		result.Autodiscover = types.StringValue(preset.Autodiscover)
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var mailPresetType = tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+	"name":         tftypes.String,
+	"version":      tftypes.Number,
+	"mx":           tftypes.List{ElementType: tftypes.Object{AttributeTypes: map[string]tftypes.Type{"priority": tftypes.Number, "destination": tftypes.String}}},
+	"spf_include":  tftypes.String,
+	"spf":          tftypes.String,
+	"autodiscover": tftypes.String,
+}}
+
+// Result of mail_preset with the MX records as "priority destination"
+type mailPresetOutput struct {
+	Name, Version   string
+	MX              []string
+	SPFInclude, SPF string
+	Autodiscover    string
+}
+
+func callMailPreset(t *testing.T, p *testProvider, args ...string) mailPresetOutput {
+	t.Helper()
+	value, err := p.callFunctionValue("mail_preset", mailPresetType, args...)
+	if err != nil {
+		t.Fatalf("mail_preset(%q) failed: %s", args, err.Text)
+	}
+	output := mailPresetOutput{
+		Name:         attrString(t, value, "name"),
+		Version:      attrString(t, value, "version"),
+		MX:           []string{},
+		SPFInclude:   attrString(t, value, "spf_include"),
+		SPF:          attrString(t, value, "spf"),
+		Autodiscover: attrString(t, value, "autodiscover"),
+	}
+	for _, mx := range elementsOf(t, attrValue(t, value, "mx")) {
+		output.MX = append(output.MX, attrString(t, mx, "priority")+" "+attrString(t, mx, "destination"))
+	}
+	return output
+}
+
+// Every preset of the table, changes to these values need a new mailPresetsVersion
+func TestMailPresetFunction(t *testing.T) {
+	p := startTestProvider(t)
+
+	tests := []struct {
+		args []string
+		want mailPresetOutput
+	}{
+		{[]string{"google_workspace"}, mailPresetOutput{
+			Name: "google_workspace", Version: "1",
+			MX:         []string{"1 smtp.google.com."},
+			SPFInclude: "_spf.google.com", SPF: "v=spf1 include:_spf.google.com ~all",
+			Autodiscover: "<null>",
+		}},
+		{[]string{"mailbox_org"}, mailPresetOutput{
+			Name: "mailbox_org", Version: "1",
+			MX:         []string{"10 mxext1.mailbox.org.", "10 mxext2.mailbox.org.", "20 mxext3.mailbox.org."},
+			SPFInclude: "mailbox.org", SPF: "v=spf1 include:mailbox.org ~all",
+			Autodiscover: "<null>",
+		}},
+		{[]string{"microsoft365", "Example.co.uk."}, mailPresetOutput{
+			Name: "microsoft365", Version: "1",
+			MX:         []string{"0 example-co-uk.mail.protection.outlook.com."},
+			SPFInclude: "spf.protection.outlook.com", SPF: "v=spf1 include:spf.protection.outlook.com ~all",
+			Autodiscover: "autodiscover.outlook.com.",
+		}},
+		// names are case-insensitive, a domain is accepted but unused by fixed MX hosts
+		{[]string{"Google_Workspace", "example.com"}, mailPresetOutput{
+			Name: "google_workspace", Version: "1",
+			MX:         []string{"1 smtp.google.com."},
+			SPFInclude: "_spf.google.com", SPF: "v=spf1 include:_spf.google.com ~all",
+			Autodiscover: "<null>",
+		}},
+	}
+	for _, tt := range tests {
+		if got := callMailPreset(t, p, tt.args...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("mail_preset(%q) = %+v, want %+v", tt.args, got, tt.want)
+		}
+	}
+
+	if len(mailPresets) != 3 {
+		t.Errorf("%d presets, add the new ones to this test", len(mailPresets))
+	}
+}
+
+func TestMailPresetFunctionErrors(t *testing.T) {
+	p := startTestProvider(t)
+
+	tests := []struct {
+		args     []string
+		argument int64
+		text     string
+	}{
+		{[]string{"fastmail"}, 0, "Unknown mail provider \"fastmail\", supported are: google_workspace, mailbox_org, microsoft365."},
+		{[]string{"microsoft365"}, 0, "pass the domainname as second argument"},
+		{[]string{"microsoft365", "example.com", "example.org"}, 1, "Expected at most one domainname."},
+	}
+	for _, tt := range tests {
+		_, err := p.callFunctionValue("mail_preset", mailPresetType, tt.args...)
+		if err == nil {
+			t.Errorf("mail_preset(%q) succeeded, want an error", tt.args)
+			continue
+		}
+		if err.FunctionArgument == nil || *err.FunctionArgument != tt.argument {
+			t.Errorf("mail_preset(%q) failed at argument %v, want %d", tt.args, err.FunctionArgument, tt.argument)
+		}
+		if !strings.Contains(err.Text, tt.text) {
+			t.Errorf("mail_preset(%q) failed with %q, want %q", tt.args, err.Text, tt.text)
+		}
+	}
+}
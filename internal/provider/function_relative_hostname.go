@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var (
+	_ function.Function = &relativeHostnameFunction{}
+)
+
+func NewRelativeHostnameFunction() function.Function {
+	return &relativeHostnameFunction{}
+}
+
+type relativeHostnameFunction struct{}
+
+func (f *relativeHostnameFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "relative_hostname"
+}
+
+func (f *relativeHostnameFunction) Definition(_ context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Hostname of a fully qualified name relative to its zone",
+		MarkdownDescription: "Converts a fully qualified name like `grafana.ops.example.com` into the hostname relative to the zone, like `grafana.ops`, " +
+			"as used by `netcupdns_record`. Returns `@` for the zone apex. Names are compared case-insensitively, a trailing dot is ignored " +
+			"and IDN names match in unicode and punycode spelling; the result is in punycode. Fails if the name is not inside the zone.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "fqdn",
+				Description: "Fully qualified name, e.g. grafana.ops.example.com.",
+			},
+			function.StringParameter{
+				Name:        "domain",
+				Description: "Domainname of the zone, e.g. example.com.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *relativeHostnameFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var fqdn, domain string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &fqdn, &domain))
+	if resp.Error != nil {
+		return
+	}
+
+	hostname, ok := relativeHostname(fqdn, domain)
+	if !ok {
+		resp.Error = function.NewArgumentFuncError(0, "The name "+fqdn+" is not part of the zone "+domain+".")
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, hostname))
+}
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRelativeHostnameFunction(t *testing.T) {
+	p := startTestProvider(t)
+
+	tests := []struct {
+		fqdn, domain string
+		want         string
+	}{
+		{"example.com", "example.com", "@"},
+		{"Example.COM.", "example.com.", "@"},
+		{"www.example.com", "example.com", "www"},
+		{"grafana.ops.example.com", "example.com", "grafana.ops"},
+		{"Grafana.Ops.Example.com.", "EXAMPLE.com", "grafana.ops"},
+		{"a.b.c.example.co.uk", "example.co.uk", "a.b.c"},
+		{"_dmarc.example.com", "example.com", "_dmarc"},
+		{"*.example.com", "example.com", "*"},
+		// IDN zones in unicode and punycode spelling
+		{"www.bücher.example", "bücher.example", "www"},
+		{"www.bücher.example", "xn--bcher-kva.example", "www"},
+		{"www.xn--bcher-kva.example", "Bücher.example", "www"},
+		{"bücher.example", "xn--bcher-kva.example", "@"},
+		{"café.bücher.example", "bücher.example", "xn--caf-dma"},
+	}
+	for _, tt := range tests {
+		got, err := p.callFunction("relative_hostname", tt.fqdn, tt.domain)
+		if err != nil {
+			t.Errorf("relative_hostname(%q, %q) failed: %s", tt.fqdn, tt.domain, err.Text)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("relative_hostname(%q, %q) = %q, want %q", tt.fqdn, tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestRelativeHostnameFunctionOutsideZone(t *testing.T) {
+	p := startTestProvider(t)
+
+	for _, args := range [][2]string{
+		{"www.example.org", "example.com"},
+		{"notexample.com", "example.com"},
+		{"example.com", "www.example.com"},
+		{"www.bücher.example", "buecher.example"},
+		{"", "example.com"},
+	} {
+		_, err := p.callFunction("relative_hostname", args[0], args[1])
+		if err == nil {
+			t.Errorf("relative_hostname(%q, %q) succeeded, want an error", args[0], args[1])
+			continue
+		}
+		if err.FunctionArgument == nil || *err.FunctionArgument != 0 {
+			t.Errorf("relative_hostname(%q, %q) failed without pointing at the fqdn: %+v", args[0], args[1], err)
+		}
+		if !strings.Contains(err.Text, "not part of the zone "+args[1]) {
+			t.Errorf("relative_hostname(%q, %q) failed with %q", args[0], args[1], err.Text)
+		}
+	}
+}
+
+// The function resolves names like the resources compare hostnames
+func TestRelativeHostnameMatchesRecordHostnames(t *testing.T) {
+	for _, fqdn := range []string{"example.com", "WWW.example.com", "grafana.ops.example.com", "_acme-challenge.Example.com"} {
+		hostname, ok := relativeHostname(fqdn, "example.com")
+		if !ok {
+			t.Fatalf("%s not inside example.com", fqdn)
+		}
+		if !hostnamesEqual(hostname, fqdn+".", "example.com") {
+			t.Errorf("relative hostname %q of %s is another name than %s. for netcupdns_record", hostname, fqdn, fqdn)
+		}
+		if normalizeHostname(hostname, "example.com") != hostname {
+			t.Errorf("relative hostname %q of %s isn't normalized", hostname, fqdn)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"strings"
+
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+	"github.com/svetob/terraform-provider-netcupdns/internal/dnstypes"
+	"golang.org/x/net/idna"
+)
+
+// Normalize a hostname relative to its zone: lowercase, with the apex
+// (empty, "@" or the bare domainname) represented as "@".
+func normalizeHostname(hostname, domainname string) string {
+	return client.NormalizeHostname(hostname, domainname)
+}
+
+// Check whether two hostnames of the same zone refer to the same name
+func hostnamesEqual(a, b, domainname string) bool {
+	return normalizeHostname(a, domainname) == normalizeHostname(b, domainname)
+}
+
+// Check whether two destinations of a record type are the same value, e.g. a TXT
+// value quoted by the API and its unquoted spelling in the configuration
+func destinationsEqual(recordType, a, b string) bool {
+	return dnstypes.NormalizeDestination(recordType, a) == dnstypes.NormalizeDestination(recordType, b)
+}
+
+// Convert a fully qualified name into a hostname relative to the zone.
+// Names are compared case-insensitively in their punycode form, so unicode
+// and punycode spellings of IDN zones match. The hostname is returned in
+// punycode, like the API stores it. Returns false if the name is not inside the zone.
+func relativeHostname(fqdn, domainname string) (string, bool) {
+	name := asciiName(fqdn)
+	zone := asciiName(domainname)
+
+	if name == zone {
+		return "@", true
+	}
+	if strings.HasSuffix(name, "."+zone) {
+		return strings.TrimSuffix(name, "."+zone), true
+	}
+	return "", false
+}
+
+// Lowercase punycode form of a name without trailing dot. Names that can't
+// be converted are only lowercased, e.g. ones containing underscores work as is.
+func asciiName(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if ascii, err := idna.ToASCII(name); err == nil {
+		return strings.ToLower(ascii)
+	}
+	return name
+}
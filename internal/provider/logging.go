@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+// Fields of a DNS record passed to tflog. Only these fields are logged, so
+// adding a field to the client structs never leaks it into the logs.
+func dnsRecordLogFields(domainname string, record client.DnsRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"domainname":  domainname,
+		"id":          record.Id,
+		"hostname":    record.Hostname,
+		"type":        record.Type,
+		"priority":    record.Priority,
+		"destination": record.Destination,
+		"state":       record.State,
+	}
+}
+
+func newDnsRecordLogFields(domainname string, record client.NewDnsRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"domainname":  domainname,
+		"hostname":    record.Hostname,
+		"type":        record.Type,
+		"priority":    record.Priority,
+		"destination": record.Destination,
+	}
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+// Fields of the log entries with message, without the fields every entry has
+func loggedFields(t *testing.T, output *bytes.Buffer, message string) [][]string {
+	t.Helper()
+
+	entries, err := tflogtest.MultilineJSONDecode(bytes.NewReader(output.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields [][]string
+	for _, entry := range entries {
+		if entry["@message"] != message {
+			continue
+		}
+		var names []string
+		for name := range entry {
+			if !strings.HasPrefix(name, "@") && !strings.HasPrefix(name, "tf_") {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		fields = append(fields, names)
+	}
+	return fields
+}
+
+// Number of API requests with action logged to output
+func loggedRequests(t *testing.T, output *bytes.Buffer, action string) int {
+	t.Helper()
+
+	entries, err := tflogtest.MultilineJSONDecode(bytes.NewReader(output.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, entry := range entries {
+		if entry["@message"] == "API request" && entry["action"] == action {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRecordLogFields(t *testing.T) {
+	var output bytes.Buffer
+	p := newTestProvider(t, nil)
+	p.captureLogs(&output)
+	domain := testDomain(t)
+
+	config := attrs{"domainname": domain, "hostname": "mail", "type": "MX", "priority": "10", "destination": "mx.example.com"}
+	created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+	p.read("netcupdns_record", created.State, created.Private)
+	config["destination"] = "mx2.example.com"
+	updated := p.apply("netcupdns_record", created.State, created.Private, config)
+	p.apply("netcupdns_record", updated.State, updated.Private, nil)
+
+	record := []string{"destination", "domainname", "hostname", "id", "priority", "state", "type"}
+	newRecord := []string{"destination", "domainname", "hostname", "priority", "type"}
+	for message, want := range map[string][]string{
+		"Create DNS Record":   newRecord,
+		"Got DNS Record":      record,
+		"Updating DNS Record": record,
+		"Deleting DNS Record": record,
+	} {
+		logged := loggedFields(t, &output, message)
+		if len(logged) == 0 {
+			t.Errorf("no log entry %q", message)
+		}
+		for _, fields := range logged {
+			if strings.Join(fields, ",") != strings.Join(want, ",") {
+				t.Errorf("%q logged fields %v, want %v", message, fields, want)
+			}
+		}
+	}
+
+	// the credentials of the provider configuration never reach the logs
+	for _, secret := range []string{"abcdefghijklmnopqrstuvwxyz", `"password"`} {
+		if strings.Contains(output.String(), secret) {
+			t.Errorf("logs contain the credential %s", secret)
+		}
+	}
+}
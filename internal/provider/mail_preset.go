@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"sort"
+	"strings"
+)
+
+// Version of the preset table, increased whenever a preset changes
+const mailPresetsVersion = 1
+
+// Records hosted mail providers expect in a zone
+type mailPreset struct {
+	MX []mailPresetMX
+	// Domain included by the SPF record
+	SPFInclude string
+	// Target of the autodiscover CNAME, empty if the provider uses none
+	Autodiscover string
+	// Whether the MX hosts are derived from the domain
+	PerDomain bool
+}
+
+type mailPresetMX struct {
+	Priority    int64
+	Destination string
+}
+
+var mailPresets = map[string]mailPreset{
+	"google_workspace": {
+		MX:         []mailPresetMX{{Priority: 1, Destination: "smtp.google.com."}},
+		SPFInclude: "_spf.google.com",
+	},
+	"microsoft365": {
+		MX:           []mailPresetMX{{Priority: 0, Destination: "{domain}.mail.protection.outlook.com."}},
+		SPFInclude:   "spf.protection.outlook.com",
+		Autodiscover: "autodiscover.outlook.com.",
+		PerDomain:    true,
+	},
+	"mailbox_org": {
+		MX: []mailPresetMX{
+			{Priority: 10, Destination: "mxext1.mailbox.org."},
+			{Priority: 10, Destination: "mxext2.mailbox.org."},
+			{Priority: 20, Destination: "mxext3.mailbox.org."},
+		},
+		SPFInclude: "mailbox.org",
+	},
+}
+
+func mailPresetNames() []string {
+	names := make([]string, 0, len(mailPresets))
+	for name := range mailPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MX records of a preset for a domain. Microsoft 365 derives its MX host from
+// the domain with dots replaced by hyphens.
+func (p mailPreset) mxFor(domain string) []mailPresetMX {
+	label := strings.ReplaceAll(strings.ToLower(strings.TrimSuffix(domain, ".")), ".", "-")
+	mx := make([]mailPresetMX, 0, len(p.MX))
+	for _, record := range p.MX {
+		record.Destination = strings.ReplaceAll(record.Destination, "{domain}", label)
+		mx = append(mx, record)
+	}
+	return mx
+}
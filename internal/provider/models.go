@@ -1,12 +1,271 @@
 package provider
 
-import "github.com/hashicorp/terraform-plugin-framework/types"
+import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/svetob/terraform-provider-netcupdns/internal/dnstypes"
+)
 
 type DnsRecord struct {
+	ID          types.String         `tfsdk:"id"`
+	Domainname  types.String         `tfsdk:"domainname"`
+	Hostname    dnstypes.Hostname    `tfsdk:"hostname"`
+	Type        dnstypes.RecordType  `tfsdk:"type"`
+	Priority    types.String         `tfsdk:"priority"`
+	Destination dnstypes.Destination `tfsdk:"destination"`
+	State       types.String         `tfsdk:"state"`
+
+	UpdatePolicy types.String `tfsdk:"update_policy"`
+	Comment      types.String `tfsdk:"comment"`
+	RecordID     types.String `tfsdk:"record_id"`
+	AllowAdopt   types.Bool   `tfsdk:"allow_adopt"`
+}
+
+type AutoconfigMail struct {
+	ID                types.String `tfsdk:"id"`
+	Domainname        types.String `tfsdk:"domainname"`
+	MailHost          types.String `tfsdk:"mail_host"`
+	Autoconfig        types.Bool   `tfsdk:"autoconfig"`
+	Autodiscover      types.Bool   `tfsdk:"autodiscover"`
+	AutodiscoverSrv   types.Bool   `tfsdk:"autodiscover_srv"`
+	ImapsSrv          types.Bool   `tfsdk:"imaps_srv"`
+	SubmissionSrv     types.Bool   `tfsdk:"submission_srv"`
+	AllowOverwrite    types.Bool   `tfsdk:"allow_overwrite"`
+	RollbackOnFailure types.Bool   `tfsdk:"rollback_on_failure"`
+	Records           types.List   `tfsdk:"records"`
+}
+
+// Nested record of resources managing a group of records
+type ManagedRecord struct {
 	ID          types.String `tfsdk:"id"`
+	Hostname    types.String `tfsdk:"hostname"`
+	Type        types.String `tfsdk:"type"`
+	Priority    types.String `tfsdk:"priority"`
+	Destination types.String `tfsdk:"destination"`
+}
+
+var managedRecordAttrTypes = map[string]attr.Type{
+	"id":          types.StringType,
+	"hostname":    types.StringType,
+	"type":        types.StringType,
+	"priority":    types.StringType,
+	"destination": types.StringType,
+}
+
+type RecordSet struct {
+	ID             types.String      `tfsdk:"id"`
+	Domainname     types.String      `tfsdk:"domainname"`
+	Records        []RecordSetRecord `tfsdk:"records"`
+	UpdateStrategy types.String      `tfsdk:"update_strategy"`
+	ManagedRecords types.List        `tfsdk:"managed_records"`
+}
+
+type RecordSetRecord struct {
+	Hostname    types.String `tfsdk:"hostname"`
+	Type        types.String `tfsdk:"type"`
+	Priority    types.String `tfsdk:"priority"`
+	Destination types.String `tfsdk:"destination"`
+}
+
+type SrvSet struct {
+	ID              types.String `tfsdk:"id"`
+	Domainname      types.String `tfsdk:"domainname"`
+	Service         types.String `tfsdk:"service"`
+	Protocol        types.String `tfsdk:"protocol"`
+	Name            types.String `tfsdk:"name"`
+	FailOnUnmanaged types.Bool   `tfsdk:"fail_on_unmanaged"`
+	Targets         []SrvTarget  `tfsdk:"targets"`
+	Records         types.List   `tfsdk:"records"`
+}
+
+type SrvTarget struct {
+	Priority types.Int64  `tfsdk:"priority"`
+	Weight   types.Int64  `tfsdk:"weight"`
+	Port     types.Int64  `tfsdk:"port"`
+	Target   types.String `tfsdk:"target"`
+}
+
+type DnsZone struct {
+	Domainname    types.String `tfsdk:"domainname"`
+	TTL           types.Int64  `tfsdk:"ttl"`
+	Serial        types.Int64  `tfsdk:"serial"`
+	Refresh       types.Int64  `tfsdk:"refresh"`
+	Retry         types.Int64  `tfsdk:"retry"`
+	Expire        types.Int64  `tfsdk:"expire"`
+	DnssecEnabled types.Bool   `tfsdk:"dnssec_enabled"`
+}
+
+type Zone struct {
+	ID         types.String `tfsdk:"id"`
+	Domainname types.String `tfsdk:"domainname"`
+	TTL        types.Int64  `tfsdk:"ttl"`
+	Refresh    types.Int64  `tfsdk:"refresh"`
+	Retry      types.Int64  `tfsdk:"retry"`
+	Expire     types.Int64  `tfsdk:"expire"`
+	Dnssec     types.Bool   `tfsdk:"dnssec"`
+}
+
+type Dnssec struct {
+	ID         types.String `tfsdk:"id"`
+	Domainname types.String `tfsdk:"domainname"`
+	Enabled    types.Bool   `tfsdk:"enabled"`
+}
+
+type DnsRecords struct {
+	Domainname    types.String                   `tfsdk:"domainname"`
+	Domainnames   []types.String                 `tfsdk:"domainnames"`
+	Type          types.String                   `tfsdk:"type"`
+	Hostname      types.String                   `tfsdk:"hostname"`
+	HostnameRegex types.String                   `tfsdk:"hostname_regex"`
+	FailOnError   types.Bool                     `tfsdk:"fail_on_error"`
+	Records       []DomainDnsRecordData          `tfsdk:"records"`
+	RecordsByKey  map[string]DomainDnsRecordData `tfsdk:"records_by_key"`
+	Errors        map[string]string              `tfsdk:"errors"`
+}
+
+// Record as exposed by data sources reading several zones
+type DomainDnsRecordData struct {
 	Domainname  types.String `tfsdk:"domainname"`
+	ID          types.String `tfsdk:"id"`
+	Hostname    types.String `tfsdk:"hostname"`
+	Type        types.String `tfsdk:"type"`
+	Priority    types.String `tfsdk:"priority"`
+	Destination types.String `tfsdk:"destination"`
+	State       types.String `tfsdk:"state"`
+}
+
+// Record as exposed by data sources
+type DnsRecordData struct {
+	ID          types.String `tfsdk:"id"`
 	Hostname    types.String `tfsdk:"hostname"`
 	Type        types.String `tfsdk:"type"`
 	Priority    types.String `tfsdk:"priority"`
 	Destination types.String `tfsdk:"destination"`
+	State       types.String `tfsdk:"state"`
+}
+
+type DnsRecordLookup struct {
+	ID          types.String `tfsdk:"id"`
+	Domainname  types.String `tfsdk:"domainname"`
+	Hostname    types.String `tfsdk:"hostname"`
+	Type        types.String `tfsdk:"type"`
+	Priority    types.String `tfsdk:"priority"`
+	Destination types.String `tfsdk:"destination"`
+	State       types.String `tfsdk:"state"`
+	FirstMatch  types.Bool   `tfsdk:"first_match"`
+}
+
+type DnssecStatus struct {
+	Domainname types.String `tfsdk:"domainname"`
+	Enabled    types.Bool   `tfsdk:"enabled"`
+}
+
+type DnsRecordId struct {
+	ID               types.String `tfsdk:"id"`
+	Domainname       types.String `tfsdk:"domainname"`
+	Hostname         types.String `tfsdk:"hostname"`
+	Type             types.String `tfsdk:"type"`
+	Destination      types.String `tfsdk:"destination"`
+	ImportId         types.String `tfsdk:"import_id"`
+	ImportIdByFields types.String `tfsdk:"import_id_by_fields"`
+}
+
+type DnsZoneFile struct {
+	Domainname   types.String   `tfsdk:"domainname"`
+	ExcludeTypes []types.String `tfsdk:"exclude_types"`
+	Content      types.String   `tfsdk:"content"`
+}
+
+type DnsResolve struct {
+	Name        types.String   `tfsdk:"name"`
+	Type        types.String   `tfsdk:"type"`
+	Domainname  types.String   `tfsdk:"domainname"`
+	Nameservers []types.String `tfsdk:"nameservers"`
+	Timeout     types.String   `tfsdk:"timeout"`
+	Answers     []types.String `tfsdk:"answers"`
+	NXDomain    types.Bool     `tfsdk:"nxdomain"`
+	Nameserver  types.String   `tfsdk:"nameserver"`
+	MatchesApi  types.Bool     `tfsdk:"matches_api"`
+}
+
+type DnsRecordSetData struct {
+	Domainname   types.String      `tfsdk:"domainname"`
+	Hostname     types.String      `tfsdk:"hostname"`
+	Type         types.String      `tfsdk:"type"`
+	AllowEmpty   types.Bool        `tfsdk:"allow_empty"`
+	Records      []RecordSetMember `tfsdk:"records"`
+	Destinations []types.String    `tfsdk:"destinations"`
+}
+
+type EffectiveRecordData struct {
+	Domainname     types.String      `tfsdk:"domainname"`
+	Hostname       types.String      `tfsdk:"hostname"`
+	Type           types.String      `tfsdk:"type"`
+	Chain          []types.String    `tfsdk:"chain"`
+	FinalHostname  types.String      `tfsdk:"final_hostname"`
+	External       types.Bool        `tfsdk:"external"`
+	ExternalTarget types.String      `tfsdk:"external_target"`
+	Records        []RecordSetMember `tfsdk:"records"`
+	Destinations   []types.String    `tfsdk:"destinations"`
+}
+
+type RecordSetMember struct {
+	ID          types.String `tfsdk:"id"`
+	Priority    types.String `tfsdk:"priority"`
+	Destination types.String `tfsdk:"destination"`
+}
+
+type TxtRecords struct {
+	Domainname types.String    `tfsdk:"domainname"`
+	Hostname   types.String    `tfsdk:"hostname"`
+	Values     []types.String  `tfsdk:"values"`
+	Records    []DnsRecordData `tfsdk:"records"`
+}
+
+type MxRecords struct {
+	Domainname types.String `tfsdk:"domainname"`
+	Hostname   types.String `tfsdk:"hostname"`
+	Records    []MxRecord   `tfsdk:"records"`
+}
+
+type MxRecord struct {
+	Priority    types.Int64  `tfsdk:"priority"`
+	Destination types.String `tfsdk:"destination"`
+}
+
+type DnsRecordExists struct {
+	Domainname  types.String `tfsdk:"domainname"`
+	Hostname    types.String `tfsdk:"hostname"`
+	Type        types.String `tfsdk:"type"`
+	Destination types.String `tfsdk:"destination"`
+	Exists      types.Bool   `tfsdk:"exists"`
+	Matches     types.Int64  `tfsdk:"matches"`
+}
+
+type DnsZoneSerial struct {
+	Domainname   types.String `tfsdk:"domainname"`
+	UseCache     types.Bool   `tfsdk:"use_cache"`
+	Serial       types.String `tfsdk:"serial"`
+	SerialNumber types.Int64  `tfsdk:"serial_number"`
+}
+
+type DnsPropagationStatus struct {
+	Name                 types.String        `tfsdk:"name"`
+	Type                 types.String        `tfsdk:"type"`
+	Expected             []types.String      `tfsdk:"expected"`
+	Resolvers            []types.String      `tfsdk:"resolvers"`
+	ConsistencyThreshold types.Int64         `tfsdk:"consistency_threshold"`
+	Timeout              types.String        `tfsdk:"timeout"`
+	Results              []PropagationResult `tfsdk:"results"`
+	Consistent           types.Bool          `tfsdk:"consistent"`
+	Percentage           types.Float64       `tfsdk:"percentage"`
+}
+
+// Answer of a single resolver of netcupdns_propagation_status
+type PropagationResult struct {
+	Nameserver types.String   `tfsdk:"nameserver"`
+	Answers    []types.String `tfsdk:"answers"`
+	NXDomain   types.Bool     `tfsdk:"nxdomain"`
+	Error      types.String   `tfsdk:"error"`
+	Matches    types.Bool     `tfsdk:"matches"`
 }
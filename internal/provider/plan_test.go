@@ -0,0 +1,291 @@
+package provider
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// Tests of this file validate and plan only, with an unconfigured provider
+// that can't reach any API. They cover the validators, defaults and plan
+// modifiers of the resources, and run in milliseconds. Normalized values of
+// the API are covered by the apply tests of each resource.
+
+type planCase struct {
+	name string
+	// state before the plan, nil to plan a create
+	prior  attrs
+	config attrs
+	// summaries of the diagnostics, and a part of the detail of the first one
+	errors, warnings []string
+	detail           string
+	// planned attributes as attrString, "<unknown>" for unknown values
+	planned map[string]string
+	// attributes whose change replaces the resource
+	replace []string
+}
+
+// Planned attribute as attrString, also for unknown values
+func plannedString(t *testing.T, planned tftypes.Value, name string) string {
+	t.Helper()
+	if !attrValue(t, planned, name).IsKnown() {
+		return "<unknown>"
+	}
+	return attrString(t, planned, name)
+}
+
+func runPlanCases(t *testing.T, typeName string, tests []planCase) {
+	t.Helper()
+	p := startTestProvider(t)
+	s := p.resourceSchema(typeName)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prior := nullState(p, typeName)
+			if tt.prior != nil {
+				prior = toValue(t, s.ValueType(), map[string]interface{}(tt.prior))
+			}
+
+			diags := p.validate(typeName, tt.config)
+			var resp *tfprotov6.PlanResourceChangeResponse
+			if !hasErrors(diags) {
+				resp = p.planResourceChange(typeName, prior, nil, tt.config)
+				diags = append(diags, resp.Diagnostics...)
+			}
+
+			if got := summaries(diags, tfprotov6.DiagnosticSeverityError); strings.Join(got, ",") != strings.Join(tt.errors, ",") {
+				t.Errorf("got errors %q, want %q", got, tt.errors)
+			}
+			if got := summaries(diags, tfprotov6.DiagnosticSeverityWarning); strings.Join(got, ",") != strings.Join(tt.warnings, ",") {
+				t.Errorf("got warnings %q, want %q", got, tt.warnings)
+			}
+			if tt.detail != "" && (len(diags) == 0 || !strings.Contains(diags[0].Detail, tt.detail)) {
+				t.Errorf("got diagnostics %v, want the first with %q in its detail", diags, tt.detail)
+			}
+			if resp == nil || hasErrors(diags) {
+				return
+			}
+
+			planned := p.decode(s, resp.PlannedState)
+			for name, want := range tt.planned {
+				if got := plannedString(t, planned, name); got != want {
+					t.Errorf("planned %s = %q, want %q", name, got, want)
+				}
+			}
+			replace := []string{}
+			for _, path := range resp.RequiresReplace {
+				replace = append(replace, path.String())
+			}
+			sort.Strings(replace)
+			want := []string{}
+			for _, name := range tt.replace {
+				want = append(want, tftypes.NewAttributePath().WithAttributeName(name).String())
+			}
+			sort.Strings(want)
+			if !reflect.DeepEqual(replace, want) {
+				t.Errorf("replaces the resource for %v, want %v", replace, want)
+			}
+		})
+	}
+}
+
+func TestPlanDnsRecord(t *testing.T) {
+	record := func(changes attrs) attrs {
+		config := attrs{"domainname": "example.com", "hostname": "www", "type": "A", "destination": "192.0.2.1"}
+		for name, value := range changes {
+			config[name] = value
+		}
+		return config
+	}
+	state := func(changes attrs) attrs {
+		prior := attrs{"id": "1", "domainname": "example.com", "hostname": "www", "type": "A", "priority": "0",
+			"destination": "192.0.2.1", "state": "yes", "update_policy": "always"}
+		for name, value := range changes {
+			prior[name] = value
+		}
+		return prior
+	}
+
+	runPlanCases(t, "netcupdns_record", []planCase{
+		// validators
+		{name: "empty label", config: record(attrs{"hostname": "www..dev"}), errors: []string{"Invalid hostname"}, detail: "contains an empty label"},
+		{name: "leading hyphen", config: record(attrs{"hostname": "-www"}), errors: []string{"Invalid hostname"}, detail: "starts or ends with a hyphen"},
+		{name: "invalid character", config: record(attrs{"hostname": "www dev"}), errors: []string{"Invalid hostname"}, detail: "invalid character"},
+		{name: "hostname outside zone", config: record(attrs{"hostname": "www.example.org."}), errors: []string{"Hostname outside of zone"}},
+		{name: "unsupported type", config: record(attrs{"type": "PTR"}), errors: []string{"Unsupported record type"}, detail: `got "PTR"`},
+		{name: "MX without priority", config: record(attrs{"type": "MX", "destination": "mail.example.com"}), errors: []string{"Missing priority"}},
+		{name: "SRV without priority", config: record(attrs{"hostname": "_sip._udp", "type": "srv", "destination": "0 5060 sip.example.com."}),
+			errors: []string{"Missing priority"}, detail: "required for SRV records"},
+		{name: "priority of an A record", config: record(attrs{"priority": "10"}), errors: []string{"Priority not supported"}},
+		{name: "invalid update policy", config: record(attrs{"update_policy": "sometimes"}), errors: []string{"Invalid update policy"}},
+		{name: "record_id with allow_adopt", config: record(attrs{"record_id": "1", "allow_adopt": true}), errors: []string{"Conflicting attributes"}},
+
+		// defaults and planned values of a create
+		{name: "create", config: record(nil),
+			planned: map[string]string{"id": "<unknown>", "update_policy": "always", "priority": "<unknown>", "state": "<unknown>", "hostname": "www"}},
+		{name: "create MX", config: record(attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"}),
+			planned: map[string]string{"priority": "10", "type": "MX"}},
+		{name: "priority 0 of an A record", config: record(attrs{"priority": "0"}), planned: map[string]string{"priority": "0"}},
+
+		// configured values are planned as written, only the applied state holds
+		// the form of the API, or Terraform rejects the plan
+		{name: "mixed-case hostname", config: record(attrs{"hostname": "WWW"}), planned: map[string]string{"hostname": "WWW"}},
+		{name: "lower-case type", config: record(attrs{"type": "a"}), planned: map[string]string{"type": "a"}},
+		{name: "padded destination", config: record(attrs{"destination": " 192.0.2.1 "}), planned: map[string]string{"destination": " 192.0.2.1 "}},
+		{name: "quoted TXT", config: record(attrs{"type": "TXT", "destination": `"v=spf1 -all"`}), planned: map[string]string{"destination": `"v=spf1 -all"`}},
+		{name: "absolute hostname", config: record(attrs{"hostname": "www.example.com."}), planned: map[string]string{"hostname": "www.example.com."}},
+		{name: "unchanged", prior: state(attrs{"hostname": "WWW", "type": "a"}), config: record(attrs{"hostname": "WWW", "type": "a"}),
+			planned: map[string]string{"id": "1", "state": "yes", "hostname": "WWW", "priority": "0"}},
+
+		// priority and in-place updates
+		{name: "priority kept without configuration", prior: state(nil), config: record(attrs{"destination": "192.0.2.2"}),
+			planned: map[string]string{"priority": "0", "destination": "192.0.2.2"}},
+		{name: "changed priority", prior: state(attrs{"type": "MX", "priority": "10", "destination": "mail.example.com"}),
+			config: record(attrs{"type": "MX", "priority": "20", "destination": "mail.example.com"}), planned: map[string]string{"priority": "20"}},
+		{name: "changed type", prior: state(nil), config: record(attrs{"hostname": "api", "type": "AAAA", "destination": "2001:db8::1"}),
+			planned: map[string]string{"hostname": "api", "type": "AAAA", "priority": "<unknown>"}},
+		{name: "comment only", prior: state(nil), config: record(attrs{"comment": "owned by the web team"}), planned: map[string]string{"comment": "owned by the web team"}},
+
+		// create_only replaces the record instead of updating it
+		{name: "create_only changed hostname", prior: state(attrs{"update_policy": "create_only"}),
+			config: record(attrs{"update_policy": "create_only", "hostname": "api"}), replace: []string{"hostname"}},
+		{name: "create_only changed type and domain", prior: state(attrs{"update_policy": "create_only"}),
+			config:  record(attrs{"update_policy": "create_only", "domainname": "example.org", "type": "AAAA", "destination": "2001:db8::1"}),
+			replace: []string{"domainname", "type"}},
+		{name: "create_only changed destination", prior: state(attrs{"update_policy": "create_only"}),
+			config: record(attrs{"update_policy": "create_only", "destination": "192.0.2.2"}), planned: map[string]string{"destination": "192.0.2.2"}},
+	})
+}
+
+func TestPlanRecordSet(t *testing.T) {
+	set := func(records ...attrs) attrs {
+		return recordSetConfig("example.com", records...)
+	}
+	www := attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"}
+	prior := attrs{"id": "example.com", "domainname": "example.com", "update_strategy": "replace",
+		"records":         []interface{}{map[string]interface{}{"hostname": "www", "type": "A", "destination": "192.0.2.1"}},
+		"managed_records": []interface{}{map[string]interface{}{"id": "1", "hostname": "www", "type": "A", "priority": "0", "destination": "192.0.2.1"}},
+	}
+
+	runPlanCases(t, "netcupdns_record_set", []planCase{
+		{name: "invalid hostname", config: set(attrs{"hostname": "-www", "type": "A", "destination": "192.0.2.1"}), errors: []string{"Invalid hostname"}},
+		{name: "unsupported type", config: set(attrs{"hostname": "www", "type": "SOA", "destination": "x"}), errors: []string{"Unsupported record type"}},
+		{name: "MX without priority", config: set(attrs{"hostname": "@", "type": "MX", "destination": "mail.example.com"}), errors: []string{"Missing priority"}},
+		{name: "priority of an A record", config: set(attrs{"hostname": "www", "type": "A", "priority": "5", "destination": "192.0.2.1"}), errors: []string{"Unsupported priority"}},
+		{name: "duplicate record", config: set(www, attrs{"hostname": "WWW", "type": "a", "destination": " 192.0.2.1 "}), errors: []string{"Duplicate record"}},
+		{name: "no records", config: set(), errors: []string{"No records"}},
+		{name: "invalid update strategy", config: func() attrs { c := set(www); c["update_strategy"] = "merge"; return c }(), errors: []string{"Invalid update strategy"}},
+
+		{name: "create", config: set(www), planned: map[string]string{"id": "<unknown>", "update_strategy": "incremental", "managed_records": "<unknown>"}},
+		{name: "changed domain", prior: prior, config: func() attrs {
+			c := set(www)
+			c["domainname"] = "example.org"
+			c["update_strategy"] = "replace"
+			return c
+		}(),
+			replace: []string{"domainname"}},
+		{name: "replace strategy", prior: prior, config: func() attrs {
+			c := set(www, attrs{"hostname": "api", "type": "A", "destination": "192.0.2.2"})
+			c["update_strategy"] = "replace"
+			return c
+		}(), warnings: []string{"Record set is replaced"}},
+		{name: "replace strategy without changes", prior: prior, config: func() attrs { c := set(www); c["update_strategy"] = "replace"; return c }(),
+			planned: map[string]string{"id": "example.com"}},
+	})
+}
+
+func TestPlanSrvSet(t *testing.T) {
+	targets := func(values ...map[string]interface{}) []interface{} {
+		result := []interface{}{}
+		for _, value := range values {
+			result = append(result, value)
+		}
+		return result
+	}
+	srv := func(changes attrs) attrs {
+		config := attrs{"domainname": "example.com", "service": "sip", "protocol": "udp", "targets": targets(srvTarget(10, 10, 5060, "sip.example.com"))}
+		for name, value := range changes {
+			config[name] = value
+		}
+		return config
+	}
+	prior := attrs{"id": "example.com/sip/udp", "domainname": "example.com", "service": "sip", "protocol": "udp",
+		"targets": targets(srvTarget(10, 10, 5060, "sip.example.com")),
+		"records": []interface{}{map[string]interface{}{"id": "1", "hostname": "_sip._udp", "type": "SRV", "priority": "10", "destination": "10 5060 sip.example.com."}},
+	}
+
+	runPlanCases(t, "netcupdns_srv_set", []planCase{
+		{name: "invalid service", config: srv(attrs{"service": "sip udp"}), errors: []string{"Invalid service"}},
+		{name: "invalid protocol", config: srv(attrs{"protocol": "_u.dp"}), errors: []string{"Invalid protocol"}},
+		{name: "no targets", config: srv(attrs{"targets": targets()}), errors: []string{"No targets"}},
+		{name: "duplicate target", config: srv(attrs{"targets": targets(srvTarget(10, 10, 5060, "sip.example.com"), srvTarget(10, 20, 5061, "sip.example.com."))}),
+			errors: []string{"Duplicate target"}},
+		{name: "negative priority", config: srv(attrs{"targets": targets(srvTarget(-1, 10, 5060, "sip.example.com"))}), errors: []string{"Invalid priority"}},
+		{name: "weight too large", config: srv(attrs{"targets": targets(srvTarget(10, 65536, 5060, "sip.example.com"))}), errors: []string{"Invalid weight"}},
+		{name: "empty target", config: srv(attrs{"targets": targets(srvTarget(10, 10, 5060, ""))}), errors: []string{"Invalid target"}},
+
+		{name: "create", config: srv(nil), planned: map[string]string{"id": "<unknown>", "records": "<unknown>"}},
+		{name: "changed weight", prior: prior, config: srv(attrs{"targets": targets(srvTarget(10, 20, 5060, "sip.example.com"))}),
+			planned: map[string]string{"id": "example.com/sip/udp"}},
+		{name: "changed service", prior: prior, config: srv(attrs{"service": "sips"}), replace: []string{"service"}},
+		{name: "changed protocol and name", prior: prior, config: srv(attrs{"protocol": "tcp", "name": "office"}), replace: []string{"name", "protocol"}},
+		{name: "changed domain", prior: prior, config: srv(attrs{"domainname": "example.org"}), replace: []string{"domainname"}},
+	})
+}
+
+func TestPlanZone(t *testing.T) {
+	zone := func(changes attrs) attrs {
+		config := attrs{"domainname": "example.com"}
+		for name, value := range changes {
+			config[name] = value
+		}
+		return config
+	}
+	prior := attrs{"id": "example.com", "domainname": "example.com", "ttl": 86400, "refresh": 28800, "retry": 7200, "expire": 1209600}
+
+	runPlanCases(t, "netcupdns_zone", []planCase{
+		{name: "zero ttl", config: zone(attrs{"ttl": 0}), errors: []string{"Invalid ttl"}, detail: "positive number"},
+		{name: "negative refresh", config: zone(attrs{"refresh": -1}), errors: []string{"Invalid refresh"}},
+		{name: "ttl beyond 31 bit", config: zone(attrs{"ttl": 1 << 31}), errors: []string{"Invalid ttl"}, detail: "at most 2147483647"},
+		{name: "retry not shorter than refresh", config: zone(attrs{"refresh": 3600, "retry": 3600}), errors: []string{"Invalid retry"}},
+		{name: "expire not longer than refresh", config: zone(attrs{"refresh": 3600, "expire": 3600}), errors: []string{"Invalid expire"}},
+		{name: "expire not longer than retry", config: zone(attrs{"retry": 7200, "expire": 3600}), errors: []string{"Invalid expire"}},
+		{name: "low ttl", config: zone(attrs{"ttl": 30}), warnings: []string{"Low TTL"}},
+		{name: "high expire", config: zone(attrs{"expire": 5 * 7 * 24 * 3600}), warnings: []string{"High expire"}},
+
+		{name: "create", config: zone(nil), planned: map[string]string{"id": "<unknown>", "ttl": "<unknown>", "expire": "<unknown>"}},
+		{name: "unconfigured settings keep the state", prior: prior, config: zone(attrs{"ttl": 300}),
+			planned: map[string]string{"id": "example.com", "ttl": "300", "refresh": "28800", "retry": "7200", "expire": "1209600"}},
+		{name: "changed domain", prior: prior, config: zone(attrs{"domainname": "example.org"}), replace: []string{"domainname"}},
+	})
+}
+
+func TestPlanAutoconfigMail(t *testing.T) {
+	mail := func(changes attrs) attrs {
+		config := attrs{"domainname": "example.com", "mail_host": "mail.example.com"}
+		for name, value := range changes {
+			config[name] = value
+		}
+		return config
+	}
+	prior := attrs{"id": "example.com", "domainname": "example.com", "mail_host": "mail.example.com",
+		"autoconfig": true, "autodiscover": true, "autodiscover_srv": true, "imaps_srv": false, "submission_srv": false,
+		"allow_overwrite": false, "rollback_on_failure": false,
+		"records": []interface{}{map[string]interface{}{"id": "1", "hostname": "autoconfig", "type": "CNAME", "priority": "0", "destination": "mail.example.com."}},
+	}
+
+	runPlanCases(t, "netcupdns_autoconfig_mail", []planCase{
+		{name: "create", config: mail(nil), planned: map[string]string{
+			"id": "<unknown>", "autoconfig": "true", "autodiscover": "true", "autodiscover_srv": "true",
+			"imaps_srv": "false", "submission_srv": "false", "allow_overwrite": "false", "rollback_on_failure": "false",
+		}},
+		{name: "unchanged", prior: prior, config: mail(nil), planned: map[string]string{"id": "example.com"}},
+		{name: "changed mail host", prior: prior, config: mail(attrs{"mail_host": "mx.example.com"}), replace: []string{"mail_host"}},
+		{name: "enabled imaps", prior: prior, config: mail(attrs{"imaps_srv": true}), replace: []string{"imaps_srv"}},
+		{name: "changed domain", prior: prior, config: mail(attrs{"domainname": "example.org"}), replace: []string{"domainname"}},
+		{name: "allow_overwrite doesn't replace", prior: prior, config: mail(attrs{"allow_overwrite": true}), planned: map[string]string{"allow_overwrite": "true"}},
+	})
+}
@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/svetob/terraform-provider-netcupdns/internal/dnstypes"
+)
+
+// Record types the API stores a priority for. It zeroes the priority of all other types.
+var priorityRecordTypes = map[string]bool{"MX": true, "SRV": true}
+
+// Plans the priority of a record the way the API will store it, so the state
+// written after apply matches the plan
+func priorityPlanModifier() planmodifier.String {
+	return priorityModifier{}
+}
+
+type priorityModifier struct{}
+
+func (m priorityModifier) Description(_ context.Context) string {
+	return "Keeps the prior priority if none is configured and rejects priorities the API would drop."
+}
+
+func (m priorityModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m priorityModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	// nothing to plan on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var recordType dnstypes.RecordType
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("type"), &recordType)...)
+	if resp.Diagnostics.HasError() || recordType.IsUnknown() || recordType.IsNull() {
+		return
+	}
+
+	if !req.ConfigValue.IsNull() {
+		priority := req.ConfigValue.ValueString()
+		if !req.ConfigValue.IsUnknown() && !priorityRecordTypes[strings.ToUpper(recordType.ValueString())] && priority != "" && priority != "0" {
+			resp.Diagnostics.AddAttributeError(
+				req.Path,
+				"Priority not supported",
+				"The API stores a priority only for MX and SRV records and would reset priority \""+priority+"\" of this "+
+					strings.ToUpper(recordType.ValueString())+" record to 0. Remove the priority from the configuration.",
+			)
+		}
+		return
+	}
+
+	// without a configured priority the API keeps the current one, unless the type changes
+	if req.State.Raw.IsNull() || req.StateValue.IsNull() {
+		return
+	}
+	var priorRecordType dnstypes.RecordType
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("type"), &priorRecordType)...)
+	if resp.Diagnostics.HasError() || !strings.EqualFold(priorRecordType.ValueString(), recordType.ValueString()) {
+		return
+	}
+	resp.PlanValue = req.StateValue
+}
@@ -2,26 +2,38 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/svetob/terraform-provider-netcupdns/internal/client"
 )
 
 // Ensure the implementation satisfies the expected interfaces
 var (
-	_ provider.Provider = &netcupCcpProvider{}
+	_ provider.Provider              = &netcupCcpProvider{}
+	_ provider.ProviderWithFunctions = &netcupCcpProvider{}
 )
 
 func New() provider.Provider {
 	return &netcupCcpProvider{}
 }
 
-type netcupCcpProvider struct{}
+type netcupCcpProvider struct {
+	// client of the last Configure, released when configured again
+	client *client.CCPClient
+}
 
 func (p *netcupCcpProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "netcupdns"
@@ -34,17 +46,106 @@ func (p *netcupCcpProvider) Schema(_ context.Context, req provider.SchemaRequest
 		Attributes: map[string]schema.Attribute{
 			"customer_number": schema.StringAttribute{
 				Optional:            true,
-				MarkdownDescription: "Netcup customer number. Alternative defined by env `NETCUP_CUSTOMER_NUMBER`",
+				MarkdownDescription: "Netcup customer number. Alternative defined by env `NETCUP_CUSTOMER_NUMBER`, see `env_prefix`",
 			},
 			"key": schema.StringAttribute{
 				Optional:            true,
 				Sensitive:           true,
-				MarkdownDescription: "Netcup CCP API key. Alternative defined by env `NETCUP_API_KEY`",
+				MarkdownDescription: "Netcup CCP API key. Alternative defined by env `NETCUP_API_KEY`, see `env_prefix`",
 			},
 			"password": schema.StringAttribute{
 				Optional:            true,
 				Sensitive:           true,
-				MarkdownDescription: "Netcup CCP API password. Alternative defined by env `NETCUP_API_PASSWORD`",
+				MarkdownDescription: "Netcup CCP API password. Alternative defined by env `NETCUP_API_PASSWORD`, see `env_prefix`",
+			},
+			"env_prefix": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Prefix of the environment variables the credentials are read from, e.g. `ACCOUNT_B` reads `ACCOUNT_B_NETCUP_CUSTOMER_NUMBER`, " +
+					"`ACCOUNT_B_NETCUP_API_KEY` and `ACCOUNT_B_NETCUP_API_PASSWORD`. Allows provider aliases for several accounts to take their credentials from the environment. " +
+					"Defaults to the unprefixed names",
+			},
+			"mock": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Use an in-memory fake of the API instead of Netcup, e.g. to run plan and apply of modules in CI without network and credentials. " +
+					"**No real DNS records are read or changed.** Zones are created empty on first use, except of domains of the reserved top-level domain `.invalid` which fail like domains of another account, and are lost when the provider process ends, " +
+					"so records created by an earlier run are not found on refresh. Credentials are neither required nor checked. Defaults to `false`",
+			},
+			"endpoint": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "URL of the CCP API as absolute `https` URL, e.g. of a test server. Alternative defined by env `NETCUP_API_ENDPOINT`, see `env_prefix`. " +
+					"Ignored if `mock` is enabled. Defaults to `" + client.HostURL + "`",
+			},
+			"request_timeout": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Maximum time a single API request may take, like `30s`, e.g. for large zones. Retries get the same time again. Defaults to `10s`",
+			},
+			"dnssec_warning": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Warn once per domain when records of a DNSSEC-signed zone are changed, as they are served only after re-signing. Defaults to `true`",
+			},
+			"wait_for_zone_update": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Wait after every write until the serial of the published zone increased. Shows a warning if it doesn't within `zone_update_timeout`. Defaults to `false`",
+			},
+			"zone_update_timeout": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Maximum time to wait for a zone update, like `90s` or `5m`. Defaults to `5m`",
+			},
+			"drift_warnings": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Add a warning for every resource changed outside of Terraform on refresh, listing the old and new value of each changed attribute, " +
+					"e.g. for drift reports of scheduled plans. Not shown on the first read after create or import. Defaults to `false`",
+			},
+			"skip_refresh": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Keep the prior state of resources on refresh instead of reading them from the API. Speeds up plans of large zones, " +
+					"but **drift is no longer detected**, also not by `terraform plan -refresh-only`. Resources are still read after create and import. " +
+					"Set it from a variable to run real refreshes, e.g. `-refresh-only -var skip_refresh=false`. Defaults to `false`",
+			},
+			"record_cache_size": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Maximum number of domains whose records are cached during a run. Least recently used domains are evicted and read again when needed. Defaults to `100`",
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Maximum number of requests sent to the API at the same time, e.g. when a data source reads several zones. Defaults to `4`",
+			},
+			"max_retries": schema.Int64Attribute{
+				Optional: true,
+				MarkdownDescription: "Maximum number of retries of a rate limited request or a request failing without response, like a timeout. " +
+					"`0` disables these retries. Changes blocked by another change of the zone are retried separately, see `zone_locked_statuscodes`. Defaults to `5`",
+			},
+			"retry_base_delay": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Delay before the first retry of a request, like `500ms` or `2s`. The delay doubles with every retry up to 30 seconds, or the base delay if it is longer, " +
+					"randomly shortened by up to half to spread retries of parallel requests. Defaults to `1s`",
+			},
+			"retry_on_statuscodes": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.Int64Type,
+				MarkdownDescription: "Additional statuscodes to retry like rate limited requests, either HTTP status codes like `503` or statuscodes of the API response. " +
+					"Allows to retry new transient errors before the provider knows them.",
+			},
+			"never_retry_statuscodes": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.Int64Type,
+				MarkdownDescription: "Statuscodes that are never retried, also if retried by default like HTTP status `429` and API statuscode `4013` reporting the rate limit. Takes precedence over `retry_on_statuscodes`.",
+			},
+			"zone_locked_statuscodes": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.Int64Type,
+				MarkdownDescription: "API statuscodes of changes rejected because another change of the zone is still processed, e.g. one made seconds earlier in the customer control panel. " +
+					"These changes are retried up to 8 times over several minutes. Netcup documents no statuscode for it, so none is known by default; add the one your responses show. " +
+					"`never_retry_statuscodes` takes precedence.",
+			},
+			"strict_api_decoding": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Log a warning for each field of an API response the provider doesn't know, once per field, as early signal of API changes. " +
+					"Unknown fields never fail an operation. Defaults to `true` if `TF_LOG` or `TF_LOG_PROVIDER` is `TRACE`, otherwise `false`",
+			},
+			"rate_limit_warning_threshold": schema.Float64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Share of the API rate limit (180 requests per minute) after which a warning is shown, between 0 and 1. Defaults to `0.8`",
 			},
 		},
 	}
@@ -55,6 +156,26 @@ type providerData struct {
 	CustomerNumber types.String `tfsdk:"customer_number"`
 	Key            types.String `tfsdk:"key"`
 	Password       types.String `tfsdk:"password"`
+
+	EnvPrefix types.String `tfsdk:"env_prefix"`
+	Endpoint  types.String `tfsdk:"endpoint"`
+	Mock      types.Bool   `tfsdk:"mock"`
+
+	DnssecWarning             types.Bool    `tfsdk:"dnssec_warning"`
+	DriftWarnings             types.Bool    `tfsdk:"drift_warnings"`
+	MaxConcurrentRequests     types.Int64   `tfsdk:"max_concurrent_requests"`
+	MaxRetries                types.Int64   `tfsdk:"max_retries"`
+	RetryBaseDelay            types.String  `tfsdk:"retry_base_delay"`
+	RecordCacheSize           types.Int64   `tfsdk:"record_cache_size"`
+	SkipRefresh               types.Bool    `tfsdk:"skip_refresh"`
+	StrictApiDecoding         types.Bool    `tfsdk:"strict_api_decoding"`
+	WaitForZoneUpdate         types.Bool    `tfsdk:"wait_for_zone_update"`
+	ZoneUpdateTimeout         types.String  `tfsdk:"zone_update_timeout"`
+	RateLimitWarningThreshold types.Float64 `tfsdk:"rate_limit_warning_threshold"`
+	RequestTimeout            types.String  `tfsdk:"request_timeout"`
+	RetryOnStatuscodes        []types.Int64 `tfsdk:"retry_on_statuscodes"`
+	NeverRetryStatuscodes     []types.Int64 `tfsdk:"never_retry_statuscodes"`
+	ZoneLockedStatuscodes     []types.Int64 `tfsdk:"zone_locked_statuscodes"`
 }
 
 func (p *netcupCcpProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
@@ -66,96 +187,349 @@ func (p *netcupCcpProvider) Configure(ctx context.Context, req provider.Configur
 		return
 	}
 
-	// User must provide a user to the provider
-	var customerNumber string
-	if config.CustomerNumber.IsUnknown() {
-		// Cannot connect to client with an unknown value
+	// Values of other resources are unknown until those are applied. Without a
+	// client every operation needing the API fails with addNotConfiguredError.
+	if unknown := unknownProviderAttributes(config); len(unknown) > 0 {
 		resp.Diagnostics.AddWarning(
-			"Unable to create client",
-			"Cannot use unknown value as customer number",
+			"Provider configuration unknown",
+			"The provider attributes "+strings.Join(unknown, ", ")+" depend on values unknown until other resources are applied. "+
+				"New resources can be planned, but resources and data sources reading from the API fail until the values are known.",
 		)
 		return
 	}
 
-	if config.CustomerNumber.IsNull() {
-		customerNumber = os.Getenv("NETCUP_CUSTOMER_NUMBER")
+	mock := config.Mock.ValueBool()
+	var customerNumber, ccpApiKey, ccpApiPassword string
+	if mock {
+		tflog.Warn(ctx, "mock is enabled, no real DNS records are read or changed")
+		customerNumber, ccpApiKey, ccpApiPassword = mockCustomerNumber, mockApiKey, mockApiPassword
 	} else {
-		customerNumber = config.CustomerNumber.ValueString()
+		var ok bool
+		customerNumber, ccpApiKey, ccpApiPassword, ok = configuredCredentials(config, &resp.Diagnostics)
+		if !ok {
+			return
+		}
 	}
 
-	if customerNumber == "" {
-		resp.Diagnostics.AddError(
-			"Unable to find customer number",
-			"Customer number cannot be an empty string",
+	rateLimitWarningThreshold := client.DefaultRateLimitWarningThreshold
+	if !config.RateLimitWarningThreshold.IsNull() && !config.RateLimitWarningThreshold.IsUnknown() {
+		rateLimitWarningThreshold = config.RateLimitWarningThreshold.ValueFloat64()
+	}
+
+	if rateLimitWarningThreshold <= 0 || rateLimitWarningThreshold > 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rate_limit_warning_threshold"),
+			"Invalid rate limit warning threshold",
+			"Rate limit warning threshold must be greater than 0 and at most 1",
 		)
 		return
 	}
 
-	var ccpApiPassword string
-	if config.Password.IsUnknown() {
+	zoneUpdateTimeout := client.DefaultZoneUpdateTimeout
+	if !config.ZoneUpdateTimeout.IsNull() && !config.ZoneUpdateTimeout.IsUnknown() {
+		timeout, err := time.ParseDuration(config.ZoneUpdateTimeout.ValueString())
+		if err != nil || timeout <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("zone_update_timeout"),
+				"Invalid zone update timeout",
+				"Zone update timeout must be a positive duration like \"5m\", got \""+config.ZoneUpdateTimeout.ValueString()+"\"",
+			)
+			return
+		}
+		zoneUpdateTimeout = timeout
+	}
+
+	var opts []client.Option
+	endpointEnv := credentialEnv(config.EnvPrefix.ValueString(), "NETCUP_API_ENDPOINT")
+	endpoint := config.Endpoint.ValueString()
+	if config.Endpoint.IsNull() {
+		endpoint = os.Getenv(endpointEnv)
+	}
+	if endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("endpoint"),
+				"Invalid endpoint",
+				"The endpoint must be an absolute https URL like \""+client.HostURL+"\", got \""+endpoint+"\". Set endpoint or the environment variable "+endpointEnv,
+			)
+			return
+		}
+		opts = append(opts, client.WithEndpoint(endpoint))
+	}
+	if !config.RequestTimeout.IsNull() && !config.RequestTimeout.IsUnknown() {
+		timeout, err := time.ParseDuration(config.RequestTimeout.ValueString())
+		if err != nil || timeout <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("request_timeout"),
+				"Invalid request timeout",
+				"Request timeout must be a positive duration like \"30s\", got \""+config.RequestTimeout.ValueString()+"\"",
+			)
+			return
+		}
+		opts = append(opts, client.WithRequestTimeout(timeout))
+	}
+	if !config.RecordCacheSize.IsNull() && !config.RecordCacheSize.IsUnknown() {
+		if config.RecordCacheSize.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("record_cache_size"),
+				"Invalid record cache size",
+				"Record cache size must be at least 1",
+			)
+			return
+		}
+		opts = append(opts, client.WithRecordCacheSize(int(config.RecordCacheSize.ValueInt64())))
+	}
+	if !config.MaxConcurrentRequests.IsNull() && !config.MaxConcurrentRequests.IsUnknown() {
+		if config.MaxConcurrentRequests.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_concurrent_requests"),
+				"Invalid number of concurrent requests",
+				"Maximum number of concurrent requests must be at least 1",
+			)
+			return
+		}
+		opts = append(opts, client.WithMaxConcurrentRequests(int(config.MaxConcurrentRequests.ValueInt64())))
+	}
+
+	maxRetries, retryBaseDelay := -1, time.Duration(0)
+	if !config.MaxRetries.IsNull() && !config.MaxRetries.IsUnknown() {
+		if config.MaxRetries.ValueInt64() < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_retries"),
+				"Invalid number of retries",
+				"Maximum number of retries must not be negative",
+			)
+			return
+		}
+		maxRetries = int(config.MaxRetries.ValueInt64())
+	}
+	if !config.RetryBaseDelay.IsNull() && !config.RetryBaseDelay.IsUnknown() {
+		delay, err := time.ParseDuration(config.RetryBaseDelay.ValueString())
+		if err != nil || delay <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("retry_base_delay"),
+				"Invalid retry base delay",
+				"Retry base delay must be a positive duration like \"1s\", got \""+config.RetryBaseDelay.ValueString()+"\"",
+			)
+			return
+		}
+		retryBaseDelay = delay
+	}
+	opts = append(opts, client.WithRetries(maxRetries, retryBaseDelay))
+
+	retryOn := statusCodes("retry_on_statuscodes", config.RetryOnStatuscodes, &resp.Diagnostics)
+	neverRetry := statusCodes("never_retry_statuscodes", config.NeverRetryStatuscodes, &resp.Diagnostics)
+	zoneLocked := statusCodes("zone_locked_statuscodes", config.ZoneLockedStatuscodes, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	opts = append(opts, client.WithRetryStatusCodes(retryOn, neverRetry), client.WithZoneLockedStatusCodes(zoneLocked))
+	if mock {
+		opts = append(opts, client.WithMemoryBackend())
+	}
+
+	// aliases configured with the same credentials share one session, but keep their own options and settings
+	c, err := client.Sessions.Acquire(ctx, customerNumber, ccpApiKey, ccpApiPassword, opts...)
+	if addRateLimitError(&resp.Diagnostics, "Netcup throttled the login of customer "+customerNumber+".", err) {
+		return
+	}
+	if err != nil {
 		resp.Diagnostics.AddError(
-			"Unable to create client",
-			"Cannot use unknown value as api password",
+			"Unable to create CCP client",
+			"Unable to authenticate customer "+customerNumber+" with Netcup CCP API\n\n"+err.Error()+"\n\n"+loginErrorExplanation(client.ClassifyLoginError(err)),
 		)
 		return
 	}
+	c.SetRateLimitWarningThreshold(rateLimitWarningThreshold)
+	if !config.StrictApiDecoding.IsNull() && !config.StrictApiDecoding.IsUnknown() {
+		c.SetStrictDecoding(config.StrictApiDecoding.ValueBool())
+	}
+	c.SetSkipRefresh(config.SkipRefresh.ValueBool())
+	c.SetDriftWarnings(config.DriftWarnings.ValueBool())
+	c.SetZoneUpdateWait(config.WaitForZoneUpdate.ValueBool(), zoneUpdateTimeout)
+	c.SetDnssecNotice(config.DnssecWarning.IsNull() || config.DnssecWarning.IsUnknown() || config.DnssecWarning.ValueBool())
 
+	if p.client != nil {
+		err = client.Sessions.Release(ctx, p.client)
+		if err != nil {
+			tflog.Warn(ctx, "Could not log out previous session", map[string]interface{}{"error": err.Error()})
+		}
+	}
+	p.client = c
+
+	resp.DataSourceData = c
+	resp.ResourceData = c
+}
+
+// Credentials of the configuration or the environment. Returns false if they
+// are unknown or invalid, with a diagnostic explaining why.
+func configuredCredentials(config providerData, diags *diag.Diagnostics) (string, string, string, bool) {
+	envPrefix := config.EnvPrefix.ValueString()
+	customerNumberEnv := credentialEnv(envPrefix, "NETCUP_CUSTOMER_NUMBER")
+	apiPasswordEnv := credentialEnv(envPrefix, "NETCUP_API_PASSWORD")
+	apiKeyEnv := credentialEnv(envPrefix, "NETCUP_API_KEY")
+
+	// User must provide a user to the provider
+	var customerNumber string
+	if config.CustomerNumber.IsNull() {
+		customerNumber = os.Getenv(customerNumberEnv)
+	} else {
+		customerNumber = config.CustomerNumber.ValueString()
+	}
+
+	if customerNumber == "" {
+		diags.AddError(
+			"Unable to find customer number",
+			"Customer number cannot be an empty string. Set customer_number or the environment variable "+customerNumberEnv,
+		)
+		return "", "", "", false
+	}
+
+	var ccpApiPassword string
 	if config.Password.IsNull() {
-		ccpApiPassword = os.Getenv("NETCUP_API_PASSWORD")
+		ccpApiPassword = os.Getenv(apiPasswordEnv)
 	} else {
 		ccpApiPassword = config.Password.ValueString()
 	}
 
 	if ccpApiPassword == "" {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Unable to find password",
-			"Api Password cannot be an empty string",
+			"Api Password cannot be an empty string. Set password or the environment variable "+apiPasswordEnv,
 		)
-		return
+		return "", "", "", false
 	}
 
 	var ccpApiKey string
-	if config.Key.IsUnknown() {
-		resp.Diagnostics.AddError(
-			"Unable to create client",
-			"Cannot use unknown value as api key",
-		)
-		return
-	}
-
 	if config.Key.IsNull() {
-		ccpApiKey = os.Getenv("NETCUP_API_KEY")
+		ccpApiKey = os.Getenv(apiKeyEnv)
 	} else {
 		ccpApiKey = config.Key.ValueString()
 	}
 
 	if ccpApiKey == "" {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Unable to create client",
-			"Api key cannot be an empty string",
+			"Api key cannot be an empty string. Set key or the environment variable "+apiKeyEnv,
 		)
-		return
+		return "", "", "", false
 	}
 
-	c, err := client.NewCCPClient(customerNumber, ccpApiKey, ccpApiPassword)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Unable to create CCP client",
-			"Unable to authenticate customer "+customerNumber+" with Netcup CCP API\n\n"+err.Error(),
-		)
-		return
+	validateCredentials([]credential{
+		{Attribute: "customer_number", Env: customerNumberEnv, FromEnv: config.CustomerNumber.IsNull(), Value: customerNumber,
+			Pattern: customerNumberPattern, Shape: "customer number, which consists of digits only"},
+		{Attribute: "key", Env: apiKeyEnv, FromEnv: config.Key.IsNull(), Value: ccpApiKey,
+			Pattern: apiKeyPattern, Shape: "Netcup API key of 20 to 64 letters and digits"},
+		{Attribute: "password", Env: apiPasswordEnv, FromEnv: config.Password.IsNull(), Value: ccpApiPassword},
+	}, diags)
+	if diags.HasError() {
+		return "", "", "", false
 	}
 
-	resp.DataSourceData = c
-	resp.ResourceData = c
+	return customerNumber, ccpApiKey, ccpApiPassword, true
+}
+
+// Attributes deciding how the provider logs in whose values are unknown
+func unknownProviderAttributes(config providerData) []string {
+	var unknown []string
+	for _, attribute := range []struct {
+		name    string
+		unknown bool
+	}{
+		{"customer_number", config.CustomerNumber.IsUnknown()},
+		{"key", config.Key.IsUnknown()},
+		{"password", config.Password.IsUnknown()},
+		{"env_prefix", config.EnvPrefix.IsUnknown()},
+		{"endpoint", config.Endpoint.IsUnknown()},
+		{"mock", config.Mock.IsUnknown()},
+	} {
+		if attribute.unknown {
+			unknown = append(unknown, attribute.name)
+		}
+	}
+	return unknown
+}
+
+// Name of a credential environment variable with the env_prefix of the provider
+func credentialEnv(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// Statuscodes of a list attribute, which must be positive
+func statusCodes(attribute string, values []types.Int64, diags *diag.Diagnostics) []int {
+	codes := make([]int, 0, len(values))
+	for i, value := range values {
+		if value.IsNull() || value.IsUnknown() {
+			continue
+		}
+		if value.ValueInt64() < 1 {
+			diags.AddAttributeError(
+				path.Root(attribute).AtListIndex(i),
+				"Invalid statuscode",
+				fmt.Sprintf("Statuscodes must be positive integers, got %d", value.ValueInt64()),
+			)
+			continue
+		}
+		codes = append(codes, int(value.ValueInt64()))
+	}
+	return codes
 }
 
 func (p *netcupCcpProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewDnsRecordDataSource,
+		NewAutoconfigMailResource,
+		NewSrvSetResource,
+		NewRecordSetResource,
+		NewZoneResource,
+		NewDnssecResource,
 	}
 }
 
 func (p *netcupCcpProvider) DataSources(_ context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewZoneDataSource,
+		NewRecordsDataSource,
+		NewRecordDataSource,
+		NewDnssecStatusDataSource,
+		NewRecordIdDataSource,
+		NewZoneFileDataSource,
+		NewResolveDataSource,
+		NewRecordSetDataSource,
+		NewTxtRecordsDataSource,
+		NewMxRecordsDataSource,
+		NewRecordExistsDataSource,
+		NewZoneSerialDataSource,
+		NewPropagationStatusDataSource,
+		NewEffectiveRecordDataSource,
+	}
+}
+
+func (p *netcupCcpProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewRelativeHostnameFunction,
+		NewMailPresetFunction,
+	}
+}
+
+// Explain how to fix a failed login
+func loginErrorExplanation(kind client.LoginErrorKind) string {
+	switch kind {
+	case client.LoginErrorCredentials:
+		return "The customer number, API key or API password is wrong. Compare them with the API keys listed in the CCP " +
+			"(https://www.customercontrolpanel.de, Master Data > API). The API password is shown only once, generate a new one if it is lost."
+	case client.LoginErrorAPINotActivated:
+		return "API access is not activated for this account. Activate it and generate an API key and password in the CCP " +
+			"(https://www.customercontrolpanel.de, Master Data > API)."
+	case client.LoginErrorThrottled:
+		return "The account is temporarily blocked, e.g. after too many failed logins or requests. Wait a few minutes before trying again."
+	case client.LoginErrorUnreachable:
+		return "The Netcup CCP API could not be reached or returned no valid response. Check the network connection and https://www.netcup-status.de."
+	default:
+		return "Check the credentials and the API settings in the CCP (https://www.customercontrolpanel.de, Master Data > API)."
+	}
 }
@@ -0,0 +1,1466 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+// Attributes of a configuration, keyed by name. Values are strings, bools,
+// ints, nil, []interface{} for lists and sets and map[string]interface{} for
+// objects. Attributes missing from the map are null.
+type attrs map[string]interface{}
+
+// Provider served over protocol version 6 like Terraform runs it, configured
+// with the mock backend. Zones of the mock live as long as the test binary, so
+// tests use their own domains. Providers alive at the same time share the
+// session and its caches like aliased provider blocks, close a provider to
+// have the next one read the zones again like a new Terraform run.
+type testProvider struct {
+	t        *testing.T
+	provider *netcupCcpProvider
+	server   tfprotov6.ProviderServer
+	schemas  *tfprotov6.GetProviderSchemaResponse
+	closed   bool
+	// context of the calls to the provider, see captureLogs
+	ctx context.Context
+}
+
+func newTestProvider(t *testing.T, config attrs) *testProvider {
+	t.Helper()
+
+	p := startTestProvider(t)
+	providerConfig := attrs{
+		"mock":            true,
+		"customer_number": "12345",
+		"key":             "abcdefghijklmnopqrstuvwxyz",
+		"password":        "password",
+	}
+	for name, value := range config {
+		providerConfig[name] = value
+	}
+	p.checkDiags("ConfigureProvider", p.configure(providerConfig))
+	return p
+}
+
+// Provider that isn't configured yet
+func startTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+
+	p := &testProvider{t: t, provider: New().(*netcupCcpProvider), ctx: context.Background()}
+	p.server = providerserver.NewProtocol6(p.provider)()
+	schemas, err := p.server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema failed: %s", err)
+	}
+	p.checkDiags("GetProviderSchema", schemas.Diagnostics)
+	p.schemas = schemas
+
+	t.Cleanup(p.close)
+	return p
+}
+
+// Configure the provider with exactly the given attributes
+func (p *testProvider) configure(config attrs) []*tfprotov6.Diagnostic {
+	p.t.Helper()
+	resp, err := p.server.ConfigureProvider(p.ctx, &tfprotov6.ConfigureProviderRequest{
+		TerraformVersion: "1.8.0",
+		Config:           p.dynamicValue(p.schemas.Provider, config),
+	})
+	if err != nil {
+		p.t.Fatalf("ConfigureProvider failed: %s", err)
+	}
+	return resp.Diagnostics
+}
+
+// Send the logs of the calls after this one to output as JSON, at all levels
+func (p *testProvider) captureLogs(output io.Writer) {
+	p.ctx = tflogtest.RootLogger(context.Background(), output)
+}
+
+// Stop the provider and release its session, once
+func (p *testProvider) close() {
+	if p.closed {
+		return
+	}
+	p.closed = true
+	_, _ = p.server.StopProvider(context.Background(), &tfprotov6.StopProviderRequest{})
+	if p.provider.client != nil {
+		_ = client.Sessions.Release(context.Background(), p.provider.client)
+	}
+}
+
+func (p *testProvider) checkDiags(step string, diags []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			p.t.Fatalf("%s failed: %s: %s", step, d.Summary, d.Detail)
+		}
+	}
+}
+
+func (p *testProvider) resourceSchema(typeName string) *tfprotov6.Schema {
+	p.t.Helper()
+	s, ok := p.schemas.ResourceSchemas[typeName]
+	if !ok {
+		p.t.Fatalf("no resource %s", typeName)
+	}
+	return s
+}
+
+func (p *testProvider) dataSourceSchema(typeName string) *tfprotov6.Schema {
+	p.t.Helper()
+	s, ok := p.schemas.DataSourceSchemas[typeName]
+	if !ok {
+		p.t.Fatalf("no data source %s", typeName)
+	}
+	return s
+}
+
+func (p *testProvider) dynamicValue(s *tfprotov6.Schema, values attrs) *tfprotov6.DynamicValue {
+	p.t.Helper()
+	typ := s.ValueType()
+	dv, err := tfprotov6.NewDynamicValue(typ, toValue(p.t, typ, map[string]interface{}(values)))
+	if err != nil {
+		p.t.Fatalf("encoding %v failed: %s", values, err)
+	}
+	return &dv
+}
+
+func (p *testProvider) decode(s *tfprotov6.Schema, dv *tfprotov6.DynamicValue) tftypes.Value {
+	p.t.Helper()
+	if dv == nil {
+		return tftypes.NewValue(s.ValueType(), nil)
+	}
+	value, err := dv.Unmarshal(s.ValueType())
+	if err != nil {
+		p.t.Fatalf("decoding failed: %s", err)
+	}
+	return value
+}
+
+func encode(t *testing.T, s *tfprotov6.Schema, value tftypes.Value) *tfprotov6.DynamicValue {
+	t.Helper()
+	dv, err := tfprotov6.NewDynamicValue(s.ValueType(), value)
+	if err != nil {
+		t.Fatalf("encoding failed: %s", err)
+	}
+	return &dv
+}
+
+// Convert a Go value of a test configuration to a value of typ
+func toValue(t *testing.T, typ tftypes.Type, v interface{}) tftypes.Value {
+	t.Helper()
+	if v == nil {
+		return tftypes.NewValue(typ, nil)
+	}
+	if value, ok := v.(tftypes.Value); ok {
+		return value
+	}
+
+	switch typ := typ.(type) {
+	case tftypes.Object:
+		given, ok := v.(map[string]interface{})
+		if !ok {
+			given = v.(attrs)
+		}
+		values := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+		for name, attributeType := range typ.AttributeTypes {
+			values[name] = toValue(t, attributeType, given[name])
+		}
+		for name := range given {
+			if _, ok := typ.AttributeTypes[name]; !ok {
+				t.Fatalf("unknown attribute %s", name)
+			}
+		}
+		return tftypes.NewValue(typ, values)
+	case tftypes.List:
+		return tftypes.NewValue(typ, elements(t, typ.ElementType, v))
+	case tftypes.Set:
+		return tftypes.NewValue(typ, elements(t, typ.ElementType, v))
+	case tftypes.Map:
+		given := v.(map[string]interface{})
+		values := make(map[string]tftypes.Value, len(given))
+		for key, value := range given {
+			values[key] = toValue(t, typ.ElementType, value)
+		}
+		return tftypes.NewValue(typ, values)
+	}
+
+	switch {
+	case typ.Is(tftypes.Number):
+		switch n := v.(type) {
+		case int:
+			return tftypes.NewValue(typ, big.NewFloat(float64(n)))
+		case float64:
+			return tftypes.NewValue(typ, big.NewFloat(n))
+		}
+	case typ.Is(tftypes.String), typ.Is(tftypes.Bool):
+		return tftypes.NewValue(typ, v)
+	}
+	t.Fatalf("can't convert %v to %s", v, typ)
+	return tftypes.Value{}
+}
+
+func elements(t *testing.T, elementType tftypes.Type, v interface{}) []tftypes.Value {
+	given := v.([]interface{})
+	values := make([]tftypes.Value, 0, len(given))
+	for _, element := range given {
+		values = append(values, toValue(t, elementType, element))
+	}
+	return values
+}
+
+// State of a resource that doesn't exist yet
+func nullState(p *testProvider, typeName string) tftypes.Value {
+	return tftypes.NewValue(p.resourceSchema(typeName).ValueType(), nil)
+}
+
+// Proposed new state like Terraform computes it: the configuration, with null
+// computed attributes taken from the prior state
+func proposedNewState(s *tfprotov6.Schema, prior, config tftypes.Value) tftypes.Value {
+	if prior.IsNull() {
+		return config
+	}
+	var priorValues, configValues map[string]tftypes.Value
+	_ = prior.As(&priorValues)
+	_ = config.As(&configValues)
+
+	values := make(map[string]tftypes.Value, len(configValues))
+	for _, attribute := range s.Block.Attributes {
+		value := configValues[attribute.Name]
+		if value.IsNull() && attribute.Computed {
+			value = priorValues[attribute.Name]
+		}
+		values[attribute.Name] = value
+	}
+	return tftypes.NewValue(config.Type(), values)
+}
+
+// Result of planning and applying a configuration of a resource
+type applied struct {
+	Planned tftypes.Value
+	State   tftypes.Value
+	Private []byte
+	Diags   []*tfprotov6.Diagnostic
+}
+
+// Plan and apply config of a resource with the given prior state, failing the
+// test on error diagnostics and, like Terraform, on applied values other than
+// the planned ones. A nil config destroys the resource.
+func (p *testProvider) apply(typeName string, prior tftypes.Value, priorPrivate []byte, config attrs) applied {
+	p.t.Helper()
+	result := p.tryApply(typeName, prior, priorPrivate, config)
+	p.checkDiags("apply of "+typeName, result.Diags)
+	return result
+}
+
+// Like apply, but returns error diagnostics instead of failing the test
+func (p *testProvider) tryApply(typeName string, prior tftypes.Value, priorPrivate []byte, config attrs) applied {
+	p.t.Helper()
+	ctx := p.ctx
+	s := p.resourceSchema(typeName)
+
+	configValue := tftypes.NewValue(s.ValueType(), nil)
+	if config != nil {
+		configValue = toValue(p.t, s.ValueType(), map[string]interface{}(config))
+		if diags := p.validate(typeName, config); hasErrors(diags) {
+			return applied{Diags: diags}
+		}
+	}
+
+	proposed := configValue
+	if config != nil {
+		proposed = proposedNewState(s, prior, configValue)
+	}
+	plan, err := p.server.PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       encode(p.t, s, prior),
+		ProposedNewState: encode(p.t, s, proposed),
+		Config:           encode(p.t, s, configValue),
+		PriorPrivate:     priorPrivate,
+	})
+	if err != nil {
+		p.t.Fatalf("PlanResourceChange failed: %s", err)
+	}
+	if hasErrors(plan.Diagnostics) {
+		return applied{Diags: plan.Diagnostics}
+	}
+	planned := p.decode(s, plan.PlannedState)
+
+	resp, err := p.server.ApplyResourceChange(ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:       typeName,
+		PriorState:     encode(p.t, s, prior),
+		PlannedState:   plan.PlannedState,
+		Config:         encode(p.t, s, configValue),
+		PlannedPrivate: plan.PlannedPrivate,
+	})
+	if err != nil {
+		p.t.Fatalf("ApplyResourceChange failed: %s", err)
+	}
+	state := p.decode(s, resp.NewState)
+	result := applied{Planned: planned, State: state, Private: resp.Private, Diags: append(plan.Diagnostics, resp.Diagnostics...)}
+	if hasErrors(resp.Diagnostics) {
+		return result
+	}
+	checkConsistent(p.t, planned, state)
+	return result
+}
+
+// Validate the config of a resource
+func (p *testProvider) validate(typeName string, config attrs) []*tfprotov6.Diagnostic {
+	p.t.Helper()
+	s := p.resourceSchema(typeName)
+	resp, err := p.server.ValidateResourceConfig(p.ctx, &tfprotov6.ValidateResourceConfigRequest{
+		TypeName: typeName,
+		Config:   encode(p.t, s, toValue(p.t, s.ValueType(), map[string]interface{}(config))),
+	})
+	if err != nil {
+		p.t.Fatalf("ValidateResourceConfig failed: %s", err)
+	}
+	return resp.Diagnostics
+}
+
+// Plan config against the prior state and return the planned state, which
+// equals the prior state if nothing changes
+func (p *testProvider) plan(typeName string, prior tftypes.Value, priorPrivate []byte, config attrs) tftypes.Value {
+	p.t.Helper()
+	planned, diags := p.tryPlan(typeName, prior, priorPrivate, config)
+	p.checkDiags("plan of "+typeName, diags)
+	return planned
+}
+
+// Plan a change of a resource, returning the diagnostics instead of failing on errors
+func (p *testProvider) tryPlan(typeName string, prior tftypes.Value, priorPrivate []byte, config attrs) (tftypes.Value, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+	resp := p.planResourceChange(typeName, prior, priorPrivate, config)
+	return p.decode(p.resourceSchema(typeName), resp.PlannedState), resp.Diagnostics
+}
+
+// Attributes whose planned change replaces the resource
+func (p *testProvider) planReplacements(typeName string, prior tftypes.Value, priorPrivate []byte, config attrs) []*tftypes.AttributePath {
+	p.t.Helper()
+	resp := p.planResourceChange(typeName, prior, priorPrivate, config)
+	p.checkDiags("plan of "+typeName, resp.Diagnostics)
+	return resp.RequiresReplace
+}
+
+func (p *testProvider) planResourceChange(typeName string, prior tftypes.Value, priorPrivate []byte, config attrs) *tfprotov6.PlanResourceChangeResponse {
+	p.t.Helper()
+	s := p.resourceSchema(typeName)
+	configValue := toValue(p.t, s.ValueType(), map[string]interface{}(config))
+	resp, err := p.server.PlanResourceChange(p.ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       encode(p.t, s, prior),
+		ProposedNewState: encode(p.t, s, proposedNewState(s, prior, configValue)),
+		Config:           encode(p.t, s, configValue),
+		PriorPrivate:     priorPrivate,
+	})
+	if err != nil {
+		p.t.Fatalf("PlanResourceChange failed: %s", err)
+	}
+	return resp
+}
+
+// Refresh the state of a resource
+func (p *testProvider) read(typeName string, state tftypes.Value, private []byte) (tftypes.Value, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+	newState, _, diags := p.refresh(typeName, state, private)
+	return newState, diags
+}
+
+// Like read, but also returns the private state after the refresh
+func (p *testProvider) refresh(typeName string, state tftypes.Value, private []byte) (tftypes.Value, []byte, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+	s := p.resourceSchema(typeName)
+	resp, err := p.server.ReadResource(p.ctx, &tfprotov6.ReadResourceRequest{
+		TypeName:     typeName,
+		CurrentState: encode(p.t, s, state),
+		Private:      private,
+	})
+	if err != nil {
+		p.t.Fatalf("ReadResource failed: %s", err)
+	}
+	return p.decode(s, resp.NewState), resp.Private, resp.Diagnostics
+}
+
+// Call a provider function with string arguments, returning its string result
+func (p *testProvider) callFunction(name string, args ...string) (string, *tfprotov6.FunctionError) {
+	p.t.Helper()
+	value, funcErr := p.callFunctionValue(name, tftypes.String, args...)
+	if funcErr != nil {
+		return "", funcErr
+	}
+	var result string
+	if err := value.As(&result); err != nil {
+		p.t.Fatal(err)
+	}
+	return result, nil
+}
+
+// Call a provider function with string arguments, returning its result of type returnType
+func (p *testProvider) callFunctionValue(name string, returnType tftypes.Type, args ...string) (tftypes.Value, *tfprotov6.FunctionError) {
+	p.t.Helper()
+	arguments := make([]*tfprotov6.DynamicValue, 0, len(args))
+	for _, arg := range args {
+		value, err := tfprotov6.NewDynamicValue(tftypes.String, tftypes.NewValue(tftypes.String, arg))
+		if err != nil {
+			p.t.Fatal(err)
+		}
+		arguments = append(arguments, &value)
+	}
+	functions, ok := p.server.(tfprotov6.FunctionServer)
+	if !ok {
+		p.t.Fatal("provider server doesn't serve functions")
+	}
+	resp, err := functions.CallFunction(p.ctx, &tfprotov6.CallFunctionRequest{Name: name, Arguments: arguments})
+	if err != nil {
+		p.t.Fatalf("CallFunction failed: %s", err)
+	}
+	if resp.Error != nil {
+		return tftypes.Value{}, resp.Error
+	}
+	value, err := resp.Result.Unmarshal(returnType)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return value, nil
+}
+
+// Import a resource by id and refresh it like terraform import does
+func (p *testProvider) importState(typeName, id string) (tftypes.Value, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+	s := p.resourceSchema(typeName)
+	resp, err := p.server.ImportResourceState(p.ctx, &tfprotov6.ImportResourceStateRequest{
+		TypeName: typeName,
+		ID:       id,
+	})
+	if err != nil {
+		p.t.Fatalf("ImportResourceState failed: %s", err)
+	}
+	if hasErrors(resp.Diagnostics) || len(resp.ImportedResources) != 1 {
+		return tftypes.Value{}, resp.Diagnostics
+	}
+	imported := resp.ImportedResources[0]
+	return p.read(typeName, p.decode(s, imported.State), imported.Private)
+}
+
+// Read a data source
+func (p *testProvider) readDataSource(typeName string, config attrs) (tftypes.Value, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+	ctx := p.ctx
+	s := p.dataSourceSchema(typeName)
+	configValue := p.dynamicValue(s, config)
+	validate, err := p.server.ValidateDataResourceConfig(ctx, &tfprotov6.ValidateDataResourceConfigRequest{
+		TypeName: typeName,
+		Config:   configValue,
+	})
+	if err != nil {
+		p.t.Fatalf("ValidateDataResourceConfig failed: %s", err)
+	}
+	if hasErrors(validate.Diagnostics) {
+		return tftypes.Value{}, validate.Diagnostics
+	}
+	resp, err := p.server.ReadDataSource(ctx, &tfprotov6.ReadDataSourceRequest{
+		TypeName: typeName,
+		Config:   configValue,
+	})
+	if err != nil {
+		p.t.Fatalf("ReadDataSource failed: %s", err)
+	}
+	return p.decode(s, resp.State), resp.Diagnostics
+}
+
+func hasErrors(diags []*tfprotov6.Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// First error diagnostic, or nil
+func firstError(diags []*tfprotov6.Diagnostic) *tfprotov6.Diagnostic {
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			return d
+		}
+	}
+	return nil
+}
+
+// Summaries of the diagnostics of a severity
+func summaries(diags []*tfprotov6.Diagnostic, severity tfprotov6.DiagnosticSeverity) []string {
+	var result []string
+	for _, d := range diags {
+		if d.Severity == severity {
+			result = append(result, d.Summary)
+		}
+	}
+	return result
+}
+
+// Fail like Terraform's "Provider produced inconsistent result after apply"
+// if a known planned attribute differs from the applied state
+func checkConsistent(t *testing.T, planned, state tftypes.Value) {
+	t.Helper()
+	if planned.IsNull() {
+		if !state.IsNull() {
+			t.Errorf("destroy left state %s", state)
+		}
+		return
+	}
+	var plannedValues, stateValues map[string]tftypes.Value
+	_ = planned.As(&plannedValues)
+	_ = state.As(&stateValues)
+	for _, name := range sortedKeys(plannedValues) {
+		value := plannedValues[name]
+		if !value.IsFullyKnown() {
+			continue
+		}
+		if !value.Equal(stateValues[name]) {
+			t.Errorf("inconsistent result after apply: %s planned as %s, applied as %s", name, value, stateValues[name])
+		}
+	}
+}
+
+func sortedKeys(values map[string]tftypes.Value) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Attribute of an object value
+func attrValue(t *testing.T, object tftypes.Value, name string) tftypes.Value {
+	t.Helper()
+	var values map[string]tftypes.Value
+	if err := object.As(&values); err != nil {
+		t.Fatalf("%s is no object: %s", object, err)
+	}
+	value, ok := values[name]
+	if !ok {
+		t.Fatalf("no attribute %s in %s", name, object)
+	}
+	return value
+}
+
+// Copy of an object value with the attribute name set to value
+func withAttr(t *testing.T, object tftypes.Value, name string, value interface{}) tftypes.Value {
+	t.Helper()
+	var values map[string]tftypes.Value
+	if err := object.As(&values); err != nil {
+		t.Fatalf("%s is no object: %s", object, err)
+	}
+	old, ok := values[name]
+	if !ok {
+		t.Fatalf("no attribute %s in %s", name, object)
+	}
+	copied := make(map[string]tftypes.Value, len(values))
+	for key, v := range values {
+		copied[key] = v
+	}
+	copied[name] = toValue(t, old.Type(), value)
+	return tftypes.NewValue(object.Type(), copied)
+}
+
+// Elements of a list or set value
+func elementsOf(t *testing.T, value tftypes.Value) []tftypes.Value {
+	t.Helper()
+	var values []tftypes.Value
+	if err := value.As(&values); err != nil {
+		t.Fatalf("%s is no list or set: %s", value, err)
+	}
+	return values
+}
+
+// String, number or bool attribute of an object value as string, "<null>" if null
+func attrString(t *testing.T, object tftypes.Value, name string) string {
+	t.Helper()
+	value := attrValue(t, object, name)
+	if value.IsNull() {
+		return "<null>"
+	}
+	if value.Type().Is(tftypes.Number) {
+		var n big.Float
+		if err := value.As(&n); err != nil {
+			t.Fatalf("%s is no number: %s", name, err)
+		}
+		return n.Text('f', -1)
+	}
+	if value.Type().Is(tftypes.Bool) {
+		var b bool
+		if err := value.As(&b); err != nil {
+			t.Fatalf("%s is no bool: %s", name, err)
+		}
+		return strconv.FormatBool(b)
+	}
+	var s string
+	if err := value.As(&s); err != nil {
+		t.Fatalf("%s is no string: %s", name, err)
+	}
+	return s
+}
+
+// String list or set attribute of an object value
+func attrStrings(t *testing.T, object tftypes.Value, name string) []string {
+	t.Helper()
+	result := []string{}
+	for _, element := range elementsOf(t, attrValue(t, object, name)) {
+		var s string
+		if err := element.As(&s); err != nil {
+			t.Fatalf("element of %s is no string: %s", name, err)
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// Client of the mock for changes made outside of Terraform
+func mockClient(t *testing.T) *client.CCPClient {
+	t.Helper()
+	c, err := client.NewCCPClient(context.Background(), "12345", "abcdefghijklmnopqrstuvwxyz", "password", client.WithMemoryBackend())
+	if err != nil {
+		t.Fatalf("NewCCPClient failed: %s", err)
+	}
+	return c
+}
+
+// Records of a zone of the mock as sorted "hostname type priority destination"
+func zoneRecords(t *testing.T, domain string) []string {
+	t.Helper()
+	p := newTestProvider(t, nil)
+	defer p.close()
+	state, diags := p.readDataSource("netcupdns_records", attrs{"domainname": domain})
+	p.checkDiags("read of zone "+domain, diags)
+	records := []string{}
+	for _, record := range elementsOf(t, attrValue(t, state, "records")) {
+		records = append(records, strings.Join([]string{
+			attrString(t, record, "hostname"), attrString(t, record, "type"), attrString(t, record, "priority"), attrString(t, record, "destination"),
+		}, " "))
+	}
+	sort.Strings(records)
+	return records
+}
+
+var updateGolden = flag.Bool("update", false, "write the golden files of testdata instead of comparing against them")
+
+// Compare got with the golden file testdata/<name>, or write it with -update
+func checkGolden(t *testing.T, name string, got string) {
+	t.Helper()
+	file := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("reading golden file, run the test with -update to create it: %s", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from the golden file, run the test with -update if the change is intended:\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+// Unique domain of the mock for a test
+func testDomain(t *testing.T) string {
+	name := strings.ToLower(t.Name())
+	name = strings.NewReplacer("/", "-", "_", "-", " ", "-").Replace(name)
+	return name + ".example"
+}
+
+// CCP endpoint accepting any login, recording the credentials of the logins.
+// The default transport trusts it while the test runs.
+type loginServer struct {
+	URL string
+
+	mu     sync.Mutex
+	logins []client.LoginData
+	// response to every login instead of a session, see fail
+	failure       []byte
+	failureStatus int
+	// called with every request other than logins before answering it
+	onRequest func(action string)
+	// response to requests other than logins with their body, if not nil
+	respond func(action string, body []byte) map[string]interface{}
+	// backend answering requests other than logins, if respond doesn't
+	backend http.RoundTripper
+}
+
+func newLoginServer(t *testing.T) *loginServer {
+	t.Helper()
+	s := &loginServer{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var request struct {
+			Action string           `json:"action"`
+			Param  client.LoginData `json:"param"`
+		}
+		if err := json.Unmarshal(body, &request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var data interface{} = ""
+		if request.Action == "login" {
+			s.mu.Lock()
+			s.logins = append(s.logins, request.Param)
+			failure, failureStatus := s.failure, s.failureStatus
+			s.mu.Unlock()
+			if failure != nil {
+				w.WriteHeader(failureStatus)
+				_, _ = w.Write(failure)
+				return
+			}
+			data = client.SessionData{SessionId: "test-session"}
+		} else if s.onRequest != nil {
+			s.onRequest(request.Action)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if request.Action != "login" && s.respond != nil {
+			if response := s.respond(request.Action, body); response != nil {
+				_ = json.NewEncoder(w).Encode(response)
+				return
+			}
+		}
+		if request.Action != "login" && s.backend != nil {
+			forwarded := r.Clone(r.Context())
+			forwarded.Body = io.NopCloser(bytes.NewReader(body))
+			res, err := s.backend.RoundTrip(forwarded)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			defer res.Body.Close()
+			w.WriteHeader(res.StatusCode)
+			_, _ = io.Copy(w, res.Body)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"action": request.Action, "status": "success", "statuscode": 2000, "shortmessage": "ok", "responsedata": data,
+		})
+	}))
+	s.URL = server.URL
+
+	transport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	t.Cleanup(func() {
+		http.DefaultTransport = transport
+		server.Close()
+	})
+	return s
+}
+
+// Answer logins with a response of the client's testdata/responses and the HTTP status
+func (s *loginServer) fail(t *testing.T, status int, response string) {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("..", "client", "testdata", "responses", response))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failure, s.failureStatus = body, status
+}
+
+func (s *loginServer) Logins() []client.LoginData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]client.LoginData(nil), s.logins...)
+}
+
+func TestConfigureCredentials(t *testing.T) {
+	unknown := tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+	attributes := attrs{"customer_number": "11111", "key": "attributekey0123456789", "password": "attribute-password"}
+	env := map[string]string{"NETCUP_CUSTOMER_NUMBER": "22222", "NETCUP_API_KEY": "environmentkey0123456789", "NETCUP_API_PASSWORD": "environment-password"}
+	fromAttributes := client.LoginData{CustomerNumber: "11111", APIKey: "attributekey0123456789", APIPassword: "attribute-password"}
+	fromEnv := client.LoginData{CustomerNumber: "22222", APIKey: "environmentkey0123456789", APIPassword: "environment-password"}
+
+	with := func(base attrs, changes attrs) attrs {
+		result := attrs{}
+		for name, value := range base {
+			result[name] = value
+		}
+		for name, value := range changes {
+			result[name] = value
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		config   attrs
+		env      map[string]string
+		login    *client.LoginData
+		errors   []string
+		warnings []string
+	}{
+		{
+			name:   "attributes",
+			config: attributes,
+			login:  &fromAttributes,
+		},
+		{
+			name:  "environment",
+			env:   env,
+			login: &fromEnv,
+		},
+		{
+			name:   "attributes take precedence over the environment",
+			config: attributes,
+			env:    env,
+			login:  &fromAttributes,
+		},
+		{
+			name:   "attributes mixed with the environment",
+			config: attrs{"customer_number": "11111"},
+			env:    env,
+			login:  &client.LoginData{CustomerNumber: "11111", APIKey: "environmentkey0123456789", APIPassword: "environment-password"},
+		},
+		{
+			name:   "environment with prefix",
+			config: attrs{"env_prefix": "ACCOUNT_B"},
+			env:    map[string]string{"ACCOUNT_B_NETCUP_CUSTOMER_NUMBER": "22222", "ACCOUNT_B_NETCUP_API_KEY": "environmentkey0123456789", "ACCOUNT_B_NETCUP_API_PASSWORD": "environment-password"},
+			login:  &fromEnv,
+		},
+		{
+			name:   "prefix ignores the environment without prefix",
+			config: attrs{"env_prefix": "ACCOUNT_B"},
+			env:    env,
+			errors: []string{"Unable to find customer number"},
+		},
+		{
+			name:   "neither set",
+			errors: []string{"Unable to find customer number"},
+		},
+		{
+			name:   "both empty",
+			config: attrs{"customer_number": "", "key": "", "password": ""},
+			env:    map[string]string{"NETCUP_CUSTOMER_NUMBER": "", "NETCUP_API_KEY": "", "NETCUP_API_PASSWORD": ""},
+			errors: []string{"Unable to find customer number"},
+		},
+		{
+			name:   "empty attribute doesn't fall back to the environment",
+			config: with(attributes, attrs{"customer_number": ""}),
+			env:    env,
+			errors: []string{"Unable to find customer number"},
+		},
+		{
+			name:   "password missing",
+			config: with(attributes, attrs{"password": nil}),
+			errors: []string{"Unable to find password"},
+		},
+		{
+			name:   "key missing",
+			config: with(attributes, attrs{"key": nil}),
+			errors: []string{"Unable to create client"},
+		},
+		{
+			name:   "swapped values",
+			config: with(attributes, attrs{"customer_number": "attributekey0123456789", "key": "11111"}),
+			errors: []string{"Invalid customer_number", "Invalid key"},
+		},
+		{
+			name:   "whitespace in the environment",
+			env:    map[string]string{"NETCUP_CUSTOMER_NUMBER": "22222\n", "NETCUP_API_KEY": "environmentkey0123456789", "NETCUP_API_PASSWORD": "environment-password"},
+			errors: []string{"Invalid customer_number"},
+		},
+		{
+			name:     "unknown customer number",
+			config:   with(attributes, attrs{"customer_number": unknown}),
+			warnings: []string{"Provider configuration unknown"},
+		},
+		{
+			name:     "unknown mock",
+			config:   with(attributes, attrs{"mock": tftypes.NewValue(tftypes.Bool, tftypes.UnknownValue)}),
+			warnings: []string{"Provider configuration unknown"},
+		},
+		{
+			name:     "unknown values of the environment are ignored",
+			config:   with(attributes, attrs{"env_prefix": unknown}),
+			env:      env,
+			warnings: []string{"Provider configuration unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newLoginServer(t)
+			t.Setenv("NETCUP_API_ENDPOINT", server.URL)
+			t.Setenv("ACCOUNT_B_NETCUP_API_ENDPOINT", server.URL)
+			for _, name := range []string{"NETCUP_CUSTOMER_NUMBER", "NETCUP_API_KEY", "NETCUP_API_PASSWORD"} {
+				t.Setenv(name, "")
+				t.Setenv("ACCOUNT_B_"+name, "")
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			p := startTestProvider(t)
+			diags := p.configure(tt.config)
+			if got := summaries(diags, tfprotov6.DiagnosticSeverityError); strings.Join(got, ",") != strings.Join(tt.errors, ",") {
+				t.Errorf("got errors %q, want %q", got, tt.errors)
+			}
+			if got := summaries(diags, tfprotov6.DiagnosticSeverityWarning); strings.Join(got, ",") != strings.Join(tt.warnings, ",") {
+				t.Errorf("got warnings %q, want %q", got, tt.warnings)
+			}
+			for _, d := range diags {
+				for _, secret := range []string{"attribute-password", "environment-password"} {
+					if strings.Contains(d.Detail, secret) {
+						t.Errorf("diagnostic %q contains the password", d.Summary)
+					}
+				}
+			}
+
+			logins := server.Logins()
+			if tt.login == nil {
+				if len(logins) > 0 {
+					t.Errorf("logged in with %+v", logins)
+				}
+				if p.provider.client != nil {
+					t.Error("a client was constructed")
+				}
+				return
+			}
+			if len(logins) != 1 || logins[0] != *tt.login {
+				t.Errorf("logged in with %+v, want %+v", logins, *tt.login)
+			}
+			if p.provider.client == nil {
+				t.Error("no client was constructed")
+			}
+		})
+	}
+}
+
+// Two aliases read the credentials of different accounts from prefixed environment variables
+func TestConfigureAliasesWithEnvPrefixes(t *testing.T) {
+	server := newLoginServer(t)
+	accounts := map[string]client.LoginData{
+		"ACCOUNT_A": {CustomerNumber: "44441", APIKey: "accountakey0123456789", APIPassword: "account-a-password"},
+		"ACCOUNT_B": {CustomerNumber: "44442", APIKey: "accountbkey0123456789", APIPassword: "account-b-password"},
+	}
+	for prefix, login := range accounts {
+		t.Setenv(prefix+"_NETCUP_API_ENDPOINT", server.URL)
+		t.Setenv(prefix+"_NETCUP_CUSTOMER_NUMBER", login.CustomerNumber)
+		t.Setenv(prefix+"_NETCUP_API_KEY", login.APIKey)
+		t.Setenv(prefix+"_NETCUP_API_PASSWORD", login.APIPassword)
+	}
+	t.Setenv("NETCUP_CUSTOMER_NUMBER", "99999")
+
+	a := startTestProvider(t)
+	a.checkDiags("configure ACCOUNT_A", a.configure(attrs{"env_prefix": "ACCOUNT_A"}))
+	b := startTestProvider(t)
+	b.checkDiags("configure ACCOUNT_B", b.configure(attrs{"env_prefix": "ACCOUNT_B"}))
+
+	logins := server.Logins()
+	if len(logins) != 2 {
+		t.Fatalf("logged in %d times, want once per alias: %+v", len(logins), logins)
+	}
+	sort.Slice(logins, func(i, j int) bool { return logins[i].CustomerNumber < logins[j].CustomerNumber })
+	if logins[0] != accounts["ACCOUNT_A"] || logins[1] != accounts["ACCOUNT_B"] {
+		t.Errorf("logged in with %+v, want the credentials of ACCOUNT_A and ACCOUNT_B", logins)
+	}
+	if a.provider.client == b.provider.client {
+		t.Error("the aliases share a client")
+	}
+}
+
+// Errors about missing credentials name the prefixed variables that were read
+func TestConfigureEnvPrefixErrors(t *testing.T) {
+	tests := []struct {
+		env     map[string]string
+		summary string
+		name    string
+	}{
+		{map[string]string{}, "Unable to find customer number", "ACCOUNT_C_NETCUP_CUSTOMER_NUMBER"},
+		{map[string]string{"ACCOUNT_C_NETCUP_CUSTOMER_NUMBER": "44443"}, "Unable to find password", "ACCOUNT_C_NETCUP_API_PASSWORD"},
+		{map[string]string{"ACCOUNT_C_NETCUP_CUSTOMER_NUMBER": "44443\n", "ACCOUNT_C_NETCUP_API_PASSWORD": "password", "ACCOUNT_C_NETCUP_API_KEY": "accountckey0123456789"},
+			"Invalid customer_number", "ACCOUNT_C_NETCUP_CUSTOMER_NUMBER"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.summary, func(t *testing.T) {
+			for _, name := range []string{"NETCUP_CUSTOMER_NUMBER", "NETCUP_API_KEY", "NETCUP_API_PASSWORD"} {
+				t.Setenv(name, "unused")
+				t.Setenv("ACCOUNT_C_"+name, "")
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			p := startTestProvider(t)
+			d := firstError(p.configure(attrs{"env_prefix": "ACCOUNT_C"}))
+			if d == nil || d.Summary != tt.summary {
+				t.Fatalf("got error %v, want %s", d, tt.summary)
+			}
+			if !strings.Contains(d.Detail, tt.name) {
+				t.Errorf("detail doesn't name %s:\n%s", tt.name, d.Detail)
+			}
+		})
+	}
+}
+
+// Without a client, e.g. after an unknown configuration, operations fail instead of panicking
+func TestConfigureUnknownThenRead(t *testing.T) {
+	p := startTestProvider(t)
+	diags := p.configure(attrs{"customer_number": tftypes.NewValue(tftypes.String, tftypes.UnknownValue)})
+	if hasErrors(diags) {
+		t.Fatalf("configure failed: %v", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+
+	_, diags = p.readDataSource("netcupdns_records", attrs{"domainname": "example.com"})
+	if d := firstError(diags); d == nil || d.Summary != "Provider not configured" {
+		t.Errorf("got errors %v, want Provider not configured", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+}
+
+// An unknown credential leaves the provider without a client. New resources
+// are planned, every operation needing the API fails with the same error.
+// terraform-plugin-framework v1.8.0 can't defer Configure, so this is the
+// behavior on every Terraform version.
+func TestConfigureUnknownCredentials(t *testing.T) {
+	domain := testDomain(t)
+	config := attrs{"domainname": domain, "hostname": "www", "type": "A", "destination": "192.0.2.1"}
+	p := newTestProvider(t, nil)
+	existing := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+	p.close()
+	changed := maps.Clone(config)
+	changed["destination"] = "192.0.2.2"
+
+	credentials := attrs{"customer_number": "12345", "key": "abcdefghijklmnopqrstuvwxyz", "password": "password"}
+	for _, attribute := range []string{"customer_number", "key", "password"} {
+		server := newLoginServer(t)
+		providerConfig := maps.Clone(credentials)
+		providerConfig["endpoint"] = server.URL
+		providerConfig[attribute] = tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+
+		p := startTestProvider(t)
+		diags := p.configure(providerConfig)
+		if hasErrors(diags) {
+			t.Fatalf("%s: configure failed: %v", attribute, summaries(diags, tfprotov6.DiagnosticSeverityError))
+		}
+		warnings := summaries(diags, tfprotov6.DiagnosticSeverityWarning)
+		if len(warnings) != 1 || warnings[0] != "Provider configuration unknown" || !strings.Contains(diags[0].Detail, attribute) {
+			t.Errorf("%s: got warnings %q, want Provider configuration unknown naming the attribute", attribute, warnings)
+		}
+		if p.provider.client != nil {
+			t.Errorf("%s: a client was constructed", attribute)
+		}
+
+		if _, diags := p.tryPlan("netcupdns_record", nullState(p, "netcupdns_record"), nil, config); hasErrors(diags) {
+			t.Errorf("%s: plan of a new record failed: %v", attribute, summaries(diags, tfprotov6.DiagnosticSeverityError))
+		}
+
+		operations := map[string][]*tfprotov6.Diagnostic{}
+		operations["create"] = p.tryApply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config).Diags
+		_, operations["read"] = p.read("netcupdns_record", existing.State, existing.Private)
+		operations["update"] = p.tryApply("netcupdns_record", existing.State, existing.Private, changed).Diags
+		operations["delete"] = p.tryApply("netcupdns_record", existing.State, existing.Private, nil).Diags
+		_, operations["import"] = p.importState("netcupdns_record", domain+"/"+attrString(t, existing.State, "id"))
+		_, operations["data source read"] = p.readDataSource("netcupdns_records", attrs{"domainname": domain})
+		for operation, diags := range operations {
+			errors := summaries(diags, tfprotov6.DiagnosticSeverityError)
+			if len(errors) != 1 || errors[0] != "Provider not configured" {
+				t.Errorf("%s: %s got errors %q, want Provider not configured", attribute, operation, errors)
+			}
+		}
+
+		if logins := server.Logins(); len(logins) > 0 {
+			t.Errorf("%s: logged in with %+v", attribute, logins)
+		}
+	}
+
+	if got := zoneRecords(t, domain); len(got) != 1 || got[0] != "www A 0 192.0.2.1" {
+		t.Errorf("zone changed without a client: %q", got)
+	}
+}
+
+// The mock ignores the credentials
+func TestConfigureMock(t *testing.T) {
+	for _, name := range []string{"NETCUP_CUSTOMER_NUMBER", "NETCUP_API_KEY", "NETCUP_API_PASSWORD"} {
+		t.Setenv(name, "")
+	}
+	p := startTestProvider(t)
+	var output bytes.Buffer
+	p.captureLogs(&output)
+	p.checkDiags("configure", p.configure(attrs{"mock": true}))
+	if p.provider.client == nil {
+		t.Error("no client was constructed")
+	}
+	if !strings.Contains(output.String(), "no real DNS records are read or changed") {
+		t.Errorf("logged no warning that the mock touches no real DNS:\n%s", output.String())
+	}
+}
+
+// A configuration of several resources plans and applies without credentials and network
+func TestMockMultiRecordConfig(t *testing.T) {
+	for _, name := range []string{"NETCUP_CUSTOMER_NUMBER", "NETCUP_API_KEY", "NETCUP_API_PASSWORD"} {
+		t.Setenv(name, "")
+	}
+	server := newLoginServer(t)
+	domain := testDomain(t)
+	connect := func() *testProvider {
+		p := startTestProvider(t)
+		p.checkDiags("configure", p.configure(attrs{"mock": true, "endpoint": server.URL}))
+		return p
+	}
+
+	setConfig := recordSetConfig(domain,
+		attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+		attrs{"hostname": "@", "type": "TXT", "destination": "v=spf1 mx -all"},
+	)
+	recordConfigs := []attrs{
+		{"domainname": domain, "hostname": "www", "type": "A", "destination": "192.0.2.1"},
+		{"domainname": domain, "hostname": "www", "type": "AAAA", "destination": "2001:db8::1"},
+		{"domainname": "other-" + domain, "hostname": "api", "type": "CNAME", "destination": "www." + domain + "."},
+	}
+
+	p := connect()
+	set := p.apply("netcupdns_record_set", nullState(p, "netcupdns_record_set"), nil, setConfig)
+	var records []applied
+	for _, config := range recordConfigs {
+		records = append(records, p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config))
+	}
+	p.close()
+
+	want := []string{
+		"@ MX 10 mail.example.com",
+		"@ TXT 0 v=spf1 mx -all",
+		"www A 0 192.0.2.1",
+		"www AAAA 0 2001:db8::1",
+	}
+	if got := zoneRecords(t, domain); !reflect.DeepEqual(got, want) {
+		t.Errorf("zone after apply:\n%q\nwant\n%q", got, want)
+	}
+	if got := zoneRecords(t, "other-"+domain); len(got) != 1 || got[0] != "api CNAME 0 www."+domain+"." {
+		t.Errorf("other zone after apply: %q", got)
+	}
+
+	// a new run sees the records of the last one and plans no changes
+	p = connect()
+	refreshed, private, diags := p.refresh("netcupdns_record_set", set.State, set.Private)
+	p.checkDiags("refresh of the record set", diags)
+	if planned := p.plan("netcupdns_record_set", refreshed, private, setConfig); !planned.Equal(refreshed) {
+		t.Errorf("plan of the record set isn't empty:\nplanned %s\nstate   %s", planned, refreshed)
+	}
+	p.apply("netcupdns_record_set", refreshed, private, nil)
+	for i, record := range records {
+		refreshed, private, diags := p.refresh("netcupdns_record", record.State, record.Private)
+		p.checkDiags("refresh of a record", diags)
+		if planned := p.plan("netcupdns_record", refreshed, private, recordConfigs[i]); !planned.Equal(refreshed) {
+			t.Errorf("plan of record %d isn't empty:\nplanned %s\nstate   %s", i, planned, refreshed)
+		}
+		p.apply("netcupdns_record", refreshed, private, nil)
+	}
+	p.close()
+
+	for _, d := range []string{domain, "other-" + domain} {
+		if got := zoneRecords(t, d); len(got) > 0 {
+			t.Errorf("zone %s after destroy: %q", d, got)
+		}
+	}
+	if logins := server.Logins(); len(logins) > 0 {
+		t.Errorf("mock contacted the endpoint with logins %+v", logins)
+	}
+}
+
+// Reconfiguring the provider logs out its previous session, a failed logout is only logged
+func TestConfigureLogsOutPreviousSession(t *testing.T) {
+	server := newLoginServer(t)
+	var mu sync.Mutex
+	logouts := 0
+	server.respond = func(action string, body []byte) map[string]interface{} {
+		if action != "logout" {
+			return nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		logouts++
+		if logouts == 1 {
+			return nil
+		}
+		return map[string]interface{}{"action": action, "status": "error", "statuscode": 4001, "shortmessage": "Session expired", "responsedata": ""}
+	}
+	configure := func(p *testProvider, customerNumber string) []*tfprotov6.Diagnostic {
+		return p.configure(attrs{
+			"endpoint": server.URL, "customer_number": customerNumber, "key": "abcdefghijklmnopqrstuvwxyz", "password": "password",
+			"max_retries": 0,
+		})
+	}
+
+	p := startTestProvider(t)
+	var output bytes.Buffer
+	p.captureLogs(&output)
+	p.checkDiags("first configure", configure(p, "48481"))
+	p.checkDiags("second configure", configure(p, "48482"))
+	if strings.Contains(output.String(), "Could not log out previous session") {
+		t.Errorf("warned about a successful logout:\n%s", output.String())
+	}
+
+	diags := configure(p, "48483")
+	p.checkDiags("third configure", diags)
+	if len(diags) > 0 {
+		t.Errorf("failed logout added diagnostics %v", summaries(diags, tfprotov6.DiagnosticSeverityWarning))
+	}
+	if !strings.Contains(output.String(), "Could not log out previous session") {
+		t.Errorf("logged no warning about the failed logout:\n%s", output.String())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if logouts != 2 {
+		t.Errorf("logged out %d times, want once per reconfiguration", logouts)
+	}
+}
+
+func TestConfigureEndpoint(t *testing.T) {
+	server := newLoginServer(t)
+	credentials := attrs{"customer_number": "49491", "key": "abcdefghijklmnopqrstuvwxyz", "password": "password"}
+	tests := []struct {
+		name     string
+		endpoint interface{}
+		env      string
+		error    bool
+	}{
+		{name: "attribute", endpoint: server.URL},
+		{name: "environment", env: server.URL},
+		{name: "attribute before the environment", endpoint: server.URL, env: "https://127.0.0.1:1/endpoint.php"},
+		{name: "http", endpoint: strings.Replace(server.URL, "https://", "http://", 1), error: true},
+		{name: "relative", endpoint: "ccp.netcup.net/run/webservice/servers/endpoint.php?JSON", error: true},
+		{name: "without host", endpoint: "https:///endpoint.php", error: true},
+		{name: "unparsable", endpoint: "https://%zz", error: true},
+		{name: "invalid in the environment", env: "ftp://ccp.netcup.net/", error: true},
+	}
+	for _, tt := range tests {
+		t.Setenv("NETCUP_API_ENDPOINT", tt.env)
+		before := len(server.Logins())
+		config := maps.Clone(credentials)
+		config["endpoint"] = tt.endpoint
+		p := startTestProvider(t)
+		diags := p.configure(config)
+		logins := len(server.Logins()) - before
+		p.close()
+
+		if tt.error {
+			if d := firstError(diags); d == nil || d.Summary != "Invalid endpoint" || d.Attribute == nil ||
+				!d.Attribute.Equal(tftypes.NewAttributePath().WithAttributeName("endpoint")) {
+				t.Errorf("%s: got errors %v, want Invalid endpoint", tt.name, summaries(diags, tfprotov6.DiagnosticSeverityError))
+			}
+			if logins > 0 {
+				t.Errorf("%s: logged in with an invalid endpoint", tt.name)
+			}
+			continue
+		}
+		if hasErrors(diags) {
+			t.Errorf("%s: configure failed: %v", tt.name, summaries(diags, tfprotov6.DiagnosticSeverityError))
+		}
+		if logins != 1 {
+			t.Errorf("%s: logged in %d times at the endpoint, want once", tt.name, logins)
+		}
+	}
+}
+
+func TestConfigureRequestTimeout(t *testing.T) {
+	server := newLoginServer(t)
+	server.onRequest = func(action string) {
+		if action == "infoDnsRecords" {
+			time.Sleep(200 * time.Millisecond)
+		}
+	}
+	configure := func(p *testProvider, timeout interface{}) []*tfprotov6.Diagnostic {
+		return p.configure(attrs{
+			"endpoint": server.URL, "customer_number": "49492", "key": "abcdefghijklmnopqrstuvwxyz", "password": "password",
+			"max_retries": 0, "request_timeout": timeout,
+		})
+	}
+
+	for _, timeout := range []string{"10", "0s", "-1s", "soon"} {
+		p := startTestProvider(t)
+		if d := firstError(configure(p, timeout)); d == nil || d.Summary != "Invalid request timeout" {
+			t.Errorf("request timeout %q: got %v, want Invalid request timeout", timeout, d)
+		}
+	}
+
+	p := startTestProvider(t)
+	p.checkDiags("configure", configure(p, "50ms"))
+	if _, diags := p.readDataSource("netcupdns_records", attrs{"domainname": testDomain(t)}); !hasErrors(diags) {
+		t.Error("read slower than request_timeout succeeded")
+	}
+	p.close()
+
+	// without request_timeout the default of 10 seconds applies
+	p = startTestProvider(t)
+	p.checkDiags("configure", configure(p, nil))
+	_, diags := p.readDataSource("netcupdns_records", attrs{"domainname": testDomain(t)})
+	p.checkDiags("read with the default request timeout", diags)
+}
+
+func TestConfigureRecordCacheSize(t *testing.T) {
+	p := startTestProvider(t)
+	diags := p.configure(attrs{"mock": true, "record_cache_size": 0})
+	if d := firstError(diags); d == nil || d.Summary != "Invalid record cache size" {
+		t.Errorf("got errors %v, want Invalid record cache size", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+
+	// a single cached domain still reads every zone right, evicted ones are read again
+	first, second := "first."+testDomain(t), "second."+testDomain(t)
+	seedZone(t, first, attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"})
+	seedZone(t, second, attrs{"hostname": "www", "type": "A", "destination": "192.0.2.2"}, attrs{"hostname": "mail", "type": "A", "destination": "192.0.2.3"})
+
+	p = newTestProvider(t, attrs{"customer_number": "45151", "record_cache_size": 1})
+	for _, read := range []struct {
+		domain string
+		want   int
+	}{{first, 1}, {second, 2}, {first, 1}, {second, 2}} {
+		state, diags := p.readDataSource("netcupdns_records", attrs{"domainname": read.domain})
+		p.checkDiags("read of "+read.domain, diags)
+		if got := len(elementsOf(t, attrValue(t, state, "records"))); got != read.want {
+			t.Errorf("read %d records of %s, want %d", got, read.domain, read.want)
+		}
+	}
+}
+
+// Each cause of a failed login is explained with what to do about it
+func TestConfigureLoginFailures(t *testing.T) {
+	tests := []struct {
+		response string
+		status   int
+		summary  string
+		explains string
+	}{
+		{"login_invalid_credentials.json", http.StatusOK, "Unable to create CCP client", "The customer number, API key or API password is wrong"},
+		{"login_api_not_activated.json", http.StatusOK, "Unable to create CCP client", "API access is not activated for this account"},
+		{"login_account_locked.json", http.StatusOK, "Unable to create CCP client", "The account is temporarily blocked"},
+		{"login_rate_limited.json", http.StatusOK, "Netcup API rate limit exceeded", "lower the parallelism of terraform"},
+		{"login_internal_error.json", http.StatusOK, "Unable to create CCP client", "Check the credentials and the API settings"},
+		{"html_bad_gateway.html", http.StatusBadGateway, "Unable to create CCP client", "The Netcup CCP API could not be reached"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.response, func(t *testing.T) {
+			server := newLoginServer(t)
+			server.fail(t, tt.status, tt.response)
+
+			p := startTestProvider(t)
+			diags := p.configure(attrs{
+				"endpoint": server.URL, "customer_number": "33333", "key": "failingkey0123456789", "password": "failing-password",
+				"max_retries": 0,
+			})
+			d := firstError(diags)
+			if d == nil || d.Summary != tt.summary {
+				t.Fatalf("got errors %v, want %s", summaries(diags, tfprotov6.DiagnosticSeverityError), tt.summary)
+			}
+			if !strings.Contains(d.Detail, tt.explains) {
+				t.Errorf("detail doesn't explain %q:\n%s", tt.explains, d.Detail)
+			}
+			if strings.Contains(d.Detail, "failing-password") || strings.Contains(d.Detail, "failingkey0123456789") {
+				t.Errorf("detail contains the credentials:\n%s", d.Detail)
+			}
+			if (tt.response == "login_invalid_credentials.json" || tt.response == "login_api_not_activated.json") && !strings.Contains(d.Detail, "customercontrolpanel.de") {
+				t.Errorf("detail doesn't name the CCP page to visit:\n%s", d.Detail)
+			}
+		})
+	}
+}
+
+// A statuscode failing reads by default is retried once configured in retry_on_statuscodes
+func TestConfigureRetryOnStatuscodes(t *testing.T) {
+	const maintenance = 4999
+	tests := []struct {
+		name     string
+		config   attrs
+		attempts int
+		fails    bool
+	}{
+		{"default", attrs{}, 1, true},
+		{"retry_on", attrs{"retry_on_statuscodes": []interface{}{maintenance}}, 2, false},
+		{"never_retry", attrs{"retry_on_statuscodes": []interface{}{maintenance}, "never_retry_statuscodes": []interface{}{maintenance}}, 1, true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newLoginServer(t)
+			var mu sync.Mutex
+			attempts := 0
+			server.respond = func(action string, body []byte) map[string]interface{} {
+				if action != "infoDnsRecords" {
+					return nil
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				attempts++
+				if attempts > 1 {
+					return nil
+				}
+				return map[string]interface{}{
+					"action": action, "status": "error", "statuscode": maintenance, "shortmessage": "Maintenance.", "responsedata": "",
+				}
+			}
+
+			config := attrs{
+				"endpoint": server.URL, "customer_number": strconv.Itoa(47470 + i), "key": "retrykey0123456789abc", "password": "retry-password",
+				"max_retries": 3, "retry_base_delay": "1ms",
+			}
+			for name, value := range tt.config {
+				config[name] = value
+			}
+			p := startTestProvider(t)
+			p.checkDiags("configure", p.configure(config))
+
+			_, diags := p.readDataSource("netcupdns_records", attrs{"domainnames": []interface{}{testDomain(t)}})
+			if hasErrors(diags) != tt.fails {
+				t.Errorf("read failed: %t, want %t: %v", hasErrors(diags), tt.fails, summaries(diags, tfprotov6.DiagnosticSeverityError))
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if attempts != tt.attempts {
+				t.Errorf("sent %d infoDnsRecords requests, want %d", attempts, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestConfigureInvalidStatuscodes(t *testing.T) {
+	for _, attribute := range []string{"retry_on_statuscodes", "never_retry_statuscodes", "zone_locked_statuscodes"} {
+		t.Run(attribute, func(t *testing.T) {
+			p := startTestProvider(t)
+			diags := p.configure(attrs{"mock": true, attribute: []interface{}{503, 0}})
+			d := firstError(diags)
+			if d == nil || d.Summary != "Invalid statuscode" {
+				t.Fatalf("got errors %v, want Invalid statuscode", summaries(diags, tfprotov6.DiagnosticSeverityError))
+			}
+			want := tftypes.NewAttributePath().WithAttributeName(attribute).WithElementKeyInt(1)
+			if d.Attribute == nil || !d.Attribute.Equal(want) {
+				t.Errorf("error at %v, want the second element of %s", d.Attribute, attribute)
+			}
+		})
+	}
+}
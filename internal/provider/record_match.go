@@ -0,0 +1,20 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+// Read the records of a zone matching the filter in deterministic order.
+// An empty hostname refers to the apex, like in the resource schema.
+func lookupDnsRecords(ctx context.Context, c *client.CCPClient, domainname string, filter client.RecordFilter) ([]client.DnsRecord, error) {
+	filter.Hostname = normalizeHostname(filter.Hostname, domainname)
+
+	matches, err := c.GetDnsRecordsFiltered(ctx, domainname, filter)
+	if err != nil {
+		return nil, err
+	}
+	sortDnsRecords(matches)
+	return matches, nil
+}
@@ -0,0 +1,551 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ resource.Resource              = &autoconfigMailResource{}
+	_ resource.ResourceWithConfigure = &autoconfigMailResource{}
+)
+
+func NewAutoconfigMailResource() resource.Resource {
+	return &autoconfigMailResource{}
+}
+
+type autoconfigMailResource struct {
+	client *client.CCPClient
+}
+
+func (r *autoconfigMailResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_autoconfig_mail"
+}
+
+func (r *autoconfigMailResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	requiresReplace := []planmodifier.Bool{boolplanmodifier.RequiresReplace()}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the conventional records used by mail clients to auto-configure themselves " +
+			"(`autoconfig`/`autodiscover` CNAMEs and the `_autodiscover._tcp`, `_imaps._tcp` and `_submission._tcp` SRV records).",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Identifier of the record group. Equals the domainname.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the records.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"mail_host": schema.StringAttribute{
+				Required:    true,
+				Description: "Hostname of the mail server the records point to, e.g. mail.example.com.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"autoconfig": schema.BoolAttribute{
+				Optional:      true,
+				Computed:      true,
+				Default:       booldefault.StaticBool(true),
+				Description:   "Create the `autoconfig` CNAME used by Thunderbird and others. Defaults to true.",
+				PlanModifiers: requiresReplace,
+			},
+			"autodiscover": schema.BoolAttribute{
+				Optional:      true,
+				Computed:      true,
+				Default:       booldefault.StaticBool(true),
+				Description:   "Create the `autodiscover` CNAME used by Outlook. Defaults to true.",
+				PlanModifiers: requiresReplace,
+			},
+			"autodiscover_srv": schema.BoolAttribute{
+				Optional:      true,
+				Computed:      true,
+				Default:       booldefault.StaticBool(true),
+				Description:   "Create the `_autodiscover._tcp` SRV record. Defaults to true.",
+				PlanModifiers: requiresReplace,
+			},
+			"imaps_srv": schema.BoolAttribute{
+				Optional:      true,
+				Computed:      true,
+				Default:       booldefault.StaticBool(false),
+				Description:   "Create the `_imaps._tcp` SRV record (RFC 6186). Defaults to false.",
+				PlanModifiers: requiresReplace,
+			},
+			"submission_srv": schema.BoolAttribute{
+				Optional:      true,
+				Computed:      true,
+				Default:       booldefault.StaticBool(false),
+				Description:   "Create the `_submission._tcp` SRV record (RFC 6186). Defaults to false.",
+				PlanModifiers: requiresReplace,
+			},
+			"allow_overwrite": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Delete existing records at the managed names instead of failing. Defaults to false.",
+			},
+			"rollback_on_failure": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+				Description: "Delete the records that were created anyway when creating the records fails. " +
+					"Otherwise they are kept in the state and the resource is replaced on the next apply. Defaults to false.",
+			},
+			"records": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Records managed by this resource.",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:    true,
+							Description: "Unique ID of the record. Provided from Netcup-API",
+						},
+						"hostname": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the record.",
+						},
+						"type": schema.StringAttribute{
+							Computed:    true,
+							Description: "Type of the record.",
+						},
+						"priority": schema.StringAttribute{
+							Computed:    true,
+							Description: "Priority of the record.",
+						},
+						"destination": schema.StringAttribute{
+							Computed:    true,
+							Description: "Target of the record.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *autoconfigMailResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.client = req.ProviderData.(*client.CCPClient)
+}
+
+// Records to emit for the enabled conventions
+func autoconfigMailRecords(m AutoconfigMail) []client.NewDnsRecord {
+	target := strings.TrimSuffix(m.MailHost.ValueString(), ".") + "."
+
+	var records []client.NewDnsRecord
+	if m.Autoconfig.ValueBool() {
+		records = append(records, client.NewDnsRecord{Hostname: "autoconfig", Type: "CNAME", Destination: target})
+	}
+	if m.Autodiscover.ValueBool() {
+		records = append(records, client.NewDnsRecord{Hostname: "autodiscover", Type: "CNAME", Destination: target})
+	}
+	if m.AutodiscoverSrv.ValueBool() {
+		records = append(records, client.NewDnsRecord{Hostname: "_autodiscover._tcp", Type: "SRV", Priority: "0", Destination: "0 443 " + target})
+	}
+	if m.ImapsSrv.ValueBool() {
+		records = append(records, client.NewDnsRecord{Hostname: "_imaps._tcp", Type: "SRV", Priority: "0", Destination: "0 993 " + target})
+	}
+	if m.SubmissionSrv.ValueBool() {
+		records = append(records, client.NewDnsRecord{Hostname: "_submission._tcp", Type: "SRV", Priority: "0", Destination: "0 587 " + target})
+	}
+	return records
+}
+
+// Existing records at the names of the wanted records
+func (r autoconfigMailResource) conflictingRecords(ctx context.Context, domainname string, wanted []client.NewDnsRecord) ([]client.DnsRecord, error) {
+	var conflicts []client.DnsRecord
+	seen := make(map[string]bool)
+	for _, w := range wanted {
+		hostname := normalizeHostname(w.Hostname, domainname)
+		if seen[hostname] {
+			continue
+		}
+		seen[hostname] = true
+
+		records, err := r.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{Hostname: hostname})
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, records...)
+	}
+	return conflicts, nil
+}
+
+// Wanted records that exist in the zone, matched by hostname, type and destination
+func (r autoconfigMailResource) existingRecords(ctx context.Context, domainname string, wanted []client.NewDnsRecord) ([]client.DnsRecord, []client.NewDnsRecord, error) {
+	var found []client.DnsRecord
+	var missing []client.NewDnsRecord
+	for _, w := range wanted {
+		records, err := r.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{
+			Hostname:    w.Hostname,
+			Type:        w.Type,
+			Destination: w.Destination,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(records) == 0 {
+			missing = append(missing, w)
+			continue
+		}
+		found = append(found, records[0])
+	}
+	return found, missing, nil
+}
+
+// Find out which records a failed create left in the zone. With rollback_on_failure
+// they are deleted, otherwise they are stored in the state, so they stay managed
+// instead of orphaned. As the create failed, the resource is replaced on the next apply.
+func (r autoconfigMailResource) reconcileFailedCreate(ctx context.Context, plan AutoconfigMail, wanted []client.NewDnsRecord, resp *resource.CreateResponse) {
+	domainname := plan.Domainname.ValueString()
+
+	found, missing, err := r.existingRecords(ctx, domainname, wanted)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading records",
+			"Could not check which autoconfig mail records of domain "+domainname+" were created: "+err.Error()+"\n"+
+				"Remove records created anyway from the zone before applying again.",
+		)
+		return
+	}
+	if len(found) == 0 {
+		return
+	}
+
+	var lines []string
+	for _, record := range missing {
+		lines = append(lines, fmt.Sprintf("  - hostname=%s type=%s destination=%s", record.Hostname, record.Type, record.Destination))
+	}
+	notCreated := ""
+	if len(lines) > 0 {
+		notCreated = "\n\nRecords not created:\n" + strings.Join(lines, "\n")
+	}
+
+	if plan.RollbackOnFailure.ValueBool() {
+		tflog.Trace(ctx, "Rolling back autoconfig mail records", map[string]interface{}{"domainname": domainname, "count": len(found)})
+
+		err = r.client.DeleteDnsRecords(ctx, domainname, found)
+		if err == nil {
+			resp.Diagnostics.AddWarning(
+				"Partially created records rolled back",
+				"Deleted the records created before the failure:\n"+formatRecordCandidates(found)+notCreated,
+			)
+			return
+		}
+		addDeleteError(&resp.Diagnostics, domainname, "roll back", err)
+
+		// keep what couldn't be deleted in the state
+		var deleteErr *client.DeleteError
+		if errors.As(err, &deleteErr) {
+			found = deleteErr.Remaining
+		}
+	}
+
+	records, diags := managedRecordsValue(ctx, found)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	plan.ID = types.StringValue(domainname)
+	plan.Records = records
+
+	resp.Diagnostics.AddWarning(
+		"Partially created records kept in state",
+		"These records were created before the failure and are managed by this resource now. "+
+			"It is replaced on the next apply, or set rollback_on_failure = true to delete them on failure:\n"+
+			formatRecordCandidates(found)+notCreated,
+	)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func managedRecordsValue(ctx context.Context, records []client.DnsRecord) (types.List, diag.Diagnostics) {
+	values := make([]ManagedRecord, 0, len(records))
+	for _, record := range records {
+		values = append(values, ManagedRecord{
+			ID:          types.StringValue(record.Id),
+			Hostname:    types.StringValue(record.Hostname),
+			Type:        types.StringValue(record.Type),
+			Priority:    types.StringValue(record.Priority),
+			Destination: types.StringValue(record.Destination),
+		})
+	}
+	return types.ListValueFrom(ctx, types.ObjectType{AttrTypes: managedRecordAttrTypes}, values)
+}
+
+// Create a new resource
+func (r autoconfigMailResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
+	// Retrieve values from plan
+	var plan AutoconfigMail
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := plan.Domainname.ValueString()
+	wanted := autoconfigMailRecords(plan)
+	if len(wanted) == 0 {
+		resp.Diagnostics.AddError(
+			"No records to create",
+			"At least one of autoconfig, autodiscover, autodiscover_srv, imaps_srv or submission_srv must be enabled.",
+		)
+		return
+	}
+
+	conflicts, err := r.conflictingRecords(ctx, domainname, wanted)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	if len(conflicts) > 0 && !plan.AllowOverwrite.ValueBool() {
+		var lines []string
+		for _, record := range conflicts {
+			lines = append(lines, fmt.Sprintf("  - %s %s %s (id=%s)", record.Hostname, record.Type, record.Destination, record.Id))
+		}
+		resp.Diagnostics.AddError(
+			"Conflicting records exist",
+			"The following records already exist at names managed by this resource. "+
+				"Remove them or set allow_overwrite = true to replace them:\n"+strings.Join(lines, "\n"),
+		)
+		return
+	}
+
+	update := beginZoneUpdate(ctx, r.client, domainname, &resp.Diagnostics)
+
+	for _, record := range conflicts {
+		tflog.Trace(ctx, "Overwriting DNS Record", dnsRecordLogFields(domainname, record))
+	}
+
+	err = r.client.DeleteDnsRecords(ctx, domainname, conflicts)
+	if err != nil {
+		addDeleteError(&resp.Diagnostics, domainname, "delete conflicting", err)
+		return
+	}
+
+	tflog.Trace(ctx, "Create autoconfig mail records", map[string]interface{}{"domainname": domainname, "count": len(wanted)})
+
+	created, err := r.client.CreateDnsRecords(ctx, domainname, wanted)
+	if err != nil {
+		if !addRateLimitError(&resp.Diagnostics, "Netcup throttled the request to create the autoconfig mail records of domain "+domainname+".", err) &&
+			!addZoneLockedError(&resp.Diagnostics, "Netcup rejected the request to create the autoconfig mail records of domain "+domainname+".", err) {
+			resp.Diagnostics.AddError(
+				"Error creating dns records",
+				"Could not create autoconfig mail records of domain "+domainname+": "+err.Error(),
+			)
+		}
+		r.reconcileFailedCreate(ctx, plan, wanted, resp)
+		return
+	}
+	warnDnssecZone(ctx, r.client, domainname, &resp.Diagnostics)
+	update.await(ctx, r.client, &resp.Diagnostics)
+
+	records, diags := managedRecordsValue(ctx, created)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue(domainname)
+	plan.Records = records
+
+	resp.Diagnostics.Append(forceNextRead(ctx, resp.Private)...)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information
+func (r autoconfigMailResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "refresh", &resp.Diagnostics) {
+		return
+	}
+
+	// Get current state
+	var state AutoconfigMail
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if skipRefresh(ctx, r.client, req.Private, &resp.Diagnostics) {
+		tflog.Trace(ctx, "Skipping refresh of autoconfig mail records", map[string]interface{}{"domainname": state.Domainname.ValueString()})
+		return
+	}
+
+	var managed []ManagedRecord
+	diags = state.Records.ElementsAs(ctx, &managed, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	existing, err := r.client.GetDnsRecords(ctx, state.Domainname.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading records",
+			"Could not read records of domain "+state.Domainname.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	var current []client.DnsRecord
+	for _, record := range managed {
+		for _, e := range existing {
+			if e.Id == record.ID.ValueString() {
+				current = append(current, e)
+				break
+			}
+		}
+	}
+
+	// Recreate the whole group if any of its records vanished
+	if len(current) != len(managed) {
+		tflog.Trace(ctx, "Autoconfig mail records missing, removing from state", map[string]interface{}{"domainname": state.Domainname.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	records, diags := managedRecordsValue(ctx, current)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Records = records
+
+	resp.Diagnostics.Append(clearForceRead(ctx, resp.Private)...)
+
+	// Set state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update resource. Every attribute except allow_overwrite forces replacement.
+func (r autoconfigMailResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
+	var plan AutoconfigMail
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get current state
+	var state AutoconfigMail
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = state.ID
+	plan.Records = state.Records
+
+	// Set state
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete resource
+func (r autoconfigMailResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
+	// Get current state
+	var state AutoconfigMail
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var managed []ManagedRecord
+	diags = state.Records.ElementsAs(ctx, &managed, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	update := beginZoneUpdate(ctx, r.client, state.Domainname.ValueString(), &resp.Diagnostics)
+
+	dnsRecords := make([]client.DnsRecord, 0, len(managed))
+	for _, record := range managed {
+		var dnsRecord = client.DnsRecord{
+			Id:          record.ID.ValueString(),
+			Hostname:    record.Hostname.ValueString(),
+			Type:        record.Type.ValueString(),
+			Priority:    record.Priority.ValueString(),
+			Destination: record.Destination.ValueString(),
+		}
+
+		tflog.Trace(ctx, "Deleting DNS Record", dnsRecordLogFields(state.Domainname.ValueString(), dnsRecord))
+		dnsRecords = append(dnsRecords, dnsRecord)
+	}
+
+	err := r.client.DeleteDnsRecords(ctx, state.Domainname.ValueString(), dnsRecords)
+	if err != nil {
+		addDeleteError(&resp.Diagnostics, state.Domainname.ValueString(), "delete", err)
+		return
+	}
+	warnDnssecZone(ctx, r.client, state.Domainname.ValueString(), &resp.Diagnostics)
+	update.await(ctx, r.client, &resp.Diagnostics)
+
+	// Remove resource from state
+	resp.State.RemoveResource(ctx)
+}
@@ -0,0 +1,255 @@
+package provider
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+func TestAutoconfigMailRecords(t *testing.T) {
+	domain := testDomain(t)
+	config := attrs{"domainname": domain, "mail_host": "mail.example.com", "imaps_srv": true}
+
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_autoconfig_mail", nullState(p, "netcupdns_autoconfig_mail"), nil, config)
+
+	want := []string{
+		"_autodiscover._tcp SRV 0 0 443 mail.example.com.",
+		"_imaps._tcp SRV 0 0 993 mail.example.com.",
+		"autoconfig CNAME 0 mail.example.com.",
+		"autodiscover CNAME 0 mail.example.com.",
+	}
+	if got := zoneRecords(t, domain); !reflect.DeepEqual(got, want) {
+		t.Errorf("zone has records %v, want %v", got, want)
+	}
+	if n := len(elementsOf(t, attrValue(t, created.State, "records"))); n != len(want) {
+		t.Errorf("state has %d records, want %d", n, len(want))
+	}
+
+	p = newTestProvider(t, nil)
+	refreshed, diags := p.read("netcupdns_autoconfig_mail", created.State, created.Private)
+	p.checkDiags("refresh", diags)
+	if planned := p.plan("netcupdns_autoconfig_mail", refreshed, created.Private, config); !planned.Equal(refreshed) {
+		t.Errorf("plan after apply isn't empty:\nplanned %s\nstate   %s", planned, refreshed)
+	}
+
+	p = newTestProvider(t, nil)
+	p.apply("netcupdns_autoconfig_mail", refreshed, created.Private, nil)
+	if got := zoneRecords(t, domain); len(got) != 0 {
+		t.Errorf("zone has records %v after destroy, want none", got)
+	}
+}
+
+func TestAutoconfigMailExistingRecords(t *testing.T) {
+	domain := testDomain(t)
+	seedZone(t, domain, attrs{"hostname": "autoconfig", "type": "A", "destination": "192.0.2.1"})
+	config := attrs{"domainname": domain, "mail_host": "mail.example.com.", "autodiscover": false, "autodiscover_srv": false}
+
+	p := newTestProvider(t, nil)
+	result := p.tryApply("netcupdns_autoconfig_mail", nullState(p, "netcupdns_autoconfig_mail"), nil, config)
+	if !hasErrors(result.Diags) {
+		t.Fatalf("create over an existing record succeeded")
+	}
+
+	config["allow_overwrite"] = true
+	p = newTestProvider(t, nil)
+	p.apply("netcupdns_autoconfig_mail", nullState(p, "netcupdns_autoconfig_mail"), nil, config)
+	if got, want := zoneRecords(t, domain), []string{"autoconfig CNAME 0 mail.example.com."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("zone has records %v, want %v", got, want)
+	}
+}
+
+// Zone of a scripted API whose next write of records fails after creating some
+// of them, like the API does for a batch with an invalid record in the middle
+type partialWriteAPI struct {
+	mu      sync.Mutex
+	records []client.DnsRecord
+	lastId  int
+	// records the next write creates before failing, -1 to not fail
+	failAfter int
+	// records a delete removes, the others stay in the zone, -1 for all
+	deleteLimit int
+}
+
+func (a *partialWriteAPI) respond(action string, body []byte) map[string]interface{} {
+	var request struct {
+		Param struct {
+			DnsRecordSet client.DnsRecordSet `json:"dnsrecordset"`
+		} `json:"param"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil
+	}
+	success := func() map[string]interface{} {
+		return map[string]interface{}{
+			"action": action, "status": "success", "statuscode": 2000, "shortmessage": "ok",
+			"responsedata": client.DnsRecordSet{DnsRecords: a.records},
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch action {
+	case "infoDnsRecords":
+		return success()
+	case "updateDnsRecords":
+		created, deleted := 0, 0
+		for _, record := range request.Param.DnsRecordSet.DnsRecords {
+			if record.DeleteRecord {
+				if a.deleteLimit >= 0 && deleted >= a.deleteLimit {
+					continue
+				}
+				deleted++
+				for i := range a.records {
+					if a.records[i].Id == record.Id {
+						a.records = append(a.records[:i], a.records[i+1:]...)
+						break
+					}
+				}
+				continue
+			}
+			if created == a.failAfter {
+				a.failAfter = -1
+				return map[string]interface{}{
+					"action": action, "status": "error", "statuscode": 5028, "shortmessage": "Validation Error.",
+					"longmessage": "The DNS record " + record.Hostname + " could not be created.", "responsedata": "",
+				}
+			}
+			created++
+			a.lastId++
+			record.Id, record.State = strconv.Itoa(a.lastId), "yes"
+			if record.Priority == "" {
+				record.Priority = "0"
+			}
+			a.records = append(a.records, record)
+		}
+		return success()
+	}
+	return nil
+}
+
+// "hostname type" of records
+func recordHostnames(records []client.DnsRecord) []string {
+	names := []string{}
+	for _, record := range records {
+		names = append(names, record.Hostname+" "+record.Type)
+	}
+	return names
+}
+
+// A create failing after the Nth record keeps the records created before in the
+// state or deletes them with rollback_on_failure, and lists the records not created
+func TestAutoconfigMailPartialFailure(t *testing.T) {
+	// records the config creates, in the order they are sent
+	all := []string{"autoconfig CNAME", "autodiscover CNAME", "_autodiscover._tcp SRV"}
+
+	tests := []struct {
+		name        string
+		failAfter   int
+		rollback    bool
+		deleteLimit int
+		// records in the zone and in the state after the create
+		remaining int
+		warning   string
+		errors    []string
+	}{
+		{"first fails", 0, false, -1, 0, "", []string{"Error creating dns records"}},
+		{"second fails", 1, false, -1, 1, "Partially created records kept in state", []string{"Error creating dns records"}},
+		{"last fails", 2, false, -1, 2, "Partially created records kept in state", []string{"Error creating dns records"}},
+		{"rolled back", 2, true, -1, 0, "Partially created records rolled back", []string{"Error creating dns records"}},
+		{"first fails rolled back", 0, true, -1, 0, "", []string{"Error creating dns records"}},
+		{"rollback fails", 2, true, 1, 1, "Partially created records kept in state", []string{"Error creating dns records", "Error deleting record"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &partialWriteAPI{failAfter: tt.failAfter, deleteLimit: tt.deleteLimit}
+			server := newLoginServer(t)
+			server.respond = api.respond
+
+			p := startTestProvider(t)
+			p.checkDiags("configure", p.configure(attrs{
+				"endpoint": server.URL, "customer_number": "47272", "key": "abcdefghijklmnopqrstuvwxyz", "password": "password",
+				"max_retries": 0,
+			}))
+			domain := testDomain(t)
+			result := p.tryApply("netcupdns_autoconfig_mail", nullState(p, "netcupdns_autoconfig_mail"), nil, attrs{
+				"domainname": domain, "mail_host": "mail.example.com", "rollback_on_failure": tt.rollback,
+			})
+
+			if got := summaries(result.Diags, tfprotov6.DiagnosticSeverityError); !reflect.DeepEqual(got, tt.errors) {
+				t.Errorf("got errors %v, want %v", got, tt.errors)
+			}
+			d := firstError(result.Diags)
+			if d == nil || !strings.Contains(d.Detail, "could not be created") {
+				t.Errorf("create error doesn't explain why: %v", d)
+			}
+
+			api.mu.Lock()
+			zone := append([]client.DnsRecord(nil), api.records...)
+			api.mu.Unlock()
+			if len(zone) != tt.remaining {
+				t.Fatalf("zone has records %v, want %d", recordHostnames(zone), tt.remaining)
+			}
+
+			if tt.remaining == 0 {
+				if !result.State.IsNull() {
+					t.Errorf("state %s, want none", result.State)
+				}
+			} else {
+				var ids []string
+				for _, record := range elementsOf(t, attrValue(t, result.State, "records")) {
+					ids = append(ids, attrString(t, record, "id"))
+				}
+				var want []string
+				for _, record := range zone {
+					want = append(want, record.Id)
+				}
+				if !reflect.DeepEqual(ids, want) {
+					t.Errorf("state manages records %v, want the records of the zone %v", ids, want)
+				}
+				if got := attrString(t, result.State, "id"); got != domain {
+					t.Errorf("state has id %q, want %s", got, domain)
+				}
+			}
+
+			warnings := summaries(result.Diags, tfprotov6.DiagnosticSeverityWarning)
+			if tt.warning == "" {
+				if len(warnings) > 0 {
+					t.Errorf("got warnings %v, want none", warnings)
+				}
+				return
+			}
+			if !reflect.DeepEqual(warnings, []string{tt.warning}) {
+				t.Fatalf("got warnings %v, want %s", warnings, tt.warning)
+			}
+			var detail string
+			for _, d := range result.Diags {
+				if d.Summary == tt.warning {
+					detail = d.Detail
+				}
+			}
+			// the warning lists the records created before the failure that are left or deleted
+			listed, notCreated, _ := strings.Cut(detail, "Records not created:")
+			for i, name := range all {
+				hostname := strings.Fields(name)[0]
+				inZone := false
+				for _, record := range zone {
+					inZone = inZone || record.Hostname == hostname
+				}
+				if want := i < tt.failAfter && (inZone || tt.deleteLimit < 0); want != strings.Contains(listed, "hostname="+hostname+" ") {
+					t.Errorf("%s listed as created: %t, want %t:\n%s", hostname, !want, want, detail)
+				}
+				if want := i >= tt.failAfter; want != strings.Contains(notCreated, "hostname="+hostname+" ") {
+					t.Errorf("%s listed as not created: %t, want %t:\n%s", hostname, !want, want, detail)
+				}
+			}
+		})
+	}
+}
@@ -2,20 +2,30 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 
-	"github.com/fatih/structs"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+	"github.com/svetob/terraform-provider-netcupdns/internal/dnstypes"
 )
 
 var (
-	_ resource.Resource                = &dnsRecordDataSource{}
-	_ resource.ResourceWithConfigure   = &dnsRecordDataSource{}
-	_ resource.ResourceWithImportState = &dnsRecordDataSource{}
+	_ resource.Resource                   = &dnsRecordDataSource{}
+	_ resource.ResourceWithConfigure      = &dnsRecordDataSource{}
+	_ resource.ResourceWithImportState    = &dnsRecordDataSource{}
+	_ resource.ResourceWithModifyPlan     = &dnsRecordDataSource{}
+	_ resource.ResourceWithValidateConfig = &dnsRecordDataSource{}
 )
 
 func NewDnsRecordDataSource() resource.Resource {
@@ -32,7 +42,7 @@ func (r *dnsRecordDataSource) Metadata(_ context.Context, req resource.MetadataR
 
 func (r *dnsRecordDataSource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "Represents a DNS-Record. See [Netcup-API](https://ccp.netcup.net/run/webservice/servers/endpoint.php#Dnsrecord)",
+		MarkdownDescription: "Represents a DNS-Record. See [Netcup-API](https://ccp.netcup.net/run/webservice/servers/endpoint.php#Dnsrecord). Two resources declaring the same hostname, type and destination of a domain are rejected when planning. The error names the record, as Terraform doesn't pass the addresses of resources to providers.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Required:    false,
@@ -43,29 +53,131 @@ func (r *dnsRecordDataSource) Schema(_ context.Context, req resource.SchemaReque
 			"domainname": schema.StringAttribute{
 				Required:    true,
 				Description: "Domainname of the record.",
+				PlanModifiers: []planmodifier.String{
+					createOnlyRequiresReplace(),
+				},
 			},
 			"hostname": schema.StringAttribute{
 				Required:    true,
-				Description: "Name of the record. Use '@' for root of domain.",
+				CustomType:  dnstypes.HostnameType{},
+				Description: "Name of the record. Use '@' for root of domain. Absolute names with a trailing dot like 'www.example.com.' are stored relative to the zone.",
+				Validators: []validator.String{
+					dnstypes.HostnameValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					createOnlyRequiresReplace(),
+				},
 			},
 			"type": schema.StringAttribute{
 				Required:    true,
-				Description: "Type of Record like A or MX.",
+				CustomType:  dnstypes.RecordTypeType{},
+				Description: "Type of Record: A, AAAA, MX, CNAME, TXT, NS, SRV, CAA, TLSA, DS, OPENPGPKEY, SMIMEA or SSHFP. Matched case-insensitively.",
+				Validators: []validator.String{
+					dnstypes.RecordTypeValidator(),
+				},
+				PlanModifiers: []planmodifier.String{
+					createOnlyRequiresReplace(),
+				},
 			},
 			"priority": schema.StringAttribute{
 				Required:    false,
 				Optional:    true,
 				Computed:    true,
-				Description: "Required for MX records.",
+				Description: "Required for MX and SRV records. Only MX and SRV records have a priority, it can't be set for other types.",
+				PlanModifiers: []planmodifier.String{
+					priorityPlanModifier(),
+				},
 			},
 			"destination": schema.StringAttribute{
 				Required:    true,
-				Description: "Target of the record.",
+				CustomType:  dnstypes.DestinationType{},
+				Description: "Target of the record. Quoted TXT values, also split into several quoted strings like long DKIM keys, equal their unquoted value.",
+			},
+			"state": schema.StringAttribute{
+				Computed:    true,
+				Description: "State of the record as reported by the API, e.g. `yes` once it is live.",
+			},
+			"update_policy": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(updatePolicyAlways),
+				MarkdownDescription: "Either `always` or `create_only`. With `create_only` the record is created by Terraform but never updated: " +
+					"changes of `destination` and `priority`, in the configuration or outside of Terraform, are ignored, " +
+					"e.g. for an initial record an appliance rewrites or tokens rotated by another system. " +
+					"Changes of `domainname`, `hostname` and `type` replace the record. Defaults to `always`.",
+			},
+			"comment": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Note on the record, e.g. who owns it or why it exists. Netcup has no record comments, " +
+					"so it is only stored in the Terraform state and changing it doesn't write to the API.",
+			},
+			"record_id": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "ID of an existing record to take over on create instead of creating a new one, e.g. in pipelines that can't run `terraform import`. " +
+					"The record must have the configured `hostname` and `type` and is updated to the configured `destination` and `priority`. " +
+					"Conflicts with `allow_adopt`. Ignored after the record was created.",
+			},
+			"allow_adopt": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Take over a record with the same `hostname`, `type` and `destination` that already exists in the zone on create. " +
+					"By default the create fails instead, as the record would be deleted on destroy although Terraform didn't create it. " +
+					"Conflicts with `record_id`. Ignored after the record was created. Defaults to `false`",
 			},
 		},
 	}
 }
 
+func (r *dnsRecordDataSource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config DnsRecord
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.UpdatePolicy.IsNull() && !config.UpdatePolicy.IsUnknown() &&
+		config.UpdatePolicy.ValueString() != updatePolicyAlways && config.UpdatePolicy.ValueString() != updatePolicyCreateOnly {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("update_policy"),
+			"Invalid update policy",
+			"The update policy must be \""+updatePolicyAlways+"\" or \""+updatePolicyCreateOnly+"\", got \""+config.UpdatePolicy.ValueString()+"\"",
+		)
+	}
+
+	// priorities set for other types are rejected by priorityPlanModifier
+	if !config.Type.IsUnknown() && priorityRecordTypes[strings.ToUpper(config.Type.ValueString())] && config.Priority.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("priority"),
+			"Missing priority",
+			"A priority is required for "+strings.ToUpper(config.Type.ValueString())+" records, e.g. \"10\". "+
+				"Without one the API stores priority 0.",
+		)
+	}
+
+	// record_id names the record to take over, allow_adopt searches for one
+	if !config.RecordID.IsNull() && config.AllowAdopt.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("allow_adopt"),
+			"Conflicting attributes",
+			"record_id and allow_adopt = true can't be set together. Set record_id to take over a record by its id, "+
+				"or allow_adopt = true to take over a record with the configured hostname, type and destination.",
+		)
+	}
+
+	if config.Hostname.IsNull() || config.Hostname.IsUnknown() || config.Domainname.IsNull() || config.Domainname.IsUnknown() {
+		return
+	}
+
+	if client.OutsideZone(config.Hostname.ValueString(), config.Domainname.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("hostname"),
+			"Hostname outside of zone",
+			"The absolute name "+config.Hostname.ValueString()+" is not part of the zone "+config.Domainname.ValueString()+". "+
+				"Use a name relative to the zone like \"www\", or an absolute name ending in "+config.Domainname.ValueString()+".",
+		)
+	}
+}
+
 func (r *dnsRecordDataSource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
@@ -76,11 +188,9 @@ func (r *dnsRecordDataSource) Configure(_ context.Context, req resource.Configur
 
 // Create a new resource
 func (r dnsRecordDataSource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	if r.client == nil {
-		resp.Diagnostics.AddError(
-			"Provider not configured",
-			"The provider hasn't been configured before apply, likely because it depends on an unknown value from another resource. This leads to weird stuff happening, so we'd prefer if you didn't do that. Thanks!",
-		)
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
 		return
 	}
 
@@ -92,37 +202,70 @@ func (r dnsRecordDataSource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
+	// send the values the API stores, so the response matches the plan
+	recordType := strings.ToUpper(plan.Type.ValueString())
 	var newDnsRecord = client.NewDnsRecord{
-		Hostname:    plan.Hostname.ValueString(),
-		Type:        plan.Type.ValueString(),
-		Destination: plan.Destination.ValueString(),
+		Hostname:    normalizeHostname(plan.Hostname.ValueString(), plan.Domainname.ValueString()),
+		Type:        recordType,
+		Destination: dnstypes.NormalizeDestination(recordType, plan.Destination.ValueString()),
 	}
 
 	if !plan.Priority.IsUnknown() && !plan.Priority.IsNull() {
 		newDnsRecord.Priority = plan.Priority.ValueString()
 	}
 
-	tflog.Trace(ctx, "Create DNS Record", structs.Map(newDnsRecord))
-
-	// Create new order
-	dnsRecord, err := r.client.CreateDnsRecord(plan.Domainname.ValueString(), newDnsRecord)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating dns record",
-			"Could not create dns record, unexpected error: "+err.Error(),
-		)
-		return
+	tflog.Trace(ctx, "Create DNS Record", newDnsRecordLogFields(plan.Domainname.ValueString(), newDnsRecord))
+
+	update := beginZoneUpdate(ctx, r.client, plan.Domainname.ValueString(), &resp.Diagnostics)
+
+	var dnsRecord *client.DnsRecord
+	if plan.RecordID.IsNull() {
+		if !plan.AllowAdopt.ValueBool() && r.recordExists(ctx, plan, newDnsRecord, &resp.Diagnostics) {
+			return
+		}
+
+		// Create new order
+		var err error
+		dnsRecord, err = r.client.CreateDnsRecord(ctx, plan.Domainname.ValueString(), newDnsRecord)
+		if err != nil {
+			addRecordError(&resp.Diagnostics, "Error creating dns record", "create", recordContext{
+				Domainname:  plan.Domainname.ValueString(),
+				Hostname:    newDnsRecord.Hostname,
+				Type:        newDnsRecord.Type,
+				Destination: newDnsRecord.Destination,
+			}, err)
+			return
+		}
+	} else {
+		var written bool
+		dnsRecord, written = r.adoptRecord(ctx, plan, newDnsRecord, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		// an unchanged record doesn't change the zone serial
+		if !written {
+			update = zoneUpdate{}
+		}
 	}
+	warnDnssecZone(ctx, r.client, plan.Domainname.ValueString(), &resp.Diagnostics)
+	update.await(ctx, r.client, &resp.Diagnostics)
 
 	var state = DnsRecord{
-		ID:          types.StringValue(dnsRecord.Id),
-		Domainname:  plan.Domainname,
-		Hostname:    types.StringValue(dnsRecord.Hostname),
-		Type:        types.StringValue(dnsRecord.Type),
-		Priority:    types.StringValue(dnsRecord.Priority),
-		Destination: types.StringValue(dnsRecord.Destination),
+		ID:           types.StringValue(dnsRecord.Id),
+		Domainname:   plan.Domainname,
+		Hostname:     plannedHostname(plan, dnsRecord.Hostname),
+		Type:         dnstypes.NewRecordTypeValue(dnsRecord.Type),
+		Priority:     types.StringValue(dnsRecord.Priority),
+		Destination:  plannedDestination(plan, *dnsRecord),
+		State:        types.StringValue(dnsRecord.State),
+		UpdatePolicy: plan.UpdatePolicy,
+		Comment:      plan.Comment,
+		RecordID:     plan.RecordID,
+		AllowAdopt:   plan.AllowAdopt,
 	}
 
+	resp.Diagnostics.Append(forceNextRead(ctx, resp.Private)...)
+
 	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -130,8 +273,110 @@ func (r dnsRecordDataSource) Create(ctx context.Context, req resource.CreateRequ
 	}
 }
 
+// Check that the zone has no record like the one to create, which the create
+// would take over. Reports whether one exists or the check failed.
+func (r dnsRecordDataSource) recordExists(ctx context.Context, plan DnsRecord, record client.NewDnsRecord, diags *diag.Diagnostics) bool {
+	domainname := plan.Domainname.ValueString()
+	candidates, err := r.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{Hostname: record.Hostname, Type: record.Type})
+	if err != nil {
+		addRecordError(diags, "Error creating dns record", "create", recordContext{
+			Domainname:  domainname,
+			Hostname:    record.Hostname,
+			Type:        record.Type,
+			Destination: record.Destination,
+		}, err)
+		return true
+	}
+
+	for _, existing := range candidates {
+		if !destinationsEqual(existing.Type, existing.Destination, record.Destination) {
+			continue
+		}
+		diags.AddError(
+			"Record already exists",
+			"The zone "+domainname+" already has the "+strings.ToUpper(existing.Type)+" record "+existing.Hostname+" -> "+existing.Destination+
+				" (id="+existing.Id+"). Import it with the id "+domainname+"/"+existing.Id+" instead of creating it, "+
+				"or set allow_adopt = true to take it over on create.",
+		)
+		return true
+	}
+	return false
+}
+
+// Take over the existing record record_id instead of creating one and update it
+// to the planned values. Reports whether the record was written.
+func (r dnsRecordDataSource) adoptRecord(ctx context.Context, plan DnsRecord, record client.NewDnsRecord, diags *diag.Diagnostics) (*client.DnsRecord, bool) {
+	domainname := plan.Domainname.ValueString()
+	id := plan.RecordID.ValueString()
+
+	existing, err := r.client.GetDnsRecordById(ctx, domainname, id)
+	if err != nil {
+		addRecordError(diags, "Error adopting dns record", "adopt", recordContext{
+			Domainname:  domainname,
+			ID:          id,
+			Hostname:    record.Hostname,
+			Type:        record.Type,
+			Destination: record.Destination,
+		}, err)
+		return nil, false
+	}
+
+	if !hostnamesEqual(existing.Hostname, record.Hostname, domainname) || !strings.EqualFold(existing.Type, record.Type) {
+		diags.AddAttributeError(
+			path.Root("record_id"),
+			"Record to adopt doesn't match",
+			"The DNS record "+id+" of domain "+domainname+" is a "+strings.ToUpper(existing.Type)+" record of "+existing.Hostname+
+				", but the configuration declares a "+strings.ToUpper(record.Type)+" record of "+record.Hostname+". "+
+				"Set record_id to a record with the configured hostname and type, or remove it to create a new record.",
+		)
+		return nil, false
+	}
+
+	tflog.Info(ctx, "Adopting existing DNS Record", dnsRecordLogFields(domainname, *existing))
+
+	if destinationsEqual(existing.Type, existing.Destination, record.Destination) &&
+		(record.Priority == "" || record.Priority == existing.Priority) {
+		return existing, false
+	}
+
+	updated, err := r.client.UpdateDnsRecord(ctx, domainname, client.DnsRecord{
+		Id:          existing.Id,
+		Hostname:    existing.Hostname,
+		Type:        existing.Type,
+		Priority:    record.Priority,
+		Destination: record.Destination,
+	})
+	if err != nil {
+		addRecordError(diags, "Error adopting dns record", "update", newRecordContext(domainname, *existing), err)
+		return nil, false
+	}
+	return updated, true
+}
+
+// Keep the planned spelling of the hostname, e.g. an absolute name, if the API stored the same name
+func plannedHostname(plan DnsRecord, stored string) dnstypes.Hostname {
+	if hostnamesEqual(plan.Hostname.ValueString(), stored, plan.Domainname.ValueString()) {
+		return plan.Hostname
+	}
+	return dnstypes.NewHostnameValue(stored)
+}
+
+// Keep the planned spelling of the destination, e.g. an unquoted TXT value, if the API stored the same value
+func plannedDestination(plan DnsRecord, stored client.DnsRecord) dnstypes.Destination {
+	if destinationsEqual(stored.Type, plan.Destination.ValueString(), stored.Destination) {
+		return plan.Destination
+	}
+	return dnstypes.NewDestinationValue(stored.Destination)
+}
+
 // Read resource information
 func (r dnsRecordDataSource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "refresh", &resp.Diagnostics) {
+		return
+	}
+
 	// Get current state
 	var state DnsRecord
 	diags := req.State.Get(ctx, &state)
@@ -140,22 +385,73 @@ func (r dnsRecordDataSource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
+	// Keep the prior state, unless it is incomplete like right after import
+	if skipRefresh(ctx, r.client, req.Private, &resp.Diagnostics) && !state.Hostname.IsNull() {
+		tflog.Trace(ctx, "Skipping refresh of DNS Record", map[string]interface{}{"domainname": state.Domainname.ValueString(), "id": state.ID.ValueString()})
+		// states written before update_policy existed
+		if state.UpdatePolicy.IsNull() {
+			state.UpdatePolicy = types.StringValue(updatePolicyAlways)
+			resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		}
+		return
+	}
+
 	// Get current value
-	dnsRecord, err := r.client.GetDnsRecordById(state.Domainname.ValueString(), state.ID.ValueString())
+	dnsRecord, err := r.client.GetDnsRecordById(ctx, state.Domainname.ValueString(), state.ID.ValueString())
+	if errors.Is(err, client.ErrRecordNotFound) {
+		// deleted outside of Terraform, e.g. in the customer control panel, so it is planned to be created again
+		tflog.Trace(ctx, "DNS Record missing, removing from state", map[string]interface{}{"domainname": state.Domainname.ValueString(), "id": state.ID.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error reading record",
-			"Could not read recordID "+state.ID.ValueString()+": "+err.Error(),
-		)
+		addRecordError(&resp.Diagnostics, "Error reading record", "read", recordContext{
+			Domainname:  state.Domainname.ValueString(),
+			ID:          state.ID.ValueString(),
+			Hostname:    state.Hostname.ValueString(),
+			Type:        state.Type.ValueString(),
+			Destination: state.Destination.ValueString(),
+		}, err)
 		return
 	}
 
-	tflog.Trace(ctx, "Got DNS Record", structs.Map(dnsRecord))
+	tflog.Trace(ctx, "Got DNS Record", dnsRecordLogFields(state.Domainname.ValueString(), *dnsRecord))
+
+	if !state.Hostname.IsNull() {
+		domainname := state.Domainname.ValueString()
+		var drift []driftedValue
+		drift = appendDrift(drift, "hostname", state.Hostname.ValueString(), dnsRecord.Hostname, hostnamesEqual(state.Hostname.ValueString(), dnsRecord.Hostname, domainname))
+		drift = appendDrift(drift, "type", state.Type.ValueString(), dnsRecord.Type, strings.EqualFold(state.Type.ValueString(), dnsRecord.Type))
+		// create_only records ignore changes of their values
+		if !createOnly(state.UpdatePolicy) {
+			drift = appendDrift(drift, "priority", state.Priority.ValueString(), dnsRecord.Priority, state.Priority.ValueString() == dnsRecord.Priority)
+			drift = appendDrift(drift, "destination", state.Destination.ValueString(), dnsRecord.Destination,
+				destinationsEqual(dnsRecord.Type, state.Destination.ValueString(), dnsRecord.Destination))
+		}
+		warnDrift(ctx, r.client, req.Private, "The DNS record "+state.ID.ValueString()+" of domain "+domainname, drift, &resp.Diagnostics)
+	}
+
+	// Keep the spelling of the prior state if it only differs in case, otherwise
+	// store the canonical form, so configs generated after import show no diff
+	if state.Hostname.IsNull() || !hostnamesEqual(state.Hostname.ValueString(), dnsRecord.Hostname, state.Domainname.ValueString()) {
+		state.Hostname = dnstypes.NewHostnameValue(normalizeHostname(dnsRecord.Hostname, state.Domainname.ValueString()))
+	}
+	if state.Type.IsNull() || !strings.EqualFold(state.Type.ValueString(), dnsRecord.Type) {
+		state.Type = dnstypes.NewRecordTypeValue(strings.ToUpper(dnsRecord.Type))
+	}
+	state.State = types.StringValue(dnsRecord.State)
+	if state.UpdatePolicy.IsNull() {
+		state.UpdatePolicy = types.StringValue(updatePolicyAlways)
+	}
+	// create_only records keep the values they were created with, whoever changed them since
+	if !createOnly(state.UpdatePolicy) || state.Destination.IsNull() {
+		state.Priority = types.StringValue(dnsRecord.Priority)
+		if state.Destination.IsNull() || !destinationsEqual(dnsRecord.Type, state.Destination.ValueString(), dnsRecord.Destination) {
+			state.Destination = dnstypes.NewDestinationValue(dnsRecord.Destination)
+		}
+	}
 
-	state.Hostname = types.StringValue(dnsRecord.Hostname)
-	state.Type = types.StringValue(dnsRecord.Type)
-	state.Priority = types.StringValue(dnsRecord.Priority)
-	state.Destination = types.StringValue(dnsRecord.Destination)
+	resp.Diagnostics.Append(clearForceRead(ctx, resp.Private)...)
 
 	// Set state
 	diags = resp.State.Set(ctx, &state)
@@ -167,6 +463,12 @@ func (r dnsRecordDataSource) Read(ctx context.Context, req resource.ReadRequest,
 
 // Update resource
 func (r dnsRecordDataSource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
 	var plan DnsRecord
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
@@ -182,38 +484,63 @@ func (r dnsRecordDataSource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
+	// the comment and update_policy only live in the state
+	changed := recordChanged(plan, state)
+	if !changed || createOnly(plan.UpdatePolicy) {
+		logFields := map[string]interface{}{"domainname": plan.Domainname.ValueString(), "id": state.ID.ValueString()}
+		if changed {
+			tflog.Info(ctx, "Not updating DNS Record with update_policy create_only", logFields)
+		} else {
+			tflog.Trace(ctx, "DNS Record unchanged, updating state only", logFields)
+		}
+		plan.ID = state.ID
+		plan.State = state.State
+		if plan.Priority.IsUnknown() {
+			plan.Priority = state.Priority
+		}
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
+	}
+
+	recordType := strings.ToUpper(plan.Type.ValueString())
 	var newDnsRecord = client.DnsRecord{
 		Id:          state.ID.ValueString(),
-		Hostname:    plan.Hostname.ValueString(),
-		Type:        plan.Type.ValueString(),
-		Destination: plan.Destination.ValueString(),
+		Hostname:    normalizeHostname(plan.Hostname.ValueString(), plan.Domainname.ValueString()),
+		Type:        recordType,
+		Destination: dnstypes.NormalizeDestination(recordType, plan.Destination.ValueString()),
 	}
 
 	if !plan.Priority.IsUnknown() && !plan.Priority.IsNull() {
 		newDnsRecord.Priority = plan.Priority.ValueString()
 	}
 
-	tflog.Trace(ctx, "Updating DNS Record", structs.Map(newDnsRecord))
+	tflog.Trace(ctx, "Updating DNS Record", dnsRecordLogFields(plan.Domainname.ValueString(), newDnsRecord))
+
+	update := beginZoneUpdate(ctx, r.client, plan.Domainname.ValueString(), &resp.Diagnostics)
 
 	// Update order by calling API
-	dnsRecord, err := r.client.UpdateDnsRecord(plan.Domainname.ValueString(), newDnsRecord)
+	dnsRecord, err := r.client.UpdateDnsRecord(ctx, plan.Domainname.ValueString(), newDnsRecord)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error update dnsRecord",
-			"Could not update dnsRecordID "+state.ID.ValueString()+": "+err.Error(),
-		)
+		addRecordError(&resp.Diagnostics, "Error updating dns record", "update", newRecordContext(plan.Domainname.ValueString(), newDnsRecord), err)
 		return
 	}
+	warnDnssecZone(ctx, r.client, plan.Domainname.ValueString(), &resp.Diagnostics)
+	update.await(ctx, r.client, &resp.Diagnostics)
 
 	// Map response body to resource schema attribute
 	// Generate resource state struct
 	var result = DnsRecord{
-		ID:          types.StringValue(state.ID.ValueString()),
-		Domainname:  types.StringValue(plan.Domainname.ValueString()),
-		Hostname:    types.StringValue(dnsRecord.Hostname),
-		Type:        types.StringValue(dnsRecord.Type),
-		Priority:    types.StringValue(dnsRecord.Priority),
-		Destination: types.StringValue(dnsRecord.Destination),
+		ID:           types.StringValue(state.ID.ValueString()),
+		Domainname:   types.StringValue(plan.Domainname.ValueString()),
+		Hostname:     plannedHostname(plan, dnsRecord.Hostname),
+		Type:         dnstypes.NewRecordTypeValue(dnsRecord.Type),
+		Priority:     types.StringValue(dnsRecord.Priority),
+		Destination:  plannedDestination(plan, *dnsRecord),
+		State:        types.StringValue(dnsRecord.State),
+		UpdatePolicy: plan.UpdatePolicy,
+		Comment:      plan.Comment,
+		RecordID:     plan.RecordID,
+		AllowAdopt:   plan.AllowAdopt,
 	}
 
 	// Set state
@@ -224,8 +551,24 @@ func (r dnsRecordDataSource) Update(ctx context.Context, req resource.UpdateRequ
 	}
 }
 
+// Whether the plan changes the record in the API compared to state
+func recordChanged(plan, state DnsRecord) bool {
+	domainname := plan.Domainname.ValueString()
+	return !strings.EqualFold(plan.Domainname.ValueString(), state.Domainname.ValueString()) ||
+		!hostnamesEqual(plan.Hostname.ValueString(), state.Hostname.ValueString(), domainname) ||
+		!strings.EqualFold(plan.Type.ValueString(), state.Type.ValueString()) ||
+		!destinationsEqual(plan.Type.ValueString(), plan.Destination.ValueString(), state.Destination.ValueString()) ||
+		(!plan.Priority.IsUnknown() && !plan.Priority.IsNull() && plan.Priority.ValueString() != state.Priority.ValueString())
+}
+
 // Delete resource
 func (r dnsRecordDataSource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
 	// Get current state
 	var state DnsRecord
 	diags := req.State.Get(ctx, &state)
@@ -242,24 +585,242 @@ func (r dnsRecordDataSource) Delete(ctx context.Context, req resource.DeleteRequ
 		Destination: state.Destination.ValueString(),
 	}
 
-	tflog.Trace(ctx, "Deleting DNS Record", structs.Map(dnsRecord))
+	tflog.Trace(ctx, "Deleting DNS Record", dnsRecordLogFields(state.Domainname.ValueString(), dnsRecord))
+
+	update := beginZoneUpdate(ctx, r.client, state.Domainname.ValueString(), &resp.Diagnostics)
 
 	// Delete order by calling API
-	err := r.client.DeleteDnsRecord(state.Domainname.ValueString(), dnsRecord)
+	err := r.client.DeleteDnsRecord(ctx, state.Domainname.ValueString(), dnsRecord)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting record",
-			"Could not delete recordID "+state.ID.ValueString()+": "+err.Error(),
-		)
+		addRecordError(&resp.Diagnostics, "Error deleting record", "delete", newRecordContext(state.Domainname.ValueString(), dnsRecord), err)
 		return
 	}
+	warnDnssecZone(ctx, r.client, state.Domainname.ValueString(), &resp.Diagnostics)
+	update.await(ctx, r.client, &resp.Diagnostics)
 
 	// Remove resource from state
 	resp.State.RemoveResource(ctx)
 }
 
-// Import resource
+// Import resource. Accepts "<domainname>/<id>" or "<domainname>/<hostname>/<type>/<destination>".
 func (r dnsRecordDataSource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Save the import identifier in the id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	parts := strings.SplitN(req.ID, "/", 4)
+	// domainnames are stored like the API returns them, so generated configs match the state
+	parts[0] = strings.ToLower(strings.TrimSuffix(parts[0], "."))
+
+	var domainname, id string
+	switch {
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		domainname, id = parts[0], parts[1]
+		r.checkImportId(ctx, domainname, id, resp)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	case len(parts) == 4 && parts[0] != "" && parts[1] != "" && parts[2] != "" && parts[3] != "":
+		domainname = parts[0]
+		id = r.importIdByFields(ctx, domainname, client.RecordFilter{Hostname: parts[1], Type: parts[2], Destination: parts[3]}, resp)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	case len(parts) == 1 && parts[0] != "":
+		// the id alone doesn't tell which zone to read the record from
+		resp.Diagnostics.AddError(
+			"Invalid import identifier",
+			"Record ids are only unique within their zone, so the import identifier must include the domainname, e.g. \"example.com/"+req.ID+"\". "+
+				"Expected \"<domainname>/<id>\" or \"<domainname>/<hostname>/<type>/<destination>\", got \""+req.ID+"\".",
+		)
+		return
+	default:
+		resp.Diagnostics.AddError(
+			"Invalid import identifier",
+			"Expected \"<domainname>/<id>\" or \"<domainname>/<hostname>/<type>/<destination>\", got \""+req.ID+"\".",
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domainname"), domainname)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+	resp.Diagnostics.Append(forceNextRead(ctx, resp.Private)...)
+}
+
+// Resolve the id of the single record matching the fields of an import identifier
+func (r dnsRecordDataSource) importIdByFields(ctx context.Context, domainname string, filter client.RecordFilter, resp *resource.ImportStateResponse) string {
+	if addNotConfiguredError(r.client, "import", &resp.Diagnostics) {
+		return ""
+	}
+
+	matches, err := lookupDnsRecords(ctx, r.client, domainname, filter)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return ""
+	}
+
+	switch len(matches) {
+	case 0:
+		resp.Diagnostics.AddError(
+			"No matching record found",
+			fmt.Sprintf("No record matches hostname=%s type=%s destination=%s in domain %s.", filter.Hostname, filter.Type, filter.Destination, domainname)+
+				r.importCandidates(ctx, domainname, filter.Hostname),
+		)
+		return ""
+	case 1:
+		return matches[0].Id
+	default:
+		resp.Diagnostics.AddError(
+			"Multiple matching records found",
+			"Import the record by id instead:\n"+formatRecordCandidates(matches),
+		)
+		return ""
+	}
+}
+
+// Maximum number of records listed by a failed import
+const maxImportCandidates = 20
+
+// Fail the import of an id the zone doesn't contain. If the zone can't be read,
+// the import proceeds and the following read reports the error.
+func (r dnsRecordDataSource) checkImportId(ctx context.Context, domainname string, id string, resp *resource.ImportStateResponse) {
+	if r.client == nil {
+		return
+	}
+
+	// every record of the zone, lookupDnsRecords reads an empty hostname as the apex
+	records, err := r.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{})
+	if err != nil {
+		return
+	}
+	for _, record := range records {
+		if record.Id == id {
+			return
+		}
+	}
+
+	resp.Diagnostics.AddError(
+		"No matching record found",
+		"No record with id "+id+" exists in domain "+domainname+"."+r.importCandidates(ctx, domainname, ""),
+	)
+}
+
+// Listing of the records of the zone to pick from after a failed import,
+// limited to hostname if it has records. Empty if the zone can't be read.
+func (r dnsRecordDataSource) importCandidates(ctx context.Context, domainname string, hostname string) string {
+	// every record of the zone, lookupDnsRecords reads an empty hostname as the apex
+	records, err := r.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{})
+	if err != nil || len(records) == 0 {
+		return ""
+	}
+	sortDnsRecords(records)
+
+	scope := "Records of the domain"
+	if hostname != "" {
+		var sameName []client.DnsRecord
+		for _, record := range records {
+			if hostnamesEqual(record.Hostname, hostname, domainname) {
+				sameName = append(sameName, record)
+			}
+		}
+		if len(sameName) > 0 {
+			records = sameName
+			scope = "Records with hostname " + hostname
+		}
+	}
+
+	listing := "\n\n" + scope + ", import one by \"" + domainname + "/<id>\":\n"
+	if len(records) > maxImportCandidates {
+		return listing + formatRecordCandidates(records[:maxImportCandidates]) + fmt.Sprintf("\n  ... and %d more", len(records)-maxImportCandidates)
+	}
+	return listing + formatRecordCandidates(records)
+}
+
+// Private state key of the id of the record a plan starts from. Terraform plans
+// a replaced resource a second time with a null prior state but the private
+// state of the first plan, so both plans claim the record for the same id.
+const plannedRecordIdKey = "planned_record_id"
+
+// Detect two resources of the configuration declaring the same record,
+// which would overwrite each other on every apply
+func (r dnsRecordDataSource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to claim on destroy, or before the provider is configured
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var plan DnsRecord
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.Domainname.IsUnknown() || plan.Hostname.IsUnknown() || plan.Type.IsUnknown() || plan.Destination.IsUnknown() {
+		return
+	}
+
+	domainname := strings.ToLower(strings.TrimSuffix(plan.Domainname.ValueString(), "."))
+	hostname := normalizeHostname(plan.Hostname.ValueString(), domainname)
+	recordType := strings.ToUpper(plan.Type.ValueString())
+	destination := dnstypes.NormalizeDestination(recordType, plan.Destination.ValueString())
+	fields := []string{domainname, hostname, recordType, destination}
+	record := fmt.Sprintf("hostname=%s type=%s destination=%s", hostname, recordType, destination)
+	// MX and SRV records differing only in priority are different records
+	if priorityRecordTypes[recordType] {
+		if plan.Priority.IsUnknown() {
+			return
+		}
+		fields = append(fields, plan.Priority.ValueString())
+		record += " priority=" + plan.Priority.ValueString()
+	}
+	key := strings.Join(fields, "|")
+
+	id, diags := plannedRecordId(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if id != "" {
+		value, err := json.Marshal(id)
+		if err != nil {
+			resp.Diagnostics.AddError("Error storing planned record", err.Error())
+			return
+		}
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, plannedRecordIdKey, value)...)
+	}
+	previous, ok := r.client.ClaimPlannedRecord(key, id)
+	if ok {
+		return
+	}
+
+	other := "another netcupdns_record resource that creates it"
+	if previous != "" {
+		other = "the netcupdns_record resource managing the record with id " + previous
+	}
+	resp.Diagnostics.AddError(
+		"Duplicate record in configuration",
+		fmt.Sprintf("The record %s of domain %s is also declared by %s. "+
+			"Both resources would manage the same record and overwrite each other; remove one of them, e.g. from one of the modules declaring it. "+
+			"Terraform doesn't tell providers the addresses of resources, search the configuration for the record to find both.",
+			record, domainname, other),
+	)
+}
+
+// Id of the record a plan starts from: the one of the prior state, or for the
+// second plan of a replacement the one kept by the first plan. Empty for new resources.
+func plannedRecordId(ctx context.Context, req resource.ModifyPlanRequest) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if !req.State.Raw.IsNull() {
+		id := types.StringNull()
+		diags.Append(req.State.GetAttribute(ctx, path.Root("id"), &id)...)
+		return id.ValueString(), diags
+	}
+
+	value, d := req.Private.GetKey(ctx, plannedRecordIdKey)
+	diags.Append(d...)
+	var id string
+	if len(value) > 0 {
+		if err := json.Unmarshal(value, &id); err != nil {
+			diags.AddError("Error reading planned record", err.Error())
+		}
+	}
+	return id, diags
 }
@@ -0,0 +1,775 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+// Values the API normalizes must be planned as stored, so Terraform doesn't
+// fail with an inconsistent result after apply and the next plan is empty.
+// Like Terraform, every operation runs in a new provider.
+func TestDnsRecordNormalizedValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		config attrs
+	}{
+		{"mixed-case hostname", attrs{"hostname": "WWW", "type": "A", "destination": "192.0.2.1"}},
+		{"lower-case type", attrs{"hostname": "www", "type": "aaaa", "destination": "2001:db8::1"}},
+		{"padded destination", attrs{"hostname": "www", "type": "A", "destination": " 192.0.2.3 "}},
+		{"quoted TXT", attrs{"hostname": "@", "type": "TXT", "destination": `"v=spf1 -all"`}},
+		{"MX with priority", attrs{"hostname": "@", "type": "mx", "priority": "10", "destination": "mail.example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := attrs{"domainname": testDomain(t)}
+			for name, value := range tt.config {
+				config[name] = value
+			}
+
+			p := newTestProvider(t, nil)
+			created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+			if id := attrString(t, created.State, "id"); id == "<null>" || id == "" {
+				t.Fatalf("created record has no id")
+			}
+
+			p = newTestProvider(t, nil)
+			refreshed, diags := p.read("netcupdns_record", created.State, created.Private)
+			p.checkDiags("refresh", diags)
+			for _, name := range []string{"hostname", "type", "destination", "priority"} {
+				if got, want := attrString(t, refreshed, name), attrString(t, created.State, name); got != want {
+					t.Errorf("refresh changed %s from %q to %q", name, want, got)
+				}
+			}
+			planned := p.plan("netcupdns_record", refreshed, created.Private, config)
+			if !planned.Equal(refreshed) {
+				t.Errorf("plan after apply isn't empty:\nplanned %s\nstate   %s", planned, refreshed)
+			}
+
+			p = newTestProvider(t, nil)
+			p.apply("netcupdns_record", refreshed, created.Private, nil)
+		})
+	}
+}
+
+// Hostnames with a trailing dot are stored relative to the zone, and keep their spelling in the state
+func TestDnsRecordAbsoluteHostname(t *testing.T) {
+	tests := []struct {
+		name     string
+		hostname string
+		stored   string
+	}{
+		{"trailing dot", "www.", "www"},
+		{"absolute", "www.<domain>.", "www"},
+		{"mixed-case absolute", "API.<domain>.", "api"},
+		{"absolute apex", "<domain>.", "@"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain := testDomain(t)
+			hostname := strings.ReplaceAll(tt.hostname, "<domain>", domain)
+			config := attrs{"domainname": domain, "hostname": hostname, "type": "A", "destination": "192.0.2.2"}
+
+			p := newTestProvider(t, nil)
+			created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+			if got := attrString(t, created.State, "hostname"); got != hostname {
+				t.Errorf("state has hostname %q, want the configured %q", got, hostname)
+			}
+			if got, want := zoneRecords(t, domain), []string{tt.stored + " A 0 192.0.2.2"}; strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("zone has %q, want %q", got, want)
+			}
+
+			p = newTestProvider(t, nil)
+			refreshed, diags := p.read("netcupdns_record", created.State, created.Private)
+			p.checkDiags("refresh", diags)
+			if planned := p.plan("netcupdns_record", refreshed, created.Private, config); !planned.Equal(refreshed) {
+				t.Errorf("plan after apply isn't empty:\nplanned %s\nstate   %s", planned, refreshed)
+			}
+
+			p = newTestProvider(t, nil)
+			p.apply("netcupdns_record", refreshed, created.Private, nil)
+		})
+	}
+}
+
+func TestDnsRecordHostnameOutsideZone(t *testing.T) {
+	p := newTestProvider(t, nil)
+	diags := p.validate("netcupdns_record", attrs{"domainname": "example.com", "hostname": "www.example.org.", "type": "A", "destination": "192.0.2.1"})
+	if d := firstError(diags); d == nil || d.Summary != "Hostname outside of zone" {
+		t.Errorf("got errors %v, want Hostname outside of zone", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+}
+
+// The API stores priority 0 for MX records without priority, so the plan is rejected
+func TestDnsRecordMxWithoutPriority(t *testing.T) {
+	p := newTestProvider(t, nil)
+	result := p.tryApply("netcupdns_record", nullState(p, "netcupdns_record"), nil, attrs{
+		"domainname": testDomain(t), "hostname": "@", "type": "MX", "destination": "mail.example.com",
+	})
+	d := firstError(result.Diags)
+	if d == nil || d.Summary != "Missing priority" {
+		t.Fatalf("got diagnostics %v, want a missing priority error", summaries(result.Diags, tfprotov6.DiagnosticSeverityError))
+	}
+	if !result.State.IsNull() && result.State.Type() != nil {
+		t.Errorf("rejected plan was applied: %s", result.State)
+	}
+}
+
+// A changed destination is written normalized and keeps its planned spelling
+func TestDnsRecordUpdateNormalizedDestination(t *testing.T) {
+	domain := testDomain(t)
+
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, attrs{"domainname": domain, "hostname": "@", "type": "TXT", "destination": "first"})
+
+	p = newTestProvider(t, nil)
+	updated := p.apply("netcupdns_record", created.State, created.Private, attrs{"domainname": domain, "hostname": "@", "type": "txt", "destination": ` "second" `})
+	if got := attrString(t, updated.State, "id"); got != attrString(t, created.State, "id") {
+		t.Errorf("update replaced the record: id %s, want %s", got, attrString(t, created.State, "id"))
+	}
+
+	p = newTestProvider(t, nil)
+	refreshed, diags := p.read("netcupdns_record", updated.State, updated.Private)
+	p.checkDiags("refresh", diags)
+	if got := attrString(t, refreshed, "destination"); got != ` "second" ` {
+		t.Errorf("destination after refresh = %q, want the configured spelling", got)
+	}
+}
+
+// MX and SRV records differing only in priority are different records, other
+// types ignore the priority when claiming a record
+func TestDnsRecordDuplicatesInConfiguration(t *testing.T) {
+	tests := []struct {
+		name      string
+		first     attrs
+		second    attrs
+		duplicate bool
+	}{
+		{
+			name:      "same record",
+			first:     attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+			second:    attrs{"hostname": "WWW", "type": "a", "destination": " 192.0.2.1 "},
+			duplicate: true,
+		},
+		{
+			name:   "other destination",
+			first:  attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+			second: attrs{"hostname": "www", "type": "A", "destination": "192.0.2.2"},
+		},
+		{
+			name:      "MX of same priority",
+			first:     attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+			second:    attrs{"hostname": "@", "type": "mx", "priority": "10", "destination": "mail.example.com"},
+			duplicate: true,
+		},
+		{
+			name:   "MX of other priority",
+			first:  attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+			second: attrs{"hostname": "@", "type": "MX", "priority": "20", "destination": "mail.example.com"},
+		},
+		{
+			name:   "SRV of other priority",
+			first:  attrs{"hostname": "_sip._tcp", "type": "SRV", "priority": "10", "destination": "5 5060 sip.example.com"},
+			second: attrs{"hostname": "_sip._tcp", "type": "SRV", "priority": "20", "destination": "5 5060 sip.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain := testDomain(t)
+			p := newTestProvider(t, nil)
+			for i, config := range []attrs{tt.first, tt.second} {
+				config["domainname"] = domain
+				_, diags := p.tryPlan("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+				d := firstError(diags)
+				duplicate := d != nil && d.Summary == "Duplicate record in configuration"
+				if want := i == 1 && tt.duplicate; duplicate != want {
+					t.Errorf("plan %d got diagnostics %v, want a duplicate error: %t", i+1, summaries(diags, tfprotov6.DiagnosticSeverityError), want)
+				}
+				if d != nil && !duplicate {
+					t.Errorf("plan %d failed: %s: %s", i+1, d.Summary, d.Detail)
+				}
+			}
+		})
+	}
+}
+
+// Terraform plans a replacement twice in the same run, first with the prior
+// state and then with a null prior state but the private state of the first plan
+func TestDnsRecordReplacementPlannedTwice(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   attrs
+		replaced bool
+	}{
+		{name: "hostname", change: attrs{"hostname": "web"}, replaced: true},
+		{name: "type", change: attrs{"type": "AAAA", "destination": "2001:db8::1"}, replaced: true},
+		{name: "domainname", change: attrs{"domainname": "other-"}, replaced: true},
+		// terraform apply -replace replaces the resource without a change
+		{name: "replace option", change: attrs{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain := testDomain(t)
+			// create_only records are replaced instead of updated
+			config := attrs{"domainname": domain, "hostname": "www", "type": "A", "destination": "192.0.2.1", "update_policy": "create_only"}
+			p := newTestProvider(t, nil)
+			created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+			p.close()
+
+			for name, value := range tt.change {
+				if name == "domainname" {
+					value = value.(string) + domain
+				}
+				config[name] = value
+			}
+			p = newTestProvider(t, nil)
+			first := p.planResourceChange("netcupdns_record", created.State, created.Private, config)
+			p.checkDiags("plan with the prior state", first.Diagnostics)
+			if replaced := len(first.RequiresReplace) > 0; replaced != tt.replaced {
+				t.Errorf("plan replaces the resource: %t, want %t", replaced, tt.replaced)
+			}
+			second := p.planResourceChange("netcupdns_record", nullState(p, "netcupdns_record"), first.PlannedPrivate, config)
+			p.checkDiags("plan with a null prior state", second.Diagnostics)
+
+			// another resource declaring the record is still a duplicate
+			_, diags := p.tryPlan("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+			d := firstError(diags)
+			if d == nil || d.Summary != "Duplicate record in configuration" {
+				t.Fatalf("got errors %v, want a duplicate error", summaries(diags, tfprotov6.DiagnosticSeverityError))
+			}
+			if want := "record with id " + attrString(t, created.State, "id"); !strings.Contains(d.Detail, want) {
+				t.Errorf("error %q doesn't name the %s", d.Detail, want)
+			}
+		})
+	}
+}
+
+func TestDnsRecordRecordIdConflictsWithAllowAdopt(t *testing.T) {
+	domain := testDomain(t)
+	config := attrs{"domainname": domain, "hostname": "www", "type": "A", "destination": "192.0.2.1"}
+
+	p := newTestProvider(t, nil)
+	existing := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+	id := attrString(t, existing.State, "id")
+
+	adopt := attrs{"record_id": id, "allow_adopt": true}
+	for name, value := range config {
+		adopt[name] = value
+	}
+	p = newTestProvider(t, nil)
+	result := p.tryApply("netcupdns_record", nullState(p, "netcupdns_record"), nil, adopt)
+	d := firstError(result.Diags)
+	if d == nil || d.Summary != "Conflicting attributes" {
+		t.Fatalf("got diagnostics %v, want a conflicting attributes error", summaries(result.Diags, tfprotov6.DiagnosticSeverityError))
+	}
+	if d.Attribute == nil || d.Attribute.String() != tftypes.NewAttributePath().WithAttributeName("allow_adopt").String() {
+		t.Errorf("error is for attribute %v, want allow_adopt", d.Attribute)
+	}
+
+	// allow_adopt = false is the default and doesn't conflict
+	adopt["allow_adopt"] = false
+	p = newTestProvider(t, nil)
+	adopted := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, adopt)
+	if got := attrString(t, adopted.State, "id"); got != id {
+		t.Errorf("adopted record %s, want %s", got, id)
+	}
+}
+
+// Steps of a record from creation to destruction, every step in a new provider
+// like a separate Terraform run
+func TestDnsRecordLifecycle(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   attrs
+		update   attrs
+		priority string
+	}{
+		{
+			name:   "A",
+			config: attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+			update: attrs{"destination": "192.0.2.2"},
+		},
+		{
+			name:     "MX",
+			config:   attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+			update:   attrs{"destination": "mx.example.com"},
+			priority: "20",
+		},
+		{
+			name:   "TXT",
+			config: attrs{"hostname": "@", "type": "TXT", "destination": "v=spf1 -all"},
+			update: attrs{"destination": "v=spf1 mx -all"},
+		},
+		{
+			name:   "CNAME",
+			config: attrs{"hostname": "api", "type": "CNAME", "destination": "www"},
+			update: attrs{"destination": "web.example.com."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain := testDomain(t)
+			config := attrs{"domainname": domain}
+			for name, value := range tt.config {
+				config[name] = value
+			}
+			// the record in the format of zoneRecords
+			record := func() string {
+				priority := "0"
+				if value, ok := config["priority"]; ok {
+					priority = value.(string)
+				}
+				return fmt.Sprintf("%s %s %s %s", config["hostname"], config["type"], priority, config["destination"])
+			}
+			checkZone := func(step string, want ...string) {
+				t.Helper()
+				if got := zoneRecords(t, domain); strings.Join(got, ",") != strings.Join(want, ",") {
+					t.Errorf("after %s the zone has %q, want %q", step, got, want)
+				}
+			}
+			// a closed provider doesn't share its cache with the next, like a new run
+			var p *testProvider
+			next := func() *testProvider {
+				if p != nil {
+					p.close()
+				}
+				p = newTestProvider(t, nil)
+				return p
+			}
+			checkEmptyPlan := func(step string, state tftypes.Value, private []byte) {
+				t.Helper()
+				p := next()
+				refreshed, diags := p.read("netcupdns_record", state, private)
+				p.checkDiags("refresh after "+step, diags)
+				if planned := p.plan("netcupdns_record", refreshed, private, config); !planned.Equal(refreshed) {
+					t.Errorf("plan after %s isn't empty:\nplanned %s\nstate   %s", step, planned, refreshed)
+				}
+			}
+
+			p = next()
+			created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+			id := attrString(t, created.State, "id")
+			checkZone("create", record())
+			checkEmptyPlan("create", created.State, created.Private)
+
+			for name, value := range tt.update {
+				config[name] = value
+			}
+			p = next()
+			current := p.apply("netcupdns_record", created.State, created.Private, config)
+			if got := attrString(t, current.State, "id"); got != id {
+				t.Errorf("destination update replaced record %s by %s", id, got)
+			}
+			checkZone("destination update", record())
+			checkEmptyPlan("destination update", current.State, current.Private)
+
+			if tt.priority != "" {
+				config["priority"] = tt.priority
+				p = next()
+				current = p.apply("netcupdns_record", current.State, current.Private, config)
+				if got := attrString(t, current.State, "id"); got != id {
+					t.Errorf("priority update replaced record %s by %s", id, got)
+				}
+				checkZone("priority update", record())
+				checkEmptyPlan("priority update", current.State, current.Private)
+			}
+
+			for _, importId := range []string{
+				domain + "/" + id,
+				fmt.Sprintf("%s/%s/%s/%s", domain, config["hostname"], config["type"], config["destination"]),
+			} {
+				p = next()
+				imported, diags := p.importState("netcupdns_record", importId)
+				p.checkDiags("import of "+importId, diags)
+				for _, name := range []string{"id", "domainname", "hostname", "type", "priority", "destination"} {
+					if got, want := attrString(t, imported, name), attrString(t, current.State, name); got != want {
+						t.Errorf("import of %s: %s = %q, want %q", importId, name, got, want)
+					}
+				}
+				if planned := p.plan("netcupdns_record", imported, nil, config); !planned.Equal(imported) {
+					t.Errorf("plan after import of %s isn't empty:\nplanned %s\nstate   %s", importId, planned, imported)
+				}
+			}
+
+			// deleted outside of Terraform, the next run recreates the record
+			err := mockClient(t).DeleteDnsRecord(context.Background(), domain, client.DnsRecord{
+				Id:          id,
+				Hostname:    attrString(t, current.State, "hostname"),
+				Type:        attrString(t, current.State, "type"),
+				Priority:    attrString(t, current.State, "priority"),
+				Destination: attrString(t, current.State, "destination"),
+			})
+			if err != nil {
+				t.Fatalf("DeleteDnsRecord failed: %s", err)
+			}
+			p = next()
+			refreshed, diags := p.read("netcupdns_record", current.State, current.Private)
+			p.checkDiags("refresh after external deletion", diags)
+			if !refreshed.IsNull() {
+				t.Fatalf("refresh kept the deleted record: %s", refreshed)
+			}
+			recreated := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+			if got := attrString(t, recreated.State, "id"); got == id {
+				t.Errorf("recreated record kept the id %s of the deleted one", id)
+			}
+			checkZone("recreation", record())
+
+			p = next()
+			destroyed := p.apply("netcupdns_record", recreated.State, recreated.Private, nil)
+			if !destroyed.State.IsNull() {
+				t.Errorf("destroy left state %s", destroyed.State)
+			}
+			checkZone("destroy")
+		})
+	}
+}
+
+// Writes to the mock increase the serial of the zone, so waiting for it ends without warnings
+func TestDnsRecordWaitForZoneUpdate(t *testing.T) {
+	p := startTestProvider(t)
+	diags := p.configure(attrs{"mock": true, "wait_for_zone_update": true, "zone_update_timeout": "forever"})
+	if d := firstError(diags); d == nil || d.Summary != "Invalid zone update timeout" {
+		t.Errorf("got errors %v, want Invalid zone update timeout", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+
+	p = newTestProvider(t, attrs{"wait_for_zone_update": true, "zone_update_timeout": "10s"})
+	config := attrs{"domainname": testDomain(t), "hostname": "www", "type": "A", "destination": "192.0.2.1"}
+	created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+	config["destination"] = "192.0.2.2"
+	updated := p.apply("netcupdns_record", created.State, created.Private, config)
+	deleted := p.apply("netcupdns_record", updated.State, updated.Private, nil)
+
+	for step, result := range map[string]applied{"create": created, "update": updated, "delete": deleted} {
+		if warnings := summaries(result.Diags, tfprotov6.DiagnosticSeverityWarning); len(warnings) != 0 {
+			t.Errorf("%s warned %v, want the zone update to be seen", step, warnings)
+		}
+	}
+}
+
+// Configuration Terraform generates for an imported resource with
+// -generate-config-out: every attribute that isn't only computed and not null
+func generatedConfig(p *testProvider, typeName string, imported tftypes.Value) attrs {
+	p.t.Helper()
+	var values map[string]tftypes.Value
+	if err := imported.As(&values); err != nil {
+		p.t.Fatal(err)
+	}
+	config := attrs{}
+	for _, attribute := range p.resourceSchema(typeName).Block.Attributes {
+		if attribute.Computed && !attribute.Optional {
+			continue
+		}
+		if value := values[attribute.Name]; !value.IsNull() {
+			config[attribute.Name] = value
+		}
+	}
+	return config
+}
+
+// Importing records created outside of Terraform and planning the generated
+// configuration shows no changes, whatever the spelling of the import id
+func TestDnsRecordImportGenerateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		record client.NewDnsRecord
+		// import id by fields, after the domainname
+		fields string
+	}{
+		{"A", client.NewDnsRecord{Hostname: "www", Type: "A", Destination: "192.0.2.1"}, "WWW/a/192.0.2.1"},
+		{"apex A", client.NewDnsRecord{Hostname: "@", Type: "A", Destination: "192.0.2.1"}, "@/A/192.0.2.1"},
+		{"MX", client.NewDnsRecord{Hostname: "@", Type: "MX", Priority: "10", Destination: "mail.example.com"}, "@/mx/mail.example.com"},
+		{"TXT", client.NewDnsRecord{Hostname: "_dmarc", Type: "TXT", Destination: "v=DMARC1; p=reject"}, "_DMARC/TXT/v=DMARC1; p=reject"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain := testDomain(t)
+			created, err := mockClient(t).CreateDnsRecord(context.Background(), domain, tt.record)
+			if err != nil {
+				t.Fatalf("CreateDnsRecord failed: %s", err)
+			}
+
+			for _, importId := range []string{
+				domain + "/" + created.Id,
+				strings.ToUpper(domain) + "./" + created.Id,
+				domain + "/" + tt.fields,
+			} {
+				p := newTestProvider(t, nil)
+				imported, diags := p.importState("netcupdns_record", importId)
+				p.checkDiags("import of "+importId, diags)
+				if got := attrString(t, imported, "id"); got != created.Id {
+					t.Fatalf("import of %s got record %s, want %s", importId, got, created.Id)
+				}
+
+				config := generatedConfig(p, "netcupdns_record", imported)
+				for name, want := range map[string]string{
+					"domainname": domain, "hostname": tt.record.Hostname, "type": tt.record.Type, "destination": tt.record.Destination,
+				} {
+					if value, ok := config[name].(tftypes.Value); !ok || attrString(t, imported, name) != want {
+						t.Errorf("import of %s generated %s = %v, want %q", importId, name, value, want)
+					}
+				}
+				if priority := attrString(t, imported, "priority"); tt.record.Priority != "" && priority != tt.record.Priority {
+					t.Errorf("import of %s generated priority %q, want %q", importId, priority, tt.record.Priority)
+				}
+
+				p.checkDiags("validation of the generated configuration", p.validate("netcupdns_record", config))
+				if planned := p.plan("netcupdns_record", imported, nil, config); !planned.Equal(imported) {
+					t.Errorf("plan of the configuration generated by import of %s isn't empty:\nplanned %s\nstate   %s", importId, planned, imported)
+				}
+				p.close()
+			}
+		})
+	}
+}
+
+// Imports finding no record list the records to pick from, by hostname for fields-based imports
+func TestDnsRecordImportCandidates(t *testing.T) {
+	domain := testDomain(t)
+	records := []attrs{
+		{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+		{"hostname": "www", "type": "AAAA", "destination": "2001:db8::1"},
+		{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+	}
+	for i := 0; i < maxImportCandidates; i++ {
+		records = append(records, attrs{"hostname": fmt.Sprintf("host%02d", i), "type": "A", "destination": "192.0.2.2"})
+	}
+	seedZone(t, domain, records...)
+	zone, err := mockClient(t).GetDnsRecords(context.Background(), domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]string)
+	for _, record := range zone {
+		ids[record.Hostname+" "+record.Type] = record.Id
+	}
+
+	tests := []struct {
+		name     string
+		importId string
+		listed   []string
+		more     int
+	}{
+		{"unknown id", domain + "/999999", nil, len(zone) - maxImportCandidates},
+		{"fields of an existing hostname", domain + "/www/A/192.0.2.9", []string{ids["www A"], ids["www AAAA"]}, 0},
+		{"fields of a hostname without records", domain + "/api/A/192.0.2.9", nil, len(zone) - maxImportCandidates},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		_, diags := p.importState("netcupdns_record", tt.importId)
+		d, listed := unmanagedRecords(t, diags, "No matching record found")
+		if d == nil {
+			t.Errorf("%s: got errors %v, want No matching record found", tt.name, summaries(diags, tfprotov6.DiagnosticSeverityError))
+			continue
+		}
+		if !strings.Contains(d.Detail, `import one by "`+domain+`/<id>"`) {
+			t.Errorf("%s: detail doesn't explain how to import a candidate:\n%s", tt.name, d.Detail)
+		}
+		sort.Strings(tt.listed)
+		if tt.listed != nil && strings.Join(listed, ",") != strings.Join(tt.listed, ",") {
+			t.Errorf("%s: listed %q, want %q", tt.name, listed, tt.listed)
+		}
+		if tt.listed == nil && len(listed) != maxImportCandidates {
+			t.Errorf("%s: listed %d records, want %d", tt.name, len(listed), maxImportCandidates)
+		}
+		more := fmt.Sprintf("... and %d more", tt.more)
+		if got := strings.Contains(d.Detail, more); got != (tt.more > 0) {
+			t.Errorf("%s: detail mentions %q: %t, want %t:\n%s", tt.name, more, got, tt.more > 0, d.Detail)
+		}
+	}
+
+	// every line names id, hostname, type and destination
+	_, diags := p.importState("netcupdns_record", domain+"/@/MX/mx.example.com")
+	want := fmt.Sprintf("  - id=%s hostname=@ type=MX destination=mail.example.com priority=10", ids["@ MX"])
+	if d := firstError(diags); d == nil || !strings.Contains(d.Detail, want) {
+		t.Errorf("got error %+v, want a detail listing %q", d, want)
+	}
+
+	// without a readable zone the import fails on the read, without listing
+	_, diags = p.importState("netcupdns_record", "missing.invalid/1")
+	if d := firstError(diags); d == nil || d.Summary == "No matching record found" || strings.Contains(d.Detail, "  - id=") {
+		t.Errorf("got errors %v, want the error reading the zone", summaries(diags, tfprotov6.DiagnosticSeverityError))
+	}
+}
+
+// Change the destination and priority of a record outside of Terraform
+func mutateRecord(t *testing.T, domain, id, priority, destination string) {
+	t.Helper()
+	ctx := context.Background()
+	c := mockClient(t)
+	defer c.Logout(ctx)
+	record, err := c.GetDnsRecordById(ctx, domain, id)
+	if err != nil {
+		t.Fatalf("GetDnsRecordById failed: %s", err)
+	}
+	record.Priority, record.Destination = priority, destination
+	if _, err := c.UpdateDnsRecord(ctx, domain, *record); err != nil {
+		t.Fatalf("UpdateDnsRecord failed: %s", err)
+	}
+}
+
+// Records with update_policy create_only keep what others wrote to them, while
+// records with the default policy are updated back to their configuration
+func TestDnsRecordUpdatePolicy(t *testing.T) {
+	tests := []struct {
+		name, policy string
+		config       attrs
+		// external change, as priority and destination
+		priority, destination string
+		// zone after applying a changed destination
+		changed, want string
+	}{
+		{"create_only A", "create_only", attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+			"0", "192.0.2.9", "192.0.2.5", "www A 0 192.0.2.9"},
+		{"create_only MX", "create_only", attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+			"20", "appliance.example.com", "mx.example.com", "@ MX 20 appliance.example.com"},
+		{"always A", "always", attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"},
+			"0", "192.0.2.9", "192.0.2.5", "www A 0 192.0.2.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain := testDomain(t)
+			config := maps.Clone(tt.config)
+			config["domainname"], config["update_policy"] = domain, tt.policy
+			createOnly := tt.policy == "create_only"
+
+			p := newTestProvider(t, nil)
+			created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+			if got := attrString(t, created.State, "update_policy"); got != tt.policy {
+				t.Errorf("state records update_policy %s, want %s", got, tt.policy)
+			}
+			id := attrString(t, created.State, "id")
+			mutateRecord(t, domain, id, tt.priority, tt.destination)
+
+			p = newTestProvider(t, nil)
+			refreshed, diags := p.read("netcupdns_record", created.State, created.Private)
+			p.checkDiags("refresh", diags)
+			if createOnly && len(diags) > 0 {
+				t.Errorf("refresh of a create_only record warned %v", summaries(diags, tfprotov6.DiagnosticSeverityWarning))
+			}
+			planned := p.plan("netcupdns_record", refreshed, nil, config)
+			if createOnly != planned.Equal(refreshed) {
+				t.Errorf("plan after the external change is empty: %t, want %t:\nplanned %s\nstate   %s",
+					planned.Equal(refreshed), createOnly, planned, refreshed)
+			}
+
+			// changed values of the configuration are planned, but not written
+			p = newTestProvider(t, nil)
+			config["destination"] = tt.changed
+			if replaced := p.planReplacements("netcupdns_record", refreshed, nil, config); len(replaced) > 0 {
+				t.Errorf("changed destination replaces the record at %v", replaced)
+			}
+			updated := p.apply("netcupdns_record", refreshed, nil, config)
+			if got := attrString(t, updated.State, "id"); got != id {
+				t.Errorf("update replaced record %s by %s", id, got)
+			}
+			if got := zoneRecords(t, domain); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("zone after update %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Changes of the identity of a create_only record replace it, as it is never updated in place
+func TestDnsRecordCreateOnlyReplacements(t *testing.T) {
+	domain := testDomain(t)
+	config := attrs{"domainname": domain, "hostname": "www", "type": "TXT", "destination": "token=1", "update_policy": "create_only"}
+
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+
+	for _, tt := range []struct{ attribute, value string }{
+		{"domainname", "other-" + domain},
+		{"hostname", "api"},
+		{"type", "CAA"},
+	} {
+		changed := maps.Clone(config)
+		changed[tt.attribute] = tt.value
+		if tt.attribute == "type" {
+			changed["destination"] = `0 issue "letsencrypt.org"`
+		}
+		replaced := p.planReplacements("netcupdns_record", created.State, created.Private, changed)
+		want := tftypes.NewAttributePath().WithAttributeName(tt.attribute)
+		found := false
+		for _, path := range replaced {
+			found = found || path.Equal(want)
+		}
+		if !found {
+			t.Errorf("change of %s replaces the record at %v, want %s", tt.attribute, replaced, tt.attribute)
+		}
+	}
+}
+
+// The comment only lives in the state, changing it doesn't write to the API
+func TestDnsRecordCommentOnlyChange(t *testing.T) {
+	domain := testDomain(t)
+	config := attrs{"domainname": domain, "hostname": "hooks", "type": "CNAME", "destination": "vendor.example.com", "comment": "required for vendor X webhook"}
+
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, config)
+	id := attrString(t, created.State, "id")
+
+	prior := created
+	for _, comment := range []interface{}{"owned by payments team", nil} {
+		var output bytes.Buffer
+		p = newTestProvider(t, nil)
+		p.captureLogs(&output)
+		config["comment"] = comment
+		updated := p.apply("netcupdns_record", prior.State, prior.Private, config)
+		if n := loggedRequests(t, &output, "updateDnsRecords"); n != 0 {
+			t.Errorf("comment %v: sent %d updateDnsRecords requests, want none", comment, n)
+		}
+		want := "<null>"
+		if comment != nil {
+			want = comment.(string)
+		}
+		if got := attrString(t, updated.State, "comment"); got != want {
+			t.Errorf("state has comment %q, want %q", got, want)
+		}
+		if got := attrString(t, updated.State, "id"); got != id {
+			t.Errorf("comment change replaced record %s by %s", id, got)
+		}
+		prior = updated
+	}
+	if got := zoneRecords(t, domain); len(got) != 1 || got[0] != "hooks CNAME 0 vendor.example.com" {
+		t.Errorf("zone after comment changes %q", got)
+	}
+
+	// changing the comment along with the destination writes once, without the comment
+	var output bytes.Buffer
+	p = newTestProvider(t, nil)
+	p.captureLogs(&output)
+	config["comment"], config["destination"] = "moved to vendor Y", "vendor-y.example.com"
+	updated := p.apply("netcupdns_record", prior.State, prior.Private, config)
+	if n := loggedRequests(t, &output, "updateDnsRecords"); n != 1 {
+		t.Errorf("sent %d updateDnsRecords requests, want 1", n)
+	}
+	entries, err := tflogtest.MultilineJSONDecode(bytes.NewReader(output.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if request, _ := entry["request"].(string); entry["@message"] == "API request bodies" && strings.Contains(request, "vendor Y") {
+			t.Errorf("the comment was sent to the API: %s", request)
+		}
+	}
+	if got := attrString(t, updated.State, "comment"); got != "moved to vendor Y" {
+		t.Errorf("state has comment %q", got)
+	}
+}
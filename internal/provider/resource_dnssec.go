@@ -0,0 +1,307 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ resource.Resource                = &dnssecResource{}
+	_ resource.ResourceWithConfigure   = &dnssecResource{}
+	_ resource.ResourceWithImportState = &dnssecResource{}
+	_ resource.ResourceWithModifyPlan  = &dnssecResource{}
+)
+
+func NewDnssecResource() resource.Resource {
+	return &dnssecResource{}
+}
+
+type dnssecResource struct {
+	client *client.CCPClient
+}
+
+func (r *dnssecResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dnssec"
+}
+
+func (r *dnssecResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Enables or disables DNSSEC for an existing DNS-Zone. The `dnssec` attribute of `netcupdns_zone` manages the same setting, " +
+			"so only one of them may manage it for a domain. Destroying the resource only removes it from the state: disabling DNSSEC " +
+			"while the registry still publishes DS records of the zone would make it unresolvable for validating resolvers.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Domainname of the zone.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the zone.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				Required:    true,
+				Description: "Whether DNSSEC is enabled for the zone.",
+			},
+		},
+	}
+}
+
+func (r *dnssecResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.client = req.ProviderData.(*client.CCPClient)
+}
+
+// Register the resource type managing the DNSSEC status of domainname, and
+// report an error if a resource of another type manages it too. Both would
+// switch DNSSEC to their own value on every apply.
+func claimDnssec(c *client.CCPClient, domainname types.String, resourceType string, diags *diag.Diagnostics) {
+	if domainname.IsUnknown() {
+		return
+	}
+
+	other, ok := c.ClaimDnssec(domainname.ValueString(), resourceType)
+	if ok {
+		return
+	}
+	diags.AddAttributeError(
+		path.Root("domainname"),
+		"DNSSEC managed twice",
+		"The DNSSEC status of domain "+domainname.ValueString()+" is managed by both a "+resourceType+" and a "+other+" resource. "+
+			"They would overwrite each other on every apply; pick one, e.g. remove the dnssec attribute of the netcupdns_zone resource "+
+			"or the netcupdns_dnssec resource.",
+	)
+}
+
+// Detect a netcupdns_zone managing the DNSSEC status of the same domain
+func (r *dnssecResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to claim on destroy, or before the provider is configured
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var domainname types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("domainname"), &domainname)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	claimDnssec(r.client, domainname, "netcupdns_dnssec", &resp.Diagnostics)
+}
+
+// Write the planned DNSSEC status, unless the zone already has it
+func (r dnssecResource) apply(ctx context.Context, plan Dnssec, diags *diag.Diagnostics) Dnssec {
+	domainname := plan.Domainname.ValueString()
+
+	current, err := r.client.RefreshDnsZone(ctx, domainname)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading zone",
+			"Could not read zone "+domainname+", it must exist in the CCP: "+err.Error(),
+		)
+		return plan
+	}
+
+	state := Dnssec{ID: types.StringValue(domainname), Domainname: plan.Domainname, Enabled: plan.Enabled}
+	if current.DNSSecStatus == plan.Enabled.ValueBool() {
+		tflog.Trace(ctx, "DNSSEC unchanged", map[string]interface{}{"domainname": domainname})
+		return state
+	}
+
+	tflog.Trace(ctx, "Updating DNSSEC", map[string]interface{}{"domainname": domainname, "enabled": plan.Enabled.ValueBool()})
+	settings := *current
+	settings.DNSSecStatus = plan.Enabled.ValueBool()
+	updated, err := r.client.UpdateDnsZone(ctx, domainname, settings)
+	if err != nil {
+		if addRateLimitError(diags, "Netcup throttled the request to update zone "+domainname+".", err) ||
+			addZoneLockedError(diags, "Netcup rejected the request to update zone "+domainname+".", err) {
+			return plan
+		}
+		diags.AddError(
+			"Error updating DNSSEC",
+			"Could not "+dnssecAction(plan.Enabled.ValueBool())+" DNSSEC for zone "+domainname+": "+err.Error(),
+		)
+		return plan
+	}
+	if updated.DNSSecStatus != plan.Enabled.ValueBool() {
+		diags.AddError(
+			"Error updating DNSSEC",
+			"Netcup accepted the request to "+dnssecAction(plan.Enabled.ValueBool())+" DNSSEC for zone "+domainname+
+				", but reports it as "+dnssecAction(updated.DNSSecStatus)+"d.",
+		)
+	}
+	return state
+}
+
+func dnssecAction(enabled bool) string {
+	if enabled {
+		return "enable"
+	}
+	return "disable"
+}
+
+// Create resource. Zones can't be created with the API, so the DNSSEC status of the existing zone is updated.
+func (r dnssecResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
+	var plan Dnssec
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := r.apply(ctx, plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(forceNextRead(ctx, resp.Private)...)
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read resource information
+func (r dnssecResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "refresh", &resp.Diagnostics) {
+		return
+	}
+
+	var state Dnssec
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Keep the prior state, unless it is incomplete like right after import
+	if skipRefresh(ctx, r.client, req.Private, &resp.Diagnostics) && !state.Enabled.IsNull() {
+		tflog.Trace(ctx, "Skipping refresh of DNSSEC", map[string]interface{}{"domainname": state.Domainname.ValueString()})
+		return
+	}
+
+	domainname := state.Domainname.ValueString()
+	zone, err := r.client.GetDnsZone(ctx, domainname)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading zone",
+			"Could not read zone "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	enabled := types.BoolValue(zone.DNSSecStatus)
+	if !state.Enabled.IsNull() {
+		drift := appendDrift(nil, "enabled", state.Enabled.String(), enabled.String(), state.Enabled.Equal(enabled))
+		warnDrift(ctx, r.client, req.Private, "DNSSEC of zone "+domainname, drift, &resp.Diagnostics)
+	}
+	state.ID = types.StringValue(domainname)
+	state.Enabled = enabled
+
+	resp.Diagnostics.Append(clearForceRead(ctx, resp.Private)...)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update resource
+func (r dnssecResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
+	var plan Dnssec
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := r.apply(ctx, plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete resource. DNSSEC is left as it is, see the description of the resource.
+func (r dnssecResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state Dnssec
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "Removing DNSSEC from state only, the zone keeps its DNSSEC status", map[string]interface{}{
+		"domainname": state.Domainname.ValueString(), "enabled": state.Enabled.ValueBool(),
+	})
+}
+
+// Import the DNSSEC status of a zone by its domainname
+func (r dnssecResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	domainname := strings.ToLower(strings.TrimSuffix(req.ID, "."))
+	if domainname == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import id",
+			"Expected the domainname of the zone like example.com, got \""+req.ID+"\"",
+		)
+		return
+	}
+	if addNotConfiguredError(r.client, "import", &resp.Diagnostics) {
+		return
+	}
+
+	zone, err := r.client.RefreshDnsZone(ctx, domainname)
+	if err != nil {
+		if addRateLimitError(&resp.Diagnostics, "Netcup throttled the request to read zone "+domainname+".", err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Cannot import DNSSEC",
+			"Could not read the zone "+domainname+". The domain must belong to the account the provider is configured with "+
+				"and use the DNS of Netcup: "+err.Error(),
+		)
+		return
+	}
+
+	state := Dnssec{
+		ID:         types.StringValue(domainname),
+		Domainname: types.StringValue(domainname),
+		Enabled:    types.BoolValue(zone.DNSSecStatus),
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	resp.Diagnostics.Append(forceNextRead(ctx, resp.Private)...)
+}
@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+func TestDnssecLifecycle(t *testing.T) {
+	domain := testDomain(t)
+
+	config := attrs{"domainname": domain, "enabled": true}
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_dnssec", nullState(p, "netcupdns_dnssec"), nil, config)
+	if !zoneSettings(t, domain).DNSSecStatus {
+		t.Error("DNSSEC not enabled after create")
+	}
+	if got := attrString(t, created.State, "id"); got != domain {
+		t.Errorf("id %s, want %s", got, domain)
+	}
+	p.close()
+
+	p = newTestProvider(t, nil)
+	refreshed, private, diags := p.refresh("netcupdns_dnssec", created.State, created.Private)
+	p.checkDiags("refresh", diags)
+	if planned := p.plan("netcupdns_dnssec", refreshed, private, config); !planned.Equal(refreshed) {
+		t.Errorf("plan after create isn't empty:\nplanned %s\nstate   %s", planned, refreshed)
+	}
+
+	var output bytes.Buffer
+	p.captureLogs(&output)
+	config["enabled"] = false
+	updated := p.apply("netcupdns_dnssec", refreshed, private, config)
+	if zoneSettings(t, domain).DNSSecStatus {
+		t.Error("DNSSEC still enabled after update")
+	}
+	if n := loggedRequests(t, &output, "updateDnsZone"); n != 1 {
+		t.Errorf("update sent %d updateDnsZone requests, want 1", n)
+	}
+
+	output.Reset()
+	p.apply("netcupdns_dnssec", updated.State, updated.Private, config)
+	if n := loggedRequests(t, &output, "updateDnsZone"); n != 0 {
+		t.Errorf("apply without changes sent %d updateDnsZone requests", n)
+	}
+
+	imported, diags := p.importState("netcupdns_dnssec", domain+".")
+	p.checkDiags("import", diags)
+	if planned := p.plan("netcupdns_dnssec", imported, nil, config); !planned.Equal(imported) {
+		t.Errorf("plan after import isn't empty:\nplanned %s\nstate   %s", planned, imported)
+	}
+
+	// destroy leaves DNSSEC as it is
+	config["enabled"] = true
+	enabled := p.apply("netcupdns_dnssec", updated.State, updated.Private, config)
+	destroyed := p.apply("netcupdns_dnssec", enabled.State, enabled.Private, nil)
+	if !destroyed.State.IsNull() {
+		t.Errorf("state after destroy %s", destroyed.State)
+	}
+	if !zoneSettings(t, domain).DNSSecStatus {
+		t.Error("destroy disabled DNSSEC")
+	}
+}
+
+func TestZoneDnssec(t *testing.T) {
+	domain := testDomain(t)
+
+	config := attrs{"domainname": domain, "dnssec": true}
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_zone", nullState(p, "netcupdns_zone"), nil, config)
+	if !zoneSettings(t, domain).DNSSecStatus {
+		t.Error("DNSSEC not enabled by the zone")
+	}
+	p.close()
+
+	// without the attribute, the zone keeps the status set by others
+	p = newTestProvider(t, nil)
+	p.apply("netcupdns_dnssec", nullState(p, "netcupdns_dnssec"), nil, attrs{"domainname": domain, "enabled": false})
+	updated := p.apply("netcupdns_zone", created.State, created.Private, attrs{"domainname": domain, "ttl": 600})
+	if zone := zoneSettings(t, domain); zone.DNSSecStatus || zone.TTL != "600" {
+		t.Errorf("zone after update without dnssec %+v, want DNSSEC kept disabled", zone)
+	}
+	if got := attrString(t, updated.State, "dnssec"); got != "true" {
+		t.Errorf("dnssec in state %s, want the planned prior value until the next refresh", got)
+	}
+	p.close()
+
+	p = newTestProvider(t, nil)
+	refreshed, diags := p.read("netcupdns_zone", updated.State, updated.Private)
+	p.checkDiags("refresh", diags)
+	if got := attrString(t, refreshed, "dnssec"); got != "false" {
+		t.Errorf("dnssec after refresh %s, want false", got)
+	}
+}
+
+func TestDnssecManagedTwice(t *testing.T) {
+	type plannedResource struct {
+		typeName string
+		config   func(domain string) attrs
+	}
+	zoneWithDnssec := plannedResource{"netcupdns_zone", func(domain string) attrs { return attrs{"domainname": domain, "dnssec": true} }}
+	zoneWithoutDnssec := plannedResource{"netcupdns_zone", func(domain string) attrs { return attrs{"domainname": domain, "ttl": 300} }}
+	dnssec := plannedResource{"netcupdns_dnssec", func(domain string) attrs { return attrs{"domainname": domain, "enabled": true} }}
+
+	tests := []struct {
+		name      string
+		resources []plannedResource
+		conflict  bool
+	}{
+		{name: "only zone", resources: []plannedResource{zoneWithDnssec}},
+		{name: "only dnssec", resources: []plannedResource{dnssec}},
+		{name: "zone without dnssec", resources: []plannedResource{zoneWithoutDnssec, dnssec}},
+		{name: "both", resources: []plannedResource{zoneWithDnssec, dnssec}, conflict: true},
+		{name: "both, dnssec first", resources: []plannedResource{dnssec, zoneWithDnssec}, conflict: true},
+		// Terraform plans a replaced resource twice
+		{name: "dnssec planned twice", resources: []plannedResource{dnssec, dnssec}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain := testDomain(t)
+			p := newTestProvider(t, nil)
+			var errors []string
+			for _, r := range tt.resources {
+				_, diags := p.tryPlan(r.typeName, nullState(p, r.typeName), nil, r.config(domain))
+				errors = append(errors, summaries(diags, tfprotov6.DiagnosticSeverityError)...)
+			}
+			switch {
+			case tt.conflict && (len(errors) != 1 || errors[0] != "DNSSEC managed twice"):
+				t.Errorf("got errors %v, want DNSSEC managed twice", errors)
+			case !tt.conflict && len(errors) > 0:
+				t.Errorf("got errors %v, want none", errors)
+			}
+
+			// the other resource type of another domain, or of another run, doesn't conflict
+			if !tt.conflict {
+				return
+			}
+			for _, r := range tt.resources[1:] {
+				_, diags := p.tryPlan(r.typeName, nullState(p, r.typeName), nil, r.config("other-"+domain))
+				if hasErrors(diags) {
+					t.Errorf("plan of %s for another domain failed: %v", r.typeName, summaries(diags, tfprotov6.DiagnosticSeverityError))
+				}
+			}
+			p.close()
+			p = newTestProvider(t, nil)
+			for _, r := range tt.resources[:1] {
+				if _, diags := p.tryPlan(r.typeName, nullState(p, r.typeName), nil, r.config(domain)); hasErrors(diags) {
+					t.Errorf("plan of %s in a new run failed: %v", r.typeName, summaries(diags, tfprotov6.DiagnosticSeverityError))
+				}
+			}
+		})
+	}
+}
+
+// Resources planned in parallel report each conflict exactly once. Run with -race.
+func TestDnssecManagedTwiceParallel(t *testing.T) {
+	const domains = 20
+	p := newTestProvider(t, nil)
+	base := testDomain(t)
+
+	var mu sync.Mutex
+	conflicts := map[string]int{}
+	var wg sync.WaitGroup
+	plan := func(typeName string, config attrs) {
+		defer wg.Done()
+		_, diags := p.tryPlan(typeName, nullState(p, typeName), nil, config)
+		for _, d := range diags {
+			if d.Severity == tfprotov6.DiagnosticSeverityError {
+				mu.Lock()
+				conflicts[config["domainname"].(string)]++
+				mu.Unlock()
+			}
+		}
+	}
+	for i := 0; i < domains; i++ {
+		domain := fmt.Sprintf("d%d.%s", i, base)
+		wg.Add(2)
+		go plan("netcupdns_dnssec", attrs{"domainname": domain, "enabled": true})
+		// every other zone leaves DNSSEC to the dnssec resource
+		zone := attrs{"domainname": domain, "ttl": 300}
+		if i%2 == 1 {
+			zone["dnssec"] = true
+		}
+		go plan("netcupdns_zone", zone)
+	}
+	wg.Wait()
+
+	for i := 0; i < domains; i++ {
+		domain := fmt.Sprintf("d%d.%s", i, base)
+		if want := i % 2; conflicts[domain] != want {
+			t.Errorf("%s: %d conflicts, want %d", domain, conflicts[domain], want)
+		}
+	}
+}
@@ -0,0 +1,778 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+	"github.com/svetob/terraform-provider-netcupdns/internal/dnstypes"
+)
+
+var (
+	_ resource.Resource                   = &recordSetResource{}
+	_ resource.ResourceWithConfigure      = &recordSetResource{}
+	_ resource.ResourceWithImportState    = &recordSetResource{}
+	_ resource.ResourceWithModifyPlan     = &recordSetResource{}
+	_ resource.ResourceWithValidateConfig = &recordSetResource{}
+)
+
+// Values of update_strategy. With replace, updates delete all records of the
+// set and create the planned ones, instead of writing only the changed records.
+const (
+	updateStrategyIncremental = "incremental"
+	updateStrategyReplace     = "replace"
+)
+
+func NewRecordSetResource() resource.Resource {
+	return &recordSetResource{}
+}
+
+type recordSetResource struct {
+	client *client.CCPClient
+}
+
+func (r *recordSetResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_record_set"
+}
+
+func (r *recordSetResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages many records of a zone as one resource, e.g. zones with dozens of records. " +
+			"All records are written in a single API request on create, update and destroy instead of one request per record, " +
+			"which is faster and stays clear of the rate limit. Only the records created or imported by the resource are managed, " +
+			"other records of the zone are left alone.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Identifier of the set, the domainname.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the records.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"records": schema.SetNestedAttribute{
+				Required:    true,
+				Description: "Records of the set. Each combination of hostname, type and destination may occur once.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"hostname": schema.StringAttribute{
+							Required:    true,
+							Description: "Name of the record. Use '@' for root of domain.",
+							Validators: []validator.String{
+								dnstypes.HostnameValidator(),
+							},
+						},
+						"type": schema.StringAttribute{
+							Required:    true,
+							Description: "Type of Record: A, AAAA, MX, CNAME, TXT, NS, SRV, CAA, TLSA, DS, OPENPGPKEY, SMIMEA or SSHFP. Matched case-insensitively.",
+							Validators: []validator.String{
+								dnstypes.RecordTypeValidator(),
+							},
+						},
+						"priority": schema.StringAttribute{
+							Optional:    true,
+							Description: "Required for MX and SRV records. Only MX and SRV records have a priority, it can't be set for other types.",
+						},
+						"destination": schema.StringAttribute{
+							Required:    true,
+							Description: "Target of the record.",
+						},
+					},
+				},
+			},
+			"update_strategy": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(updateStrategyIncremental),
+				MarkdownDescription: "Either `incremental` or `replace`. With `incremental` an update writes only added and removed records and changed priorities in a single request, " +
+					"so unchanged records are never missing. With `replace` an update deletes all records of the set and then creates the planned ones, " +
+					"so the records are briefly missing; the plan warns about it. If a write fails, the state keeps the records that exist afterwards. " +
+					"Defaults to `incremental`.",
+			},
+			"managed_records": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Records managed by this resource, as stored by the API.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:    true,
+							Description: "Unique ID of the record. Provided from Netcup-API",
+						},
+						"hostname": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the record.",
+						},
+						"type": schema.StringAttribute{
+							Computed:    true,
+							Description: "Type of the record.",
+						},
+						"priority": schema.StringAttribute{
+							Computed:    true,
+							Description: "Priority of the record.",
+						},
+						"destination": schema.StringAttribute{
+							Computed:    true,
+							Description: "Target of the record.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *recordSetResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var strategy types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("update_strategy"), &strategy)...)
+	if !strategy.IsNull() && !strategy.IsUnknown() &&
+		strategy.ValueString() != updateStrategyIncremental && strategy.ValueString() != updateStrategyReplace {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("update_strategy"),
+			"Invalid update strategy",
+			"The update strategy must be \""+updateStrategyIncremental+"\" or \""+updateStrategyReplace+"\", got \""+strategy.ValueString()+"\"",
+		)
+	}
+
+	var domainname types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("domainname"), &domainname)...)
+
+	var records types.Set
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("records"), &records)...)
+	if resp.Diagnostics.HasError() || records.IsNull() || records.IsUnknown() {
+		return
+	}
+	if len(records.Elements()) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("records"),
+			"No records",
+			"A record set needs at least one record.",
+		)
+		return
+	}
+
+	var values []RecordSetRecord
+	resp.Diagnostics.Append(records.ElementsAs(ctx, &values, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, record := range values {
+		if record.Type.IsUnknown() || record.Priority.IsUnknown() {
+			continue
+		}
+		recordType := strings.ToUpper(record.Type.ValueString())
+		priority := record.Priority.ValueString()
+		switch {
+		case priorityRecordTypes[recordType] && record.Priority.IsNull():
+			resp.Diagnostics.AddAttributeError(
+				path.Root("records"),
+				"Missing priority",
+				"The "+recordType+" record "+record.Hostname.ValueString()+" needs a priority.",
+			)
+		case !priorityRecordTypes[recordType] && priority != "" && priority != "0":
+			resp.Diagnostics.AddAttributeError(
+				path.Root("records"),
+				"Unsupported priority",
+				"Only MX and SRV records have a priority, the API drops the priority "+priority+" of the "+recordType+" record "+record.Hostname.ValueString()+".",
+			)
+		}
+
+		if record.Hostname.IsUnknown() || record.Destination.IsUnknown() {
+			continue
+		}
+		key := recordSetKey(domainname.ValueString(), record.Hostname.ValueString(), record.Type.ValueString(), record.Destination.ValueString())
+		if seen[key] {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("records"),
+				"Duplicate record",
+				"The "+recordType+" record "+record.Hostname.ValueString()+" with destination "+record.Destination.ValueString()+" occurs more than once. "+
+					"Each combination of hostname, type and destination may occur once.",
+			)
+		}
+		seen[key] = true
+	}
+}
+
+func (r *recordSetResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.client = req.ProviderData.(*client.CCPClient)
+}
+
+// Warn when an update replaces the records of the set, as the plan only lists the changed entries
+func (r *recordSetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var strategy types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("update_strategy"), &strategy)...)
+	if resp.Diagnostics.HasError() || strategy.ValueString() != updateStrategyReplace {
+		return
+	}
+
+	var domainname types.String
+	var planned types.Set
+	var state RecordSet
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("domainname"), &domainname)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("records"), &planned)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !planned.IsUnknown() {
+		var records []RecordSetRecord
+		resp.Diagnostics.Append(planned.ElementsAs(ctx, &records, true)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		changes := recordSetChanges(RecordSet{Domainname: domainname, Records: records}, recordSetManaged(ctx, state, &resp.Diagnostics))
+		if len(changes.Create)+len(changes.Update)+len(changes.Delete) == 0 {
+			return
+		}
+	}
+
+	resp.Diagnostics.AddAttributeWarning(
+		path.Root("records"),
+		"Record set is replaced",
+		"With update_strategy = \""+updateStrategyReplace+"\" all records of the set in domain "+domainname.ValueString()+" are deleted and the planned records created again, "+
+			"also those listed as unchanged. The records are missing until the new ones are created. "+
+			"Use update_strategy = \""+updateStrategyIncremental+"\" to write only the changed records.",
+	)
+}
+
+// Identity of a record within a set. Records with the same key but another priority are updated in place.
+func recordSetKey(domainname, hostname, recordType, destination string) string {
+	return normalizeHostname(hostname, domainname) + "|" + strings.ToUpper(recordType) + "|" + dnstypes.NormalizeDestination(recordType, destination)
+}
+
+func recordSetRecordKey(domainname string, record RecordSetRecord) string {
+	return recordSetKey(domainname, record.Hostname.ValueString(), record.Type.ValueString(), record.Destination.ValueString())
+}
+
+func dnsRecordSetKey(domainname string, record client.DnsRecord) string {
+	return recordSetKey(domainname, record.Hostname, record.Type, record.Destination)
+}
+
+// Records of the set as stored by the API, with the id tracked for each
+func recordSetManaged(ctx context.Context, m RecordSet, diags *diag.Diagnostics) []client.DnsRecord {
+	if m.ManagedRecords.IsNull() || m.ManagedRecords.IsUnknown() {
+		return nil
+	}
+
+	var managed []ManagedRecord
+	diags.Append(m.ManagedRecords.ElementsAs(ctx, &managed, false)...)
+
+	records := make([]client.DnsRecord, 0, len(managed))
+	for _, record := range managed {
+		records = append(records, client.DnsRecord{
+			Id:          record.ID.ValueString(),
+			Hostname:    record.Hostname.ValueString(),
+			Type:        record.Type.ValueString(),
+			Priority:    record.Priority.ValueString(),
+			Destination: record.Destination.ValueString(),
+		})
+	}
+	return records
+}
+
+// Entries of live records. Entries of prior keep their spelling, and their
+// priority if the configuration leaves it out and the API stores it as 0.
+func recordSetRecords(domainname string, live []client.DnsRecord, prior []RecordSetRecord) []RecordSetRecord {
+	priorByKey := make(map[string]RecordSetRecord, len(prior))
+	for _, record := range prior {
+		priorByKey[recordSetRecordKey(domainname, record)] = record
+	}
+
+	records := make([]RecordSetRecord, 0, len(live))
+	for _, record := range live {
+		entry := RecordSetRecord{
+			Hostname:    types.StringValue(record.Hostname),
+			Type:        types.StringValue(record.Type),
+			Priority:    types.StringNull(),
+			Destination: types.StringValue(record.Destination),
+		}
+		if priorityRecordTypes[strings.ToUpper(record.Type)] {
+			entry.Priority = types.StringValue(record.Priority)
+		}
+
+		if p, ok := priorByKey[dnsRecordSetKey(domainname, record)]; ok {
+			entry.Hostname, entry.Type, entry.Destination = p.Hostname, p.Type, p.Destination
+			if priorityOrZero(p.Priority.ValueString()) == priorityOrZero(record.Priority) {
+				entry.Priority = p.Priority
+			}
+		}
+		records = append(records, entry)
+	}
+	return records
+}
+
+// Entries as sorted "hostname type priority destination" list for comparison and drift warnings
+func formatRecordSetRecords(domainname string, records []RecordSetRecord) string {
+	values := make([]string, 0, len(records))
+	for _, record := range records {
+		values = append(values, normalizeHostname(record.Hostname.ValueString(), domainname)+" "+strings.ToUpper(record.Type.ValueString())+" "+
+			priorityOrZero(record.Priority.ValueString())+" "+dnstypes.NormalizeDestination(record.Type.ValueString(), record.Destination.ValueString()))
+	}
+	sort.Strings(values)
+	return strings.Join(values, ", ")
+}
+
+// Changes turning the managed records into the entries of plan. Managed records
+// are matched by hostname, type and destination, so only changed priorities are updates.
+func recordSetChanges(plan RecordSet, managed []client.DnsRecord) client.RecordChanges {
+	domainname := plan.Domainname.ValueString()
+	managedByKey := make(map[string]client.DnsRecord, len(managed))
+	for _, record := range managed {
+		managedByKey[dnsRecordSetKey(domainname, record)] = record
+	}
+
+	var changes client.RecordChanges
+	for _, entry := range plan.Records {
+		key := recordSetRecordKey(domainname, entry)
+		record, ok := managedByKey[key]
+		if !ok {
+			recordType := strings.ToUpper(entry.Type.ValueString())
+			changes.Create = append(changes.Create, client.NewDnsRecord{
+				Hostname:    normalizeHostname(entry.Hostname.ValueString(), domainname),
+				Type:        recordType,
+				Priority:    entry.Priority.ValueString(),
+				Destination: dnstypes.NormalizeDestination(recordType, entry.Destination.ValueString()),
+			})
+			continue
+		}
+		delete(managedByKey, key)
+
+		if priorityRecordTypes[strings.ToUpper(entry.Type.ValueString())] &&
+			priorityOrZero(entry.Priority.ValueString()) != priorityOrZero(record.Priority) {
+			record.Priority = entry.Priority.ValueString()
+			changes.Update = append(changes.Update, record)
+		}
+	}
+	for _, record := range managed {
+		if _, ok := managedByKey[dnsRecordSetKey(domainname, record)]; ok {
+			changes.Delete = append(changes.Delete, record)
+		}
+	}
+	return changes
+}
+
+// Write the changes of plan and store the resulting records in it. If the write
+// fails, plan holds the records existing afterwards instead. Reports whether
+// plan holds the records of the set, which is false if they couldn't be read.
+func (r recordSetResource) apply(ctx context.Context, plan *RecordSet, managed []client.DnsRecord, action string, diags *diag.Diagnostics) bool {
+	domainname := plan.Domainname.ValueString()
+	changes := recordSetChanges(*plan, managed)
+
+	// records existing before the write tell created records apart after a failure
+	before, err := r.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{})
+	if err != nil {
+		diags.AddError(
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return false
+	}
+
+	update := beginZoneUpdate(ctx, r.client, domainname, diags)
+
+	var records []client.DnsRecord
+	changed := len(changes.Create)+len(changes.Update)+len(changes.Delete) > 0
+	if changed && managed != nil && plan.UpdateStrategy.ValueString() == updateStrategyReplace {
+		records, err = r.replaceRecords(ctx, *plan, managed)
+	} else {
+		records, err = r.changeRecords(ctx, domainname, changes, managed)
+	}
+	if err != nil {
+		request := " the request to " + action + " the record set of domain " + domainname + "."
+		if !addRateLimitError(diags, "Netcup throttled"+request, err) && !addZoneLockedError(diags, "Netcup rejected"+request, err) {
+			diags.AddError(
+				"Error writing dns records",
+				"Could not "+action+" the record set of domain "+domainname+": "+err.Error(),
+			)
+		}
+		return r.existingRecords(ctx, plan, before, managed, diags)
+	}
+	if changed {
+		warnDnssecZone(ctx, r.client, domainname, diags)
+		update.await(ctx, r.client, diags)
+	}
+
+	managedRecords, d := managedRecordsValue(ctx, records)
+	diags.Append(d...)
+	plan.ID = types.StringValue(domainname)
+	plan.ManagedRecords = managedRecords
+	return true
+}
+
+// Write only the changes of the records, in a single request
+func (r recordSetResource) changeRecords(ctx context.Context, domainname string, changes client.RecordChanges, managed []client.DnsRecord) ([]client.DnsRecord, error) {
+	tflog.Trace(ctx, "Writing DNS records", map[string]interface{}{
+		"domainname": domainname, "create": len(changes.Create), "update": len(changes.Update), "delete": len(changes.Delete),
+	})
+
+	result, err := r.client.ChangeDnsRecords(ctx, domainname, changes)
+	if err != nil {
+		return nil, err
+	}
+	return replacedRecords(managed, result), nil
+}
+
+// Delete all managed records, then create every record of plan
+func (r recordSetResource) replaceRecords(ctx context.Context, plan RecordSet, managed []client.DnsRecord) ([]client.DnsRecord, error) {
+	domainname := plan.Domainname.ValueString()
+	creates := recordSetChanges(plan, nil).Create
+
+	tflog.Trace(ctx, "Replacing DNS records", map[string]interface{}{
+		"domainname": domainname, "create": len(creates), "delete": len(managed),
+	})
+
+	if err := r.client.DeleteDnsRecords(ctx, domainname, managed); err != nil {
+		return nil, err
+	}
+	return r.client.CreateDnsRecords(ctx, domainname, creates)
+}
+
+// Store the records of the set existing after a failed write in plan: managed
+// records that are left and records of plan created by the write. Reports
+// whether they could be read.
+func (r recordSetResource) existingRecords(ctx context.Context, plan *RecordSet, before, managed []client.DnsRecord, diags *diag.Diagnostics) bool {
+	domainname := plan.Domainname.ValueString()
+	after, err := r.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{})
+	if err != nil {
+		diags.AddError(
+			"Error reading records",
+			"Could not read the records of domain "+domainname+" after the failed write, refresh to find out which records of the set exist: "+err.Error(),
+		)
+		return false
+	}
+
+	ids := make(map[string]bool, len(managed))
+	for _, record := range managed {
+		ids[record.Id] = true
+	}
+	existed := make(map[string]bool, len(before))
+	for _, record := range before {
+		existed[record.Id] = true
+	}
+	wanted := make(map[string]bool, len(plan.Records))
+	for _, entry := range plan.Records {
+		wanted[recordSetRecordKey(domainname, entry)] = true
+	}
+
+	var records []client.DnsRecord
+	for _, record := range after {
+		if ids[record.Id] || (!existed[record.Id] && wanted[dnsRecordSetKey(domainname, record)]) {
+			records = append(records, record)
+		}
+	}
+	sortDnsRecords(records)
+
+	tflog.Debug(ctx, "Records of record set existing after failed write", map[string]interface{}{"domainname": domainname, "records": len(records)})
+
+	managedRecords, d := managedRecordsValue(ctx, records)
+	diags.Append(d...)
+	plan.ID = types.StringValue(domainname)
+	plan.Records = recordSetRecords(domainname, records, plan.Records)
+	plan.ManagedRecords = managedRecords
+	return true
+}
+
+// Create a new resource
+func (r recordSetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
+	var plan RecordSet
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := plan.Domainname.ValueString()
+	live, err := r.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	wanted := make(map[string]bool, len(plan.Records))
+	for _, entry := range plan.Records {
+		wanted[recordSetRecordKey(domainname, entry)] = true
+	}
+	var existing []client.DnsRecord
+	var ids []string
+	for _, record := range live {
+		if wanted[dnsRecordSetKey(domainname, record)] {
+			existing = append(existing, record)
+			ids = append(ids, record.Id)
+		}
+	}
+	if len(existing) > 0 {
+		resp.Diagnostics.AddError(
+			"Records already exist",
+			"Records of the set already exist in domain "+domainname+" and would be deleted on destroy although Terraform didn't create them. "+
+				"Import them with the id "+domainname+"/"+strings.Join(ids, ",")+" or remove them:\n"+formatRecordCandidates(existing),
+		)
+		return
+	}
+
+	// records created before a failure are stored, so they are deleted with the tainted set
+	if !r.apply(ctx, &plan, nil, "create", &resp.Diagnostics) || len(plan.Records) == 0 {
+		return
+	}
+
+	resp.Diagnostics.Append(forceNextRead(ctx, resp.Private)...)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read resource information
+func (r recordSetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "refresh", &resp.Diagnostics) {
+		return
+	}
+
+	var state RecordSet
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := state.Domainname.ValueString()
+
+	// Keep the prior state, unless it is incomplete like right after import
+	if skipRefresh(ctx, r.client, req.Private, &resp.Diagnostics) && state.Records != nil {
+		tflog.Trace(ctx, "Skipping refresh of record set", map[string]interface{}{"domainname": domainname})
+		// states written before update_strategy existed
+		if state.UpdateStrategy.IsNull() {
+			state.UpdateStrategy = types.StringValue(updateStrategyIncremental)
+			resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		}
+		return
+	}
+
+	managed := recordSetManaged(ctx, state, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	zone, err := r.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	ids := make(map[string]bool, len(managed))
+	for _, record := range managed {
+		ids[record.Id] = true
+	}
+	var live []client.DnsRecord
+	for _, record := range zone {
+		if ids[record.Id] {
+			live = append(live, record)
+		}
+	}
+	sortDnsRecords(live)
+
+	if len(live) == 0 {
+		tflog.Trace(ctx, "Records of record set missing, removing from state", map[string]interface{}{"domainname": domainname})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	records := recordSetRecords(domainname, live, state.Records)
+	if state.Records != nil {
+		before, after := formatRecordSetRecords(domainname, state.Records), formatRecordSetRecords(domainname, records)
+		warnDrift(ctx, r.client, req.Private, "The record set of domain "+domainname,
+			appendDrift(nil, "records", before, after, before == after), &resp.Diagnostics)
+	}
+	state.Records = records
+
+	managedRecords, diags := managedRecordsValue(ctx, live)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.ID = types.StringValue(domainname)
+	state.ManagedRecords = managedRecords
+	if state.UpdateStrategy.IsNull() {
+		state.UpdateStrategy = types.StringValue(updateStrategyIncremental)
+	}
+
+	resp.Diagnostics.Append(clearForceRead(ctx, resp.Private)...)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update resource. Added and removed records are written together with
+// changed priorities in a single request, or all records are replaced with
+// update_strategy replace.
+func (r recordSetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
+	var plan, state RecordSet
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	managed := recordSetManaged(ctx, state, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// after a failed write the state holds the records that exist. If they
+	// can't be read either, the prior state is kept explicitly instead of
+	// relying on the framework, as a state without managed_records would
+	// drop the set on the next refresh and leave its records unmanaged.
+	if !r.apply(ctx, &plan, managed, "update", &resp.Diagnostics) {
+		resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+		return
+	}
+	if len(plan.Records) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete resource
+func (r recordSetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
+	var state RecordSet
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dnsRecords := recordSetManaged(ctx, state, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := state.Domainname.ValueString()
+	update := beginZoneUpdate(ctx, r.client, domainname, &resp.Diagnostics)
+
+	for _, dnsRecord := range dnsRecords {
+		tflog.Trace(ctx, "Deleting DNS Record", dnsRecordLogFields(domainname, dnsRecord))
+	}
+
+	err := r.client.DeleteDnsRecords(ctx, domainname, dnsRecords)
+	if err != nil {
+		addDeleteError(&resp.Diagnostics, domainname, "delete", err)
+		return
+	}
+	warnDnssecZone(ctx, r.client, domainname, &resp.Diagnostics)
+	update.await(ctx, r.client, &resp.Diagnostics)
+
+	resp.State.RemoveResource(ctx)
+}
+
+// Import by domainname and the comma separated ids of the records, e.g.
+// example.com/12,13,14, or by domainname alone to import every record of the zone
+func (r recordSetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	domainname, list, ok := strings.Cut(req.ID, "/")
+	if domainname == "" || (ok && list == "") {
+		resp.Diagnostics.AddError(
+			"Invalid import id",
+			"Expected an id like example.com or example.com/12,13,14, got \""+req.ID+"\"",
+		)
+		return
+	}
+	domainname = strings.ToLower(strings.TrimSuffix(domainname, "."))
+
+	var records []client.DnsRecord
+	if ok {
+		for _, id := range strings.Split(list, ",") {
+			id = strings.TrimSpace(id)
+			if id == "" {
+				resp.Diagnostics.AddError(
+					"Invalid import id",
+					"The record ids of the import id \""+req.ID+"\" cannot be empty",
+				)
+				return
+			}
+			records = append(records, client.DnsRecord{Id: id})
+		}
+	} else {
+		if addNotConfiguredError(r.client, "import", &resp.Diagnostics) {
+			return
+		}
+		zone, err := r.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading records",
+				"Could not read records of domain "+domainname+": "+err.Error(),
+			)
+			return
+		}
+		if len(zone) == 0 {
+			resp.Diagnostics.AddError(
+				"Nothing to import",
+				"Domain "+domainname+" has no records",
+			)
+			return
+		}
+		records = zone
+	}
+
+	// Read fills in the records, only their ids are needed to find them
+	managed, diags := managedRecordsValue(ctx, records)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domainname"), domainname)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), domainname)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("managed_records"), managed)...)
+}
@@ -0,0 +1,365 @@
+package provider
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+func recordSetConfig(domain string, records ...attrs) attrs {
+	values := make([]interface{}, 0, len(records))
+	for _, record := range records {
+		values = append(values, record)
+	}
+	return attrs{"domainname": domain, "records": values}
+}
+
+func TestRecordSetNormalizedDestinations(t *testing.T) {
+	domain := testDomain(t)
+	config := recordSetConfig(domain,
+		attrs{"hostname": "WWW", "type": "a", "destination": " 192.0.2.1 "},
+		attrs{"hostname": "@", "type": "TXT", "destination": `"v=spf1 -all"`},
+		attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+	)
+
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_record_set", nullState(p, "netcupdns_record_set"), nil, config)
+	if n := len(elementsOf(t, attrValue(t, created.State, "managed_records"))); n != 3 {
+		t.Fatalf("manages %d records, want 3", n)
+	}
+
+	p = newTestProvider(t, nil)
+	refreshed, diags := p.read("netcupdns_record_set", created.State, created.Private)
+	p.checkDiags("refresh", diags)
+	if planned := p.plan("netcupdns_record_set", refreshed, created.Private, config); !planned.Equal(refreshed) {
+		t.Errorf("plan after apply isn't empty:\nplanned %s\nstate   %s", planned, refreshed)
+	}
+}
+
+// Hostnames with a trailing dot are created relative to the zone, and an unchanged set plans no changes
+func TestRecordSetAbsoluteHostnames(t *testing.T) {
+	domain := testDomain(t)
+	config := recordSetConfig(domain,
+		attrs{"hostname": "www.", "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": "api." + domain + ".", "type": "A", "destination": "192.0.2.2"},
+		attrs{"hostname": domain + ".", "type": "A", "destination": "192.0.2.3"},
+	)
+
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_record_set", nullState(p, "netcupdns_record_set"), nil, config)
+	want := []string{"@ A 0 192.0.2.3", "api A 0 192.0.2.2", "www A 0 192.0.2.1"}
+	if got := zoneRecords(t, domain); !reflect.DeepEqual(got, want) {
+		t.Errorf("zone has %q, want %q", got, want)
+	}
+
+	p = newTestProvider(t, nil)
+	refreshed, diags := p.read("netcupdns_record_set", created.State, created.Private)
+	p.checkDiags("refresh", diags)
+	if planned := p.plan("netcupdns_record_set", refreshed, created.Private, config); !planned.Equal(refreshed) {
+		t.Errorf("plan after apply isn't empty:\nplanned %s\nstate   %s", planned, refreshed)
+	}
+}
+
+// Ids of the managed records of a record set by "hostname destination"
+func managedRecordIds(t *testing.T, state tftypes.Value) map[string]string {
+	t.Helper()
+	ids := make(map[string]string)
+	for _, record := range elementsOf(t, attrValue(t, state, "managed_records")) {
+		ids[attrString(t, record, "hostname")+" "+attrString(t, record, "destination")] = attrString(t, record, "id")
+	}
+	return ids
+}
+
+func TestRecordSetUpdateStrategies(t *testing.T) {
+	tests := []struct {
+		strategy string
+		// whether unchanged records keep their id
+		kept bool
+	}{
+		{updateStrategyIncremental, true},
+		{updateStrategyReplace, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			domain := testDomain(t)
+			config := recordSetConfig(domain,
+				attrs{"hostname": "a", "type": "A", "destination": "192.0.2.1"},
+				attrs{"hostname": "b", "type": "A", "destination": "192.0.2.2"},
+			)
+			config["update_strategy"] = tt.strategy
+
+			p := newTestProvider(t, nil)
+			created := p.apply("netcupdns_record_set", nullState(p, "netcupdns_record_set"), nil, config)
+			if got := attrString(t, created.State, "update_strategy"); got != tt.strategy {
+				t.Errorf("update_strategy = %s, want %s", got, tt.strategy)
+			}
+			before := managedRecordIds(t, created.State)
+
+			changed := recordSetConfig(domain,
+				attrs{"hostname": "a", "type": "A", "destination": "192.0.2.1"},
+				attrs{"hostname": "c", "type": "A", "destination": "192.0.2.3"},
+			)
+			changed["update_strategy"] = tt.strategy
+
+			// the plan warns about replaced records
+			p = newTestProvider(t, nil)
+			_, diags := p.tryPlan("netcupdns_record_set", created.State, created.Private, changed)
+			p.checkDiags("plan", diags)
+			warned := len(summaries(diags, tfprotov6.DiagnosticSeverityWarning)) > 0 &&
+				summaries(diags, tfprotov6.DiagnosticSeverityWarning)[0] == "Record set is replaced"
+			if warned != !tt.kept {
+				t.Errorf("plan got warnings %v, want a replace warning: %t", summaries(diags, tfprotov6.DiagnosticSeverityWarning), !tt.kept)
+			}
+
+			updated := p.apply("netcupdns_record_set", created.State, created.Private, changed)
+			after := managedRecordIds(t, updated.State)
+			if len(after) != 2 || after["a 192.0.2.1"] == "" || after["c 192.0.2.3"] == "" {
+				t.Fatalf("manages %v, want a and c", after)
+			}
+			if kept := after["a 192.0.2.1"] == before["a 192.0.2.1"]; kept != tt.kept {
+				t.Errorf("record a has id %s, before %s, want kept: %t", after["a 192.0.2.1"], before["a 192.0.2.1"], tt.kept)
+			}
+
+			zone, diags := p.readDataSource("netcupdns_records", attrs{"domainname": domain})
+			p.checkDiags("read zone", diags)
+			if n := len(elementsOf(t, attrValue(t, zone, "records"))); n != 2 {
+				t.Errorf("zone has %d records, want 2", n)
+			}
+
+			// a changed strategy alone writes nothing
+			other := updateStrategyReplace
+			if tt.strategy == updateStrategyReplace {
+				other = updateStrategyIncremental
+			}
+			changed["update_strategy"] = other
+			p = newTestProvider(t, nil)
+			_, diags = p.tryPlan("netcupdns_record_set", updated.State, updated.Private, changed)
+			if warnings := summaries(diags, tfprotov6.DiagnosticSeverityWarning); len(warnings) > 0 {
+				t.Errorf("plan of the strategy change got warnings %v", warnings)
+			}
+			switched := p.apply("netcupdns_record_set", updated.State, updated.Private, changed)
+			if ids := managedRecordIds(t, switched.State); ids["a 192.0.2.1"] != after["a 192.0.2.1"] || ids["c 192.0.2.3"] != after["c 192.0.2.3"] {
+				t.Errorf("strategy change rewrote the records: %v, before %v", ids, after)
+			}
+		})
+	}
+}
+
+// A write failing midway leaves the records existing afterwards in the state.
+// The mock rejects records with an empty destination like the API.
+func TestRecordSetFailedUpdate(t *testing.T) {
+	tests := []struct {
+		strategy string
+		// records existing after the failed update
+		existing []string
+	}{
+		// the single request is rejected and nothing changes
+		{updateStrategyIncremental, []string{"a 192.0.2.1", "b 192.0.2.2"}},
+		// the records are deleted before the create is rejected
+		{updateStrategyReplace, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			domain := testDomain(t)
+			config := recordSetConfig(domain,
+				attrs{"hostname": "a", "type": "A", "destination": "192.0.2.1"},
+				attrs{"hostname": "b", "type": "A", "destination": "192.0.2.2"},
+			)
+			config["update_strategy"] = tt.strategy
+
+			p := newTestProvider(t, nil)
+			created := p.apply("netcupdns_record_set", nullState(p, "netcupdns_record_set"), nil, config)
+
+			rejected := recordSetConfig(domain,
+				attrs{"hostname": "a", "type": "A", "destination": "192.0.2.1"},
+				attrs{"hostname": "c", "type": "TXT", "destination": " "},
+			)
+			rejected["update_strategy"] = tt.strategy
+			p = newTestProvider(t, nil)
+			result := p.tryApply("netcupdns_record_set", created.State, created.Private, rejected)
+			if d := firstError(result.Diags); d == nil || d.Summary != "Error writing dns records" {
+				t.Fatalf("got errors %v, want a write error", summaries(result.Diags, tfprotov6.DiagnosticSeverityError))
+			}
+
+			var got []string
+			if !result.State.IsNull() {
+				for key := range managedRecordIds(t, result.State) {
+					got = append(got, key)
+				}
+				if n := len(elementsOf(t, attrValue(t, result.State, "records"))); n != len(got) {
+					t.Errorf("state has %d records for %d managed records", n, len(got))
+				}
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.existing) {
+				t.Errorf("state manages %v, want %v", got, tt.existing)
+			}
+
+			zone, diags := p.readDataSource("netcupdns_records", attrs{"domainname": domain})
+			p.checkDiags("read zone", diags)
+			if n := len(elementsOf(t, attrValue(t, zone, "records"))); n != len(tt.existing) {
+				t.Errorf("zone has %d records, want %d", n, len(tt.existing))
+			}
+		})
+	}
+}
+
+// A failed write whose records can't be read afterwards keeps the prior state,
+// so the set and its records stay managed
+func TestRecordSetFailedUpdateAndRead(t *testing.T) {
+	server := newLoginServer(t)
+	server.backend = client.NewMemoryBackend()
+	var mu sync.Mutex
+	failing, written := false, false
+	server.respond = func(action string, body []byte) map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case !failing:
+			return nil
+		case action == "updateDnsRecords":
+			written = true
+		case action != "infoDnsRecords" || !written:
+			return nil
+		}
+		return map[string]interface{}{"action": action, "status": "error", "statuscode": 5000, "shortmessage": "Internal error", "responsedata": ""}
+	}
+	p := startTestProvider(t)
+	p.checkDiags("configure", p.configure(attrs{
+		"endpoint": server.URL, "customer_number": "47171", "key": "abcdefghijklmnopqrstuvwxyz", "password": "password",
+		"max_retries": 0,
+	}))
+
+	domain := testDomain(t)
+	config := recordSetConfig(domain, attrs{"hostname": "a", "type": "A", "destination": "192.0.2.1"})
+	created := p.apply("netcupdns_record_set", nullState(p, "netcupdns_record_set"), nil, config)
+
+	mu.Lock()
+	failing = true
+	mu.Unlock()
+	changed := recordSetConfig(domain,
+		attrs{"hostname": "a", "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": "b", "type": "A", "destination": "192.0.2.2"},
+	)
+	result := p.tryApply("netcupdns_record_set", created.State, created.Private, changed)
+	errors := summaries(result.Diags, tfprotov6.DiagnosticSeverityError)
+	if len(errors) != 2 || errors[0] != "Error writing dns records" || errors[1] != "Error reading records" {
+		t.Fatalf("got errors %v, want a write and a read error", errors)
+	}
+	if !result.State.Equal(created.State) {
+		t.Errorf("state after the failed update isn't the prior state:\nstate %s\nprior %s", result.State, created.State)
+	}
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	refreshed, diags := p.read("netcupdns_record_set", result.State, result.Private)
+	p.checkDiags("refresh", diags)
+	if refreshed.IsNull() || len(managedRecordIds(t, refreshed)) != 1 {
+		t.Errorf("refresh after the failed update got %s, want the set with its record", refreshed)
+	}
+}
+
+func TestRecordSetInvalidUpdateStrategy(t *testing.T) {
+	p := newTestProvider(t, nil)
+	config := recordSetConfig("example.com", attrs{"hostname": "a", "type": "A", "destination": "192.0.2.1"})
+	config["update_strategy"] = "recreate"
+	if d := firstError(p.validate("netcupdns_record_set", config)); d == nil || d.Summary != "Invalid update strategy" {
+		t.Errorf("got error %v, want an invalid update strategy error", d)
+	}
+}
+
+// Destroying a record set deletes its records and leaves the other records of the zone
+func TestRecordSetDestroy(t *testing.T) {
+	domain := testDomain(t)
+	seedZone(t, domain, attrs{"hostname": "other", "type": "A", "destination": "192.0.2.9"})
+	config := recordSetConfig(domain,
+		attrs{"hostname": "a", "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": "b", "type": "A", "destination": "192.0.2.2"},
+		attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+	)
+
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_record_set", nullState(p, "netcupdns_record_set"), nil, config)
+	destroyed := p.apply("netcupdns_record_set", created.State, created.Private, nil)
+	if !destroyed.State.IsNull() {
+		t.Errorf("state after destroy is %s, want null", destroyed.State)
+	}
+	p.close()
+
+	if got, want := zoneRecords(t, domain), []string{"other A 0 192.0.2.9"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("zone has %q after destroy, want %q", got, want)
+	}
+}
+
+// Importing a zone by domainname manages all of its records, and planning the
+// matching or generated configuration shows no changes
+func TestRecordSetImportWholeZone(t *testing.T) {
+	domain := testDomain(t)
+	var records []attrs
+	for i := 0; i < 10; i++ {
+		host := fmt.Sprintf("host%d", i)
+		records = append(records,
+			attrs{"hostname": host, "type": "A", "destination": fmt.Sprintf("192.0.2.%d", i+1)},
+			attrs{"hostname": host, "type": "AAAA", "destination": fmt.Sprintf("2001:db8::%d", i+1)},
+			attrs{"hostname": host, "type": "MX", "priority": strconv.Itoa(10 * (i + 1)), "destination": "mail.example.com"},
+		)
+	}
+	// seedZone adds the domainname to the records it creates
+	seeded := make([]attrs, 0, len(records))
+	for _, record := range records {
+		seeded = append(seeded, maps.Clone(record))
+	}
+	seedZone(t, domain, seeded...)
+	seedZone(t, "other-"+domain, attrs{"hostname": "www", "type": "A", "destination": "192.0.2.1"})
+
+	p := newTestProvider(t, nil)
+	imported, diags := p.importState("netcupdns_record_set", domain+".")
+	p.checkDiags("import", diags)
+	if got := attrString(t, imported, "domainname"); got != domain {
+		t.Errorf("imported domainname %s, want %s", got, domain)
+	}
+	if n := len(elementsOf(t, attrValue(t, imported, "managed_records"))); n != 30 {
+		t.Fatalf("import manages %d records, want 30", n)
+	}
+	if n := len(elementsOf(t, attrValue(t, imported, "records"))); n != 30 {
+		t.Fatalf("imported %d records, want 30", n)
+	}
+
+	configs := map[string]attrs{
+		"matching":  recordSetConfig(domain, records...),
+		"generated": generatedConfig(p, "netcupdns_record_set", imported),
+	}
+	for name, config := range configs {
+		if planned := p.plan("netcupdns_record_set", imported, nil, config); !planned.Equal(imported) {
+			t.Errorf("plan of the %s config after import isn't empty:\nplanned %s\nstate   %s", name, planned, imported)
+		}
+	}
+}
+
+func TestRecordSetImportInvalidId(t *testing.T) {
+	domain := testDomain(t)
+	p := newTestProvider(t, nil)
+	for _, tt := range []struct{ id, summary string }{
+		{"/12,13", "Invalid import id"},
+		{domain + "/", "Invalid import id"},
+		{domain + "/12,,13", "Invalid import id"},
+		{domain, "Nothing to import"},
+		{"example.invalid", "Error reading records"},
+	} {
+		_, diags := p.importState("netcupdns_record_set", tt.id)
+		if d := firstError(diags); d == nil || d.Summary != tt.summary {
+			t.Errorf("import %q: got errors %v, want %s", tt.id, summaries(diags, tfprotov6.DiagnosticSeverityError), tt.summary)
+		}
+	}
+}
@@ -0,0 +1,748 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ resource.Resource                   = &srvSetResource{}
+	_ resource.ResourceWithConfigure      = &srvSetResource{}
+	_ resource.ResourceWithImportState    = &srvSetResource{}
+	_ resource.ResourceWithModifyPlan     = &srvSetResource{}
+	_ resource.ResourceWithValidateConfig = &srvSetResource{}
+)
+
+// Private state key of the ids of the records written or imported by the set,
+// to tell records added outside of Terraform apart after a refresh
+const srvManagedIdsKey = "managed_ids"
+
+// Maximum number of unmanaged records listed by a plan removing them
+const maxListedUnmanagedRecords = 10
+
+// Service and protocol labels of an SRV owner name, with or without the leading underscore
+var srvLabelPattern = regexp.MustCompile(`^_?[A-Za-z0-9-]+$`)
+
+func NewSrvSetResource() resource.Resource {
+	return &srvSetResource{}
+}
+
+type srvSetResource struct {
+	client *client.CCPClient
+}
+
+func (r *srvSetResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_srv_set"
+}
+
+func (r *srvSetResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	requiresReplace := []planmodifier.String{stringplanmodifier.RequiresReplace()}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages all SRV records of a service as one set, e.g. the `_sip._udp` records of several SIP servers. " +
+			"The resource owns every SRV record at the owner name `_<service>._<protocol>[.<name>]`: " +
+			"records added outside of Terraform show up as drift and are removed on the next apply.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Identifier of the set in the format domainname/service/protocol[/name].",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"domainname": schema.StringAttribute{
+				Required:      true,
+				Description:   "Domainname of the records.",
+				PlanModifiers: requiresReplace,
+			},
+			"service": schema.StringAttribute{
+				Required:      true,
+				Description:   "Symbolic name of the service, e.g. sip or xmpp-client. A leading underscore is optional.",
+				PlanModifiers: requiresReplace,
+			},
+			"protocol": schema.StringAttribute{
+				Required:      true,
+				Description:   "Protocol of the service, e.g. tcp or udp. A leading underscore is optional.",
+				PlanModifiers: requiresReplace,
+			},
+			"name": schema.StringAttribute{
+				Optional:      true,
+				Description:   "Name the service is offered for, relative to the zone. Defaults to the root of the domain.",
+				PlanModifiers: requiresReplace,
+			},
+			"fail_on_unmanaged": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Fail plans that remove SRV records added outside of Terraform, instead of warning about them. Defaults to false.",
+			},
+			"targets": schema.SetNestedAttribute{
+				Required:    true,
+				Description: "Servers of the service. Each combination of priority and target may occur once.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"priority": schema.Int64Attribute{
+							Required:    true,
+							Description: "Priority of the server, lower values are preferred. Between 0 and 65535.",
+						},
+						"weight": schema.Int64Attribute{
+							Required:    true,
+							Description: "Relative weight among servers of the same priority. Between 0 and 65535.",
+						},
+						"port": schema.Int64Attribute{
+							Required:    true,
+							Description: "Port of the service on the server. Between 0 and 65535.",
+						},
+						"target": schema.StringAttribute{
+							Required:    true,
+							Description: "Hostname of the server, e.g. sip1.example.com. Use '.' to announce that the service is not available.",
+						},
+					},
+				},
+			},
+			"records": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Records managed by this resource.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:    true,
+							Description: "Unique ID of the record. Provided from Netcup-API",
+						},
+						"hostname": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the record.",
+						},
+						"type": schema.StringAttribute{
+							Computed:    true,
+							Description: "Type of the record.",
+						},
+						"priority": schema.StringAttribute{
+							Computed:    true,
+							Description: "Priority of the record.",
+						},
+						"destination": schema.StringAttribute{
+							Computed:    true,
+							Description: "Target of the record.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *srvSetResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	for _, attribute := range []string{"service", "protocol"} {
+		var label types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(attribute), &label)...)
+		if label.IsNull() || label.IsUnknown() {
+			continue
+		}
+		if !srvLabelPattern.MatchString(label.ValueString()) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attribute),
+				"Invalid "+attribute,
+				"The "+attribute+" must consist of letters, digits and hyphens, got \""+label.ValueString()+"\"",
+			)
+		}
+	}
+
+	var targets types.Set
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("targets"), &targets)...)
+	if resp.Diagnostics.HasError() || targets.IsNull() || targets.IsUnknown() {
+		return
+	}
+	if len(targets.Elements()) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("targets"),
+			"No targets",
+			"An SRV set needs at least one target. Use target \".\" to announce that the service is not available.",
+		)
+		return
+	}
+
+	var values []SrvTarget
+	resp.Diagnostics.Append(targets.ElementsAs(ctx, &values, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range values {
+		for name, value := range map[string]types.Int64{"priority": t.Priority, "weight": t.Weight, "port": t.Port} {
+			if !value.IsNull() && !value.IsUnknown() && (value.ValueInt64() < 0 || value.ValueInt64() > 65535) {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("targets"),
+					"Invalid "+name,
+					fmt.Sprintf("The %s of an SRV target must be between 0 and 65535, got %d", name, value.ValueInt64()),
+				)
+			}
+		}
+		if t.Priority.IsUnknown() || t.Target.IsUnknown() || t.Target.IsNull() {
+			continue
+		}
+		if strings.TrimSpace(t.Target.ValueString()) == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("targets"),
+				"Invalid target",
+				"The target of an SRV record cannot be empty, use \".\" to announce that the service is not available",
+			)
+			continue
+		}
+		key := srvTargetKey(t.Priority.ValueInt64(), t.Target.ValueString())
+		if seen[key] {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("targets"),
+				"Duplicate target",
+				fmt.Sprintf("The target %s occurs more than once with priority %d. Each combination of priority and target may occur once.", t.Target.ValueString(), t.Priority.ValueInt64()),
+			)
+		}
+		seen[key] = true
+	}
+}
+
+func (r *srvSetResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.client = req.ProviderData.(*client.CCPClient)
+}
+
+// Warn about records added outside of Terraform that an update removes, as the
+// plan only shows them as removed targets. With fail_on_unmanaged the plan fails.
+func (r *srvSetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing is removed on create, destroy only removes the records of the state
+	// and unchanged sets aren't written at all
+	if r.client == nil || req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || req.Plan.Raw.Equal(req.State.Raw) {
+		return
+	}
+
+	var domainname, service, protocol, name types.String
+	var failOnUnmanaged types.Bool
+	var planned types.Set
+	var records types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("domainname"), &domainname)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("service"), &service)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("protocol"), &protocol)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("name"), &name)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("fail_on_unmanaged"), &failOnUnmanaged)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("targets"), &planned)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("records"), &records)...)
+	if resp.Diagnostics.HasError() || planned.IsUnknown() || domainname.IsUnknown() || name.IsUnknown() {
+		return
+	}
+	var targets []SrvTarget
+	resp.Diagnostics.Append(planned.ElementsAs(ctx, &targets, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	keep := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		if t.Priority.IsUnknown() || t.Target.IsUnknown() {
+			return
+		}
+		keep[srvTargetKey(t.Priority.ValueInt64(), t.Target.ValueString())] = true
+	}
+
+	managed, d := srvManagedIds(ctx, req.Private, records)
+	resp.Diagnostics.Append(d...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	set := SrvSet{Domainname: domainname, Service: service, Protocol: protocol, Name: name}
+	owner := srvOwner(set)
+	live, err := r.client.GetDnsRecordsFiltered(ctx, domainname.ValueString(), client.RecordFilter{Hostname: owner, Type: "SRV"})
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Error reading records",
+			"Could not read records of domain "+domainname.ValueString()+" to check for records added outside of Terraform: "+err.Error(),
+		)
+		return
+	}
+
+	var unmanaged []client.DnsRecord
+	for _, record := range live {
+		if managed[record.Id] {
+			continue
+		}
+		if t, err := parseSrvRecord(record); err == nil && keep[srvTargetKey(t.Priority.ValueInt64(), t.Target.ValueString())] {
+			continue
+		}
+		unmanaged = append(unmanaged, record)
+	}
+	if len(unmanaged) == 0 {
+		return
+	}
+
+	listing := formatRecordCandidates(unmanaged)
+	if len(unmanaged) > maxListedUnmanagedRecords {
+		listing = formatRecordCandidates(unmanaged[:maxListedUnmanagedRecords]) + fmt.Sprintf("\n  ... and %d more", len(unmanaged)-maxListedUnmanagedRecords)
+	}
+	detail := fmt.Sprintf("The apply removes %d SRV records at %s of domain %s that weren't created by Terraform:\n%s\n\n", len(unmanaged), owner, domainname.ValueString(), listing) +
+		"Add them to targets to keep them."
+	if failOnUnmanaged.ValueBool() {
+		resp.Diagnostics.AddAttributeError(path.Root("targets"), "Unmanaged records would be removed", detail+" Set fail_on_unmanaged = false to remove them.")
+		return
+	}
+	resp.Diagnostics.AddAttributeWarning(path.Root("targets"), "Unmanaged records are removed", detail+" Set fail_on_unmanaged = true to fail plans removing them.")
+}
+
+// Remember the records of the set as written or imported by it
+func setSrvManagedIds(ctx context.Context, private privateSetter, records types.List) diag.Diagnostics {
+	var managed []ManagedRecord
+	diags := records.ElementsAs(ctx, &managed, false)
+	if diags.HasError() {
+		return diags
+	}
+	ids := make([]string, 0, len(managed))
+	for _, record := range managed {
+		ids = append(ids, record.ID.ValueString())
+	}
+	value, err := json.Marshal(ids)
+	if err != nil {
+		diags.AddError("Error storing managed records", err.Error())
+		return diags
+	}
+	return append(diags, private.SetKey(ctx, srvManagedIdsKey, value)...)
+}
+
+// Ids of the records written or imported by the set. States written before the
+// ids were kept fall back to the records of the state.
+func srvManagedIds(ctx context.Context, private privateGetter, records types.List) (map[string]bool, diag.Diagnostics) {
+	value, diags := private.GetKey(ctx, srvManagedIdsKey)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	var ids []string
+	if len(value) > 0 {
+		if err := json.Unmarshal(value, &ids); err != nil {
+			diags.AddError("Error reading managed records", err.Error())
+			return nil, diags
+		}
+	} else if !records.IsNull() && !records.IsUnknown() {
+		var managed []ManagedRecord
+		diags.Append(records.ElementsAs(ctx, &managed, false)...)
+		for _, record := range managed {
+			ids = append(ids, record.ID.ValueString())
+		}
+	}
+
+	managed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		managed[id] = true
+	}
+	return managed, diags
+}
+
+// Owner name of the records relative to the zone, e.g. _sip._udp or _sip._udp.office
+func srvOwner(m SrvSet) string {
+	owner := "_" + strings.TrimPrefix(strings.ToLower(m.Service.ValueString()), "_") +
+		"._" + strings.TrimPrefix(strings.ToLower(m.Protocol.ValueString()), "_")
+	if name := normalizeHostname(m.Name.ValueString(), m.Domainname.ValueString()); name != "@" {
+		owner += "." + name
+	}
+	return owner
+}
+
+func srvSetID(m SrvSet) string {
+	id := m.Domainname.ValueString() + "/" + m.Service.ValueString() + "/" + m.Protocol.ValueString()
+	if !m.Name.IsNull() {
+		id += "/" + m.Name.ValueString()
+	}
+	return id
+}
+
+// Identity of a target within a set. Targets are compared like hostnames.
+func srvTargetKey(priority int64, target string) string {
+	return strconv.FormatInt(priority, 10) + "|" + strings.ToLower(strings.TrimSuffix(target, "."))
+}
+
+// Parse an SRV record, whose destination holds weight, port and target
+func parseSrvRecord(record client.DnsRecord) (SrvTarget, error) {
+	priority, err := strconv.ParseInt(priorityOrZero(record.Priority), 10, 64)
+	if err != nil {
+		return SrvTarget{}, fmt.Errorf("invalid priority %q of SRV record %s", record.Priority, record.Id)
+	}
+	fields := strings.Fields(record.Destination)
+	if len(fields) != 3 {
+		return SrvTarget{}, fmt.Errorf("SRV record %s has destination %q instead of weight, port and target", record.Id, record.Destination)
+	}
+	weight, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return SrvTarget{}, fmt.Errorf("invalid weight %q of SRV record %s", fields[0], record.Id)
+	}
+	port, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return SrvTarget{}, fmt.Errorf("invalid port %q of SRV record %s", fields[1], record.Id)
+	}
+	return SrvTarget{
+		Priority: types.Int64Value(priority),
+		Weight:   types.Int64Value(weight),
+		Port:     types.Int64Value(port),
+		Target:   types.StringValue(fields[2]),
+	}, nil
+}
+
+// Records for the targets of a set. Targets keep the id of the live record with
+// the same priority and target, so changed weights and ports are updated in place.
+func srvRecords(m SrvSet, live []client.DnsRecord) []client.DnsRecord {
+	liveByKey := make(map[string]string, len(live))
+	for _, record := range live {
+		if t, err := parseSrvRecord(record); err == nil {
+			liveByKey[srvTargetKey(t.Priority.ValueInt64(), t.Target.ValueString())] = record.Id
+		}
+	}
+
+	owner := srvOwner(m)
+	records := make([]client.DnsRecord, 0, len(m.Targets))
+	for _, t := range m.Targets {
+		records = append(records, client.DnsRecord{
+			Id:          liveByKey[srvTargetKey(t.Priority.ValueInt64(), t.Target.ValueString())],
+			Hostname:    owner,
+			Type:        "SRV",
+			Priority:    strconv.FormatInt(t.Priority.ValueInt64(), 10),
+			Destination: fmt.Sprintf("%d %d %s", t.Weight.ValueInt64(), t.Port.ValueInt64(), strings.TrimSuffix(t.Target.ValueString(), ".")+"."),
+		})
+	}
+	return records
+}
+
+// Targets of live records. Targets of prior keep their spelling, e.g. without trailing dot.
+func srvTargets(live []client.DnsRecord, prior []SrvTarget) ([]SrvTarget, error) {
+	spelling := make(map[string]types.String, len(prior))
+	for _, t := range prior {
+		spelling[srvTargetKey(t.Priority.ValueInt64(), t.Target.ValueString())] = t.Target
+	}
+
+	targets := make([]SrvTarget, 0, len(live))
+	for _, record := range live {
+		t, err := parseSrvRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		if target, ok := spelling[srvTargetKey(t.Priority.ValueInt64(), t.Target.ValueString())]; ok {
+			t.Target = target
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// Targets as sorted "priority weight port target" list for comparison and drift warnings
+func formatSrvTargets(targets []SrvTarget) string {
+	values := make([]string, 0, len(targets))
+	for _, t := range targets {
+		values = append(values, fmt.Sprintf("%d %d %d %s", t.Priority.ValueInt64(), t.Weight.ValueInt64(), t.Port.ValueInt64(), strings.ToLower(strings.TrimSuffix(t.Target.ValueString(), "."))))
+	}
+	sort.Strings(values)
+	return strings.Join(values, ", ")
+}
+
+// Records of the set after ReplaceAllRecords turned live into result
+func replacedRecords(live []client.DnsRecord, result client.ReplaceResult) []client.DnsRecord {
+	deleted := make(map[string]bool, len(result.Deleted))
+	for _, record := range result.Deleted {
+		deleted[record.Id] = true
+	}
+	updated := make(map[string]client.DnsRecord, len(result.Updated))
+	for _, record := range result.Updated {
+		updated[record.Id] = record
+	}
+
+	var records []client.DnsRecord
+	for _, record := range live {
+		if deleted[record.Id] {
+			continue
+		}
+		if u, ok := updated[record.Id]; ok {
+			record = u
+		}
+		records = append(records, record)
+	}
+	records = append(records, result.Created...)
+	sortDnsRecords(records)
+	return records
+}
+
+// Write the targets of plan and store the resulting records in it
+func (r srvSetResource) apply(ctx context.Context, plan *SrvSet, live []client.DnsRecord, action string, diags *diag.Diagnostics) {
+	domainname := plan.Domainname.ValueString()
+	owner := srvOwner(*plan)
+
+	update := beginZoneUpdate(ctx, r.client, domainname, diags)
+
+	tflog.Trace(ctx, "Writing SRV records", map[string]interface{}{"domainname": domainname, "hostname": owner, "count": len(plan.Targets)})
+
+	result, err := r.client.ReplaceAllRecords(ctx, domainname, srvRecords(*plan, live), client.ReplaceOptions{
+		Scope: &client.RecordFilter{Hostname: owner, Type: "SRV"},
+	})
+	if err != nil {
+		request := " the request to " + action + " the SRV records " + owner + " of domain " + domainname + "."
+		if !addRateLimitError(diags, "Netcup throttled"+request, err) && !addZoneLockedError(diags, "Netcup rejected"+request, err) {
+			diags.AddError(
+				"Error writing dns records",
+				"Could not "+action+" the SRV records "+owner+" of domain "+domainname+": "+err.Error(),
+			)
+		}
+		return
+	}
+	if result.HasChanges() {
+		warnDnssecZone(ctx, r.client, domainname, diags)
+		update.await(ctx, r.client, diags)
+	}
+
+	records, d := managedRecordsValue(ctx, replacedRecords(live, result))
+	diags.Append(d...)
+	plan.ID = types.StringValue(srvSetID(*plan))
+	plan.Records = records
+}
+
+// Create a new resource
+func (r srvSetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
+	var plan SrvSet
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := plan.Domainname.ValueString()
+	owner := srvOwner(plan)
+	live, err := r.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{Hostname: owner, Type: "SRV"})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+	if len(live) > 0 {
+		resp.Diagnostics.AddError(
+			"Conflicting records exist",
+			"SRV records already exist at "+owner+" and would be owned by this resource. "+
+				"Import them with the id "+srvSetID(plan)+" or remove them:\n"+formatRecordCandidates(live),
+		)
+		return
+	}
+
+	r.apply(ctx, &plan, live, "create", &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(setSrvManagedIds(ctx, resp.Private, plan.Records)...)
+	resp.Diagnostics.Append(forceNextRead(ctx, resp.Private)...)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read resource information
+func (r srvSetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "refresh", &resp.Diagnostics) {
+		return
+	}
+
+	var state SrvSet
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Keep the prior state, unless it is incomplete like right after import
+	if skipRefresh(ctx, r.client, req.Private, &resp.Diagnostics) && state.Targets != nil {
+		tflog.Trace(ctx, "Skipping refresh of SRV records", map[string]interface{}{"domainname": state.Domainname.ValueString(), "hostname": srvOwner(state)})
+		return
+	}
+
+	domainname := state.Domainname.ValueString()
+	live, err := lookupDnsRecords(ctx, r.client, domainname, client.RecordFilter{Hostname: srvOwner(state), Type: "SRV"})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	if len(live) == 0 {
+		tflog.Trace(ctx, "SRV records missing, removing from state", map[string]interface{}{"domainname": domainname, "hostname": srvOwner(state)})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	targets, err := srvTargets(live, state.Targets)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unexpected record value",
+			"Could not read the SRV records "+srvOwner(state)+" of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+	imported := state.Targets == nil
+	if !imported {
+		before, after := formatSrvTargets(state.Targets), formatSrvTargets(targets)
+		warnDrift(ctx, r.client, req.Private, "The SRV records "+srvOwner(state)+" of domain "+domainname,
+			appendDrift(nil, "targets", before, after, before == after), &resp.Diagnostics)
+	}
+	state.Targets = targets
+
+	records, diags := managedRecordsValue(ctx, live)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// imported sets own the records found at the owner name
+	if imported {
+		resp.Diagnostics.Append(setSrvManagedIds(ctx, resp.Private, records)...)
+	}
+	state.ID = types.StringValue(srvSetID(state))
+	state.Records = records
+
+	resp.Diagnostics.Append(clearForceRead(ctx, resp.Private)...)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update resource. Only targets change in place, added and removed targets
+// are written together with changed weights and ports.
+func (r srvSetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
+	var plan SrvSet
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := plan.Domainname.ValueString()
+	live, err := r.client.GetDnsRecordsFiltered(ctx, domainname, client.RecordFilter{Hostname: srvOwner(plan), Type: "SRV"})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading records",
+			"Could not read records of domain "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	r.apply(ctx, &plan, live, "update", &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(setSrvManagedIds(ctx, resp.Private, plan.Records)...)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete resource
+func (r srvSetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
+	var state SrvSet
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var managed []ManagedRecord
+	diags = state.Records.ElementsAs(ctx, &managed, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainname := state.Domainname.ValueString()
+	update := beginZoneUpdate(ctx, r.client, domainname, &resp.Diagnostics)
+
+	dnsRecords := make([]client.DnsRecord, 0, len(managed))
+	for _, record := range managed {
+		var dnsRecord = client.DnsRecord{
+			Id:          record.ID.ValueString(),
+			Hostname:    record.Hostname.ValueString(),
+			Type:        record.Type.ValueString(),
+			Priority:    record.Priority.ValueString(),
+			Destination: record.Destination.ValueString(),
+		}
+
+		tflog.Trace(ctx, "Deleting DNS Record", dnsRecordLogFields(domainname, dnsRecord))
+		dnsRecords = append(dnsRecords, dnsRecord)
+	}
+
+	err := r.client.DeleteDnsRecords(ctx, domainname, dnsRecords)
+	if err != nil {
+		addDeleteError(&resp.Diagnostics, domainname, "delete", err)
+		return
+	}
+	warnDnssecZone(ctx, r.client, domainname, &resp.Diagnostics)
+	update.await(ctx, r.client, &resp.Diagnostics)
+
+	resp.State.RemoveResource(ctx)
+}
+
+// Import by domainname/service/protocol or domainname/service/protocol/name
+func (r srvSetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.Split(req.ID, "/")
+	if len(parts) < 3 || len(parts) > 4 {
+		resp.Diagnostics.AddError(
+			"Invalid import id",
+			"Expected an id like example.com/sip/udp or example.com/sip/udp/office, got \""+req.ID+"\"",
+		)
+		return
+	}
+	for _, part := range parts {
+		if part == "" {
+			resp.Diagnostics.AddError(
+				"Invalid import id",
+				"The parts of the import id \""+req.ID+"\" cannot be empty",
+			)
+			return
+		}
+	}
+
+	domainname := strings.ToLower(strings.TrimSuffix(parts[0], "."))
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domainname"), domainname)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("service"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("protocol"), parts[2])...)
+	if len(parts) == 4 {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), parts[3])...)
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
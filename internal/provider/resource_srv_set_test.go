@@ -0,0 +1,309 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+func srvSetConfig(domain string, targets ...string) attrs {
+	values := make([]interface{}, 0, len(targets))
+	for i, target := range targets {
+		values = append(values, map[string]interface{}{"priority": 10, "weight": 10 * (i + 1), "port": 5060, "target": target})
+	}
+	return attrs{"domainname": domain, "service": "sip", "protocol": "udp", "targets": values}
+}
+
+// Add an SRV record outside of Terraform
+func createSrvRecord(t *testing.T, domain, hostname, priority, destination string) string {
+	t.Helper()
+	created, err := mockClient(t).CreateDnsRecord(context.Background(), domain, client.NewDnsRecord{Hostname: hostname, Type: "SRV", Priority: priority, Destination: destination})
+	if err != nil {
+		t.Fatalf("CreateDnsRecord failed: %s", err)
+	}
+	return created.Id
+}
+
+var listedRecordId = regexp.MustCompile(`(?m)^  - id=(\S+) `)
+
+// Diagnostic listing unmanaged records and the sorted ids it lists
+func unmanagedRecords(t *testing.T, diags []*tfprotov6.Diagnostic, summary string) (*tfprotov6.Diagnostic, []string) {
+	t.Helper()
+	for _, d := range diags {
+		if d.Summary != summary {
+			continue
+		}
+		ids := []string{}
+		for _, match := range listedRecordId.FindAllStringSubmatch(d.Detail, -1) {
+			ids = append(ids, match[1])
+		}
+		sort.Strings(ids)
+		return d, ids
+	}
+	return nil, nil
+}
+
+// The plan of the next run lists exactly the records of the owner name that
+// weren't created by Terraform and aren't in the configuration
+func TestSrvSetUnmanagedRecordsWarning(t *testing.T) {
+	domain := testDomain(t)
+	config := srvSetConfig(domain, "sip1.example.com", "sip2.example.com")
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_srv_set", nullState(p, "netcupdns_srv_set"), nil, config)
+	p.close()
+
+	stray := createSrvRecord(t, domain, "_sip._udp", "20", "0 5060 sip3.example.com.")
+	otherStray := createSrvRecord(t, domain, "_sip._udp", "30", "0 5060 sip4.example.com.")
+	// other owner names and types are left alone
+	createSrvRecord(t, domain, "_sip._tcp", "10", "0 5060 sip5.example.com.")
+	if _, err := mockClient(t).CreateDnsRecord(context.Background(), domain, client.NewDnsRecord{Hostname: "_sip._udp", Type: "TXT", Destination: "not srv"}); err != nil {
+		t.Fatal(err)
+	}
+
+	p = newTestProvider(t, nil)
+	refreshed, private, diags := p.refresh("netcupdns_srv_set", created.State, created.Private)
+	p.checkDiags("refresh", diags)
+
+	// removing a managed target isn't reported, it is part of the configuration
+	config = srvSetConfig(domain, "sip1.example.com")
+	_, diags = p.tryPlan("netcupdns_srv_set", refreshed, private, config)
+	d, ids := unmanagedRecords(t, diags, "Unmanaged records are removed")
+	if d == nil {
+		t.Fatalf("got warnings %v, want the unmanaged records", summaries(diags, tfprotov6.DiagnosticSeverityWarning))
+	}
+	want := []string{otherStray, stray}
+	sort.Strings(want)
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("listed records %v, want the strays %v:\n%s", ids, want, d.Detail)
+	}
+	if d.Severity != tfprotov6.DiagnosticSeverityWarning || !strings.Contains(d.Detail, "_sip._udp of domain "+domain) || !strings.Contains(d.Detail, "sip3.example.com.") {
+		t.Errorf("got %s diagnostic %q", d.Severity, d.Detail)
+	}
+
+	// a stray kept by the configuration is updated instead of removed
+	config = srvSetConfig(domain, "sip1.example.com", "sip2.example.com")
+	config["targets"] = append(config["targets"].([]interface{}), map[string]interface{}{"priority": 20, "weight": 5, "port": 5060, "target": "sip3.example.com"})
+	_, diags = p.tryPlan("netcupdns_srv_set", refreshed, private, config)
+	if _, ids := unmanagedRecords(t, diags, "Unmanaged records are removed"); !reflect.DeepEqual(ids, []string{otherStray}) {
+		t.Errorf("listed records %v with a stray in the configuration, want %s", ids, otherStray)
+	}
+
+	config["fail_on_unmanaged"] = true
+	_, diags = p.tryPlan("netcupdns_srv_set", refreshed, private, config)
+	d, ids = unmanagedRecords(t, diags, "Unmanaged records would be removed")
+	if d == nil || d.Severity != tfprotov6.DiagnosticSeverityError || !reflect.DeepEqual(ids, []string{otherStray}) {
+		t.Errorf("fail_on_unmanaged got errors %v listing %v, want an error listing %s", summaries(diags, tfprotov6.DiagnosticSeverityError), ids, otherStray)
+	}
+
+	// after the apply the remaining records are managed
+	config["fail_on_unmanaged"] = nil
+	config["targets"] = append(config["targets"].([]interface{}), map[string]interface{}{"priority": 30, "weight": 0, "port": 5060, "target": "sip4.example.com"})
+	updated := p.apply("netcupdns_srv_set", refreshed, private, config)
+	if d, _ := unmanagedRecords(t, updated.Diags, "Unmanaged records are removed"); d != nil {
+		t.Errorf("plan keeping every record warned: %s", d.Detail)
+	}
+	config = srvSetConfig(domain, "sip1.example.com")
+	_, diags = p.tryPlan("netcupdns_srv_set", updated.State, updated.Private, config)
+	if d, _ := unmanagedRecords(t, diags, "Unmanaged records are removed"); d != nil {
+		t.Errorf("plan removing managed records warned: %s", d.Detail)
+	}
+}
+
+// Long lists of unmanaged records are capped
+func TestSrvSetUnmanagedRecordsCapped(t *testing.T) {
+	domain := testDomain(t)
+	config := srvSetConfig(domain, "sip1.example.com")
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_srv_set", nullState(p, "netcupdns_srv_set"), nil, config)
+	p.close()
+
+	for i := 0; i < maxListedUnmanagedRecords+3; i++ {
+		createSrvRecord(t, domain, "_sip._udp", "20", fmt.Sprintf("0 5060 stray%d.example.com.", i))
+	}
+
+	p = newTestProvider(t, nil)
+	refreshed, private, diags := p.refresh("netcupdns_srv_set", created.State, created.Private)
+	p.checkDiags("refresh", diags)
+	_, diags = p.tryPlan("netcupdns_srv_set", refreshed, private, config)
+	d, ids := unmanagedRecords(t, diags, "Unmanaged records are removed")
+	if d == nil {
+		t.Fatalf("got warnings %v, want the unmanaged records", summaries(diags, tfprotov6.DiagnosticSeverityWarning))
+	}
+	if len(ids) != maxListedUnmanagedRecords || !strings.Contains(d.Detail, "... and 3 more") || !strings.Contains(d.Detail, fmt.Sprintf("removes %d SRV records", maxListedUnmanagedRecords+3)) {
+		t.Errorf("listed %d records, want %d and the number of the others:\n%s", len(ids), maxListedUnmanagedRecords, d.Detail)
+	}
+}
+
+// Records found on import belong to the set, and unchanged sets warn about nothing
+func TestSrvSetImportedRecordsManaged(t *testing.T) {
+	domain := testDomain(t)
+	createSrvRecord(t, domain, "_sip._udp", "10", "10 5060 sip1.example.com.")
+	createSrvRecord(t, domain, "_sip._udp", "20", "0 5060 sip2.example.com.")
+
+	p := newTestProvider(t, nil)
+	resp, err := p.server.ImportResourceState(context.Background(), &tfprotov6.ImportResourceStateRequest{TypeName: "netcupdns_srv_set", ID: domain + "/sip/udp"})
+	if err != nil {
+		t.Fatalf("ImportResourceState failed: %s", err)
+	}
+	if len(resp.ImportedResources) != 1 {
+		t.Fatalf("import got errors %v", summaries(resp.Diagnostics, tfprotov6.DiagnosticSeverityError))
+	}
+	imported, private, diags := p.refresh("netcupdns_srv_set", p.decode(p.resourceSchema("netcupdns_srv_set"), resp.ImportedResources[0].State), resp.ImportedResources[0].Private)
+	p.checkDiags("refresh after import", diags)
+	if !strings.Contains(string(private), srvManagedIdsKey) {
+		t.Errorf("import didn't keep the ids of the records in the private state %s", private)
+	}
+
+	_, diags = p.tryPlan("netcupdns_srv_set", imported, private, srvSetConfig(domain, "sip1.example.com"))
+	if d, _ := unmanagedRecords(t, diags, "Unmanaged records are removed"); d != nil {
+		t.Errorf("plan removing an imported record warned: %s", d.Detail)
+	}
+
+	// without refresh the stray isn't in the state, and nothing is written
+	stray := createSrvRecord(t, domain, "_sip._udp", "30", "0 5060 sip3.example.com.")
+	_, diags = p.tryPlan("netcupdns_srv_set", imported, private, attrs{
+		"domainname": domain, "service": "sip", "protocol": "udp",
+		"targets": []interface{}{
+			map[string]interface{}{"priority": 10, "weight": 10, "port": 5060, "target": "sip1.example.com."},
+			map[string]interface{}{"priority": 20, "weight": 0, "port": 5060, "target": "sip2.example.com."},
+		},
+	})
+	if d, _ := unmanagedRecords(t, diags, "Unmanaged records are removed"); d != nil {
+		t.Errorf("plan without changes warned about %s: %s", stray, d.Detail)
+	}
+}
+
+func srvTarget(priority, weight, port int, target string) map[string]interface{} {
+	return map[string]interface{}{"priority": priority, "weight": weight, "port": port, "target": target}
+}
+
+// Additions, removals and weight changes are written as one set, leaving
+// records at other names and of other types alone
+func TestSrvSetReconcile(t *testing.T) {
+	domain := testDomain(t)
+	seedZone(t, domain,
+		attrs{"hostname": "_sip._udp", "type": "TXT", "destination": "not an SRV record"},
+		attrs{"hostname": "_xmpp-client._tcp", "type": "SRV", "priority": "5", "destination": "0 5222 xmpp.example.com."},
+	)
+	config := attrs{"domainname": domain, "service": "sip", "protocol": "udp", "targets": []interface{}{
+		srvTarget(10, 60, 5060, "sip1.example.com"),
+		srvTarget(10, 40, 5060, "sip2.example.com."),
+	}}
+
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_srv_set", nullState(p, "netcupdns_srv_set"), nil, config)
+	ids := map[string]string{}
+	for _, record := range elementsOf(t, attrValue(t, created.State, "records")) {
+		ids[attrString(t, record, "destination")] = attrString(t, record, "id")
+	}
+	if len(ids) != 2 {
+		t.Fatalf("created records %v, want 2", ids)
+	}
+
+	config["targets"] = []interface{}{
+		srvTarget(10, 80, 5061, "sip1.example.com"),
+		srvTarget(20, 0, 5060, "sip3.example.com"),
+	}
+	p = newTestProvider(t, nil)
+	updated := p.apply("netcupdns_srv_set", created.State, created.Private, config)
+	want := []string{
+		"_sip._udp SRV 10 80 5061 sip1.example.com.",
+		"_sip._udp SRV 20 0 5060 sip3.example.com.",
+		"_sip._udp TXT 0 not an SRV record",
+		"_xmpp-client._tcp SRV 5 0 5222 xmpp.example.com.",
+	}
+	if got := zoneRecords(t, domain); !reflect.DeepEqual(got, want) {
+		t.Errorf("zone after update:\n%q\nwant\n%q", got, want)
+	}
+	for _, record := range elementsOf(t, attrValue(t, updated.State, "records")) {
+		if attrString(t, record, "destination") == "80 5061 sip1.example.com." && attrString(t, record, "id") != ids["60 5060 sip1.example.com."] {
+			t.Errorf("changed weight and port replaced record %s by %s", ids["60 5060 sip1.example.com."], attrString(t, record, "id"))
+		}
+	}
+
+	p = newTestProvider(t, nil)
+	refreshed, private, diags := p.refresh("netcupdns_srv_set", updated.State, updated.Private)
+	p.checkDiags("refresh", diags)
+	if planned := p.plan("netcupdns_srv_set", refreshed, private, config); !planned.Equal(refreshed) {
+		t.Errorf("plan after update isn't empty:\nplanned %s\nstate   %s", planned, refreshed)
+	}
+
+	p.apply("netcupdns_srv_set", refreshed, private, nil)
+	want = []string{
+		"_sip._udp TXT 0 not an SRV record",
+		"_xmpp-client._tcp SRV 5 0 5222 xmpp.example.com.",
+	}
+	if got := zoneRecords(t, domain); !reflect.DeepEqual(got, want) {
+		t.Errorf("zone after destroy:\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSrvSetValidation(t *testing.T) {
+	p := newTestProvider(t, nil)
+	tests := []struct {
+		name    string
+		changes attrs
+		summary string
+	}{
+		{"duplicate target", attrs{"targets": []interface{}{srvTarget(10, 10, 5060, "sip.example.com"), srvTarget(10, 20, 5061, "SIP.example.com.")}}, "Duplicate target"},
+		{"priority too large", attrs{"targets": []interface{}{srvTarget(65536, 10, 5060, "sip.example.com")}}, "Invalid priority"},
+		{"negative weight", attrs{"targets": []interface{}{srvTarget(10, -1, 5060, "sip.example.com")}}, "Invalid weight"},
+		{"port too large", attrs{"targets": []interface{}{srvTarget(10, 10, 70000, "sip.example.com")}}, "Invalid port"},
+		{"empty target", attrs{"targets": []interface{}{srvTarget(10, 10, 5060, " ")}}, "Invalid target"},
+		{"no targets", attrs{"targets": []interface{}{}}, "No targets"},
+		{"invalid service", attrs{"service": "sip.udp"}, "Invalid service"},
+		{"invalid protocol", attrs{"protocol": "u dp"}, "Invalid protocol"},
+	}
+	for _, tt := range tests {
+		config := srvSetConfig("example.com", "sip.example.com")
+		for name, value := range tt.changes {
+			config[name] = value
+		}
+		diags := p.validate("netcupdns_srv_set", config)
+		if got := summaries(diags, tfprotov6.DiagnosticSeverityError); len(got) != 1 || got[0] != tt.summary {
+			t.Errorf("%s: got errors %q, want %s", tt.name, got, tt.summary)
+		}
+	}
+
+	// the same target may occur with different priorities, and the range is inclusive
+	config := srvSetConfig("example.com")
+	config["targets"] = []interface{}{srvTarget(0, 0, 0, "sip.example.com"), srvTarget(65535, 65535, 65535, "sip.example.com")}
+	p.checkDiags("validation of valid targets", p.validate("netcupdns_srv_set", config))
+}
+
+// Importing by domain/service/protocol/name reads the SRV records at that owner name
+func TestSrvSetImportWithName(t *testing.T) {
+	domain := testDomain(t)
+	createSrvRecord(t, domain, "_sip._tcp.office", "10", "60 5060 sip1.example.com.")
+	createSrvRecord(t, domain, "_sip._tcp.office", "20", "40 5061 sip2.example.com.")
+	createSrvRecord(t, domain, "_sip._tcp", "10", "0 5060 other.example.com.")
+
+	p := newTestProvider(t, nil)
+	imported, diags := p.importState("netcupdns_srv_set", domain+"/sip/tcp/office")
+	p.checkDiags("import", diags)
+	if n := len(elementsOf(t, attrValue(t, imported, "records"))); n != 2 {
+		t.Fatalf("imported %d records, want the 2 at _sip._tcp.office", n)
+	}
+
+	config := attrs{"domainname": domain, "service": "sip", "protocol": "tcp", "name": "office", "targets": []interface{}{
+		srvTarget(10, 60, 5060, "sip1.example.com."),
+		srvTarget(20, 40, 5061, "sip2.example.com."),
+	}}
+	if planned := p.plan("netcupdns_srv_set", imported, nil, config); !planned.Equal(imported) {
+		t.Errorf("plan after import isn't empty:\nplanned %s\nstate   %s", planned, imported)
+	}
+
+	for _, id := range []string{domain + "/sip", domain + "/sip/tcp/office/extra", domain + "//tcp"} {
+		_, diags := p.importState("netcupdns_srv_set", id)
+		if d := firstError(diags); d == nil || d.Summary != "Invalid import id" {
+			t.Errorf("import %q: got errors %v, want Invalid import id", id, summaries(diags, tfprotov6.DiagnosticSeverityError))
+		}
+	}
+}
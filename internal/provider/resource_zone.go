@@ -0,0 +1,497 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var (
+	_ resource.Resource                   = &zoneResource{}
+	_ resource.ResourceWithConfigure      = &zoneResource{}
+	_ resource.ResourceWithImportState    = &zoneResource{}
+	_ resource.ResourceWithModifyPlan     = &zoneResource{}
+	_ resource.ResourceWithValidateConfig = &zoneResource{}
+)
+
+func NewZoneResource() resource.Resource {
+	return &zoneResource{}
+}
+
+type zoneResource struct {
+	client *client.CCPClient
+}
+
+func (r *zoneResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_zone"
+}
+
+func (r *zoneResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	useStateForUnknown := []planmodifier.Int64{int64planmodifier.UseStateForUnknown()}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the TTL and SOA settings of an existing DNS-Zone. See [Netcup-API](https://ccp.netcup.net/run/webservice/servers/endpoint.php#Dnszone). " +
+			"Zones can't be created or deleted with the API: the zone must exist, settings that aren't configured keep their current value, " +
+			"and destroying the resource only removes it from the state.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Domainname of the zone.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"domainname": schema.StringAttribute{
+				Required:    true,
+				Description: "Domainname of the zone.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"ttl": schema.Int64Attribute{
+				Optional:      true,
+				Computed:      true,
+				Description:   "Default TTL of the records of the zone in seconds, e.g. 300 for dynamic DNS. Values below 60 seconds show a warning.",
+				PlanModifiers: useStateForUnknown,
+			},
+			"refresh": schema.Int64Attribute{
+				Optional:      true,
+				Computed:      true,
+				Description:   "Refresh interval of the zone in seconds. Must be longer than retry.",
+				PlanModifiers: useStateForUnknown,
+			},
+			"retry": schema.Int64Attribute{
+				Optional:      true,
+				Computed:      true,
+				Description:   "Retry interval of the zone in seconds. Must be shorter than refresh.",
+				PlanModifiers: useStateForUnknown,
+			},
+			"expire": schema.Int64Attribute{
+				Optional:      true,
+				Computed:      true,
+				Description:   "Expire time of the zone in seconds. Must be longer than refresh; values above 4 weeks show a warning.",
+				PlanModifiers: useStateForUnknown,
+			},
+			"dnssec": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				MarkdownDescription: "Whether DNSSEC is enabled for the zone. When not configured the zone keeps its DNSSEC status. " +
+					"Conflicts with a `netcupdns_dnssec` resource of the same domain, only one of them may manage it.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *zoneResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config Zone
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, field := range zoneFields(&config) {
+		if field.value.IsNull() || field.value.IsUnknown() || field.value.ValueInt64() > 0 {
+			continue
+		}
+		resp.Diagnostics.AddAttributeError(
+			path.Root(field.name),
+			"Invalid "+field.name,
+			"The "+field.name+" of a zone must be a positive number of seconds, got "+strconv.FormatInt(field.value.ValueInt64(), 10),
+		)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateZoneTimers(config, &resp.Diagnostics)
+}
+
+const (
+	// RFC 2181 limits TTLs and SOA timers to 31 bit
+	maxZoneSeconds = 1<<31 - 1
+	// resolvers often raise lower TTLs, and the zone is queried very often
+	lowZoneTTL = 60
+	// RFC 1912 recommends an expire time of 2 to 4 weeks
+	highZoneExpire = 4 * 7 * 24 * 60 * 60
+)
+
+// Check the relationship of the configured SOA timers: secondaries refresh the
+// zone every refresh seconds, retry a failed refresh after retry seconds and
+// stop serving it after expire seconds without a successful refresh. Settings
+// that aren't configured keep the current value of the zone and aren't checked.
+func validateZoneTimers(config Zone, diags *diag.Diagnostics) {
+	known := func(value types.Int64) bool {
+		return !value.IsNull() && !value.IsUnknown()
+	}
+
+	for _, field := range zoneFields(&config) {
+		if known(*field.value) && field.value.ValueInt64() > maxZoneSeconds {
+			diags.AddAttributeError(
+				path.Root(field.name),
+				"Invalid "+field.name,
+				fmt.Sprintf("The %s of a zone must be at most %d seconds, the largest value DNS allows, got %d", field.name, maxZoneSeconds, field.value.ValueInt64()),
+			)
+		}
+	}
+	if diags.HasError() {
+		return
+	}
+
+	// retry < refresh < expire
+	if known(config.Retry) && known(config.Refresh) && config.Retry.ValueInt64() >= config.Refresh.ValueInt64() {
+		diags.AddAttributeError(
+			path.Root("retry"),
+			"Invalid retry",
+			fmt.Sprintf("The retry interval (%d) must be shorter than the refresh interval (%d). "+
+				"Secondaries retry a failed refresh after the retry interval, which shouldn't take longer than a regular refresh.",
+				config.Retry.ValueInt64(), config.Refresh.ValueInt64()),
+		)
+	}
+	if known(config.Refresh) && known(config.Expire) && config.Refresh.ValueInt64() >= config.Expire.ValueInt64() {
+		diags.AddAttributeError(
+			path.Root("expire"),
+			"Invalid expire",
+			fmt.Sprintf("The expire time (%d) must be longer than the refresh interval (%d). "+
+				"Secondaries stop serving the zone when it couldn't be refreshed within the expire time, so they would expire it before the next refresh.",
+				config.Expire.ValueInt64(), config.Refresh.ValueInt64()),
+		)
+	}
+	if !known(config.Refresh) && known(config.Retry) && known(config.Expire) && config.Retry.ValueInt64() >= config.Expire.ValueInt64() {
+		diags.AddAttributeError(
+			path.Root("expire"),
+			"Invalid expire",
+			fmt.Sprintf("The expire time (%d) must be longer than the retry interval (%d). "+
+				"Secondaries stop serving the zone when it couldn't be refreshed within the expire time, so they would expire it before retrying a failed refresh.",
+				config.Expire.ValueInt64(), config.Retry.ValueInt64()),
+		)
+	}
+
+	if known(config.TTL) && config.TTL.ValueInt64() < lowZoneTTL {
+		diags.AddAttributeWarning(
+			path.Root("ttl"),
+			"Low TTL",
+			fmt.Sprintf("A TTL of %d seconds is unusually low. Many resolvers use at least %d seconds instead, "+
+				"and every lookup of a record that isn't cached is sent to the name servers of Netcup.", config.TTL.ValueInt64(), lowZoneTTL),
+		)
+	}
+	if known(config.Expire) && config.Expire.ValueInt64() > highZoneExpire {
+		diags.AddAttributeWarning(
+			path.Root("expire"),
+			"High expire",
+			fmt.Sprintf("An expire time of %d seconds is unusually high. Secondaries keep serving an outdated zone for that long "+
+				"when they can't reach the primary; RFC 1912 recommends 2 to 4 weeks (1209600 to %d seconds).", config.Expire.ValueInt64(), highZoneExpire),
+		)
+	}
+}
+
+func (r *zoneResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.client = req.ProviderData.(*client.CCPClient)
+}
+
+// Settings of a zone with the attribute they belong to
+type zoneField struct {
+	name    string
+	value   *types.Int64
+	setting *string
+}
+
+func zoneFields(zone *Zone) []zoneField {
+	return []zoneField{
+		{name: "ttl", value: &zone.TTL},
+		{name: "refresh", value: &zone.Refresh},
+		{name: "retry", value: &zone.Retry},
+		{name: "expire", value: &zone.Expire},
+	}
+}
+
+func zoneSettingFields(zone *Zone, settings *client.DnsZone) []zoneField {
+	fields := zoneFields(zone)
+	for i, setting := range []*string{&settings.TTL, &settings.Refresh, &settings.Retry, &settings.Expire} {
+		fields[i].setting = setting
+	}
+	return fields
+}
+
+// State of a zone from its settings in the API
+func zoneState(domainname string, settings client.DnsZone, diags *diag.Diagnostics) Zone {
+	state := Zone{
+		ID:         types.StringValue(domainname),
+		Domainname: types.StringValue(domainname),
+		Dnssec:     types.BoolValue(settings.DNSSecStatus),
+	}
+	for _, field := range zoneSettingFields(&state, &settings) {
+		n, err := strconv.ParseInt(*field.setting, 10, 64)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root(field.name),
+				"Unexpected zone value",
+				"Could not parse "+field.name+" value "+strconv.Quote(*field.setting)+" returned by the API: "+err.Error(),
+			)
+			continue
+		}
+		*field.value = types.Int64Value(n)
+	}
+	return state
+}
+
+// Write the configured settings of the plan, keeping the current value of the others.
+// The DNSSEC status is only written if dnssec is configured.
+func (r zoneResource) apply(ctx context.Context, plan Zone, dnssec types.Bool, diags *diag.Diagnostics) (state Zone) {
+	domainname := plan.Domainname.ValueString()
+
+	current, err := r.client.RefreshDnsZone(ctx, domainname)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading zone",
+			"Could not read zone "+domainname+", it must exist in the CCP: "+err.Error(),
+		)
+		return plan
+	}
+
+	settings := *current
+	changed := false
+	for _, field := range zoneSettingFields(&plan, &settings) {
+		if field.value.IsNull() || field.value.IsUnknown() {
+			continue
+		}
+		value := strconv.FormatInt(field.value.ValueInt64(), 10)
+		changed = changed || *field.setting != value
+		*field.setting = value
+	}
+	if dnssec.IsNull() && !plan.Dnssec.IsUnknown() {
+		// keep the planned prior value, a netcupdns_dnssec resource may change the status during the same apply
+		defer func() { state.Dnssec = plan.Dnssec }()
+	} else if !dnssec.IsNull() && !dnssec.IsUnknown() {
+		changed = changed || settings.DNSSecStatus != dnssec.ValueBool()
+		settings.DNSSecStatus = dnssec.ValueBool()
+	}
+	if !changed {
+		tflog.Trace(ctx, "DNS Zone unchanged", map[string]interface{}{"domainname": domainname})
+		return zoneState(domainname, settings, diags)
+	}
+
+	tflog.Trace(ctx, "Updating DNS Zone", map[string]interface{}{
+		"domainname": domainname, "ttl": settings.TTL, "refresh": settings.Refresh, "retry": settings.Retry, "expire": settings.Expire,
+		"dnssec": settings.DNSSecStatus,
+	})
+	updated, err := r.client.UpdateDnsZone(ctx, domainname, settings)
+	if err != nil {
+		if addRateLimitError(diags, "Netcup throttled the request to update zone "+domainname+".", err) ||
+			addZoneLockedError(diags, "Netcup rejected the request to update zone "+domainname+".", err) {
+			return plan
+		}
+		diags.AddError(
+			"Error updating zone",
+			"Could not update the settings of zone "+domainname+": "+err.Error(),
+		)
+		return plan
+	}
+	return zoneState(domainname, *updated, diags)
+}
+
+// Detect a netcupdns_dnssec resource managing the DNSSEC status of the same domain
+func (r *zoneResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to claim on destroy, or before the provider is configured
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var config Zone
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() || config.Dnssec.IsNull() {
+		return
+	}
+	claimDnssec(r.client, config.Domainname, "netcupdns_zone", &resp.Diagnostics)
+}
+
+// Create resource. Zones can't be created with the API, so the settings of the existing zone are updated.
+func (r zoneResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
+	var plan Zone
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var dnssec types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("dnssec"), &dnssec)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := r.apply(ctx, plan, dnssec, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Domainname = plan.Domainname
+
+	resp.Diagnostics.Append(forceNextRead(ctx, resp.Private)...)
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read resource information
+func (r zoneResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "refresh", &resp.Diagnostics) {
+		return
+	}
+
+	var state Zone
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Keep the prior state, unless it is incomplete like right after import
+	if skipRefresh(ctx, r.client, req.Private, &resp.Diagnostics) && !state.TTL.IsNull() {
+		tflog.Trace(ctx, "Skipping refresh of DNS Zone", map[string]interface{}{"domainname": state.Domainname.ValueString()})
+		return
+	}
+
+	domainname := state.Domainname.ValueString()
+	zone, err := r.client.GetDnsZone(ctx, domainname)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("domainname"),
+			"Error reading zone",
+			"Could not read zone "+domainname+": "+err.Error(),
+		)
+		return
+	}
+
+	tflog.Trace(ctx, "Got DNS Zone", map[string]interface{}{"domainname": zone.Name, "serial": zone.Serial})
+
+	current := zoneState(domainname, *zone, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	current.Domainname = state.Domainname
+
+	if !state.TTL.IsNull() {
+		var drift []driftedValue
+		currentFields := zoneFields(&current)
+		for i, field := range zoneFields(&state) {
+			before, after := field.value.String(), currentFields[i].value.String()
+			drift = appendDrift(drift, field.name, before, after, before == after)
+		}
+		warnDrift(ctx, r.client, req.Private, "The zone "+domainname, drift, &resp.Diagnostics)
+	}
+
+	resp.Diagnostics.Append(clearForceRead(ctx, resp.Private)...)
+
+	diags = resp.State.Set(ctx, &current)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update resource
+func (r zoneResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	if addNotConfiguredError(r.client, "apply", &resp.Diagnostics) {
+		return
+	}
+
+	var plan Zone
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var dnssec types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("dnssec"), &dnssec)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := r.apply(ctx, plan, dnssec, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Domainname = plan.Domainname
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete resource. Zones can't be deleted with the API, so the zone and its settings are left as they are.
+func (r zoneResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state Zone
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "Removing DNS Zone from state only, zones can't be deleted with the API", map[string]interface{}{"domainname": state.Domainname.ValueString()})
+}
+
+// Import a zone by its domainname. The settings are read right away, so a
+// config matching the zone plans no changes and a missing zone fails the import.
+func (r zoneResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	defer warnNearRateLimit(r.client, &resp.Diagnostics)
+
+	domainname := strings.ToLower(strings.TrimSuffix(req.ID, "."))
+	if domainname == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import id",
+			"Expected the domainname of the zone like example.com, got \""+req.ID+"\"",
+		)
+		return
+	}
+	if addNotConfiguredError(r.client, "import", &resp.Diagnostics) {
+		return
+	}
+
+	zone, err := r.client.RefreshDnsZone(ctx, domainname)
+	if err != nil {
+		if addRateLimitError(&resp.Diagnostics, "Netcup throttled the request to read zone "+domainname+".", err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Cannot import zone",
+			"Could not read the zone "+domainname+". The domain must belong to the account the provider is configured with "+
+				"and use the DNS of Netcup: "+err.Error(),
+		)
+		return
+	}
+
+	state := zoneState(domainname, *zone, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	resp.Diagnostics.Append(forceNextRead(ctx, resp.Private)...)
+}
@@ -0,0 +1,266 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+// Diagnostic expected by a validation test: summary and attribute
+type expectedDiag struct {
+	summary   string
+	attribute string
+}
+
+func diagsOf(diags []*tfprotov6.Diagnostic, severity tfprotov6.DiagnosticSeverity) []expectedDiag {
+	var result []expectedDiag
+	for _, d := range diags {
+		if d.Severity != severity {
+			continue
+		}
+		attribute := ""
+		if d.Attribute != nil {
+			attribute = d.Attribute.String()
+		}
+		result = append(result, expectedDiag{summary: d.Summary, attribute: attribute})
+	}
+	return result
+}
+
+func equalDiags(got, want []expectedDiag) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func atAttribute(summary, name string) expectedDiag {
+	return expectedDiag{summary: summary, attribute: tftypes.NewAttributePath().WithAttributeName(name).String()}
+}
+
+func TestZoneValidateTimers(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   attrs
+		errors   []expectedDiag
+		warnings []expectedDiag
+	}{
+		{
+			name:   "defaults of Netcup",
+			config: attrs{"ttl": 86400, "refresh": 28800, "retry": 7200, "expire": 1209600},
+		},
+		{
+			name:   "nothing configured",
+			config: attrs{},
+		},
+		{
+			name:   "retry equal to refresh",
+			config: attrs{"refresh": 3600, "retry": 3600},
+			errors: []expectedDiag{atAttribute("Invalid retry", "retry")},
+		},
+		{
+			name:   "retry longer than refresh",
+			config: attrs{"refresh": 3600, "retry": 7200, "expire": 1209600},
+			errors: []expectedDiag{atAttribute("Invalid retry", "retry")},
+		},
+		{
+			name:   "expire shorter than refresh",
+			config: attrs{"refresh": 28800, "retry": 7200, "expire": 3600},
+			errors: []expectedDiag{atAttribute("Invalid expire", "expire")},
+		},
+		{
+			name:   "expire shorter than retry without refresh",
+			config: attrs{"retry": 7200, "expire": 3600},
+			errors: []expectedDiag{atAttribute("Invalid expire", "expire")},
+		},
+		{
+			name:   "every relationship violated",
+			config: attrs{"refresh": 3600, "retry": 7200, "expire": 600},
+			errors: []expectedDiag{atAttribute("Invalid retry", "retry"), atAttribute("Invalid expire", "expire")},
+		},
+		{
+			name:   "retry alone",
+			config: attrs{"retry": 86400},
+		},
+		{
+			name:   "not positive",
+			config: attrs{"ttl": 0},
+			errors: []expectedDiag{atAttribute("Invalid ttl", "ttl")},
+		},
+		{
+			name:   "above the DNS limit",
+			config: attrs{"ttl": 1 << 31},
+			errors: []expectedDiag{atAttribute("Invalid ttl", "ttl")},
+		},
+		{
+			name:     "low TTL",
+			config:   attrs{"ttl": 30},
+			warnings: []expectedDiag{atAttribute("Low TTL", "ttl")},
+		},
+		{
+			name:   "TTL of 60 seconds",
+			config: attrs{"ttl": 60},
+		},
+		{
+			name:     "high expire",
+			config:   attrs{"expire": 2 * highZoneExpire},
+			warnings: []expectedDiag{atAttribute("High expire", "expire")},
+		},
+		{
+			name:   "expire of 4 weeks",
+			config: attrs{"expire": highZoneExpire},
+		},
+		{
+			name:     "warnings with errors",
+			config:   attrs{"ttl": 30, "refresh": 3600, "retry": 3600},
+			errors:   []expectedDiag{atAttribute("Invalid retry", "retry")},
+			warnings: []expectedDiag{atAttribute("Low TTL", "ttl")},
+		},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		config := attrs{"domainname": "example.com"}
+		for name, value := range tt.config {
+			config[name] = value
+		}
+		diags := p.validate("netcupdns_zone", config)
+		if got := diagsOf(diags, tfprotov6.DiagnosticSeverityError); !equalDiags(got, tt.errors) {
+			t.Errorf("%s: got errors %v, want %v", tt.name, got, tt.errors)
+		}
+		if got := diagsOf(diags, tfprotov6.DiagnosticSeverityWarning); !equalDiags(got, tt.warnings) {
+			t.Errorf("%s: got warnings %v, want %v", tt.name, got, tt.warnings)
+		}
+	}
+}
+
+// An imported zone has every setting, so a config matching the zone plans no changes
+func TestZoneImportThenPlan(t *testing.T) {
+	domain := testDomain(t)
+	config := attrs{"domainname": domain, "ttl": 300, "refresh": 3600, "retry": 600, "expire": 604800}
+
+	// settings changed before, e.g. in the customer control panel
+	p := newTestProvider(t, nil)
+	p.apply("netcupdns_zone", nullState(p, "netcupdns_zone"), nil, config)
+
+	p = newTestProvider(t, nil)
+	imported, diags := p.importState("netcupdns_zone", domain+".")
+	p.checkDiags("import", diags)
+	for name, want := range map[string]string{"id": domain, "domainname": domain, "ttl": "300", "refresh": "3600", "retry": "600", "expire": "604800"} {
+		if got := attrString(t, imported, name); got != want {
+			t.Errorf("imported %s = %s, want %s", name, got, want)
+		}
+	}
+
+	planned := p.plan("netcupdns_zone", imported, nil, config)
+	if !planned.Equal(imported) {
+		t.Errorf("plan after import isn't empty:\nplanned %s\nstate   %s", planned, imported)
+	}
+	// settings that aren't configured keep their imported value
+	planned = p.plan("netcupdns_zone", imported, nil, attrs{"domainname": domain})
+	if !planned.Equal(imported) {
+		t.Errorf("plan without settings after import isn't empty:\nplanned %s\nstate   %s", planned, imported)
+	}
+}
+
+func TestZoneImportErrors(t *testing.T) {
+	tests := []struct {
+		id      string
+		summary string
+	}{
+		{"", "Invalid import id"},
+		{"missing.invalid", "Cannot import zone"},
+	}
+
+	p := newTestProvider(t, nil)
+	for _, tt := range tests {
+		_, diags := p.importState("netcupdns_zone", tt.id)
+		if d := firstError(diags); d == nil || d.Summary != tt.summary {
+			t.Errorf("import of %q got errors %v, want %s", tt.id, summaries(diags, tfprotov6.DiagnosticSeverityError), tt.summary)
+		}
+	}
+}
+
+// Settings of a zone of the mock, read past the caches
+func zoneSettings(t *testing.T, domain string) client.DnsZone {
+	t.Helper()
+	zone, err := mockClient(t).RefreshDnsZone(context.Background(), domain)
+	if err != nil {
+		t.Fatalf("RefreshDnsZone failed: %s", err)
+	}
+	return *zone
+}
+
+func TestZoneLifecycle(t *testing.T) {
+	domain := testDomain(t)
+	initial := zoneSettings(t, domain)
+
+	// settings that aren't configured keep their value
+	config := attrs{"domainname": domain, "ttl": 300}
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_zone", nullState(p, "netcupdns_zone"), nil, config)
+	zone := zoneSettings(t, domain)
+	if zone.TTL != "300" || zone.Refresh != initial.Refresh || zone.Retry != initial.Retry || zone.Expire != initial.Expire {
+		t.Errorf("zone after create %+v, was %+v", zone, initial)
+	}
+	if got := attrString(t, created.State, "refresh"); got != initial.Refresh {
+		t.Errorf("refresh in state %s, want %s of the zone", got, initial.Refresh)
+	}
+	p.close()
+
+	p = newTestProvider(t, nil)
+	refreshed, private, diags := p.refresh("netcupdns_zone", created.State, created.Private)
+	p.checkDiags("refresh", diags)
+	if planned := p.plan("netcupdns_zone", refreshed, private, config); !planned.Equal(refreshed) {
+		t.Errorf("plan after create isn't empty:\nplanned %s\nstate   %s", planned, refreshed)
+	}
+
+	var output bytes.Buffer
+	p.captureLogs(&output)
+	config["refresh"] = 7200
+	config["retry"] = 1800
+	updated := p.apply("netcupdns_zone", refreshed, private, config)
+	if zone := zoneSettings(t, domain); zone.TTL != "300" || zone.Refresh != "7200" || zone.Retry != "1800" {
+		t.Errorf("zone after update %+v", zone)
+	}
+	if n := loggedRequests(t, &output, "updateDnsZone"); n != 1 {
+		t.Errorf("update sent %d updateDnsZone requests, want 1", n)
+	}
+
+	// an apply of the unchanged config writes nothing
+	output.Reset()
+	p.apply("netcupdns_zone", updated.State, updated.Private, config)
+	if n := loggedRequests(t, &output, "updateDnsZone"); n != 0 {
+		t.Errorf("apply without changes sent %d updateDnsZone requests", n)
+	}
+
+	other := maps.Clone(config)
+	other["domainname"] = "other-" + domain
+	replaced := p.planReplacements("netcupdns_zone", updated.State, updated.Private, other)
+	if len(replaced) != 1 || !replaced[0].Equal(tftypes.NewAttributePath().WithAttributeName("domainname")) {
+		t.Errorf("changed domainname replaces %v, want the resource", replaced)
+	}
+
+	// zones can't be deleted, destroy only forgets the resource
+	output.Reset()
+	destroyed := p.apply("netcupdns_zone", updated.State, updated.Private, nil)
+	if !destroyed.State.IsNull() {
+		t.Errorf("state after destroy %s", destroyed.State)
+	}
+	if n := loggedRequests(t, &output, "updateDnsZone"); n != 0 {
+		t.Errorf("destroy sent %d updateDnsZone requests", n)
+	}
+	if zone := zoneSettings(t, domain); zone.TTL != "300" || zone.Refresh != "7200" {
+		t.Errorf("zone after destroy %+v, want the settings kept", zone)
+	}
+}
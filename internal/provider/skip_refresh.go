@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+// Private state key of resources that must be read from the API on their next
+// refresh even with skip_refresh, set on create and import
+const forceReadKey = "force_read"
+
+type privateGetter interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+type privateSetter interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// Require a real read on the next refresh
+func forceNextRead(ctx context.Context, private privateSetter) diag.Diagnostics {
+	return private.SetKey(ctx, forceReadKey, []byte("true"))
+}
+
+// Mark the resource as read from the API
+func clearForceRead(ctx context.Context, private privateSetter) diag.Diagnostics {
+	return private.SetKey(ctx, forceReadKey, nil)
+}
+
+// Whether Read should keep the prior state because of skip_refresh
+func skipRefresh(ctx context.Context, c *client.CCPClient, private privateGetter, diags *diag.Diagnostics) bool {
+	if c == nil || !c.SkipRefresh() {
+		return false
+	}
+
+	force, d := private.GetKey(ctx, forceReadKey)
+	diags.Append(d...)
+	return len(force) == 0
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+// Change the destination of a record outside of Terraform
+func changeDestination(t *testing.T, domain, id, destination string) {
+	t.Helper()
+	c := mockClient(t)
+	defer c.Logout(context.Background())
+
+	record, err := c.GetDnsRecordById(context.Background(), domain, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Destination = destination
+	if _, err := c.UpdateDnsRecord(context.Background(), domain, *record); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSkipRefresh(t *testing.T) {
+	domain := testDomain(t)
+	skip := attrs{"skip_refresh": true}
+
+	// a provider of its own for each step, like separate Terraform runs
+	p := newTestProvider(t, skip)
+	created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, attrs{"domainname": domain, "hostname": "www", "type": "A", "destination": "192.0.2.1"})
+	id := attrString(t, created.State, "id")
+	p.close()
+
+	// the first refresh after create reads the API
+	changeDestination(t, domain, id, "192.0.2.2")
+	p = newTestProvider(t, skip)
+	state, private, diags := p.refresh("netcupdns_record", created.State, created.Private)
+	p.checkDiags("refresh after create", diags)
+	if got := attrString(t, state, "destination"); got != "192.0.2.2" {
+		t.Errorf("refresh after create read destination %s, want the changed 192.0.2.2", got)
+	}
+	p.close()
+
+	// later refreshes keep the prior state
+	changeDestination(t, domain, id, "192.0.2.3")
+	p = newTestProvider(t, skip)
+	skipped, skippedPrivate, diags := p.refresh("netcupdns_record", state, private)
+	p.checkDiags("skipped refresh", diags)
+	if !skipped.Equal(state) {
+		t.Errorf("skipped refresh changed the state:\ngot  %s\nwant %s", skipped, state)
+	}
+	p.close()
+
+	// skip_refresh = false, as for terraform plan -refresh-only, reads the API again
+	p = newTestProvider(t, nil)
+	refreshed, diags := p.read("netcupdns_record", skipped, skippedPrivate)
+	p.checkDiags("refresh-only", diags)
+	if got := attrString(t, refreshed, "destination"); got != "192.0.2.3" {
+		t.Errorf("refresh without skip_refresh read destination %s, want 192.0.2.3", got)
+	}
+	p.close()
+
+	// imports always read the API
+	p = newTestProvider(t, skip)
+	imported, diags := p.importState("netcupdns_record", domain+"/"+id)
+	p.checkDiags("import", diags)
+	if got := attrString(t, imported, "destination"); got != "192.0.2.3" {
+		t.Errorf("import read destination %s, want 192.0.2.3", got)
+	}
+}
+
+// A record deleted outside of Terraform stays in the state until a real refresh
+func TestSkipRefreshKeepsDeletedRecord(t *testing.T) {
+	domain := testDomain(t)
+	p := newTestProvider(t, nil)
+	created := p.apply("netcupdns_record", nullState(p, "netcupdns_record"), nil, attrs{"domainname": domain, "hostname": "www", "type": "A", "destination": "192.0.2.1"})
+	state, private, diags := p.refresh("netcupdns_record", created.State, created.Private)
+	p.checkDiags("refresh after create", diags)
+	p.close()
+
+	c := mockClient(t)
+	defer c.Logout(context.Background())
+	record, err := c.GetDnsRecordById(context.Background(), domain, attrString(t, state, "id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteDnsRecord(context.Background(), domain, *record); err != nil {
+		t.Fatal(err)
+	}
+
+	p = newTestProvider(t, attrs{"skip_refresh": true})
+	if skipped, diags := p.read("netcupdns_record", state, private); hasErrors(diags) || !skipped.Equal(state) {
+		t.Errorf("skipped refresh of a deleted record returned %s, want the prior state", skipped)
+	}
+	p.close()
+
+	p = newTestProvider(t, nil)
+	if refreshed, diags := p.read("netcupdns_record", state, private); hasErrors(diags) || !refreshed.IsNull() {
+		t.Errorf("refresh of a deleted record returned %s, want it removed", refreshed)
+	}
+}
@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+// Number of resources changed at once, like terraform -parallelism
+const stressParallelism = 10
+
+// Run fn for each of n resources, at most stressParallelism of them at once
+func parallel(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, stressParallelism)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// Records of the zones of domains as "domain hostname destination" with the
+// ids they are stored as
+func stressZones(t *testing.T, p *testProvider, domains []string) map[string][]string {
+	t.Helper()
+	zones := map[string][]string{}
+	for _, domain := range domains {
+		state, diags := p.readDataSource("netcupdns_records", attrs{"domainname": domain})
+		p.checkDiags("read of zone "+domain, diags)
+		for _, record := range elementsOf(t, attrValue(t, state, "records")) {
+			key := domain + " " + attrString(t, record, "hostname") + " " + attrString(t, record, "destination")
+			zones[key] = append(zones[key], attrString(t, record, "id"))
+		}
+	}
+	return zones
+}
+
+// Every resource exists exactly once in its zone with a unique id, and the zones hold nothing else
+func checkStressZones(t *testing.T, step string, p *testProvider, domains []string, configs []attrs, states []applied) {
+	t.Helper()
+	zones := stressZones(t, p, domains)
+	ids := map[string]int{}
+	for i, config := range configs {
+		id := attrString(t, states[i].State, "id")
+		if other, ok := ids[id]; ok {
+			t.Errorf("%s: resources %d and %d have the same id %s", step, other, i, id)
+		}
+		ids[id] = i
+
+		key := fmt.Sprintf("%s %s %s", config["domainname"], config["hostname"], config["destination"])
+		if got := zones[key]; len(got) != 1 || got[0] != id {
+			t.Errorf("%s: resource %d with id %s is stored as %v", step, i, id, got)
+		}
+		delete(zones, key)
+	}
+	if len(zones) > 0 {
+		leftovers := make([]string, 0, len(zones))
+		for key := range zones {
+			leftovers = append(leftovers, key)
+		}
+		sort.Strings(leftovers)
+		t.Errorf("%s: records %q aren't managed by any resource", step, leftovers)
+	}
+}
+
+// Resources managed in parallel keep their records apart, against a mock
+// answering after random delays to widen race windows. Run with -race.
+func TestStressParallelRecords(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the stress test changes 100 records several times")
+	}
+
+	server := newLoginServer(t)
+	server.backend = client.NewMemoryBackend()
+	var mu sync.Mutex
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	server.onRequest = func(string) {
+		mu.Lock()
+		delay := time.Duration(random.Intn(2000)) * time.Microsecond
+		mu.Unlock()
+		time.Sleep(delay)
+	}
+	run := func() *testProvider {
+		p := startTestProvider(t)
+		p.checkDiags("configure", p.configure(attrs{
+			"endpoint": server.URL, "customer_number": "47171", "key": "abcdefghijklmnopqrstuvwxyz", "password": "password",
+		}))
+		return p
+	}
+
+	domains := []string{"one." + testDomain(t), "two." + testDomain(t), "three." + testDomain(t)}
+	configs := make([]attrs, 100)
+	for i := range configs {
+		// several records of a domain share a hostname, to catch the adoption of another resource's record
+		configs[i] = attrs{
+			"domainname":  domains[i%len(domains)],
+			"hostname":    fmt.Sprintf("host%d", i%7),
+			"type":        "A",
+			"destination": fmt.Sprintf("198.51.100.%d", i),
+		}
+	}
+	states := make([]applied, len(configs))
+	apply := func(step string, p *testProvider, i int, config attrs) {
+		prior, private := nullState(p, "netcupdns_record"), []byte(nil)
+		if states[i].State.Type() != nil {
+			prior, private = states[i].State, states[i].Private
+		}
+		result := p.tryApply("netcupdns_record", prior, private, config)
+		if d := firstError(result.Diags); d != nil {
+			t.Errorf("%s of resource %d: %s: %s", step, i, d.Summary, d.Detail)
+			return
+		}
+		states[i] = result
+	}
+
+	p := run()
+	parallel(len(configs), func(i int) { apply("create", p, i, configs[i]) })
+	if t.Failed() {
+		t.FailNow()
+	}
+	checkStressZones(t, "create", p, domains, configs, states)
+	p.close()
+
+	// a new run refreshes every resource and changes half of them
+	p = run()
+	parallel(len(configs), func(i int) {
+		state, private, diags := p.refresh("netcupdns_record", states[i].State, states[i].Private)
+		if d := firstError(diags); d != nil {
+			t.Errorf("refresh of resource %d: %s: %s", i, d.Summary, d.Detail)
+			return
+		}
+		states[i].State, states[i].Private = state, private
+		if i%2 == 0 {
+			configs[i]["destination"] = strings.Replace(configs[i]["destination"].(string), "198.51.100.", "203.0.113.", 1)
+			apply("update", p, i, configs[i])
+		}
+	})
+	if t.Failed() {
+		t.FailNow()
+	}
+	checkStressZones(t, "update", p, domains, configs, states)
+	p.close()
+
+	p = run()
+	parallel(len(configs), func(i int) { apply("destroy", p, i, nil) })
+	if zones := stressZones(t, p, domains); len(zones) > 0 {
+		t.Errorf("destroy left records %v", zones)
+	}
+}
@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+var sweepDomain = flag.String("sweep", "", "delete the records acceptance tests left in the given domain instead of running the tests")
+
+// Prefix of the hostnames acceptance tests create records at
+const acceptanceTestPrefix = "tf-acc-test-"
+
+// A single label of the prefix and a random suffix of at least 8 characters,
+// so names like "tf-acc-test" or "tf-acc-test-db.example" of real zones never match
+var acceptanceTestHostnamePattern = regexp.MustCompile(`^` + regexp.QuoteMeta(acceptanceTestPrefix) + `[a-z0-9]{8,}$`)
+
+// Random hostname for the records of an acceptance test
+func acceptanceTestHostname() string {
+	suffix := make([]byte, 5)
+	if _, err := rand.Read(suffix); err != nil {
+		panic(err)
+	}
+	return acceptanceTestPrefix + hex.EncodeToString(suffix)
+}
+
+// Whether a record at hostname was created by an acceptance test and may be swept
+func isAcceptanceTestHostname(hostname string) bool {
+	return acceptanceTestHostnamePattern.MatchString(strings.ToLower(hostname))
+}
+
+// Delete the records of acceptance tests in domainname, returning the deleted records
+func sweepAcceptanceRecords(ctx context.Context, c *client.CCPClient, domainname string) ([]client.DnsRecord, error) {
+	records, err := c.GetDnsRecords(ctx, domainname)
+	if err != nil {
+		return nil, err
+	}
+
+	var leftovers []client.DnsRecord
+	for _, record := range records {
+		if isAcceptanceTestHostname(record.Hostname) {
+			leftovers = append(leftovers, record)
+		}
+	}
+	return leftovers, c.DeleteDnsRecords(ctx, domainname, leftovers)
+}
+
+// With -sweep, the records of failed acceptance runs are deleted from the test
+// zone with the credentials of the environment instead of running the tests
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if *sweepDomain == "" {
+		os.Exit(m.Run())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	c, err := client.NewCCPClient(ctx, os.Getenv("NETCUP_CUSTOMER_NUMBER"), os.Getenv("NETCUP_API_KEY"), os.Getenv("NETCUP_API_PASSWORD"))
+	if err != nil {
+		log.Fatalf("[ERROR] sweeping %s: %s", *sweepDomain, err)
+	}
+	defer c.Logout(ctx)
+
+	swept, err := sweepAcceptanceRecords(ctx, c, *sweepDomain)
+	for _, record := range swept {
+		log.Printf("[INFO] swept %s %s %s", record.Hostname, record.Type, record.Destination)
+	}
+	if err != nil {
+		log.Fatalf("[ERROR] sweeping %s: %s", *sweepDomain, err)
+	}
+}
+
+func TestIsAcceptanceTestHostname(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     bool
+	}{
+		{acceptanceTestHostname(), true},
+		{"tf-acc-test-0123abcd", true},
+		{"TF-ACC-TEST-0123ABCD", true},
+		{"@", false},
+		{"www", false},
+		{"", false},
+		{"tf-acc-test", false},
+		{"tf-acc-test-", false},
+		{"tf-acc-test-db", false},
+		{"tf-acc-test-prod-db", false},
+		{"tf-acc-testing-0123abcd", false},
+		{"my-tf-acc-test-0123abcd", false},
+		{"tf-acc-test-0123abcd.www", false},
+		{"www.tf-acc-test-0123abcd", false},
+		{"tf-acc-test-0123abcd.example.com.", false},
+		{"_dmarc.tf-acc-test-0123abcd", false},
+		{"tf_acc_test_0123abcd", false},
+	}
+	for _, tt := range tests {
+		if got := isAcceptanceTestHostname(tt.hostname); got != tt.want {
+			t.Errorf("isAcceptanceTestHostname(%q) = %t, want %t", tt.hostname, got, tt.want)
+		}
+	}
+}
+
+func TestSweepAcceptanceRecords(t *testing.T) {
+	domain := testDomain(t)
+	leftover := acceptanceTestHostname()
+	seedZone(t, domain,
+		attrs{"hostname": leftover, "type": "A", "destination": "192.0.2.1"},
+		attrs{"hostname": leftover, "type": "TXT", "destination": "leftover"},
+		attrs{"hostname": "www", "type": "A", "destination": "192.0.2.2"},
+		attrs{"hostname": "tf-acc-test", "type": "A", "destination": "192.0.2.3"},
+		attrs{"hostname": "@", "type": "MX", "priority": "10", "destination": "mail.example.com"},
+	)
+
+	swept, err := sweepAcceptanceRecords(context.Background(), mockClient(t), domain)
+	if err != nil {
+		t.Fatalf("sweepAcceptanceRecords failed: %s", err)
+	}
+	if len(swept) != 2 {
+		t.Errorf("swept %v, want the two records at %s", swept, leftover)
+	}
+	want := []string{"@ MX 10 mail.example.com", "tf-acc-test A 0 192.0.2.3", "www A 0 192.0.2.2"}
+	if got := zoneRecords(t, domain); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("zone has %q after the sweep, want %q", got, want)
+	}
+
+	// nothing left to sweep
+	swept, err = sweepAcceptanceRecords(context.Background(), mockClient(t), domain)
+	if err != nil || len(swept) != 0 {
+		t.Errorf("second sweep deleted %v, %v", swept, err)
+	}
+}
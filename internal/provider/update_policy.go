@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Values of update_policy. Records with create_only are created by Terraform,
+// but their destination and priority are left to others afterwards.
+const (
+	updatePolicyAlways     = "always"
+	updatePolicyCreateOnly = "create_only"
+)
+
+func createOnly(policy types.String) bool {
+	return policy.ValueString() == updatePolicyCreateOnly
+}
+
+// Replaces a create_only record if the attribute changes, as it is never updated in place
+func createOnlyRequiresReplace() planmodifier.String {
+	return stringplanmodifier.RequiresReplaceIf(
+		func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+			var policy types.String
+			resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("update_policy"), &policy)...)
+			resp.RequiresReplace = createOnly(policy)
+		},
+		"Replaces the record if update_policy is create_only.",
+		"Replaces the record if `update_policy` is `create_only`.",
+	)
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+	"github.com/svetob/terraform-provider-netcupdns/internal/dnstypes"
+)
+
+// Render a zone in RFC 1035 master file syntax. Records are expected to be sorted.
+func renderZoneFile(domainname string, zone *client.DnsZone, records []client.DnsRecord) string {
+	origin := strings.TrimSuffix(domainname, ".") + "."
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "; Zone %s exported from the Netcup CCP API\n", origin)
+	fmt.Fprintf(&b, "; serial %s, refresh %s, retry %s, expire %s, dnssec %t\n", zone.Serial, zone.Refresh, zone.Retry, zone.Expire, zone.DNSSecStatus)
+	fmt.Fprintf(&b, "$ORIGIN %s\n", origin)
+	fmt.Fprintf(&b, "$TTL %s\n", zone.TTL)
+
+	for _, record := range records {
+		fmt.Fprintf(&b, "%s\tIN\t%s\t%s\n", record.Hostname, strings.ToUpper(record.Type), zoneFileRdata(record))
+	}
+	return b.String()
+}
+
+// Render the record data of a record, including its priority where the type carries one
+func zoneFileRdata(record client.DnsRecord) string {
+	switch strings.ToUpper(record.Type) {
+	case "TXT":
+		return dnstypes.FormatTXTValue(dnstypes.ParseTXTValue(record.Destination))
+	case "CNAME", "NS":
+		return absoluteTarget(record.Destination)
+	case "MX":
+		return priorityOrZero(record.Priority) + " " + absoluteTarget(record.Destination)
+	case "SRV":
+		// Netcup keeps the priority separate from "<weight> <port> <target>"
+		fields := strings.Fields(record.Destination)
+		if len(fields) == 3 {
+			fields[2] = absoluteTarget(fields[2])
+		}
+		return priorityOrZero(record.Priority) + " " + strings.Join(fields, " ")
+	default:
+		return record.Destination
+	}
+}
+
+func priorityOrZero(priority string) string {
+	if priority == "" {
+		return "0"
+	}
+	return priority
+}
+
+// Qualify a target name so it isn't interpreted relative to $ORIGIN
+func absoluteTarget(target string) string {
+	if target == "@" || strings.HasSuffix(target, ".") {
+		return target
+	}
+	return target + "."
+}
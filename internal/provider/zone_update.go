@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
+)
+
+// Serial of a zone before a write, to wait for after it
+type zoneUpdate struct {
+	domainname string
+	serial     string
+	wait       bool
+}
+
+// Read the serial of the zone before writing to it, if wait_for_zone_update is enabled
+func beginZoneUpdate(ctx context.Context, c *client.CCPClient, domainname string, diags *diag.Diagnostics) zoneUpdate {
+	serial, wait, err := c.ZoneSerialBeforeWrite(ctx, domainname)
+	if err != nil {
+		diags.AddWarning(
+			"Not waiting for zone update",
+			"Could not read the serial of zone "+domainname+" before writing: "+err.Error(),
+		)
+		return zoneUpdate{}
+	}
+
+	tflog.Trace(ctx, "Zone serial before write", map[string]interface{}{"domainname": domainname, "serial": serial, "wait": wait})
+	return zoneUpdate{domainname: domainname, serial: serial, wait: wait}
+}
+
+// Wait for the serial of the zone to increase after a successful write
+func (u zoneUpdate) await(ctx context.Context, c *client.CCPClient, diags *diag.Diagnostics) {
+	if !u.wait {
+		return
+	}
+
+	err := c.WaitForZoneUpdate(ctx, u.domainname, u.serial)
+	if errors.Is(err, client.ErrZoneNotUpdated) {
+		diags.AddWarning(
+			"Zone "+u.domainname+" not updated",
+			"The records were written, but the published zone did not change in time: "+err.Error()+
+				". The change may still be pending at Netcup.",
+		)
+		return
+	}
+	if err != nil {
+		diags.AddWarning(
+			"Could not wait for zone update",
+			"The records were written, but the serial of zone "+u.domainname+" could not be read: "+err.Error(),
+		)
+	}
+}
@@ -0,0 +1,152 @@
+package resolver
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Time between checks of Checker.Wait if no PollInterval is given
+const DefaultPollInterval = 5 * time.Second
+
+// Checker tells whether a record propagated, i.e. enough resolvers serve the
+// expected answers. Lookups use TCP if a UDP response is truncated.
+type Checker struct {
+	// Nameservers (host or host:port) to query. Defaults to the authoritative nameservers of the name.
+	Resolvers []string
+	// Time between checks of Wait. Defaults to DefaultPollInterval.
+	PollInterval time.Duration
+	// Number of resolvers that must serve the expected answers. Defaults to all of them.
+	Threshold int
+}
+
+// Answer of a single resolver. A failed lookup, e.g. SERVFAIL, is reported in
+// Err instead of failing the whole check.
+type ResolverStatus struct {
+	Nameserver string
+	Result     *Result
+	Err        error
+	Matches    bool
+}
+
+// Result of a check, with the resolvers in the order they were given
+type Status struct {
+	Resolvers []ResolverStatus
+	Matching  int
+	Required  int
+}
+
+// Whether at least the required number of resolvers serve the expected answers
+func (s Status) Consistent() bool {
+	return s.Matching >= s.Required
+}
+
+// Share of resolvers serving the expected answers, from 0 to 100
+func (s Status) Percentage() float64 {
+	if len(s.Resolvers) == 0 {
+		return 0
+	}
+	return float64(s.Matching) * 100 / float64(len(s.Resolvers))
+}
+
+// Check queries every resolver once. expected is compared with the answers in
+// the format of Lookup, an empty expected matches a name without records.
+// Fails only if no resolvers are given and the authoritative ones can't be found.
+func (c Checker) Check(ctx context.Context, name, recordType string, expected []string) (Status, error) {
+	resolvers := c.Resolvers
+	if len(resolvers) == 0 {
+		var err error
+		resolvers, err = Authoritative(ctx, name)
+		if err != nil {
+			return Status{}, err
+		}
+	}
+
+	want := append([]string(nil), expected...)
+	sort.Strings(want)
+
+	status := Status{
+		Resolvers: make([]ResolverStatus, len(resolvers)),
+		Required:  c.required(len(resolvers)),
+	}
+	var wg sync.WaitGroup
+	for i, nameserver := range resolvers {
+		wg.Add(1)
+		go func(i int, nameserver string) {
+			defer wg.Done()
+			result, err := Lookup(ctx, name, recordType, nameserver)
+			status.Resolvers[i] = ResolverStatus{
+				Nameserver: nameserver,
+				Result:     result,
+				Err:        err,
+				Matches:    err == nil && equalAnswers(result.Answers, want),
+			}
+		}(i, nameserver)
+	}
+	wg.Wait()
+
+	for _, r := range status.Resolvers {
+		if r.Matches {
+			status.Matching++
+		}
+	}
+	return status, nil
+}
+
+// Wait checks every PollInterval until the status is consistent. If ctx ends
+// first, the status of the last check completed before is returned along with
+// the error of ctx, as lookups cut short by ctx tell nothing.
+func (c Checker) Wait(ctx context.Context, name, recordType string, expected []string) (Status, error) {
+	interval := c.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	var last Status
+	for {
+		status, err := c.Check(ctx, name, recordType, expected)
+		if err == nil && status.Consistent() {
+			return status, nil
+		}
+		if ctx.Err() != nil {
+			if last.Resolvers == nil {
+				last = status
+			}
+			return last, ctx.Err()
+		}
+		if err != nil {
+			return status, err
+		}
+		last = status
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return last, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Threshold limited to the number of resolvers
+func (c Checker) required(resolvers int) int {
+	if c.Threshold <= 0 || c.Threshold > resolvers {
+		return resolvers
+	}
+	return c.Threshold
+}
+
+// Compare sorted answers
+func equalAnswers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,192 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/svetob/terraform-provider-netcupdns/internal/resolver/resolvertest"
+)
+
+var (
+	newRecord = resolvertest.Record{Name: "www.example.test", Type: "A", Data: "192.0.2.2"}
+	oldRecord = resolvertest.Record{Name: "www.example.test", Type: "A", Data: "192.0.2.1"}
+)
+
+// Addresses of servers serving the given records, one server each
+func testResolvers(t *testing.T, records ...resolvertest.Record) ([]string, []*resolvertest.Server) {
+	t.Helper()
+	var addrs []string
+	var servers []*resolvertest.Server
+	for _, record := range records {
+		server := newTestServer(t, record)
+		addrs = append(addrs, server.Addr)
+		servers = append(servers, server)
+	}
+	return addrs, servers
+}
+
+func TestCheckerThreshold(t *testing.T) {
+	// two of three resolvers serve the new address
+	resolvers, _ := testResolvers(t, newRecord, oldRecord, newRecord)
+
+	tests := []struct {
+		threshold  int
+		required   int
+		consistent bool
+	}{
+		{0, 3, false},
+		{-1, 3, false},
+		{1, 1, true},
+		{2, 2, true},
+		{3, 3, false},
+		// more than there are resolvers requires all of them
+		{5, 3, false},
+	}
+	for _, tt := range tests {
+		status, err := Checker{Resolvers: resolvers, Threshold: tt.threshold}.Check(context.Background(), "www.example.test", "A", []string{"192.0.2.2"})
+		if err != nil {
+			t.Fatalf("Check failed: %s", err)
+		}
+		if status.Matching != 2 || status.Required != tt.required || status.Consistent() != tt.consistent {
+			t.Errorf("threshold %d: %d of %d required matching, consistent %t, want 2 of %d, %t",
+				tt.threshold, status.Matching, status.Required, status.Consistent(), tt.required, tt.consistent)
+		}
+		if p := status.Percentage(); p < 66.6 || p > 66.7 {
+			t.Errorf("threshold %d: percentage %f, want 66.7", tt.threshold, p)
+		}
+		// resolvers in the order given
+		for i, want := range []bool{true, false, true} {
+			r := status.Resolvers[i]
+			if r.Nameserver != resolvers[i] || r.Matches != want || r.Err != nil {
+				t.Errorf("threshold %d: resolver %d is %+v, want %s matching %t", tt.threshold, i, r, resolvers[i], want)
+			}
+		}
+	}
+}
+
+func TestCheckerExpectedAnswers(t *testing.T) {
+	server := newTestServer(t,
+		resolvertest.Record{Name: "example.test", Type: "A", Data: "192.0.2.2"},
+		resolvertest.Record{Name: "example.test", Type: "A", Data: "192.0.2.1"},
+	)
+	checker := Checker{Resolvers: []string{server.Addr}}
+
+	tests := []struct {
+		name     string
+		expected []string
+		matches  bool
+	}{
+		{"example.test", []string{"192.0.2.1", "192.0.2.2"}, true},
+		// order of the expected answers doesn't matter
+		{"example.test", []string{"192.0.2.2", "192.0.2.1"}, true},
+		{"example.test", []string{"192.0.2.1"}, false},
+		{"example.test", []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"}, false},
+		{"example.test", nil, false},
+		// nothing expected matches a removed record
+		{"missing.example.test", nil, true},
+		{"missing.example.test", []string{"192.0.2.1"}, false},
+	}
+	for _, tt := range tests {
+		status, err := checker.Check(context.Background(), tt.name, "A", tt.expected)
+		if err != nil {
+			t.Fatalf("Check failed: %s", err)
+		}
+		if got := status.Resolvers[0].Matches; got != tt.matches || status.Consistent() != tt.matches {
+			t.Errorf("%s with expected %v matches: %t, want %t", tt.name, tt.expected, got, tt.matches)
+		}
+	}
+}
+
+// A resolver that doesn't answer counts as not matching without failing the check
+func TestCheckerUnresponsiveResolver(t *testing.T) {
+	resolvers, servers := testResolvers(t, newRecord, newRecord)
+	servers[1].SetSilent(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	status, err := Checker{Resolvers: resolvers, Threshold: 1}.Check(ctx, "www.example.test", "A", []string{"192.0.2.2"})
+	if err != nil {
+		t.Fatalf("Check failed: %s", err)
+	}
+	if !status.Resolvers[0].Matches || status.Resolvers[1].Matches || status.Resolvers[1].Err == nil {
+		t.Errorf("got statuses %+v, want the first matching and an error of the second", status.Resolvers)
+	}
+	if !status.Consistent() {
+		t.Errorf("one of the required one resolver matches, but the status isn't consistent")
+	}
+}
+
+// Truncated UDP responses are retried over TCP
+func TestCheckerTCPFallback(t *testing.T) {
+	resolvers, servers := testResolvers(t, newRecord)
+	servers[0].SetTruncated(true)
+
+	status, err := Checker{Resolvers: resolvers}.Check(context.Background(), "www.example.test", "A", []string{"192.0.2.2"})
+	if err != nil {
+		t.Fatalf("Check failed: %s", err)
+	}
+	if !status.Consistent() {
+		t.Errorf("got status %+v, want the answer received over TCP", status.Resolvers)
+	}
+	if servers[0].TCPQueries() == 0 {
+		t.Error("no query was retried over TCP")
+	}
+}
+
+// Wait polls until enough resolvers serve the new answer
+func TestCheckerWait(t *testing.T) {
+	resolvers, servers := testResolvers(t, newRecord, oldRecord, oldRecord)
+	checker := Checker{Resolvers: resolvers, PollInterval: 20 * time.Millisecond, Threshold: 2}
+
+	// the second resolver picks up the change after it was asked twice
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for servers[1].Queries() < 2 {
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+		servers[1].SetRecords(newRecord)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	status, err := checker.Wait(ctx, "www.example.test", "A", []string{"192.0.2.2"})
+	if err != nil {
+		t.Fatalf("Wait failed: %s", err)
+	}
+	if !status.Consistent() || status.Matching != 2 {
+		t.Errorf("Wait returned %d matching resolvers, want 2", status.Matching)
+	}
+	if n := servers[0].Queries(); n < 2 {
+		t.Errorf("Wait checked %d times, want at least twice", n)
+	}
+}
+
+// Wait gives up when ctx ends and returns the last status
+func TestCheckerWaitTimeout(t *testing.T) {
+	resolvers, servers := testResolvers(t, newRecord, oldRecord)
+	checker := Checker{Resolvers: resolvers, PollInterval: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	status, err := checker.Wait(ctx, "www.example.test", "A", []string{"192.0.2.2"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait returned error %v, want the deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Wait took %s despite the deadline", elapsed)
+	}
+	if len(status.Resolvers) != 2 || status.Matching != 1 || status.Consistent() {
+		t.Errorf("Wait returned status %+v, want the last check with 1 of 2 matching", status)
+	}
+	if n := servers[1].Queries(); n < 3 {
+		t.Errorf("Wait checked %d times in 100ms with a poll interval of 10ms", n)
+	}
+}
@@ -0,0 +1,135 @@
+// Package resolver performs live DNS lookups against specific nameservers.
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Result of a lookup. Answers are normalized and sorted, see Lookup.
+type Result struct {
+	Answers    []string
+	NXDomain   bool
+	Nameserver string
+}
+
+// Lookup queries nameserver ("host" or "host:port", the system resolver if empty)
+// for records of the given type. Answers are rendered like record data in a zone
+// file: lowercase absolute names for CNAME/NS, "<pref> <host>" for MX,
+// "<priority> <weight> <port> <target>" for SRV and the joined value for TXT.
+// A non-existing name is not an error but reported via Result.NXDomain.
+func Lookup(ctx context.Context, name, recordType, nameserver string) (*Result, error) {
+	r := newResolver(nameserver)
+	result := &Result{Nameserver: nameserver}
+
+	var answers []string
+	var err error
+	switch strings.ToUpper(recordType) {
+	case "A", "AAAA":
+		var addrs []net.IPAddr
+		addrs, err = r.LookupIPAddr(ctx, name)
+		for _, addr := range addrs {
+			isV4 := addr.IP.To4() != nil
+			if isV4 == (strings.ToUpper(recordType) == "A") {
+				answers = append(answers, addr.IP.String())
+			}
+		}
+	case "CNAME":
+		var cname string
+		cname, err = r.LookupCNAME(ctx, name)
+		if err == nil && !strings.EqualFold(cname, Fqdn(name)) {
+			answers = append(answers, strings.ToLower(cname))
+		}
+	case "MX":
+		var mxs []*net.MX
+		mxs, err = r.LookupMX(ctx, name)
+		for _, mx := range mxs {
+			answers = append(answers, strconv.Itoa(int(mx.Pref))+" "+strings.ToLower(mx.Host))
+		}
+	case "NS":
+		var nss []*net.NS
+		nss, err = r.LookupNS(ctx, name)
+		for _, ns := range nss {
+			answers = append(answers, strings.ToLower(ns.Host))
+		}
+	case "TXT":
+		answers, err = r.LookupTXT(ctx, name)
+	case "SRV":
+		var srvs []*net.SRV
+		_, srvs, err = r.LookupSRV(ctx, "", "", name)
+		for _, srv := range srvs {
+			answers = append(answers, fmt.Sprintf("%d %d %d %s", srv.Priority, srv.Weight, srv.Port, strings.ToLower(srv.Target)))
+		}
+	default:
+		return nil, fmt.Errorf("lookups of type %s are not supported", recordType)
+	}
+
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			result.NXDomain = true
+			result.Answers = []string{}
+			return result, nil
+		}
+		return nil, err
+	}
+
+	sort.Strings(answers)
+	result.Answers = answers
+	if result.Answers == nil {
+		result.Answers = []string{}
+	}
+	return result, nil
+}
+
+// Authoritative returns the nameservers of the zone containing name, found by
+// walking up the labels of name until an NS record set exists.
+func Authoritative(ctx context.Context, name string) ([]string, error) {
+	labels := strings.Split(strings.TrimSuffix(name, "."), ".")
+	for i := 0; i < len(labels)-1; i++ {
+		zone := strings.Join(labels[i:], ".")
+		nss, err := net.DefaultResolver.LookupNS(ctx, zone)
+		if err != nil || len(nss) == 0 {
+			continue
+		}
+		servers := make([]string, 0, len(nss))
+		for _, ns := range nss {
+			servers = append(servers, strings.TrimSuffix(ns.Host, "."))
+		}
+		sort.Strings(servers)
+		return servers, nil
+	}
+	return nil, fmt.Errorf("could not find authoritative nameservers for %s", name)
+}
+
+// Fqdn returns name with a trailing dot
+func Fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+func newResolver(nameserver string) *net.Resolver {
+	if nameserver == "" {
+		return net.DefaultResolver
+	}
+
+	address := nameserver
+	if _, _, err := net.SplitHostPort(nameserver); err != nil {
+		address = net.JoinHostPort(nameserver, "53")
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, address)
+		},
+	}
+}
@@ -0,0 +1,120 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/svetob/terraform-provider-netcupdns/internal/resolver/resolvertest"
+)
+
+var dkim = "v=DKIM1; k=rsa; p=" + strings.Repeat("A", 300)
+
+func newTestServer(t *testing.T, records ...resolvertest.Record) *resolvertest.Server {
+	t.Helper()
+	server, err := resolvertest.NewServer(records...)
+	if err != nil {
+		t.Fatalf("starting DNS server: %s", err)
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLookup(t *testing.T) {
+	server := newTestServer(t,
+		resolvertest.Record{Name: "example.test", Type: "A", Data: "192.0.2.2"},
+		resolvertest.Record{Name: "example.test", Type: "A", Data: "192.0.2.1"},
+		resolvertest.Record{Name: "example.test", Type: "AAAA", Data: "2001:db8:0:0::1"},
+		resolvertest.Record{Name: "example.test", Type: "MX", Data: "20 Backup.example.test"},
+		resolvertest.Record{Name: "example.test", Type: "MX", Data: "10 mail.example.test"},
+		resolvertest.Record{Name: "example.test", Type: "NS", Data: "ns1.example.test"},
+		resolvertest.Record{Name: "example.test", Type: "TXT", Data: "v=spf1 mx -all"},
+		resolvertest.Record{Name: "dkim._domainkey.example.test", Type: "TXT", Data: dkim},
+		resolvertest.Record{Name: "www.example.test", Type: "CNAME", Data: "example.test"},
+		resolvertest.Record{Name: "_sip._tcp.example.test", Type: "SRV", Data: "10 5 5060 sip.example.test"},
+	)
+
+	tests := []struct {
+		name       string
+		recordType string
+		answers    []string
+		nxdomain   bool
+	}{
+		{"example.test", "A", []string{"192.0.2.1", "192.0.2.2"}, false},
+		{"example.test", "aaaa", []string{"2001:db8::1"}, false},
+		{"example.test", "MX", []string{"10 mail.example.test.", "20 backup.example.test."}, false},
+		{"example.test", "NS", []string{"ns1.example.test."}, false},
+		{"example.test", "TXT", []string{"v=spf1 mx -all"}, false},
+		{"dkim._domainkey.example.test", "TXT", []string{dkim}, false},
+		{"www.example.test", "CNAME", []string{"example.test."}, false},
+		{"www.example.test", "A", []string{"192.0.2.1", "192.0.2.2"}, false},
+		{"_sip._tcp.example.test", "SRV", []string{"10 5 5060 sip.example.test."}, false},
+		// the net package reports a name without records of the type like a missing name
+		{"example.test", "SRV", []string{}, true},
+		{"missing.example.test", "A", []string{}, true},
+		{"missing.example.test", "TXT", []string{}, true},
+	}
+
+	for _, tt := range tests {
+		result, err := Lookup(context.Background(), tt.name, tt.recordType, server.Addr)
+		if err != nil {
+			t.Errorf("Lookup(%s %s) failed: %s", tt.name, tt.recordType, err)
+			continue
+		}
+		if !equal(result.Answers, tt.answers) {
+			t.Errorf("Lookup(%s %s) = %q, want %q", tt.name, tt.recordType, result.Answers, tt.answers)
+		}
+		if result.NXDomain != tt.nxdomain {
+			t.Errorf("Lookup(%s %s) NXDomain = %t, want %t", tt.name, tt.recordType, result.NXDomain, tt.nxdomain)
+		}
+		if result.Nameserver != server.Addr {
+			t.Errorf("Lookup(%s %s) Nameserver = %s, want %s", tt.name, tt.recordType, result.Nameserver, server.Addr)
+		}
+	}
+}
+
+func TestLookupUnsupportedType(t *testing.T) {
+	server := newTestServer(t)
+	if _, err := Lookup(context.Background(), "example.test", "CAA", server.Addr); err == nil {
+		t.Error("Lookup of type CAA succeeded")
+	}
+	if n := server.Queries(); n != 0 {
+		t.Errorf("sent %d queries for an unsupported type", n)
+	}
+}
+
+func TestLookupTimeout(t *testing.T) {
+	server := newTestServer(t, resolvertest.Record{Name: "example.test", Type: "A", Data: "192.0.2.1"})
+	server.SetSilent(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := Lookup(ctx, "example.test", "A", server.Addr); err == nil {
+		t.Error("Lookup against a silent server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Lookup took %s despite the timeout", elapsed)
+	}
+}
+
+func TestFqdn(t *testing.T) {
+	for name, want := range map[string]string{"example.test": "example.test.", "example.test.": "example.test."} {
+		if got := Fqdn(name); got != want {
+			t.Errorf("Fqdn(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
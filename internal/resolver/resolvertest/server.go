@@ -0,0 +1,382 @@
+// Package resolvertest provides an in-process DNS server for tests of lookups.
+package resolvertest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Record served by a Server. Data is given like the answers of resolver.Lookup:
+// "<pref> <host>" for MX, "<priority> <weight> <port> <target>" for SRV and the
+// unquoted value for TXT, which is split into character-strings of 255 bytes.
+type Record struct {
+	Name string
+	Type string
+	Data string
+}
+
+// Server answers UDP and TCP queries for its records authoritatively. Names without
+// records are answered with NXDOMAIN, CNAME records are followed within the server.
+type Server struct {
+	// Address (host:port) to use as nameserver, for UDP and TCP
+	Addr string
+
+	conn       net.PacketConn
+	listener   net.Listener
+	mu         sync.Mutex
+	records    []Record
+	silent     bool
+	truncate   bool
+	failure    bool
+	queries    int
+	tcpQueries int
+	tcpConns   map[net.Conn]bool
+	done       sync.WaitGroup
+}
+
+// NewServer starts a server on a local port. Close it when done.
+func NewServer(records ...Record) (*Server, error) {
+	// the TCP port must be the one of UDP, which may be taken
+	var lastErr error
+	for i := 0; i < 10; i++ {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		listener, err := net.Listen("tcp", conn.LocalAddr().String())
+		if err != nil {
+			conn.Close()
+			lastErr = err
+			continue
+		}
+		s := &Server{
+			Addr:     conn.LocalAddr().String(),
+			conn:     conn,
+			listener: listener,
+			records:  records,
+			tcpConns: make(map[net.Conn]bool),
+		}
+		s.done.Add(2)
+		go s.serve()
+		go s.serveTCP()
+		return s, nil
+	}
+	return nil, lastErr
+}
+
+// Replace the records served
+func (s *Server) SetRecords(records ...Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = records
+}
+
+// Drop queries without answering, e.g. to test timeouts
+func (s *Server) SetSilent(silent bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.silent = silent
+}
+
+// Answer UDP queries with truncated responses without answers, so resolvers
+// retry over TCP like for responses too large for UDP
+func (s *Server) SetTruncated(truncate bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.truncate = truncate
+}
+
+// Answer queries with SERVFAIL, like a resolver failing to reach the authoritative nameservers
+func (s *Server) SetServerFailure(failure bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failure = failure
+}
+
+// Number of queries received, over UDP and TCP
+func (s *Server) Queries() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queries
+}
+
+// Number of queries received over TCP
+func (s *Server) TCPQueries() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tcpQueries
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	s.conn.Close()
+	s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.tcpConns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.done.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.done.Done()
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.queries++
+		silent, truncate := s.silent, s.truncate
+		s.mu.Unlock()
+		if silent {
+			continue
+		}
+
+		response, err := s.answer(buf[:n], truncate)
+		if err != nil {
+			continue
+		}
+		s.conn.WriteTo(response, addr)
+	}
+}
+
+// Serve queries over TCP, each prefixed with its length like responses
+func (s *Server) serveTCP() {
+	defer s.done.Done()
+	var conns sync.WaitGroup
+	defer conns.Wait()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.tcpConns[conn] = true
+		s.mu.Unlock()
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			defer func() {
+				conn.Close()
+				s.mu.Lock()
+				delete(s.tcpConns, conn)
+				s.mu.Unlock()
+			}()
+			for {
+				var length uint16
+				if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+					return
+				}
+				query := make([]byte, length)
+				if _, err := io.ReadFull(conn, query); err != nil {
+					return
+				}
+
+				s.mu.Lock()
+				s.queries++
+				s.tcpQueries++
+				silent := s.silent
+				s.mu.Unlock()
+				if silent {
+					continue
+				}
+
+				response, err := s.answer(query, false)
+				if err != nil {
+					return
+				}
+				if err := binary.Write(conn, binary.BigEndian, uint16(len(response))); err != nil {
+					return
+				}
+				if _, err := conn.Write(response); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+func (s *Server) answer(query []byte, truncate bool) ([]byte, error) {
+	var request dnsmessage.Message
+	if err := request.Unpack(query); err != nil {
+		return nil, err
+	}
+	if len(request.Questions) != 1 {
+		return nil, fmt.Errorf("expected one question, got %d", len(request.Questions))
+	}
+	question := request.Questions[0]
+
+	response := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:            request.ID,
+			Response:      true,
+			Authoritative: true,
+		},
+		Questions: request.Questions,
+	}
+	if truncate {
+		response.Truncated = true
+		return response.Pack()
+	}
+	s.mu.Lock()
+	failure := s.failure
+	s.mu.Unlock()
+	if failure {
+		response.Authoritative = false
+		response.RCode = dnsmessage.RCodeServerFailure
+		return response.Pack()
+	}
+
+	name := strings.ToLower(question.Name.String())
+	if !s.exists(name) {
+		response.RCode = dnsmessage.RCodeNameError
+		return response.Pack()
+	}
+
+	// follow CNAME records, limited against loops
+	for i := 0; i < 8; i++ {
+		if question.Type != dnsmessage.TypeCNAME {
+			if cname := s.lookup(name, "CNAME"); len(cname) > 0 {
+				resource, err := newResource(name, cname[0])
+				if err != nil {
+					return nil, err
+				}
+				response.Answers = append(response.Answers, resource)
+				name = fqdn(cname[0].Data)
+				continue
+			}
+		}
+		break
+	}
+
+	for _, record := range s.lookup(name, typeName(question.Type)) {
+		resource, err := newResource(name, record)
+		if err != nil {
+			return nil, err
+		}
+		response.Answers = append(response.Answers, resource)
+	}
+	return response.Pack()
+}
+
+func (s *Server) exists(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, record := range s.records {
+		if fqdn(record.Name) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) lookup(name, recordType string) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []Record
+	for _, record := range s.records {
+		if fqdn(record.Name) == name && strings.EqualFold(record.Type, recordType) {
+			result = append(result, record)
+		}
+	}
+	return result
+}
+
+func typeName(t dnsmessage.Type) string {
+	return strings.TrimPrefix(t.String(), "Type")
+}
+
+func fqdn(name string) string {
+	name = strings.ToLower(name)
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+func newResource(name string, record Record) (dnsmessage.Resource, error) {
+	header := dnsmessage.ResourceHeader{Class: dnsmessage.ClassINET, TTL: 60}
+	var err error
+	if header.Name, err = dnsmessage.NewName(name); err != nil {
+		return dnsmessage.Resource{}, err
+	}
+
+	body, err := newBody(record)
+	if err != nil {
+		return dnsmessage.Resource{}, fmt.Errorf("record %s %s %q: %w", record.Name, record.Type, record.Data, err)
+	}
+	return dnsmessage.Resource{Header: header, Body: body}, nil
+}
+
+func newBody(record Record) (dnsmessage.ResourceBody, error) {
+	fields := strings.Fields(record.Data)
+	switch strings.ToUpper(record.Type) {
+	case "A":
+		ip := net.ParseIP(record.Data).To4()
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IPv4 address")
+		}
+		var a [4]byte
+		copy(a[:], ip)
+		return &dnsmessage.AResource{A: a}, nil
+	case "AAAA":
+		ip := net.ParseIP(record.Data)
+		if ip == nil || ip.To4() != nil {
+			return nil, fmt.Errorf("invalid IPv6 address")
+		}
+		var aaaa [16]byte
+		copy(aaaa[:], ip)
+		return &dnsmessage.AAAAResource{AAAA: aaaa}, nil
+	case "CNAME":
+		target, err := dnsmessage.NewName(fqdn(record.Data))
+		return &dnsmessage.CNAMEResource{CNAME: target}, err
+	case "NS":
+		target, err := dnsmessage.NewName(fqdn(record.Data))
+		return &dnsmessage.NSResource{NS: target}, err
+	case "MX":
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("expected \"<pref> <host>\"")
+		}
+		pref, err := strconv.ParseUint(fields[0], 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		target, err := dnsmessage.NewName(fqdn(fields[1]))
+		return &dnsmessage.MXResource{Pref: uint16(pref), MX: target}, err
+	case "SRV":
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("expected \"<priority> <weight> <port> <target>\"")
+		}
+		var numbers [3]uint16
+		for i := range numbers {
+			n, err := strconv.ParseUint(fields[i], 10, 16)
+			if err != nil {
+				return nil, err
+			}
+			numbers[i] = uint16(n)
+		}
+		target, err := dnsmessage.NewName(fqdn(fields[3]))
+		return &dnsmessage.SRVResource{Priority: numbers[0], Weight: numbers[1], Port: numbers[2], Target: target}, err
+	case "TXT":
+		var chunks []string
+		value := record.Data
+		for len(value) > 255 {
+			chunks = append(chunks, value[:255])
+			value = value[255:]
+		}
+		return &dnsmessage.TXTResource{TXT: append(chunks, value)}, nil
+	default:
+		return nil, fmt.Errorf("type not supported")
+	}
+}
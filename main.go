@@ -3,9 +3,15 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"runtime"
+	"runtime/debug"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/svetob/terraform-provider-netcupdns/internal/client"
 	"github.com/svetob/terraform-provider-netcupdns/internal/provider"
 )
 
@@ -20,19 +26,69 @@ var (
 )
 
 func main() {
-	var debug bool
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			os.Exit(command(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
 
-	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
-	flag.Parse()
+	debugMode, exit := parseFlags(os.Args[1:], os.Stdout)
+	if exit {
+		return
+	}
 
+	// protocol 6 is required by provider functions and matches terraform-registry-manifest.json
 	opts := providerserver.ServeOpts{
-		Address: "registry.terraform.io/svetob/netcupdns",
-		Debug:   debug,
+		Address:         "registry.terraform.io/svetob/netcupdns",
+		Debug:           debugMode,
+		ProtocolVersion: 6,
 	}
 
 	err := providerserver.Serve(context.Background(), provider.New, opts)
 
+	// log out the sessions shared by the provider configurations of this process
+	if closeErr := client.Sessions.Close(context.Background()); closeErr != nil {
+		log.Printf("[WARN] Could not log out: %s", closeErr)
+	}
+
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 }
+
+// Parse the flags of the plugin server. With -version the build information is
+// printed to w and exit is set, as the plugin server must not start then.
+// Invalid flags end the process like flag.Parse does.
+func parseFlags(args []string, w io.Writer) (debugMode, exit bool) {
+	var showVersion bool
+
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	flags.BoolVar(&debugMode, "debug", false, "set to true to run the provider with support for debuggers like delve")
+	flags.BoolVar(&showVersion, "version", false, "print version information and exit")
+	flags.Parse(args)
+
+	if showVersion {
+		printVersion(w)
+		return debugMode, true
+	}
+	return debugMode, false
+}
+
+// Print the build information of the provider binary
+func printVersion(w io.Writer) {
+	frameworkVersion := "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == "github.com/hashicorp/terraform-plugin-framework" {
+				frameworkVersion = dep.Version
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "terraform-provider-netcupdns %s\n", version)
+	if commit != "" {
+		fmt.Fprintf(w, "commit: %s\n", commit)
+	}
+	fmt.Fprintf(w, "go: %s\n", runtime.Version())
+	fmt.Fprintf(w, "terraform-plugin-framework: %s\n", frameworkVersion)
+}
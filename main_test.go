@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseFlagsVersion(t *testing.T) {
+	defer func(v, c string) { version, commit = v, c }(version, commit)
+	version, commit = "1.2.3", "0123abc"
+
+	var output bytes.Buffer
+	_, exit := parseFlags([]string{"-version"}, &output)
+	if !exit {
+		t.Error("-version does not exit before starting the plugin server")
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	want := []string{
+		"terraform-provider-netcupdns 1.2.3",
+		"commit: 0123abc",
+		"go: " + runtime.Version(),
+	}
+	if len(lines) != 4 || strings.Join(lines[:3], "\n") != strings.Join(want, "\n") {
+		t.Fatalf("-version printed %q, want %q and the framework version", lines, want)
+	}
+	if !strings.HasPrefix(lines[3], "terraform-plugin-framework: ") {
+		t.Errorf("-version printed %q, want the framework version", lines[3])
+	}
+}
+
+func TestParseFlagsVersionWithoutCommit(t *testing.T) {
+	defer func(v, c string) { version, commit = v, c }(version, commit)
+	version, commit = "dev", ""
+
+	var output bytes.Buffer
+	parseFlags([]string{"-version"}, &output)
+	if strings.Contains(output.String(), "commit") {
+		t.Errorf("-version printed an empty commit: %q", output.String())
+	}
+}
+
+func TestParseFlagsServe(t *testing.T) {
+	tests := []struct {
+		args  []string
+		debug bool
+	}{
+		{nil, false},
+		{[]string{"-debug"}, true},
+		{[]string{"-debug=false"}, false},
+	}
+	for _, tt := range tests {
+		var output bytes.Buffer
+		debugMode, exit := parseFlags(tt.args, &output)
+		if exit || debugMode != tt.debug {
+			t.Errorf("parseFlags(%q) = %t, %t, want debug %t without exiting", tt.args, debugMode, exit, tt.debug)
+		}
+		if output.Len() > 0 {
+			t.Errorf("parseFlags(%q) printed %q, which would break the plugin handshake", tt.args, output.String())
+		}
+	}
+}